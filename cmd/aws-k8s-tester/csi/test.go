@@ -1,9 +1,12 @@
 package csi
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"reflect"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -12,6 +15,7 @@ import (
 	"github.com/aws/aws-k8s-tester/internal/ec2"
 	"github.com/aws/aws-k8s-tester/internal/ssh"
 	"github.com/aws/aws-k8s-tester/pkg/fileutil"
+	"github.com/aws/aws-k8s-tester/pkg/junit"
 
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
@@ -28,6 +32,7 @@ func newTest() *cobra.Command {
 	cmd.PersistentFlags().DurationVar(&timeout, "timeout", 20*time.Minute, "e2e test timeout")
 	cmd.PersistentFlags().StringVar(&vpcID, "vpc-id", "vpc-0c59620d91b2e1f92", "existing VPC ID to use (provided default VPC ID belongs to aws-k8s-tester test account, leave empty to create a new one)")
 	cmd.PersistentFlags().BoolVar(&journalctlLogs, "journalctl-logs", false, "true to get journalctl logs from EC2 instance")
+	cmd.PersistentFlags().StringVar(&artifactsDir, "artifacts-dir", os.Getenv("ARTIFACTS"), "directory to write JUnit XML and JSON test results (leave empty to skip)")
 
 	cmd.AddCommand(
 		newTestIntegration(),
@@ -40,6 +45,7 @@ var branchOrPR string
 var timeout time.Duration
 var vpcID string
 var journalctlLogs bool
+var artifactsDir string
 
 /*
 go install -v ./cmd/aws-k8s-tester
@@ -60,19 +66,24 @@ func newTestIntegration() *cobra.Command {
 }
 
 func testIntegrationFunc(cmd *cobra.Command, args []string) {
+	start := time.Now().UTC()
+
 	credEnv := "AWS_SHARED_CREDENTIALS_FILE"
 	if os.Getenv(credEnv) == "" || !fileutil.Exist(os.Getenv(credEnv)) {
 		fmt.Fprintln(os.Stderr, "no AWS_SHARED_CREDENTIALS_FILE found")
+		saveTestResult(start, errors.New("no AWS_SHARED_CREDENTIALS_FILE found"), "")
 		os.Exit(1)
 	}
 	if timeout == time.Duration(0) {
 		fmt.Fprintf(os.Stderr, "no timeout specified (%q)\n", timeout)
+		saveTestResult(start, fmt.Errorf("no timeout specified (%q)", timeout), "")
 		os.Exit(1)
 	}
 
 	lg, err := zap.NewProduction()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to create logger (%v)\n", err)
+		saveTestResult(start, fmt.Errorf("failed to create logger (%v)", err), "")
 		os.Exit(1)
 	}
 	lg.Info(
@@ -94,10 +105,12 @@ func testIntegrationFunc(cmd *cobra.Command, args []string) {
 	ec, err = ec2.NewDeployer(cfg)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to create EC2 deployer (%v)\n", err)
+		saveTestResult(start, fmt.Errorf("failed to create EC2 deployer (%v)", err), "")
 		os.Exit(1)
 	}
 	if err = ec.Create(); err != nil {
 		fmt.Fprintf(os.Stderr, "failed to create EC2 instance (%v)\n", err)
+		saveTestResult(start, fmt.Errorf("failed to create EC2 instance (%v)", err), "")
 		os.Exit(1)
 	}
 
@@ -112,6 +125,7 @@ func testIntegrationFunc(cmd *cobra.Command, args []string) {
 	})
 	if serr != nil {
 		fmt.Fprintf(os.Stderr, "failed to create SSH (%v)\n", err)
+		saveTestResult(start, fmt.Errorf("failed to create SSH (%v)", serr), "")
 		if terminateOnExit {
 			ec.Delete()
 		} else {
@@ -123,6 +137,7 @@ func testIntegrationFunc(cmd *cobra.Command, args []string) {
 
 	if err = sh.Connect(); err != nil {
 		fmt.Fprintf(os.Stderr, "failed to connect SSH (%v)\n", err)
+		saveTestResult(start, fmt.Errorf("failed to connect SSH (%v)", err), "")
 		if terminateOnExit {
 			ec.Delete()
 		} else {
@@ -140,6 +155,7 @@ ready:
 		select {
 		case <-timer.C:
 			fmt.Fprintf(os.Stderr, "test timed out (%v)\n", timeout)
+			saveTestResult(start, fmt.Errorf("test timed out (%v)", timeout), "")
 			if terminateOnExit {
 				ec.Delete()
 			} else {
@@ -158,6 +174,7 @@ ready:
 				sh.Close()
 				if serr := sh.Connect(); serr != nil {
 					fmt.Fprintf(os.Stderr, "failed to connect SSH (%v)\n", serr)
+					saveTestResult(start, fmt.Errorf("failed to connect SSH (%v)", serr), "")
 					if terminateOnExit {
 						ec.Delete()
 					} else {
@@ -188,6 +205,7 @@ ready:
 	)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to read /etc/environment (%v)\n", err)
+		saveTestResult(start, fmt.Errorf("failed to read /etc/environment (%v)", err), "")
 		if terminateOnExit {
 			ec.Delete()
 		} else {
@@ -215,6 +233,7 @@ ready:
 	if err != nil {
 		// handle "Process exited with status 2" error
 		fmt.Fprintf(os.Stderr, "CSI integration test FAILED (%v, %v)\n", err, reflect.TypeOf(err))
+		saveTestResult(start, fmt.Errorf("CSI integration test FAILED (%v)", err), string(out))
 		if terminateOnExit {
 			ec.Delete()
 		} else {
@@ -234,6 +253,7 @@ ready:
 	*/
 	if !strings.Contains(testOutput, "1 Passed") {
 		fmt.Fprintln(os.Stderr, "CSI integration test FAILED")
+		saveTestResult(start, errors.New("CSI integration test FAILED"), testOutput)
 		if terminateOnExit {
 			ec.Delete()
 		} else {
@@ -242,6 +262,8 @@ ready:
 		os.Exit(1)
 	}
 
+	saveTestResult(start, nil, testOutput)
+
 	if journalctlLogs {
 		// full journal logs (e.g. disk mounts)
 		lg.Info("fetching journal logs")
@@ -275,3 +297,39 @@ func isReady(txt string) bool {
 		(strings.Contains(txt, `Cloud-init v.`) &&
 			strings.Contains(txt, `finished at`))
 }
+
+// saveTestResult writes the CSI integration test result
+// to the artifacts directory, if any.
+func saveTestResult(start time.Time, err error, testOutput string) {
+	if artifactsDir == "" {
+		return
+	}
+	s := junit.NewSuite("csi-integration")
+	s.Add("integration", time.Now().UTC().Sub(start), err, parseGinkgoSummary(testOutput))
+	if serr := s.Save(artifactsDir); serr != nil {
+		fmt.Fprintf(os.Stderr, "failed to save test result to %q (%v)\n", artifactsDir, serr)
+	}
+}
+
+var ginkgoSummary = regexp.MustCompile(`(\d+) Passed \| (\d+) Failed \| (\d+) Pending \| (\d+) Skipped`)
+
+/*
+to parse:
+
+SUCCESS! -- 1 Passed | 0 Failed | 0 Pending | 0 Skipped
+*/
+func parseGinkgoSummary(txt string) map[string]float64 {
+	ms := ginkgoSummary.FindStringSubmatch(txt)
+	if len(ms) != 5 {
+		return nil
+	}
+	metrics := make(map[string]float64, 4)
+	for i, k := range []string{"passed", "failed", "pending", "skipped"} {
+		v, err := strconv.ParseFloat(ms[i+1], 64)
+		if err != nil {
+			return nil
+		}
+		metrics[k] = v
+	}
+	return metrics
+}
@@ -2,10 +2,14 @@ package eks
 
 import (
 	"context"
+	"crypto/tls"
+	"encoding/json"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
@@ -16,6 +20,7 @@ import (
 	humanize "github.com/dustin/go-humanize"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
 )
 
 func newIngress() *cobra.Command {
@@ -37,15 +42,26 @@ func newIngressServer() *cobra.Command {
 		Run:   ingressServerFunc,
 	}
 	cmd.PersistentFlags().StringVar(&ingressServerPort, "port", ":32030", "specify the ingress test server port")
+	cmd.PersistentFlags().StringVar(&ingressServerGRPCPort, "grpc-port", "", "specify the gRPC echo server port (empty to disable)")
 	cmd.PersistentFlags().IntVar(&ingressServerRoutes, "routes", 3, "specify the number of routes (e.g. /ingress-test-00001, /ingress-test-00002, and so on)")
 	cmd.PersistentFlags().IntVar(&ingressServerResponseSize, "response-size", 40*1024, "specify the server response size")
+	cmd.PersistentFlags().StringVar(&ingressServerFaults, "faults", "", "specify the faults to inject in JSON array (e.g. '[{\"route\":\"*\",\"latency-ms\":100}]')")
+	cmd.PersistentFlags().StringVar(&ingressServerFaultsPath, "faults-path", "", "specify the file path to read the faults in JSON array (overrides '--faults')")
+	cmd.PersistentFlags().DurationVar(&ingressServerDrainPeriod, "drain-period", 0, "specify the period to fail readiness and keep serving requests on SIGTERM, before shutting down (0 to shut down right away)")
+	cmd.PersistentFlags().DurationVar(&ingressServerShutdownTimeout, "shutdown-timeout", 10*time.Second, "specify the timeout to wait for in-flight requests on shutdown")
 	return cmd
 }
 
 var (
 	ingressServerPort         string
+	ingressServerGRPCPort     string
 	ingressServerRoutes       int
 	ingressServerResponseSize int
+	ingressServerFaults       string
+	ingressServerFaultsPath   string
+
+	ingressServerDrainPeriod     time.Duration
+	ingressServerShutdownTimeout time.Duration
 )
 
 func ingressServerFunc(cmd *cobra.Command, args []string) {
@@ -58,22 +74,36 @@ func ingressServerFunc(cmd *cobra.Command, args []string) {
 	lg.Info(
 		"starting ingress server",
 		zap.String("port", ingressServerPort),
+		zap.String("grpc-port", ingressServerGRPCPort),
 		zap.Int("routes", ingressServerRoutes),
 		zap.String("response-size", humanize.Bytes(uint64(ingressServerResponseSize))),
+		zap.Duration("drain-period", ingressServerDrainPeriod),
 	)
 
+	var faults []server.Fault
+	switch {
+	case ingressServerFaultsPath != "":
+		faults, err = server.ReadFaults(ingressServerFaultsPath)
+	case ingressServerFaults != "":
+		faults, err = server.ParseFaults([]byte(ingressServerFaults))
+	}
+	if err != nil {
+		lg.Fatal("failed to parse faults", zap.Error(err))
+	}
+
 	notifier := make(chan os.Signal, 1)
 	signal.Notify(notifier, syscall.SIGINT, syscall.SIGTERM)
 
 	rootCtx, rootCancel := context.WithCancel(context.Background())
+	health := server.NewHealth()
 	var mux *http.ServeMux
-	mux, err = server.NewMux(rootCtx, lg, ingressServerRoutes, ingressServerResponseSize)
+	mux, err = server.NewMux(rootCtx, lg, ingressServerRoutes, ingressServerResponseSize, faults, health)
 	if err != nil {
 		panic(err)
 	}
 	srv := &http.Server{
 		Addr:    ingressServerPort,
-		Handler: mux,
+		Handler: health.Track(mux),
 	}
 	errc := make(chan error)
 	go func() {
@@ -81,11 +111,51 @@ func ingressServerFunc(cmd *cobra.Command, args []string) {
 		errc <- srv.ListenAndServe()
 	}()
 
-	lg.Info("received signal, shutting down server", zap.String("signal", (<-notifier).String()))
+	var gs *grpc.Server
+	if ingressServerGRPCPort != "" {
+		var ln net.Listener
+		ln, err = net.Listen("tcp", ingressServerGRPCPort)
+		if err != nil {
+			lg.Fatal("failed to listen gRPC port", zap.Error(err))
+		}
+		gs = server.NewGRPCServer()
+		go func() {
+			lg.Info("started serving gRPC")
+			if serr := gs.Serve(ln); serr != nil {
+				lg.Warn("gRPC server stopped", zap.Error(serr))
+			}
+		}()
+	}
+
+	lg.Info("received signal, draining server", zap.String("signal", (<-notifier).String()))
+
+	// second signal stops draining
+	stopc := make(chan struct{})
+	go func() {
+		if sig, ok := <-notifier; ok {
+			lg.Warn("received signal while draining", zap.String("signal", sig.String()))
+			close(stopc)
+		}
+	}()
+	if err = server.Drain(lg, srv, health, ingressServerDrainPeriod, ingressServerShutdownTimeout, stopc); err != nil {
+		lg.Warn("failed to drain server", zap.Error(err))
+	}
 	rootCancel()
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
-	srv.Shutdown(ctx)
-	cancel()
+	if gs != nil {
+		// wait for in-flight gRPC streams up to the shutdown timeout
+		donec := make(chan struct{})
+		go func() {
+			gs.GracefulStop()
+			close(donec)
+		}()
+		select {
+		case <-donec:
+		case <-stopc:
+			gs.Stop()
+		case <-time.After(ingressServerShutdownTimeout):
+			gs.Stop()
+		}
+	}
 	lg.Info("shut down server", zap.Error(<-errc))
 
 	signal.Stop(notifier)
@@ -101,7 +171,36 @@ func newIngressClient() *cobra.Command {
 	cmd.PersistentFlags().IntVar(&ingressClientRoutes, "routes", 10, "total number of routes")
 	cmd.PersistentFlags().IntVar(&ingressClientClients, "clients", 100, "total number of concurrent clients")
 	cmd.PersistentFlags().IntVar(&ingressClientRequests, "requests", 5000, "total number of requests")
+	cmd.PersistentFlags().Float64Var(&ingressClientRate, "rate", 0, "target requests per second in open-loop mode (0 to send requests back-to-back)")
+	cmd.PersistentFlags().Float64Var(&ingressClientRateStep, "rate-step", 0, "requests per second to add every '--rate-step-interval' in open-loop mode")
+	cmd.PersistentFlags().DurationVar(&ingressClientRateStepInterval, "rate-step-interval", 0, "interval to increase the rate by '--rate-step'")
+	cmd.PersistentFlags().StringVar(&ingressClientProfile.Type, "profile", "", "load profile 'constant', 'ramp', 'step' or 'spike' (empty to send '--requests' requests)")
+	cmd.PersistentFlags().BoolVar(&ingressClientProfile.OpenLoop, "profile-open-loop", false, "true to apply profile load levels to requests per second, instead of active clients")
+	cmd.PersistentFlags().DurationVar(&ingressClientProfile.Duration, "profile-duration", 0, "load profile duration")
+	cmd.PersistentFlags().Float64Var(&ingressClientProfile.Start, "profile-start", 0, "load level at start")
+	cmd.PersistentFlags().Float64Var(&ingressClientProfile.Peak, "profile-peak", 0, "load level at the end of ramp and step, or during spike")
+	cmd.PersistentFlags().IntVar(&ingressClientProfile.Steps, "profile-steps", 0, "number of steps of step profile")
+	cmd.PersistentFlags().DurationVar(&ingressClientProfile.SpikeStart, "profile-spike-start", 0, "offset when spike begins")
+	cmd.PersistentFlags().DurationVar(&ingressClientProfile.SpikeDuration, "profile-spike-duration", 0, "duration of spike")
+	cmd.PersistentFlags().DurationVar(&ingressClientBucketInterval, "bucket-interval", 10*time.Second, "time window to aggregate results (0 to disable)")
 	cmd.PersistentFlags().StringVar(&ingressClientResultPath, "result-path", "", "file path to output results in encoded 'eksconfig.Config' YAML")
+	cmd.PersistentFlags().StringArrayVar(&ingressClientShards, "shard", nil, "load balancer endpoint and routes in 'endpoint=start-end' format, when routes are sharded across multiple load balancers (overrides '--endpoint' and '--routes')")
+	cmd.PersistentFlags().StringVar(&ingressClientBarrierPath, "barrier-path", "", "file path to wait for the start time in RFC3339 format, before sending requests (empty to start immediately)")
+	cmd.PersistentFlags().DurationVar(&ingressClientBarrierTimeout, "barrier-timeout", 30*time.Minute, "timeout to wait for the start time")
+	cmd.PersistentFlags().StringVar(&ingressClientOutput, "output", "text", "'text' to print results, or 'json' to print structured results to merge with other clients")
+	cmd.PersistentFlags().IntVar(&ingressClientTransport.MaxConnsPerHost, "max-conns-per-host", 0, "maximum number of connections per load balancer (0 for unlimited)")
+	cmd.PersistentFlags().BoolVar(&ingressClientTransport.DisableKeepAlive, "disable-keep-alive", false, "true to open a new connection per request")
+	cmd.PersistentFlags().BoolVar(&ingressClientTransport.HTTP2, "http2", false, "true to send requests only over HTTP/2 (requires HTTPS)")
+	cmd.PersistentFlags().DurationVar(&ingressClientTransport.Timeout, "timeout", 30*time.Second, "request timeout (0 for no timeout)")
+	cmd.PersistentFlags().BoolVar(&ingressClientTransport.InsecureSkipVerify, "insecure-skip-verify", false, "true to skip server certificate verification")
+	cmd.PersistentFlags().StringVar(&ingressClientMethod, "method", "GET", "HTTP method 'GET', 'POST' or 'PUT'")
+	cmd.PersistentFlags().StringArrayVar(&ingressClientHeaders, "header", nil, "HTTP header in 'Key: Value' format to add to each request")
+	cmd.PersistentFlags().StringVar(&ingressClientBody, "body", "", "request body of 'POST' and 'PUT'")
+	cmd.PersistentFlags().StringVar(&ingressClientMode, "mode", "http", "'http' to send requests to routes, 'websocket' or 'grpc' to send messages over one long-lived connection")
+	cmd.PersistentFlags().IntVar(&ingressClientStream.Messages, "stream-messages", 10, "number of messages to send in 'websocket' and 'grpc' modes")
+	cmd.PersistentFlags().DurationVar(&ingressClientStream.Interval, "stream-interval", time.Second, "interval between messages in 'websocket' and 'grpc' modes")
+	cmd.PersistentFlags().DurationVar(&ingressClientStream.Idle, "stream-idle", 0, "duration to keep the connection idle before the last message in 'websocket' and 'grpc' modes")
+	cmd.PersistentFlags().IntVar(&ingressClientStream.PayloadSize, "stream-payload-size", 1024, "message payload size in 'websocket' and 'grpc' modes")
 	return cmd
 }
 
@@ -111,13 +210,37 @@ var (
 	ingressClientClients    int
 	ingressClientRequests   int
 	ingressClientResultPath string
+
+	ingressClientRate             float64
+	ingressClientRateStep         float64
+	ingressClientRateStepInterval time.Duration
+
+	ingressClientProfile        client.Profile
+	ingressClientBucketInterval time.Duration
+
+	ingressClientShards         []string
+	ingressClientBarrierPath    string
+	ingressClientBarrierTimeout time.Duration
+	ingressClientOutput         string
+
+	ingressClientTransport client.TransportOptions
+	ingressClientMethod    string
+	ingressClientHeaders   []string
+	ingressClientBody      string
+
+	ingressClientMode   string
+	ingressClientStream client.StreamOptions
 )
 
 func ingressClientFunc(cmd *cobra.Command, args []string) {
-	if ingressClientEp == "" {
+	if ingressClientEp == "" && len(ingressClientShards) == 0 {
 		fmt.Fprintf(os.Stderr, "invalid ingress test endpoint %q", ingressClientEp)
 		os.Exit(1)
 	}
+	if ingressClientOutput != "text" && ingressClientOutput != "json" {
+		fmt.Fprintf(os.Stderr, "invalid output %q", ingressClientOutput)
+		os.Exit(1)
+	}
 
 	lg, err := zap.NewProduction()
 	if err != nil {
@@ -125,25 +248,92 @@ func ingressClientFunc(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
+	switch ingressClientMode {
+	case "http":
+	case client.ProtocolWebSocket, client.ProtocolGRPC:
+		ingressStreamClient(lg)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "invalid mode %q", ingressClientMode)
+		os.Exit(1)
+	}
+
 	// send loads from client to server
-	cli, err := client.New(lg, ingressClientEp, ingressClientRoutes, ingressClientClients, ingressClientRequests)
+	var cli *client.Client
+	if len(ingressClientShards) > 0 {
+		targets := make([]client.Target, 0, len(ingressClientShards))
+		for _, s := range ingressClientShards {
+			tg, perr := client.ParseShardFlag(s)
+			if perr != nil {
+				lg.Fatal("invalid shard", zap.Error(perr))
+			}
+			targets = append(targets, tg)
+		}
+		cli, err = client.NewSharded(lg, targets, ingressClientClients, ingressClientRequests)
+	} else {
+		cli, err = client.New(lg, ingressClientEp, ingressClientRoutes, ingressClientClients, ingressClientRequests)
+	}
 	if err != nil {
 		lg.Fatal("failed to create client", zap.Error(err))
 	}
+	cli.HTTPClient = client.NewHTTPClient(ingressClientTransport, nil)
+	if err = client.ValidateMethod(ingressClientMethod, []byte(ingressClientBody)); err != nil {
+		lg.Fatal("invalid request", zap.Error(err))
+	}
+	cli.Method = ingressClientMethod
+	cli.Body = []byte(ingressClientBody)
+	cli.Header, err = client.ParseHeaders(ingressClientHeaders)
+	if err != nil {
+		lg.Fatal("invalid request header", zap.Error(err))
+	}
+	cli.Rate = ingressClientRate
+	cli.RateStep = ingressClientRateStep
+	cli.RateStepInterval = ingressClientRateStepInterval
+	cli.BucketInterval = ingressClientBucketInterval
+	if ingressClientProfile.Type != "" {
+		if err = ingressClientProfile.Validate(); err != nil {
+			lg.Fatal("invalid load profile", zap.Error(err))
+		}
+		cli.Profile = &ingressClientProfile
+	}
+
+	if ingressClientBarrierPath != "" {
+		stopc := make(chan struct{})
+		notifier := make(chan os.Signal, 1)
+		signal.Notify(notifier, syscall.SIGINT, syscall.SIGTERM)
+		go func() {
+			<-notifier
+			close(stopc)
+		}()
+		if err = client.WaitBarrier(lg, ingressClientBarrierPath, ingressClientBarrierTimeout, stopc); err != nil {
+			lg.Fatal("failed to wait for start barrier", zap.Error(err))
+		}
+		signal.Stop(notifier)
+	}
 
 	lg.Info("starting ingress client")
 	rs := cli.Run()
 	lg.Info("finished ingress client")
 
-	fmt.Println(rs)
+	switch ingressClientOutput {
+	case "json":
+		if err = client.WriteResult(os.Stdout, rs); err != nil {
+			lg.Fatal("failed to write result", zap.Error(err))
+		}
+	default:
+		fmt.Println(rs)
+	}
 
 	if ingressClientResultPath != "" {
 		cfg := &eksconfig.Config{
 			ConfigPath:   ingressClientResultPath,
 			ClusterState: &eksconfig.ClusterState{},
 			ALBIngressController: &eksconfig.ALBIngressController{
-				TestResultQPS:      rs.QPS,
-				TestResultFailures: rs.Failure,
+				TestResultQPS:               rs.QPS,
+				TestResultFailures:          rs.Failure,
+				TestResultIntegrityFailures: rs.ErrorCategories[client.ErrorCategoryIntegrity],
+				TestResultDropped:           rs.Dropped,
+				TestResultLate:              rs.Late,
 			},
 		}
 		cfg.Sync()
@@ -159,3 +349,45 @@ func ingressClientFunc(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 }
+
+// ingressStreamClient sends messages over one WebSocket connection
+// or gRPC stream to the endpoint.
+func ingressStreamClient(lg *zap.Logger) {
+	ingressClientStream.Timeout = ingressClientTransport.Timeout
+	var tlsCfg *tls.Config
+	if strings.HasPrefix(ingressClientEp, "https://") {
+		tlsCfg = &tls.Config{InsecureSkipVerify: ingressClientTransport.InsecureSkipVerify}
+	}
+
+	var rs client.StreamResult
+	var err error
+	switch ingressClientMode {
+	case client.ProtocolWebSocket:
+		var u string
+		u, err = client.WebSocketURL(ingressClientEp)
+		if err != nil {
+			lg.Fatal("invalid endpoint", zap.Error(err))
+		}
+		rs, err = client.RunWebSocket(lg, u, tlsCfg, ingressClientStream)
+	case client.ProtocolGRPC:
+		var target string
+		target, err = client.GRPCTarget(ingressClientEp)
+		if err != nil {
+			lg.Fatal("invalid endpoint", zap.Error(err))
+		}
+		rs, err = client.RunGRPC(lg, target, tlsCfg, ingressClientStream)
+	}
+	if err != nil {
+		lg.Fatal("failed to connect", zap.String("mode", ingressClientMode), zap.Error(err))
+	}
+
+	d, err := json.MarshalIndent(rs, "", "  ")
+	if err != nil {
+		lg.Fatal("failed to encode result", zap.Error(err))
+	}
+	fmt.Println(string(d))
+	if rs.Failures > 0 {
+		fmt.Fprintf(os.Stderr, "expected no failure, got %v", rs.Failures)
+		os.Exit(1)
+	}
+}
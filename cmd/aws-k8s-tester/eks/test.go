@@ -3,10 +3,12 @@ package eks
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/aws/aws-k8s-tester/eksconfig"
 	"github.com/aws/aws-k8s-tester/ekstester"
 	"github.com/aws/aws-k8s-tester/internal/eks"
+	"github.com/aws/aws-k8s-tester/pkg/junit"
 
 	"github.com/spf13/cobra"
 )
@@ -16,6 +18,7 @@ func newTest() *cobra.Command {
 		Use:   "test <subcommand>",
 		Short: "Test commands",
 	}
+	cmd.PersistentFlags().StringVar(&artifactsDir, "artifacts-dir", os.Getenv("ARTIFACTS"), "directory to write JUnit XML and JSON test results (leave empty to skip)")
 	cmd.AddCommand(
 		newTestGetWorkerNodeLogs(),
 		newTestDumpClusterLogs(),
@@ -24,6 +27,20 @@ func newTest() *cobra.Command {
 	return cmd
 }
 
+var artifactsDir string
+
+// saveTestResult writes the test result to the artifacts directory, if any.
+func saveTestResult(name string, took time.Duration, err error, metrics map[string]float64) {
+	if artifactsDir == "" {
+		return
+	}
+	s := junit.NewSuite("eks-" + name)
+	s.Add(name, took, err, metrics)
+	if serr := s.Save(artifactsDir); serr != nil {
+		fmt.Fprintf(os.Stderr, "failed to save test result to %q (%v)\n", artifactsDir, serr)
+	}
+}
+
 func newTestGetWorkerNodeLogs() *cobra.Command {
 	return &cobra.Command{
 		Use:   "get-worker-node-logs",
@@ -101,6 +118,12 @@ func newTestALB() *cobra.Command {
 	cmd.AddCommand(
 		newTestALBCorrectness(),
 		newTestALBQPS(),
+		newTestALBTargetTypes(),
+		newTestALBUpgrade(),
+		newTestALBReconcileLatency(),
+		newTestALBRollingUpdate(),
+		newTestELBServices(),
+		newTestALBDistributedQPS(),
 		newTestALBMetrics(),
 	)
 	return cmd
@@ -132,7 +155,10 @@ func testALBCorrectness(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
-	if err = tester.TestALBCorrectness(); err != nil {
+	now := time.Now().UTC()
+	err = tester.TestALBCorrectness()
+	saveTestResult("alb-correctness", time.Now().UTC().Sub(now), err, nil)
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed correctness test %v\n", err)
 		os.Exit(1)
 	}
@@ -164,12 +190,269 @@ func testALBQPS(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
-	if err = tester.TestALBQPS(); err != nil {
+	now := time.Now().UTC()
+	err = tester.TestALBQPS()
+	var metrics map[string]float64
+	if cfg, lerr := tester.LoadConfig(); lerr == nil && cfg.ALBIngressController != nil {
+		metrics = map[string]float64{
+			"qps":                cfg.ALBIngressController.TestResultQPS,
+			"expected-qps":       cfg.ALBIngressController.TestExpectQPS,
+			"failures":           float64(cfg.ALBIngressController.TestResultFailures),
+			"integrity-failures": float64(cfg.ALBIngressController.TestResultIntegrityFailures),
+		}
+	}
+	saveTestResult("alb-qps", time.Now().UTC().Sub(now), err, metrics)
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed scalability QPS test %v\n", err)
 		os.Exit(1)
 	}
 }
 
+func newTestALBTargetTypes() *cobra.Command {
+	return &cobra.Command{
+		Use:   "target-types",
+		Short: "Runs ALB correctness and QPS tests with both 'instance' and 'ip' target types, and compares results",
+		Run:   testALBTargetTypes,
+	}
+}
+
+func testALBTargetTypes(cmd *cobra.Command, args []string) {
+	if path == "" {
+		fmt.Fprintln(os.Stderr, "'--path' flag is not specified")
+		os.Exit(1)
+	}
+
+	cfg, err := eksconfig.Load(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load configuration %q (%v)\n", path, err)
+		os.Exit(1)
+	}
+	var tester ekstester.Tester
+	tester, err = eks.NewTester(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create EKS deployer %v\n", err)
+		os.Exit(1)
+	}
+
+	now := time.Now().UTC()
+	err = tester.TestALBTargetTypes()
+	var metrics map[string]float64
+	if cfg, lerr := tester.LoadConfig(); lerr == nil && cfg.ALBIngressController != nil {
+		metrics = make(map[string]float64)
+		for _, r := range cfg.ALBIngressController.TargetTypeResults {
+			metrics[r.TargetType+"-qps"] = r.QPS
+			metrics[r.TargetType+"-latency-p50-ms"] = r.LatencyP50.Seconds() * 1000
+			metrics[r.TargetType+"-latency-p99-ms"] = r.LatencyP99.Seconds() * 1000
+			metrics[r.TargetType+"-failures"] = float64(r.Failures)
+		}
+	}
+	saveTestResult("alb-target-types", time.Now().UTC().Sub(now), err, metrics)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed target type comparison test %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func newTestALBUpgrade() *cobra.Command {
+	return &cobra.Command{
+		Use:   "upgrade",
+		Short: "Rolls ALB Ingress Controller to the upgrade image while sending traffic, and checks ALB resources are not re-created",
+		Run:   testALBUpgrade,
+	}
+}
+
+func testALBUpgrade(cmd *cobra.Command, args []string) {
+	if path == "" {
+		fmt.Fprintln(os.Stderr, "'--path' flag is not specified")
+		os.Exit(1)
+	}
+
+	cfg, err := eksconfig.Load(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load configuration %q (%v)\n", path, err)
+		os.Exit(1)
+	}
+	var tester ekstester.Tester
+	tester, err = eks.NewTester(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create EKS deployer %v\n", err)
+		os.Exit(1)
+	}
+
+	now := time.Now().UTC()
+	err = tester.TestALBUpgrade()
+	var metrics map[string]float64
+	if cfg, lerr := tester.LoadConfig(); lerr == nil && cfg.ALBIngressController != nil && cfg.ALBIngressController.UpgradeResult != nil {
+		r := cfg.ALBIngressController.UpgradeResult
+		metrics = map[string]float64{
+			"requests":       float64(r.Requests),
+			"failures":       float64(r.Failures),
+			"recreated-arns": float64(len(r.RemovedARNs) + len(r.AddedARNs)),
+		}
+	}
+	saveTestResult("alb-upgrade", time.Now().UTC().Sub(now), err, metrics)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed upgrade test %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func newTestALBReconcileLatency() *cobra.Command {
+	return &cobra.Command{
+		Use:   "reconcile-latency",
+		Short: "Measures the latency of each ALB reconcile phase from Ingress object creation to the first successful response",
+		Run:   testALBReconcileLatency,
+	}
+}
+
+func testALBReconcileLatency(cmd *cobra.Command, args []string) {
+	if path == "" {
+		fmt.Fprintln(os.Stderr, "'--path' flag is not specified")
+		os.Exit(1)
+	}
+
+	cfg, err := eksconfig.Load(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load configuration %q (%v)\n", path, err)
+		os.Exit(1)
+	}
+	var tester ekstester.Tester
+	tester, err = eks.NewTester(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create EKS deployer %v\n", err)
+		os.Exit(1)
+	}
+
+	now := time.Now().UTC()
+	err = tester.TestALBReconcileLatency()
+	var metrics map[string]float64
+	if cfg, lerr := tester.LoadConfig(); lerr == nil && cfg.ALBIngressController != nil {
+		metrics = eks.ReconcileLatencyMetrics(cfg.ALBIngressController.ReconcileLatencies)
+	}
+	saveTestResult("alb-reconcile-latency", time.Now().UTC().Sub(now), err, metrics)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed reconcile latency test %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func newTestALBRollingUpdate() *cobra.Command {
+	return &cobra.Command{
+		Use:   "rolling-update",
+		Short: "Rolls the ingress test server behind the ALB, and reports failed requests, 502/503 counts and time-to-stable",
+		Run:   testALBRollingUpdate,
+	}
+}
+
+func testALBRollingUpdate(cmd *cobra.Command, args []string) {
+	if path == "" {
+		fmt.Fprintln(os.Stderr, "'--path' flag is not specified")
+		os.Exit(1)
+	}
+
+	cfg, err := eksconfig.Load(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load configuration %q (%v)\n", path, err)
+		os.Exit(1)
+	}
+	var tester ekstester.Tester
+	tester, err = eks.NewTester(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create EKS deployer %v\n", err)
+		os.Exit(1)
+	}
+
+	now := time.Now().UTC()
+	err = tester.TestALBRollingUpdate()
+	var metrics map[string]float64
+	if cfg, lerr := tester.LoadConfig(); lerr == nil && cfg.ALBIngressController != nil {
+		metrics = eks.RollingUpdateMetrics(cfg.ALBIngressController.RollingUpdateResults)
+	}
+	saveTestResult("alb-rolling-update", time.Now().UTC().Sub(now), err, metrics)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed rolling update test %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func newTestELBServices() *cobra.Command {
+	return &cobra.Command{
+		Use:   "elb-services",
+		Short: "Creates LoadBalancer type Services with classic ELB and NLB, and runs correctness and QPS tests",
+		Run:   testELBServices,
+	}
+}
+
+func testELBServices(cmd *cobra.Command, args []string) {
+	if path == "" {
+		fmt.Fprintln(os.Stderr, "'--path' flag is not specified")
+		os.Exit(1)
+	}
+
+	cfg, err := eksconfig.Load(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load configuration %q (%v)\n", path, err)
+		os.Exit(1)
+	}
+	var tester ekstester.Tester
+	tester, err = eks.NewTester(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create EKS deployer %v\n", err)
+		os.Exit(1)
+	}
+
+	now := time.Now().UTC()
+	err = tester.TestELBServices()
+	var metrics map[string]float64
+	if cfg, lerr := tester.LoadConfig(); lerr == nil && cfg.ALBIngressController != nil {
+		metrics = eks.ELBServiceMetrics(cfg.ALBIngressController.LoadBalancerServiceResults)
+	}
+	saveTestResult("elb-services", time.Now().UTC().Sub(now), err, metrics)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed load balancer Service test %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func newTestALBDistributedQPS() *cobra.Command {
+	return &cobra.Command{
+		Use:   "distributed-qps",
+		Short: "Runs ALB QPS test from multiple ingress client pods, and merges their results",
+		Run:   testALBDistributedQPS,
+	}
+}
+
+func testALBDistributedQPS(cmd *cobra.Command, args []string) {
+	if path == "" {
+		fmt.Fprintln(os.Stderr, "'--path' flag is not specified")
+		os.Exit(1)
+	}
+
+	cfg, err := eksconfig.Load(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load configuration %q (%v)\n", path, err)
+		os.Exit(1)
+	}
+	var tester ekstester.Tester
+	tester, err = eks.NewTester(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create EKS deployer %v\n", err)
+		os.Exit(1)
+	}
+
+	now := time.Now().UTC()
+	err = tester.TestALBDistributedQPS()
+	var metrics map[string]float64
+	if cfg, lerr := tester.LoadConfig(); lerr == nil && cfg.ALBIngressController != nil {
+		metrics = eks.ALBDistributedQPSMetrics(cfg.ALBIngressController)
+	}
+	saveTestResult("alb-distributed-qps", time.Now().UTC().Sub(now), err, metrics)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed distributed QPS test %v\n", err)
+		os.Exit(1)
+	}
+}
+
 func newTestALBMetrics() *cobra.Command {
 	return &cobra.Command{
 		Use:   "metrics",
@@ -196,7 +479,10 @@ func testALBMetrics(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
-	if err = tester.TestALBMetrics(); err != nil {
+	now := time.Now().UTC()
+	err = tester.TestALBMetrics()
+	saveTestResult("alb-metrics", time.Now().UTC().Sub(now), err, nil)
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed metrics test %v\n", err)
 		os.Exit(1)
 	}
@@ -1,12 +1,16 @@
 package etcd
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-k8s-tester/etcdconfig"
 	"github.com/aws/aws-k8s-tester/internal/etcd"
+	"github.com/aws/aws-k8s-tester/pkg/junit"
 
 	"github.com/spf13/cobra"
 )
@@ -16,12 +20,15 @@ func newTest() *cobra.Command {
 		Use:   "test",
 		Short: "Run etcd tests",
 	}
+	cmd.PersistentFlags().StringVar(&artifactsDir, "artifacts-dir", os.Getenv("ARTIFACTS"), "directory to write JUnit XML and JSON test results (leave empty to skip)")
 	cmd.AddCommand(
 		newTestE2E(),
 	)
 	return cmd
 }
 
+var artifactsDir string
+
 func newTestE2E() *cobra.Command {
 	return &cobra.Command{
 		Use:   "e2e",
@@ -33,21 +40,69 @@ func newTestE2E() *cobra.Command {
 func testE2EFunc(cmd *cobra.Command, args []string) {
 	cfg := etcdconfig.NewDefault()
 
+	suite := junit.NewSuite("etcd-e2e")
+
 	tester, err := etcd.NewTester(cfg)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to create etcd tester %v\n", err)
-		os.Exit(1)
+		suite.Add("create", 0, err, nil)
+		exit(suite)
 	}
 
-	if err = tester.Deploy(); err != nil {
+	now := time.Now().UTC()
+	err = tester.Deploy()
+	suite.Add("deploy", time.Now().UTC().Sub(now), err, nil)
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to deploy etcd tester %v\n", err)
-		os.Exit(1)
+		exit(suite)
+	}
+
+	now = time.Now().UTC()
+	hh := tester.CheckHealth()
+	healthy, errs := 0, []string{}
+	for id, h := range hh {
+		if h.Error != nil {
+			errs = append(errs, fmt.Sprintf("%q: %v", id, h.Error))
+			continue
+		}
+		healthy++
+	}
+	sort.Strings(errs)
+	err = nil
+	if len(errs) > 0 {
+		err = errors.New(strings.Join(errs, ", "))
 	}
+	suite.Add("check-health", time.Now().UTC().Sub(now), err, map[string]float64{
+		"nodes":         float64(len(hh)),
+		"healthy-nodes": float64(healthy),
+	})
 
 	time.Sleep(cfg.WaitBeforeDown)
 
-	if err = tester.Terminate(); err != nil {
-		fmt.Fprintf(os.Stderr, "failed to create etcd tester %v\n", err)
-		os.Exit(1)
+	now = time.Now().UTC()
+	err = tester.Terminate()
+	suite.Add("terminate", time.Now().UTC().Sub(now), err, nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to terminate etcd tester %v\n", err)
 	}
+	if suite.Failed() {
+		exit(suite)
+	}
+	save(suite)
+}
+
+// save writes the test results to the artifacts directory, if any.
+func save(suite *junit.Suite) {
+	if artifactsDir == "" {
+		return
+	}
+	if err := suite.Save(artifactsDir); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to save test result to %q (%v)\n", artifactsDir, err)
+	}
+}
+
+// exit saves the test results and exits with non-zero code.
+func exit(suite *junit.Suite) {
+	save(suite)
+	os.Exit(1)
 }
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/aws/aws-k8s-tester/ec2config/plugins"
+	"github.com/aws/aws-k8s-tester/pkg/awsapi"
 	ec2types "github.com/aws/aws-k8s-tester/pkg/awsapi/ec2"
 
 	gyaml "github.com/ghodss/yaml"
@@ -23,6 +24,41 @@ type Config struct {
 	AWSAccountID string `json:"aws-account-id,omitempty"`
 	// AWSRegion is the AWS region.
 	AWSRegion string `json:"aws-region,omitempty"`
+	// AWSEndpoints maps each service name to its custom endpoint
+	// (e.g. "ec2" to a local AWS emulator).
+	// Supported services are "acm", "autoscaling", "cloudformation", "ec2", "eks",
+	// "elb", "elbv2", "iam", "s3", and "sts". Leave empty to use production endpoints.
+	AWSEndpoints map[string]string `json:"aws-endpoints,omitempty"`
+	// AWSProfile is the named profile in the shared AWS config and credentials files.
+	// Leave empty to use the default credential chain.
+	AWSProfile string `json:"aws-profile,omitempty"`
+	// AWSRoleARN is the ARN of the IAM role to assume for all AWS API calls.
+	// The role is assumed with "AWSWebIdentityTokenFile", if set.
+	// Otherwise, with the base credentials (e.g. "AWSProfile").
+	AWSRoleARN string `json:"aws-role-arn,omitempty"`
+	// AWSRoleExternalID is the external ID to pass when assuming "AWSRoleARN".
+	// Cannot be used with "AWSWebIdentityTokenFile".
+	AWSRoleExternalID string `json:"aws-role-external-id,omitempty"`
+	// AWSRoleSessionName is the session name to use when assuming "AWSRoleARN".
+	// If empty, a unique session name is generated.
+	AWSRoleSessionName string `json:"aws-role-session-name,omitempty"`
+	// AWSWebIdentityTokenFile is the file path to the OpenID Connect token
+	// to assume "AWSRoleARN" with, instead of long-lived keys.
+	AWSWebIdentityTokenFile string `json:"aws-web-identity-token-file,omitempty"`
+	// AWSAPIRecordPath is the file path to record all AWS API requests and responses,
+	// with credentials redacted. The recorded cassette can be replayed in unit tests.
+	// Leave empty to disable recording.
+	AWSAPIRecordPath string `json:"aws-api-record-path,omitempty"`
+	// AWSAPIRetry is the retry policy of all AWS API calls,
+	// with jittered exponential backoff. Empty fields are set to defaults.
+	AWSAPIRetry awsapi.RetryConfig `json:"aws-api-retry,omitempty"`
+	// AWSAPIServiceRetry maps each service name (e.g. "ec2", "cloudformation")
+	// to its retry policy, overriding "AWSAPIRetry".
+	AWSAPIServiceRetry map[string]awsapi.RetryConfig `json:"aws-api-service-retry,omitempty"`
+	// AWSAPIStats is the number of calls, retries and throttles of each AWS API
+	// (e.g. "ec2:DescribeInstances"), to tune polling intervals.
+	// Read-only to be updated by deployer.
+	AWSAPIStats map[string]awsapi.APIStats `json:"aws-api-stats,omitempty"`
 
 	// LogDebug is true to enable debug level logging.
 	LogDebug bool `json:"log-debug"`
@@ -333,6 +369,21 @@ func (cfg *Config) UpdateFromEnvs() error {
 				return fmt.Errorf("parsing field name %q not supported", fieldName)
 			}
 
+		case reflect.Map:
+			// e.g. "eks=https://eks-beta.us-west-2.amazonaws.com,s3=http://localhost:4566"
+			if vv.Field(i).Type() != reflect.TypeOf(map[string]string{}) {
+				return fmt.Errorf("%q (%v) is not supported as an env", env, vv.Field(i).Type())
+			}
+			mm := make(map[string]string)
+			for _, kv := range strings.Split(sv, ",") {
+				ss := strings.SplitN(kv, "=", 2)
+				if len(ss) != 2 {
+					return fmt.Errorf("failed to parse %q (%q, expected 'key=value')", sv, env)
+				}
+				mm[ss[0]] = ss[1]
+			}
+			vv.Field(i).Set(reflect.ValueOf(mm))
+
 		default:
 			return fmt.Errorf("%q (%v) is not supported as an env", env, vv.Field(i).Type())
 		}
@@ -352,6 +403,12 @@ func (cfg *Config) ValidateAndSetDefaults() (err error) {
 	if cfg.AWSRegion == "" {
 		return errors.New("empty AWSRegion")
 	}
+	if err = awsapi.ValidateEndpoints(cfg.AWSEndpoints); err != nil {
+		return fmt.Errorf("invalid AWSEndpoints (%v)", err)
+	}
+	if err = awsapi.ValidateCredentials(cfg.AWSProfile, cfg.AWSRoleARN, cfg.AWSRoleExternalID, cfg.AWSRoleSessionName, cfg.AWSWebIdentityTokenFile); err != nil {
+		return fmt.Errorf("invalid AWS credentials (%v)", err)
+	}
 	if cfg.UserName == "" {
 		return errors.New("empty UserName")
 	}
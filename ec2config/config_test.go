@@ -3,6 +3,7 @@ package ec2config
 import (
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -11,6 +12,11 @@ func TestEnv(t *testing.T) {
 
 	os.Setenv("AWS_K8S_TESTER_EC2_COUNT", "100")
 	os.Setenv("AWS_K8S_TESTER_EC2_AWS_REGION", "us-east-1")
+	os.Setenv("AWS_K8S_TESTER_EC2_AWS_PROFILE", "dev")
+	os.Setenv("AWS_K8S_TESTER_EC2_AWS_ROLE_ARN", "arn:aws:iam::123456789012:role/ec2-test")
+	os.Setenv("AWS_K8S_TESTER_EC2_AWS_ROLE_EXTERNAL_ID", "my-external-id")
+	os.Setenv("AWS_K8S_TESTER_EC2_AWS_ROLE_SESSION_NAME", "my-session")
+	os.Setenv("AWS_K8S_TESTER_EC2_AWS_WEB_IDENTITY_TOKEN_FILE", "/var/run/secrets/token")
 	os.Setenv("AWS_K8S_TESTER_EC2_CONFIG_PATH", "test-path")
 	os.Setenv("AWS_K8S_TESTER_EC2_DOWN", "false")
 	os.Setenv("AWS_K8S_TESTER_EC2_LOG_DEBUG", "false")
@@ -30,6 +36,11 @@ func TestEnv(t *testing.T) {
 	defer func() {
 		os.Unsetenv("AWS_K8S_TESTER_EC2_COUNT")
 		os.Unsetenv("AWS_K8S_TESTER_EC2_AWS_REGION")
+		os.Unsetenv("AWS_K8S_TESTER_EC2_AWS_PROFILE")
+		os.Unsetenv("AWS_K8S_TESTER_EC2_AWS_ROLE_ARN")
+		os.Unsetenv("AWS_K8S_TESTER_EC2_AWS_ROLE_EXTERNAL_ID")
+		os.Unsetenv("AWS_K8S_TESTER_EC2_AWS_ROLE_SESSION_NAME")
+		os.Unsetenv("AWS_K8S_TESTER_EC2_AWS_WEB_IDENTITY_TOKEN_FILE")
 		os.Unsetenv("AWS_K8S_TESTER_EC2_CONFIG_PATH")
 		os.Unsetenv("AWS_K8S_TESTER_EC2_DOWN")
 		os.Unsetenv("AWS_K8S_TESTER_EC2_LOG_DEBUG")
@@ -57,6 +68,21 @@ func TestEnv(t *testing.T) {
 	if cfg.AWSRegion != "us-east-1" {
 		t.Fatalf("AWSRegion unexpected %q", cfg.AWSRegion)
 	}
+	if cfg.AWSProfile != "dev" {
+		t.Fatalf("AWSProfile unexpected %q", cfg.AWSProfile)
+	}
+	if cfg.AWSRoleARN != "arn:aws:iam::123456789012:role/ec2-test" {
+		t.Fatalf("AWSRoleARN unexpected %q", cfg.AWSRoleARN)
+	}
+	if cfg.AWSRoleExternalID != "my-external-id" {
+		t.Fatalf("AWSRoleExternalID unexpected %q", cfg.AWSRoleExternalID)
+	}
+	if cfg.AWSRoleSessionName != "my-session" {
+		t.Fatalf("AWSRoleSessionName unexpected %q", cfg.AWSRoleSessionName)
+	}
+	if cfg.AWSWebIdentityTokenFile != "/var/run/secrets/token" {
+		t.Fatalf("AWSWebIdentityTokenFile unexpected %q", cfg.AWSWebIdentityTokenFile)
+	}
 	if cfg.ConfigPath != "test-path" {
 		t.Fatalf("ConfigPath unexpected %q", cfg.ConfigPath)
 	}
@@ -103,3 +129,18 @@ func TestEnv(t *testing.T) {
 		t.Fatalf("VPCCIDR expected '192.168.0.0/8', got %q", cfg.VPCCIDR)
 	}
 }
+
+func TestValidateCredentials(t *testing.T) {
+	cfg := NewDefault()
+	cfg.AWSRoleARN = "arn:aws:iam::123456789012:role/ec2-test"
+	cfg.AWSRoleExternalID = "my-external-id"
+	cfg.AWSWebIdentityTokenFile = "/var/run/secrets/token"
+
+	err := cfg.ValidateAndSetDefaults()
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if !strings.Contains(err.Error(), "external ID") {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
@@ -2,9 +2,11 @@
 package eksconfig
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -14,6 +16,7 @@ import (
 	"time"
 
 	"github.com/aws/aws-k8s-tester/ec2config"
+	"github.com/aws/aws-k8s-tester/pkg/awsapi"
 	"github.com/aws/aws-k8s-tester/pkg/awsapi/ec2"
 
 	gyaml "github.com/ghodss/yaml"
@@ -81,7 +84,31 @@ type Config struct {
 	// to the path "/etc/aws-cred-aws-k8s-tester/aws-cred-aws-k8s-tester", under "kube-system" namespace.
 	// Path must be an absolute path, although it will try to parse '~/.aws' or '${HOME}/.aws'.
 	// If "AWS_SHARED_CREDENTIALS_FILE" is specified, this field will overwritten.
+	// Cannot be used with "AWSMountRole".
 	AWSCredentialToMountPath string `json:"aws-credential-to-mount-path,omitempty"`
+	// AWSMountRole is true to mount an AWS config file that assumes "AWSRoleARN"
+	// from the worker node instance profile, instead of "AWSCredentialToMountPath".
+	// The trust policy of "AWSRoleARN" must allow "sts:AssumeRole" from the worker
+	// node instance role (see "ClusterState.CFStackWorkerNodeGroupWorkerNodeInstanceRoleARN").
+	AWSMountRole bool `json:"aws-mount-role"`
+	// AWSProfile is the named profile in the shared AWS config and credentials files.
+	// Leave empty to use the default credential chain.
+	AWSProfile string `json:"aws-profile,omitempty"`
+	// AWSRoleARN is the ARN of the IAM role to assume for all AWS API calls.
+	// The role is assumed with "AWSWebIdentityTokenFile", if set.
+	// Otherwise, with the base credentials (e.g. "AWSProfile").
+	// Set "AWSMountRole" to assume the same role in the ALB Ingress Controller.
+	AWSRoleARN string `json:"aws-role-arn,omitempty"`
+	// AWSRoleExternalID is the external ID to pass when assuming "AWSRoleARN".
+	// Cannot be used with "AWSWebIdentityTokenFile".
+	AWSRoleExternalID string `json:"aws-role-external-id,omitempty"`
+	// AWSRoleSessionName is the session name to use when assuming "AWSRoleARN".
+	// If empty, a unique session name is generated.
+	// Requires "AWSWebIdentityTokenFile".
+	AWSRoleSessionName string `json:"aws-role-session-name,omitempty"`
+	// AWSWebIdentityTokenFile is the file path to the OpenID Connect token
+	// to assume "AWSRoleARN" with, instead of long-lived keys.
+	AWSWebIdentityTokenFile string `json:"aws-web-identity-token-file,omitempty"`
 	// AWSRegion is the AWS geographic area for EKS deployment.
 	// Currently supported regions are:
 	// - us-east-1; US East (N. Virginia)
@@ -89,10 +116,30 @@ type Config struct {
 	// - eu-west-1; EU West (Dublin)
 	// If empty, set default region.
 	AWSRegion string `json:"aws-region,omitempty"`
-	// AWSCustomEndpoint defines AWS custom endpoint for pre-release versions.
+	// AWSCustomEndpoint defines AWS custom EKS endpoint for pre-release versions.
 	// Must be left empty to use production EKS service.
-	// TODO: define custom endpoints for CloudFormation, EC2, STS
+	// Other services are not affected (see "AWSEndpoints").
 	AWSCustomEndpoint string `json:"aws-custom-endpoint,omitempty"`
+	// AWSEndpoints maps each service name to its custom endpoint
+	// (e.g. "eks" to a pre-release EKS endpoint, while EC2, IAM and
+	// CloudFormation stay on production, or every service to a local AWS emulator).
+	// Supported services are "acm", "autoscaling", "cloudformation", "ec2", "eks",
+	// "elb", "elbv2", "iam", "s3", and "sts". Leave empty to use production endpoints.
+	AWSEndpoints map[string]string `json:"aws-endpoints,omitempty"`
+	// AWSAPIRecordPath is the file path to record all AWS API requests and responses,
+	// with credentials redacted. The recorded cassette can be replayed in unit tests.
+	// Leave empty to disable recording.
+	AWSAPIRecordPath string `json:"aws-api-record-path,omitempty"`
+	// AWSAPIRetry is the retry policy of all AWS API calls,
+	// with jittered exponential backoff. Empty fields are set to defaults.
+	AWSAPIRetry awsapi.RetryConfig `json:"aws-api-retry,omitempty"`
+	// AWSAPIServiceRetry maps each service name (e.g. "ec2", "cloudformation")
+	// to its retry policy, overriding "AWSAPIRetry".
+	AWSAPIServiceRetry map[string]awsapi.RetryConfig `json:"aws-api-service-retry,omitempty"`
+	// AWSAPIStats is the number of calls, retries and throttles of each AWS API
+	// (e.g. "ec2:DescribeInstances"), to tune polling intervals.
+	// Read-only to be updated by deployer.
+	AWSAPIStats map[string]awsapi.APIStats `json:"aws-api-stats,omitempty"`
 
 	// WorkerNodeAMI is the Amazon EKS worker node AMI ID for the specified Region.
 	// Reference https://docs.aws.amazon.com/eks/latest/userguide/getting-started.html.
@@ -232,11 +279,19 @@ type ALBIngressController struct {
 	// Created is true if ALB had started its creation operation.
 	Created bool `json:"created"`
 	// Enable is true to create an ALB Ingress Controller with sample ingress deployment.
-	// 'AWSCredentialToMountPath' must be provided to configure ALB Ingress Controller.
+	// 'AWSCredentialToMountPath' or 'AWSMountRole' must be provided to configure ALB Ingress Controller.
 	Enable bool `json:"enable"`
 
 	// IngressControllerImage is the ALB Ingress Controller container image.
 	IngressControllerImage string `json:"ingress-controller-image,omitempty"`
+	// UpgradeIngressControllerImage is the ALB Ingress Controller container image
+	// to roll the controller Deployment to, while sending test traffic.
+	// Leave empty to skip the upgrade test.
+	// Only supported when "TestMode" is "ingress-test-server".
+	UpgradeIngressControllerImage string `json:"upgrade-ingress-controller-image,omitempty"`
+	// UpgradeResult is the result of last upgrade test.
+	// Must be left empty.
+	UpgradeResult *UpgradeResult `json:"upgrade-result,omitempty"`
 	// UploadTesterLogs is true to auto-upload ALB tester logs.
 	UploadTesterLogs bool `json:"upload-tester-logs"`
 
@@ -264,10 +319,18 @@ type ALBIngressController struct {
 	TestServerReplicas int `json:"test-server-replicas,omitempty"`
 	// TestServerRoutes is the number of ALB Ingress Controller routes to test.
 	// It will be auto-generated starting with '/ingress-test-0000000'.
-	// Supports up to 30.
+	// Routes are sharded across multiple Ingress objects (thus ALBs)
+	// by "TestServerRoutesPerIngress". Supports up to 1500.
 	// Only required when ALB Ingress "TestMode" is "ingress-test-server".
 	// Otherwise, set it to 1.
 	TestServerRoutes int `json:"test-server-routes,omitempty"`
+	// TestServerRoutesPerIngress is the maximum number of routes in one Ingress object.
+	// Each Ingress object creates its own ALB. Supports up to 30.
+	TestServerRoutesPerIngress int `json:"test-server-routes-per-ingress,omitempty"`
+	// TestServerNamespaces is the number of namespaces to spread the
+	// Ingress objects across ("default", "ingress-test-server-1", ...).
+	// Ingress test server is deployed to each namespace with "TestServerReplicas".
+	TestServerNamespaces int `json:"test-server-namespaces,omitempty"`
 	// TestClients is the number of concurrent ALB Ingress Controller test clients.
 	// Supports up to 300.
 	TestClients int `json:"test-clients,omitempty"`
@@ -280,6 +343,76 @@ type ALBIngressController struct {
 	TestResponseSize int `json:"test-response-size,omitempty"`
 	// TestClientErrorThreshold is the maximum errors that are ok to happen before failing the tests.
 	TestClientErrorThreshold int64 `json:"test-client-error-threshold,omitempty"`
+	// TestServerFaults is the faults to inject in ingress test server routes.
+	// Only supported in "ingress-test-server" mode. Raise "TestClientErrorThreshold"
+	// when injecting errors or connection resets. Route "*" matches all generated
+	// routes but not the "/ingress-test" correctness check path.
+	TestServerFaults []ServerFault `json:"test-server-faults,omitempty"`
+	// TestServerDrainSeconds is the period in seconds for ingress test server
+	// pods to fail readiness and keep serving requests on termination, before
+	// shutting down. Combine with "TestRollingUpdateDeregistrationDelays"
+	// to measure requests lost during pod termination.
+	// Only supported in "ingress-test-server" mode. Zero to shut down right away.
+	TestServerDrainSeconds int `json:"test-server-drain-seconds,omitempty"`
+	// TestClientRate is the target number of requests per second for QPS tests.
+	// If non-zero, requests are scheduled at a constant arrival rate (open-loop)
+	// and latency is measured from the intended send time, so that tail latency
+	// is not under-reported by clients waiting on slow responses.
+	// If zero, "TestClients" send requests back-to-back (closed-loop).
+	TestClientRate float64 `json:"test-client-rate,omitempty"`
+	// TestClientRateStep is the number of requests per second to add to
+	// "TestClientRate" every "TestClientRateStepSeconds".
+	TestClientRateStep float64 `json:"test-client-rate-step,omitempty"`
+	// TestClientRateStepSeconds is the interval in seconds to increase the rate by "TestClientRateStep".
+	TestClientRateStepSeconds int `json:"test-client-rate-step-seconds,omitempty"`
+	// TestClientProfile is the load profile of QPS tests:
+	// "constant", "ramp", "step" or "spike".
+	// If empty, QPS tests send "TestClientRequests" requests.
+	// Otherwise, requests are sent for "TestClientProfileDurationSeconds"
+	// (also used for "wrk" duration in nginx test mode).
+	TestClientProfile string `json:"test-client-profile,omitempty"`
+	// TestClientProfileOpenLoop is true to apply the profile load levels
+	// to the request rate per second (open-loop), instead of to the number
+	// of active clients (up to "TestClients").
+	TestClientProfileOpenLoop bool `json:"test-client-profile-open-loop"`
+	// TestClientProfileDurationSeconds is the duration of the load profile in seconds.
+	TestClientProfileDurationSeconds int `json:"test-client-profile-duration-seconds,omitempty"`
+	// TestClientProfileStart is the load level at start.
+	TestClientProfileStart float64 `json:"test-client-profile-start,omitempty"`
+	// TestClientProfilePeak is the load level at the end of "ramp" and "step" profiles,
+	// or during the "spike".
+	TestClientProfilePeak float64 `json:"test-client-profile-peak,omitempty"`
+	// TestClientProfileSteps is the number of steps of "step" profile.
+	TestClientProfileSteps int `json:"test-client-profile-steps,omitempty"`
+	// TestClientProfileSpikeStartSeconds is the offset in seconds when the "spike" begins.
+	TestClientProfileSpikeStartSeconds int `json:"test-client-profile-spike-start-seconds,omitempty"`
+	// TestClientProfileSpikeSeconds is the duration in seconds of the "spike".
+	TestClientProfileSpikeSeconds int `json:"test-client-profile-spike-seconds,omitempty"`
+	// TestClientBucketSeconds is the time window in seconds
+	// to aggregate QPS test results into "TestResultBuckets".
+	TestClientBucketSeconds int `json:"test-client-bucket-seconds,omitempty"`
+	// TestClientMaxConnsPerHost is the maximum number of connections
+	// per ALB from the ingress test server client.
+	// If zero, connections are not limited.
+	TestClientMaxConnsPerHost int `json:"test-client-max-conns-per-host,omitempty"`
+	// TestClientDisableKeepAlive is true to open a new connection per request,
+	// instead of reusing persistent connections.
+	TestClientDisableKeepAlive bool `json:"test-client-disable-keep-alive"`
+	// TestClientHTTP2 is true to send requests only over HTTP/2.
+	// Requires "EnableHTTPS", since ALB only supports HTTP/2 over TLS.
+	TestClientHTTP2 bool `json:"test-client-http2"`
+	// TestClientTimeoutSeconds is the request timeout in seconds.
+	// If zero, requests do not time out.
+	TestClientTimeoutSeconds int `json:"test-client-timeout-seconds,omitempty"`
+	// TestClientMethod is the HTTP method of ingress test server client requests:
+	// "GET", "POST" or "PUT". Defaults to "GET".
+	TestClientMethod string `json:"test-client-method,omitempty"`
+	// TestClientHeaders is the list of HTTP headers in "Key: Value" format
+	// added to ingress test server client requests.
+	TestClientHeaders []string `json:"test-client-headers,omitempty"`
+	// TestClientBody is the body of ingress test server client requests.
+	// Only supported with "POST" and "PUT" methods.
+	TestClientBody string `json:"test-client-body,omitempty"`
 	// TestExpectQPS is the expected QPS.
 	// It is used as a scalability test lower bound.
 	TestExpectQPS float64 `json:"test-expect-qps,omitempty"`
@@ -287,6 +420,173 @@ type ALBIngressController struct {
 	TestResultQPS float64 `json:"test-result-qps,omitempty"`
 	// TestResultFailures is the number of failed requests of last test run.
 	TestResultFailures int64 `json:"test-result-failures,omitempty"`
+	// TestResultIntegrityFailures is the number of responses of last test run,
+	// whose payloads did not match the length or checksum headers
+	// of ingress test server (e.g. truncated or corrupted by the load balancer).
+	// Included in "TestResultFailures".
+	TestResultIntegrityFailures int64 `json:"test-result-integrity-failures,omitempty"`
+	// TestResultLatencyP50 is the 50th percentile request latency of last test run.
+	TestResultLatencyP50 time.Duration `json:"test-result-latency-p50,omitempty"`
+	// TestResultLatencyP99 is the 99th percentile request latency of last test run.
+	TestResultLatencyP99 time.Duration `json:"test-result-latency-p99,omitempty"`
+	// TestResultDropped is the number of requests not sent at the intended
+	// send time of last open-loop test run, because all clients were busy.
+	TestResultDropped int64 `json:"test-result-dropped,omitempty"`
+	// TestResultLate is the number of requests sent late
+	// in last open-loop test run.
+	TestResultLate int64 `json:"test-result-late,omitempty"`
+	// TestResultBuckets is the results of last test run
+	// aggregated per "TestClientBucketSeconds".
+	TestResultBuckets []LoadBucket `json:"test-result-buckets,omitempty"`
+
+	// CompareTargetTypes is true to run correctness and QPS tests with both
+	// "instance" and "ip" target types in sequence on the same cluster,
+	// and to write a side-by-side report to "TargetTypeComparisonOutputToUploadPath".
+	// The target type in "TargetType" runs last, so that the cluster ends
+	// in the configured target type.
+	CompareTargetTypes bool `json:"compare-target-types"`
+	// TargetTypeResults is the test results of each target type
+	// from last target type comparison.
+	// Must be left empty.
+	TargetTypeResults []TargetTypeResult `json:"target-type-results,omitempty"`
+
+	// TestReconcileLatencyRuns is the number of times to create and delete
+	// a separate Ingress object, to measure how long it takes from
+	// Ingress object creation to serving traffic in each reconcile phase.
+	// Leave 0 to skip the reconcile latency test.
+	TestReconcileLatencyRuns int `json:"test-reconcile-latency-runs"`
+	// ReconcileLatencies is the reconcile timestamps of each run
+	// from last reconcile latency test.
+	// Must be left empty.
+	ReconcileLatencies []ReconcileLatency `json:"reconcile-latencies,omitempty"`
+
+	// TestStickiness is true to create a separate Ingress object with
+	// target group stickiness enabled, and to verify that each client
+	// with the ALB cookie is served by the same pod.
+	// Only supported with "ip" target type, since "instance" target
+	// is a node that may forward requests to any pod.
+	TestStickiness bool `json:"test-stickiness"`
+	// StickinessDurationSeconds is the ALB cookie duration in seconds.
+	StickinessDurationSeconds int `json:"stickiness-duration-seconds,omitempty"`
+	// TestEcho is true to send a request to the ingress test server echo route,
+	// and to verify the "X-Forwarded-For", "X-Forwarded-Proto", "X-Forwarded-Port"
+	// and "X-Amzn-Trace-Id" headers added by ALB. If access logs are enabled,
+	// the request is matched to its access log entry by trace ID, and the target
+	// in the access log is verified for the target type. With "CompareTargetTypes",
+	// this runs for both target types.
+	TestEcho bool `json:"test-echo"`
+	// TestResultTraceID is the "X-Amzn-Trace-Id" header received
+	// by the ingress test server in last echo test run.
+	TestResultTraceID string `json:"test-result-trace-id,omitempty"`
+	// TestWeightedRouting is true to deploy a second ingress test server,
+	// to create a separate Ingress object with weighted forward action
+	// between two services, and to verify the observed traffic split.
+	TestWeightedRouting bool `json:"test-weighted-routing"`
+	// WeightedRoutingWeight is the percentage of traffic
+	// forwarded to the second ingress test server.
+	WeightedRoutingWeight int `json:"weighted-routing-weight,omitempty"`
+	// WeightedRoutingTolerance is the maximum difference between
+	// observed and expected traffic ratio of the second service.
+	WeightedRoutingTolerance float64 `json:"weighted-routing-tolerance,omitempty"`
+	// TestResultWeightedRoutingRatio is the traffic ratio of
+	// the second service observed in last weighted routing test.
+	TestResultWeightedRoutingRatio float64 `json:"test-result-weighted-routing-ratio,omitempty"`
+
+	// TestWebSocket is true to create a separate Ingress object with
+	// "StreamIdleTimeoutSeconds" ALB idle timeout, and to verify that an active
+	// WebSocket connection outlives the idle timeout, while an idle connection
+	// is closed after the idle timeout.
+	TestWebSocket bool `json:"test-websocket"`
+	// TestGRPC is true to serve the gRPC echo service from ingress test server,
+	// to create a separate Ingress object with gRPC backend protocol version,
+	// and to run the same tests as "TestWebSocket" over a gRPC bidirectional stream.
+	// Requires HTTPS listener, and an ingress controller image that supports
+	// "alb.ingress.kubernetes.io/backend-protocol-version" annotation.
+	TestGRPC bool `json:"test-grpc"`
+	// StreamIdleTimeoutSeconds is the ALB idle timeout in seconds
+	// of the Ingress objects for WebSocket and gRPC tests.
+	StreamIdleTimeoutSeconds int `json:"stream-idle-timeout-seconds,omitempty"`
+	// StreamMessages is the number of messages to send over an active connection.
+	// "StreamMessages" x "StreamIntervalSeconds" must exceed "StreamIdleTimeoutSeconds".
+	StreamMessages int `json:"stream-messages,omitempty"`
+	// StreamIntervalSeconds is the interval in seconds between messages.
+	StreamIntervalSeconds int `json:"stream-interval-seconds,omitempty"`
+	// TestResultStreams is the WebSocket and gRPC results of last test run.
+	TestResultStreams []StreamTestResult `json:"test-result-streams,omitempty"`
+
+	// TestRollingUpdateDeregistrationDelays is the list of target group
+	// deregistration delays in seconds to test. For each delay, the ingress
+	// test server Deployment in "default" namespace is rolled while
+	// the ingress client sends requests to the ALB.
+	// Leave empty to skip the rolling update test.
+	TestRollingUpdateDeregistrationDelays []int `json:"test-rolling-update-deregistration-delays,omitempty"`
+	// TestRollingUpdateReadinessGate is true to repeat each rolling update
+	// with the pod readiness gate on ALB target health, so that old pods
+	// are not terminated before new pods are registered and healthy.
+	// Only supported with "ip" target type.
+	TestRollingUpdateReadinessGate bool `json:"test-rolling-update-readiness-gate"`
+	// RollingUpdateResults is the result of each run
+	// from last rolling update test.
+	// Must be left empty.
+	RollingUpdateResults []RollingUpdateResult `json:"rolling-update-results,omitempty"`
+
+	// TestLoadBalancerServices is true to create "LoadBalancer" type Service
+	// objects for the ingress test server in "default" namespace, one with
+	// classic ELB and the other with NLB, and to run correctness and QPS tests
+	// against each load balancer. The Services are deleted on "Down".
+	TestLoadBalancerServices bool `json:"test-load-balancer-services"`
+	// LoadBalancerServiceToDNSName maps each "LoadBalancer" type Service name
+	// to its load balancer DNS name.
+	// Must be left empty.
+	LoadBalancerServiceToDNSName map[string]string `json:"load-balancer-service-to-dns-name,omitempty"`
+	// LoadBalancerServiceResults is the test results of each "LoadBalancer" type Service
+	// from last load balancer Service test.
+	// Must be left empty.
+	LoadBalancerServiceResults []LoadBalancerServiceResult `json:"load-balancer-service-results,omitempty"`
+
+	// TestDistributedClients is the number of ingress client pods to send
+	// requests from worker nodes at the same time, when a single tester host
+	// cannot saturate the ALB. Each pod runs "TestClients" clients with the
+	// same load settings as the QPS test, so the total load is multiplied by
+	// the number of pods. Pods wait on a start barrier until all are running,
+	// and their results are merged into one report.
+	// "AllowedSourceCIDRs" must cover the public IPs of the worker nodes.
+	// Leave 0 to skip the distributed QPS test.
+	TestDistributedClients int `json:"test-distributed-clients"`
+	// TestResultDistributedQPS is the merged QPS of last distributed test run.
+	TestResultDistributedQPS float64 `json:"test-result-distributed-qps,omitempty"`
+	// TestResultDistributedFailures is the number of failed requests
+	// of all client pods in last distributed test run.
+	TestResultDistributedFailures int64 `json:"test-result-distributed-failures,omitempty"`
+	// TestResultDistributedLatencyP50 is the 50th percentile request latency
+	// of all client pods in last distributed test run.
+	TestResultDistributedLatencyP50 time.Duration `json:"test-result-distributed-latency-p50,omitempty"`
+	// TestResultDistributedLatencyP99 is the 99th percentile request latency
+	// of all client pods in last distributed test run.
+	TestResultDistributedLatencyP99 time.Duration `json:"test-result-distributed-latency-p99,omitempty"`
+
+	// EnableHTTPS is true to add an HTTPS listener on port 443 to the ALB,
+	// and to run the tests over TLS.
+	EnableHTTPS bool `json:"enable-https"`
+	// HTTPSCertificateARN is the ACM certificate ARN for the HTTPS listener.
+	// If empty, a self-signed certificate is generated and imported into ACM.
+	HTTPSCertificateARN string `json:"https-certificate-arn,omitempty"`
+	// HTTPSCertificateImported is true if the certificate was imported by the tester.
+	// Must be left empty.
+	HTTPSCertificateImported bool `json:"https-certificate-imported"`
+	// HTTPSCertificatePath is the file path to the PEM-encoded self-signed certificate.
+	// Must be left empty.
+	// This will be overwritten by cluster name.
+	HTTPSCertificatePath string `json:"https-certificate-path,omitempty"`
+	// HTTPSSSLPolicy is the security policy of the HTTPS listener.
+	// https://docs.aws.amazon.com/elasticloadbalancing/latest/application/create-https-listener.html#describe-ssl-policies
+	HTTPSSSLPolicy string `json:"https-ssl-policy,omitempty"`
+	// TestResultSSLProtocol is the TLS protocol negotiated in last test run
+	// (e.g. "TLSv1.2"), as in ALB access log "ssl_protocol" field.
+	TestResultSSLProtocol string `json:"test-result-ssl-protocol,omitempty"`
+	// TestResultSSLCipher is the TLS cipher negotiated in last test run
+	// (e.g. "ECDHE-RSA-AES128-GCM-SHA256"), as in ALB access log "ssl_cipher" field.
+	TestResultSSLCipher string `json:"test-result-ssl-cipher,omitempty"`
 
 	// IngressTestServerDeploymentServiceSpecPath is the file path to test pod deployment and service YAML spec.
 	IngressTestServerDeploymentServiceSpecPath       string `json:"ingress-test-server-deployment-service-spec-path,omitempty"`
@@ -300,6 +600,34 @@ type ALBIngressController struct {
 	IngressObjectSpecPath       string `json:"ingress-object-spec-path,omitempty"`
 	IngressObjectSpecPathBucket string `json:"ingress-object-spec-path-bucket,omitempty"`
 	IngressObjectSpecPathURL    string `json:"ingress-object-spec-path-url,omitempty"`
+	// ReconcileLatencyIngressSpecPath is the file path to the Ingress object
+	// YAML spec that is created and deleted to measure reconcile latency.
+	// Must be left empty.
+	ReconcileLatencyIngressSpecPath string `json:"reconcile-latency-ingress-spec-path,omitempty"`
+	// SidecarSpecPath is the file path to the sidecar Deployment YAML spec,
+	// used to send requests from inside the cluster.
+	// Must be left empty.
+	SidecarSpecPath string `json:"sidecar-spec-path,omitempty"`
+	// StickinessIngressSpecPath is the file path to the Ingress object
+	// YAML spec with target group stickiness enabled.
+	// Must be left empty.
+	StickinessIngressSpecPath string `json:"stickiness-ingress-spec-path,omitempty"`
+	// WeightedRoutingSpecPath is the file path to the second ingress test
+	// server and the Ingress object YAML spec with weighted forward action.
+	// Must be left empty.
+	WeightedRoutingSpecPath string `json:"weighted-routing-spec-path,omitempty"`
+	// StreamIngressSpecPath is the file path to the Ingress object
+	// YAML spec for WebSocket and gRPC tests.
+	// Must be left empty.
+	StreamIngressSpecPath string `json:"stream-ingress-spec-path,omitempty"`
+	// LoadBalancerServiceSpecPath is the file path to "LoadBalancer" type
+	// Service objects YAML spec.
+	// Must be left empty.
+	LoadBalancerServiceSpecPath string `json:"load-balancer-service-spec-path,omitempty"`
+	// DistributedClientSpecPath is the file path to the ingress client pods
+	// and the start barrier ConfigMap YAML spec.
+	// Must be left empty.
+	DistributedClientSpecPath string `json:"distributed-client-spec-path,omitempty"`
 
 	// required for ALB Ingress Controller
 	// Ingress object requires:
@@ -312,8 +640,25 @@ type ALBIngressController struct {
 	// ELBv2SecurityGroupIDPortOpen is the security group ID created to
 	// open 80 and 443 ports for ALB Ingress Controller.
 	ELBv2SecurityGroupIDPortOpen string `json:"elbv2-security-group-id-port-open,omitempty"`
+	// AllowedSourceCIDRs is the list of source CIDRs allowed to access
+	// ALB ports 80 and 443 (e.g. "1.2.3.4/32").
+	// If empty, defaults to the public IP of the tester.
+	AllowedSourceCIDRs []string `json:"allowed-source-cidrs,omitempty"`
+	// TestAllowedSourceCIDRs is true to confirm that ALB refuses traffic
+	// from outside "AllowedSourceCIDRs", by sending requests from
+	// the sidecar server on a worker node.
+	TestAllowedSourceCIDRs bool `json:"test-allowed-source-cidrs"`
 	// ELBv2NamespaceToDNSName maps each namespace to ALB Ingress DNS name (address).
+	// If a namespace has multiple Ingress objects, it is the first one's.
 	ELBv2NamespaceToDNSName map[string]string `json:"elbv2-namespace-to-dns-name,omitempty"`
+	// AWSResourcesFailures is the list of mismatches between ALB resources
+	// (listeners, listener rules, security groups, target groups)
+	// and Ingress objects, from last ALB resources test.
+	// Must be left empty.
+	AWSResourcesFailures []string `json:"aws-resources-failures,omitempty"`
+	// IngressShards is the list of Ingress objects that serve the generated routes.
+	// Must be left empty.
+	IngressShards []IngressShard `json:"ingress-shards,omitempty"`
 	// ELBv2NameToDNSName maps each ALB name to its DNS name.
 	// e.g. address is 431f09fb-default-ingressfo-0222-899555794.us-west-2.elb.amazonaws.com,
 	// then AWS ELBv2 name is 431f09fb-default-ingressfo-0222.
@@ -345,6 +690,34 @@ type ALBIngressController struct {
 	ScalabilityOutputToUploadPath       string `json:"scalability-output-to-upload-path,omitempty"`
 	ScalabilityOutputToUploadPathBucket string `json:"scalability-output-to-upload-path-bucket,omitempty"`
 	ScalabilityOutputToUploadPathURL    string `json:"scalability-output-to-upload-path-url,omitempty"`
+	// ScalabilityJSONOutputToUploadPath is the structured scalability
+	// test result file path in JSON to upload to cloud storage.
+	// Must be left empty.
+	// This will be overwritten by cluster name.
+	ScalabilityJSONOutputToUploadPath       string `json:"scalability-json-output-to-upload-path,omitempty"`
+	ScalabilityJSONOutputToUploadPathBucket string `json:"scalability-json-output-to-upload-path-bucket,omitempty"`
+	ScalabilityJSONOutputToUploadPathURL    string `json:"scalability-json-output-to-upload-path-url,omitempty"`
+	// DistributedQPSOutputToUploadPath is the merged result file path
+	// in JSON of distributed QPS test to upload to cloud storage.
+	// Must be left empty.
+	// This will be overwritten by cluster name.
+	DistributedQPSOutputToUploadPath       string `json:"distributed-qps-output-to-upload-path,omitempty"`
+	DistributedQPSOutputToUploadPathBucket string `json:"distributed-qps-output-to-upload-path-bucket,omitempty"`
+	DistributedQPSOutputToUploadPathURL    string `json:"distributed-qps-output-to-upload-path-url,omitempty"`
+	// ScalabilityRoutesCSVOutputToUploadPath is the per-route scalability
+	// test result file path in CSV to upload to cloud storage.
+	// Must be left empty.
+	// This will be overwritten by cluster name.
+	ScalabilityRoutesCSVOutputToUploadPath       string `json:"scalability-routes-csv-output-to-upload-path,omitempty"`
+	ScalabilityRoutesCSVOutputToUploadPathBucket string `json:"scalability-routes-csv-output-to-upload-path-bucket,omitempty"`
+	ScalabilityRoutesCSVOutputToUploadPathURL    string `json:"scalability-routes-csv-output-to-upload-path-url,omitempty"`
+	// ScalabilityThroughputCSVOutputToUploadPath is the per-second throughput
+	// of scalability test file path in CSV to upload to cloud storage.
+	// Must be left empty.
+	// This will be overwritten by cluster name.
+	ScalabilityThroughputCSVOutputToUploadPath       string `json:"scalability-throughput-csv-output-to-upload-path,omitempty"`
+	ScalabilityThroughputCSVOutputToUploadPathBucket string `json:"scalability-throughput-csv-output-to-upload-path-bucket,omitempty"`
+	ScalabilityThroughputCSVOutputToUploadPathURL    string `json:"scalability-throughput-csv-output-to-upload-path-url,omitempty"`
 	// MetricsOutputToUploadPath is the ALB Ingress Controller metrics output
 	// file path to upload to cloud storage.
 	// Must be left empty.
@@ -352,6 +725,222 @@ type ALBIngressController struct {
 	MetricsOutputToUploadPath       string `json:"metrics-output-to-upload-path,omitempty"`
 	MetricsOutputToUploadPathBucket string `json:"metrics-output-to-upload-path-bucket,omitempty"`
 	MetricsOutputToUploadPathURL    string `json:"metrics-output-to-upload-path-url,omitempty"`
+	// TargetTypeComparisonOutputToUploadPath is the target type comparison
+	// report file path to upload to cloud storage.
+	// Must be left empty.
+	// This will be overwritten by cluster name.
+	TargetTypeComparisonOutputToUploadPath       string `json:"target-type-comparison-output-to-upload-path,omitempty"`
+	TargetTypeComparisonOutputToUploadPathBucket string `json:"target-type-comparison-output-to-upload-path-bucket,omitempty"`
+	TargetTypeComparisonOutputToUploadPathURL    string `json:"target-type-comparison-output-to-upload-path-url,omitempty"`
+	// ReconcileLatencyOutputToUploadPath is the reconcile latency
+	// report file path to upload to cloud storage.
+	// Must be left empty.
+	// This will be overwritten by cluster name.
+	ReconcileLatencyOutputToUploadPath       string `json:"reconcile-latency-output-to-upload-path,omitempty"`
+	ReconcileLatencyOutputToUploadPathBucket string `json:"reconcile-latency-output-to-upload-path-bucket,omitempty"`
+	ReconcileLatencyOutputToUploadPathURL    string `json:"reconcile-latency-output-to-upload-path-url,omitempty"`
+	// RollingUpdateOutputToUploadPath is the rolling update
+	// report file path to upload to cloud storage.
+	// Must be left empty.
+	// This will be overwritten by cluster name.
+	RollingUpdateOutputToUploadPath       string `json:"rolling-update-output-to-upload-path,omitempty"`
+	RollingUpdateOutputToUploadPathBucket string `json:"rolling-update-output-to-upload-path-bucket,omitempty"`
+	RollingUpdateOutputToUploadPathURL    string `json:"rolling-update-output-to-upload-path-url,omitempty"`
+}
+
+// IngressShard is an Ingress object (and its ALB) that serves
+// a range of ingress test server routes.
+type IngressShard struct {
+	// Namespace is the namespace of Ingress object.
+	Namespace string `json:"namespace"`
+	// Name is the Ingress object name.
+	Name string `json:"name"`
+	// RouteStart is the first route index (inclusive) of the shard.
+	RouteStart int `json:"route-start"`
+	// RouteEnd is the last route index (exclusive) of the shard.
+	RouteEnd int `json:"route-end"`
+	// ELBv2Name is the name of ALB created for the Ingress object.
+	ELBv2Name string `json:"elbv2-name,omitempty"`
+	// DNSName is the DNS name of ALB created for the Ingress object.
+	DNSName string `json:"dns-name,omitempty"`
+}
+
+// ReconcileLatency is the timestamps of each reconcile phase
+// from Ingress object creation to the first successful response.
+// Zero timestamp means the phase was not reached.
+type ReconcileLatency struct {
+	// IngressName is the name of Ingress object.
+	IngressName string `json:"ingress-name"`
+	// IngressCreated is the time when Ingress object was applied.
+	IngressCreated time.Time `json:"ingress-created"`
+	// ALBCreated is the time when ALB ARN first appeared in ELBv2.
+	ALBCreated time.Time `json:"alb-created"`
+	// ListenerRulesCreated is the time when ALB listener rules
+	// (other than default rules) were first found.
+	ListenerRulesCreated time.Time `json:"listener-rules-created"`
+	// TargetHealthy is the time when the first target became healthy.
+	TargetHealthy time.Time `json:"target-healthy"`
+	// DNSResolvable is the time when ALB DNS name first resolved.
+	DNSResolvable time.Time `json:"dns-resolvable"`
+	// FirstSuccess is the time of the first HTTP 200 response.
+	FirstSuccess time.Time `json:"first-success"`
+	// Error is the error message, if the run failed.
+	Error string `json:"error,omitempty"`
+}
+
+// UpgradeResult is the result of ALB Ingress Controller upgrade test.
+type UpgradeResult struct {
+	// FromImage is the controller image before upgrade.
+	FromImage string `json:"from-image"`
+	// ToImage is the controller image after upgrade.
+	ToImage string `json:"to-image"`
+	// RolloutTook is the duration that took to roll out the new controller.
+	RolloutTook string `json:"rollout-took,omitempty"`
+	// Requests is the number of requests sent during upgrade.
+	Requests int64 `json:"requests"`
+	// Failures is the number of failed requests during upgrade.
+	Failures int64 `json:"failures"`
+	// RemovedARNs is the list of ALB listeners, listener rules
+	// and target groups that no longer exist after upgrade.
+	RemovedARNs []string `json:"removed-arns,omitempty"`
+	// AddedARNs is the list of ALB listeners, listener rules
+	// and target groups that were created during upgrade.
+	AddedARNs []string `json:"added-arns,omitempty"`
+}
+
+// RollingUpdateResult is the result of rolling the ingress test server
+// Deployment behind the ALB, with a deregistration delay and readiness gate.
+type RollingUpdateResult struct {
+	// DeregistrationDelaySeconds is the target group deregistration delay.
+	DeregistrationDelaySeconds int `json:"deregistration-delay-seconds"`
+	// ReadinessGate is true if new pods had the readiness gate on ALB target health.
+	ReadinessGate bool `json:"readiness-gate"`
+	// DrainSeconds is the ingress test server drain period on pod termination.
+	DrainSeconds int `json:"drain-seconds"`
+	// Started is the time when the rolling update was triggered.
+	Started time.Time `json:"started"`
+	// Stable is the time when the rollout completed and all targets became healthy.
+	Stable time.Time `json:"stable"`
+	// TimeToStable is the duration from "Started" to "Stable".
+	TimeToStable string `json:"time-to-stable,omitempty"`
+	// Requests is the number of requests sent during the rolling update.
+	Requests int64 `json:"requests"`
+	// Failures is the number of failed requests during the rolling update.
+	Failures int64 `json:"failures"`
+	// ELB502 is the number of HTTP 502 responses in ALB access logs.
+	// -1 if access logs are disabled.
+	ELB502 int `json:"elb-502"`
+	// ELB503 is the number of HTTP 503 responses in ALB access logs.
+	// -1 if access logs are disabled.
+	ELB503 int `json:"elb-503"`
+	// Error is the error message, if the run failed.
+	Error string `json:"error,omitempty"`
+}
+
+// StreamTestResult is the result of a WebSocket or gRPC connection test.
+type StreamTestResult struct {
+	// Protocol is either "websocket" or "grpc".
+	Protocol string `json:"protocol"`
+	// Idle is the idle duration before the last message.
+	Idle time.Duration `json:"idle"`
+	// Messages is the number of messages sent.
+	Messages int64 `json:"messages"`
+	// Failures is the number of messages not echoed back, excluding the message after idle.
+	Failures int64 `json:"failures"`
+	// LatencyP50 is the 50th percentile message round-trip latency.
+	LatencyP50 time.Duration `json:"latency-p50"`
+	// LatencyP99 is the 99th percentile message round-trip latency.
+	LatencyP99 time.Duration `json:"latency-p99"`
+	// Duration is the duration from connect to the last echoed message.
+	Duration time.Duration `json:"duration"`
+	// IdleClosed is true if the connection was closed while idle.
+	IdleClosed bool `json:"idle-closed"`
+}
+
+// ServerFault is the fault-injection behavior of ingress test server routes.
+type ServerFault struct {
+	// Route is the route path (e.g. "/ingress-test-00000"),
+	// or "*" for all generated routes.
+	Route string `json:"route"`
+	// LatencyDistribution is "fixed", "uniform" or "exponential".
+	LatencyDistribution string `json:"latency-distribution,omitempty"`
+	// LatencyMs is the latency to add, or the mean of exponential distribution.
+	LatencyMs int `json:"latency-ms,omitempty"`
+	// LatencyMaxMs is the upper bound of uniform and exponential distribution.
+	LatencyMaxMs int `json:"latency-max-ms,omitempty"`
+	// ErrorRate is the ratio of requests (0 to 1) to respond with "ErrorStatusCode".
+	ErrorRate float64 `json:"error-rate,omitempty"`
+	// ErrorStatusCode is the error status code. Defaults to 503.
+	ErrorStatusCode int `json:"error-status-code,omitempty"`
+	// ResetRate is the ratio of requests (0 to 1) to close the connection abruptly.
+	ResetRate float64 `json:"reset-rate,omitempty"`
+	// SlowBytesPerSecond is the rate to stream the response body.
+	SlowBytesPerSecond int `json:"slow-bytes-per-second,omitempty"`
+	// ResponseSizeMin and ResponseSizeMax are the range of response size.
+	ResponseSizeMin int `json:"response-size-min,omitempty"`
+	ResponseSizeMax int `json:"response-size-max,omitempty"`
+}
+
+// LoadBucket is the QPS test result aggregated over a time window.
+type LoadBucket struct {
+	// Start is the offset of the time window from the test start.
+	Start time.Duration `json:"start"`
+	// Level is the load level of the profile at the start of the time window.
+	Level float64 `json:"level"`
+	// Requests is the number of requests sent in the time window.
+	Requests int64 `json:"requests"`
+	// Success is the number of successful requests.
+	Success int64 `json:"success"`
+	// Failure is the number of failed requests.
+	Failure int64 `json:"failure"`
+	// QPS is the number of successful requests per second.
+	QPS float64 `json:"qps"`
+	// LatencyP50 is the 50th percentile request latency.
+	LatencyP50 time.Duration `json:"latency-p50"`
+	// LatencyP99 is the 99th percentile request latency.
+	LatencyP99 time.Duration `json:"latency-p99"`
+}
+
+// LoadBalancerServiceResult is the test result of a "LoadBalancer" type Service.
+type LoadBalancerServiceResult struct {
+	// Service is the name of Service object.
+	Service string `json:"service"`
+	// Type is either "classic" or "nlb".
+	Type string `json:"type"`
+	// DNSName is the load balancer DNS name.
+	DNSName string `json:"dns-name,omitempty"`
+	// ProvisionTook is the duration that took to create the Service object
+	// until the load balancer serves the test server.
+	ProvisionTook string `json:"provision-took,omitempty"`
+	// QPS is the QPS of QPS test.
+	QPS float64 `json:"qps"`
+	// LatencyP50 is the 50th percentile request latency of QPS test.
+	LatencyP50 time.Duration `json:"latency-p50"`
+	// LatencyP99 is the 99th percentile request latency of QPS test.
+	LatencyP99 time.Duration `json:"latency-p99"`
+	// Failures is the number of failed requests of QPS test.
+	Failures int64 `json:"failures"`
+	// Errors is the list of errors from target validation, correctness and QPS tests.
+	Errors []string `json:"errors,omitempty"`
+}
+
+// TargetTypeResult is the ALB test result of a target type.
+type TargetTypeResult struct {
+	// TargetType is either "instance" or "ip".
+	TargetType string `json:"target-type"`
+	// IngressUpTook is the duration that took to create Ingress objects
+	// until ALB serves the test server.
+	IngressUpTook string `json:"ingress-up-took,omitempty"`
+	// QPS is the QPS of QPS test.
+	QPS float64 `json:"qps"`
+	// LatencyP50 is the 50th percentile request latency of QPS test.
+	LatencyP50 time.Duration `json:"latency-p50"`
+	// LatencyP99 is the 99th percentile request latency of QPS test.
+	LatencyP99 time.Duration `json:"latency-p99"`
+	// Failures is the number of failed requests of QPS test.
+	Failures int64 `json:"failures"`
+	// Errors is the list of test errors (e.g. failed correctness test).
+	Errors []string `json:"errors,omitempty"`
 }
 
 // NewDefault returns a copy of the default configuration.
@@ -424,16 +1013,26 @@ var defaultConfig = Config{
 		TargetType: "instance",
 		TestMode:   "nginx",
 
-		TestScalability:          true,
-		TestScalabilityMinutes:   1,
-		TestMetrics:              true,
-		TestServerReplicas:       1,
-		TestServerRoutes:         1,
-		TestClients:              200,
-		TestClientRequests:       20000,
-		TestResponseSize:         40 * 1024, // 40 KB
-		TestClientErrorThreshold: 10,
-		TestExpectQPS:            20000,
+		TestScalability:            true,
+		TestScalabilityMinutes:     1,
+		TestClientBucketSeconds:    10,
+		TestClientTimeoutSeconds:   30,
+		TestMetrics:                true,
+		TestServerReplicas:         1,
+		TestServerRoutes:           1,
+		TestServerRoutesPerIngress: 30,
+		TestServerNamespaces:       1,
+		TestClients:                200,
+		TestClientRequests:         20000,
+		TestResponseSize:           40 * 1024, // 40 KB
+		TestClientErrorThreshold:   10,
+		TestExpectQPS:              20000,
+		StickinessDurationSeconds:  300,
+		WeightedRoutingWeight:      20,
+		WeightedRoutingTolerance:   0.05,
+		StreamIdleTimeoutSeconds:   30,
+		StreamMessages:             40,
+		StreamIntervalSeconds:      1,
 	},
 }
 
@@ -525,9 +1124,14 @@ func (cfg *Config) BackupConfig() (p string, err error) {
 
 const (
 	// maxTestServerRoutes is the maximum number of routes.
-	maxTestServerRoutes = 30
+	// 50 ALBs (default limit per region) with 30 routes each.
+	maxTestServerRoutes = 1500
+	// maxTestServerRoutesPerIngress is the maximum number of routes per Ingress object.
+	maxTestServerRoutesPerIngress = 30
 	// maxTestClients is the maximum number of clients.
 	maxTestClients = 1000
+	// maxTestDistributedClients is the maximum number of ingress client pods.
+	maxTestDistributedClients = 100
 	// maxTestClientRequests is the maximum number of requests.
 	maxTestClientRequests = 50000
 	// maxTestResponseSize is the maximum response size for ingress test server.
@@ -606,6 +1210,19 @@ func (cfg *Config) ValidateAndSetDefaults() error {
 	if ok := checkEKSEp(cfg.AWSCustomEndpoint); !ok {
 		return fmt.Errorf("AWSCustomEndpoint %q is not valid", cfg.AWSCustomEndpoint)
 	}
+	if err := awsapi.ValidateEndpoints(cfg.AWSEndpoints); err != nil {
+		return fmt.Errorf("invalid AWSEndpoints (%v)", err)
+	}
+	if err := awsapi.ValidateCredentials(cfg.AWSProfile, cfg.AWSRoleARN, cfg.AWSRoleExternalID, cfg.AWSRoleSessionName, cfg.AWSWebIdentityTokenFile); err != nil {
+		return fmt.Errorf("invalid AWS credentials (%v)", err)
+	}
+	// aws-iam-authenticator only takes the session name from the environment with web identity
+	if cfg.AWSRoleSessionName != "" && cfg.AWSWebIdentityTokenFile == "" {
+		return fmt.Errorf("AWSRoleSessionName %q requires AWSWebIdentityTokenFile for kubeconfig", cfg.AWSRoleSessionName)
+	}
+	if ep, ok := cfg.AWSEndpoints["eks"]; ok && cfg.AWSCustomEndpoint != "" && ep != cfg.AWSCustomEndpoint {
+		return fmt.Errorf("AWSCustomEndpoint %q conflicts with AWSEndpoints EKS endpoint %q", cfg.AWSCustomEndpoint, ep)
+	}
 
 	// resources created from aws-k8s-tester always follow
 	// the same naming convention
@@ -709,6 +1326,48 @@ func (cfg *Config) ValidateAndSetDefaults() error {
 		cfg.ALBIngressController.IngressObjectSpecPathBucket,
 	)
 
+	cfg.ALBIngressController.ReconcileLatencyIngressSpecPath = fmt.Sprintf(
+		"%s.%s.alb.ingress.reconcile-latency.yaml",
+		cfg.ConfigPath,
+		cfg.ClusterName,
+	)
+
+	cfg.ALBIngressController.SidecarSpecPath = fmt.Sprintf(
+		"%s.%s.alb.sidecar.yaml",
+		cfg.ConfigPath,
+		cfg.ClusterName,
+	)
+
+	cfg.ALBIngressController.StickinessIngressSpecPath = fmt.Sprintf(
+		"%s.%s.alb.ingress.stickiness.yaml",
+		cfg.ConfigPath,
+		cfg.ClusterName,
+	)
+
+	cfg.ALBIngressController.WeightedRoutingSpecPath = fmt.Sprintf(
+		"%s.%s.alb.weighted-routing.yaml",
+		cfg.ConfigPath,
+		cfg.ClusterName,
+	)
+
+	cfg.ALBIngressController.StreamIngressSpecPath = fmt.Sprintf(
+		"%s.%s.alb.stream.yaml",
+		cfg.ConfigPath,
+		cfg.ClusterName,
+	)
+
+	cfg.ALBIngressController.LoadBalancerServiceSpecPath = fmt.Sprintf(
+		"%s.%s.elb.service.yaml",
+		cfg.ConfigPath,
+		cfg.ClusterName,
+	)
+
+	cfg.ALBIngressController.DistributedClientSpecPath = fmt.Sprintf(
+		"%s.%s.alb.distributed-client.yaml",
+		cfg.ConfigPath,
+		cfg.ClusterName,
+	)
+
 	cfg.ALBIngressController.ScalabilityOutputToUploadPath = fmt.Sprintf(
 		"%s.%s.alb.scalability.txt",
 		cfg.ConfigPath,
@@ -724,6 +1383,66 @@ func (cfg *Config) ValidateAndSetDefaults() error {
 		cfg.ALBIngressController.ScalabilityOutputToUploadPathBucket,
 	)
 
+	cfg.ALBIngressController.ScalabilityJSONOutputToUploadPath = fmt.Sprintf(
+		"%s.%s.alb.scalability.json",
+		cfg.ConfigPath,
+		cfg.ClusterName,
+	)
+	cfg.ALBIngressController.ScalabilityJSONOutputToUploadPathBucket = filepath.Join(
+		cfg.ClusterName,
+		"alb.scalability.json",
+	)
+	cfg.ALBIngressController.ScalabilityJSONOutputToUploadPathURL = genS3URL(
+		cfg.AWSRegion,
+		cfg.Tag,
+		cfg.ALBIngressController.ScalabilityJSONOutputToUploadPathBucket,
+	)
+
+	cfg.ALBIngressController.DistributedQPSOutputToUploadPath = fmt.Sprintf(
+		"%s.%s.alb.distributed-qps.json",
+		cfg.ConfigPath,
+		cfg.ClusterName,
+	)
+	cfg.ALBIngressController.DistributedQPSOutputToUploadPathBucket = filepath.Join(
+		cfg.ClusterName,
+		"alb.distributed-qps.json",
+	)
+	cfg.ALBIngressController.DistributedQPSOutputToUploadPathURL = genS3URL(
+		cfg.AWSRegion,
+		cfg.Tag,
+		cfg.ALBIngressController.DistributedQPSOutputToUploadPathBucket,
+	)
+
+	cfg.ALBIngressController.ScalabilityRoutesCSVOutputToUploadPath = fmt.Sprintf(
+		"%s.%s.alb.scalability.routes.csv",
+		cfg.ConfigPath,
+		cfg.ClusterName,
+	)
+	cfg.ALBIngressController.ScalabilityRoutesCSVOutputToUploadPathBucket = filepath.Join(
+		cfg.ClusterName,
+		"alb.scalability.routes.csv",
+	)
+	cfg.ALBIngressController.ScalabilityRoutesCSVOutputToUploadPathURL = genS3URL(
+		cfg.AWSRegion,
+		cfg.Tag,
+		cfg.ALBIngressController.ScalabilityRoutesCSVOutputToUploadPathBucket,
+	)
+
+	cfg.ALBIngressController.ScalabilityThroughputCSVOutputToUploadPath = fmt.Sprintf(
+		"%s.%s.alb.scalability.throughput.csv",
+		cfg.ConfigPath,
+		cfg.ClusterName,
+	)
+	cfg.ALBIngressController.ScalabilityThroughputCSVOutputToUploadPathBucket = filepath.Join(
+		cfg.ClusterName,
+		"alb.scalability.throughput.csv",
+	)
+	cfg.ALBIngressController.ScalabilityThroughputCSVOutputToUploadPathURL = genS3URL(
+		cfg.AWSRegion,
+		cfg.Tag,
+		cfg.ALBIngressController.ScalabilityThroughputCSVOutputToUploadPathBucket,
+	)
+
 	cfg.ALBIngressController.MetricsOutputToUploadPath = fmt.Sprintf(
 		"%s.%s.alb.metrics.txt",
 		cfg.ConfigPath,
@@ -738,8 +1457,70 @@ func (cfg *Config) ValidateAndSetDefaults() error {
 		cfg.Tag,
 		cfg.ALBIngressController.MetricsOutputToUploadPathBucket,
 	)
+
+	cfg.ALBIngressController.TargetTypeComparisonOutputToUploadPath = fmt.Sprintf(
+		"%s.%s.alb.target-type-comparison.txt",
+		cfg.ConfigPath,
+		cfg.ClusterName,
+	)
+	cfg.ALBIngressController.TargetTypeComparisonOutputToUploadPathBucket = filepath.Join(
+		cfg.ClusterName,
+		"alb.target-type-comparison.txt",
+	)
+	cfg.ALBIngressController.TargetTypeComparisonOutputToUploadPathURL = genS3URL(
+		cfg.AWSRegion,
+		cfg.Tag,
+		cfg.ALBIngressController.TargetTypeComparisonOutputToUploadPathBucket,
+	)
+
+	cfg.ALBIngressController.ReconcileLatencyOutputToUploadPath = fmt.Sprintf(
+		"%s.%s.alb.reconcile-latency.txt",
+		cfg.ConfigPath,
+		cfg.ClusterName,
+	)
+	cfg.ALBIngressController.ReconcileLatencyOutputToUploadPathBucket = filepath.Join(
+		cfg.ClusterName,
+		"alb.reconcile-latency.txt",
+	)
+	cfg.ALBIngressController.ReconcileLatencyOutputToUploadPathURL = genS3URL(
+		cfg.AWSRegion,
+		cfg.Tag,
+		cfg.ALBIngressController.ReconcileLatencyOutputToUploadPathBucket,
+	)
+
+	cfg.ALBIngressController.RollingUpdateOutputToUploadPath = fmt.Sprintf(
+		"%s.%s.alb.rolling-update.txt",
+		cfg.ConfigPath,
+		cfg.ClusterName,
+	)
+	cfg.ALBIngressController.RollingUpdateOutputToUploadPathBucket = filepath.Join(
+		cfg.ClusterName,
+		"alb.rolling-update.txt",
+	)
+	cfg.ALBIngressController.RollingUpdateOutputToUploadPathURL = genS3URL(
+		cfg.AWSRegion,
+		cfg.Tag,
+		cfg.ALBIngressController.RollingUpdateOutputToUploadPathBucket,
+	)
 	////////////////////////////////////////////////////////////////////////
 
+	if cfg.AWSMountRole {
+		if cfg.AWSRoleARN == "" {
+			return errors.New("AWSMountRole requires AWSRoleARN")
+		}
+		if cfg.AWSCredentialToMountPath != "" && cfg.AWSCredentialToMountPath != defaultConfig.AWSCredentialToMountPath {
+			return fmt.Errorf("cannot mount both AWSCredentialToMountPath %q and AWSRoleARN %q", cfg.AWSCredentialToMountPath, cfg.AWSRoleARN)
+		}
+		// the role is mounted instead of the default credential file
+		cfg.AWSCredentialToMountPath = ""
+	}
+	// no default credential file is expected when running without long-lived keys
+	if (cfg.AWSRoleARN != "" || cfg.AWSWebIdentityTokenFile != "") &&
+		cfg.AWSCredentialToMountPath == defaultConfig.AWSCredentialToMountPath &&
+		!exist(cfg.AWSCredentialToMountPath) {
+		cfg.AWSCredentialToMountPath = ""
+	}
+
 	if cfg.AWSCredentialToMountPath != "" && os.Getenv("AWS_SHARED_CREDENTIALS_FILE") == "" {
 		p := cfg.AWSCredentialToMountPath
 		if filepath.IsAbs(p) && !exist(p) {
@@ -758,7 +1539,7 @@ func (cfg *Config) ValidateAndSetDefaults() error {
 	}
 
 	// overwrite with env
-	if os.Getenv("AWS_SHARED_CREDENTIALS_FILE") != "" {
+	if !cfg.AWSMountRole && os.Getenv("AWS_SHARED_CREDENTIALS_FILE") != "" {
 		p := os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
 		var err error
 		p, err = filepath.Abs(p)
@@ -771,7 +1552,7 @@ func (cfg *Config) ValidateAndSetDefaults() error {
 		cfg.AWSCredentialToMountPath = p
 	}
 
-	if cfg.AWSCredentialToMountPath == "" && cfg.ALBIngressController != nil {
+	if cfg.AWSCredentialToMountPath == "" && !cfg.AWSMountRole && cfg.ALBIngressController != nil {
 		if cfg.ALBIngressController.Enable {
 			return errors.New("cannot create AWS ALB Ingress Controller without AWS credential")
 		}
@@ -816,12 +1597,130 @@ func (cfg *Config) ValidateAndSetDefaults() error {
 		cfg.ALBIngressController.ScalabilityOutputToUploadPath = fmt.Sprintf("%s.alb-ingress-controller.scalability.log", cfg.ConfigPath)
 		cfg.ALBIngressController.MetricsOutputToUploadPath = fmt.Sprintf("%s.alb-ingress-controller.metrics.log", cfg.ConfigPath)
 
+		if cfg.ALBIngressController.EnableHTTPS {
+			if cfg.ALBIngressController.HTTPSSSLPolicy == "" {
+				cfg.ALBIngressController.HTTPSSSLPolicy = "ELBSecurityPolicy-2016-08"
+			}
+			cfg.ALBIngressController.HTTPSCertificatePath = fmt.Sprintf("%s.alb-ingress-controller.https.crt", cfg.ConfigPath)
+		}
+
+		for _, cidr := range cfg.ALBIngressController.AllowedSourceCIDRs {
+			if _, _, err := net.ParseCIDR(cidr); err != nil {
+				return fmt.Errorf("invalid ALB allowed source CIDR %q (%v)", cidr, err)
+			}
+		}
+
+		if cfg.ALBIngressController.TestStickiness {
+			if cfg.ALBIngressController.TestMode != "ingress-test-server" {
+				return fmt.Errorf("ALB stickiness test is not supported in test mode %q", cfg.ALBIngressController.TestMode)
+			}
+			if cfg.ALBIngressController.TargetType != "ip" {
+				return fmt.Errorf("ALB stickiness test is not supported with target type %q", cfg.ALBIngressController.TargetType)
+			}
+			if cfg.ALBIngressController.TestServerReplicas < 2 {
+				return fmt.Errorf("ALB stickiness test requires at least 2 test server replicas, got %d", cfg.ALBIngressController.TestServerReplicas)
+			}
+			if cfg.ALBIngressController.StickinessDurationSeconds < 1 || cfg.ALBIngressController.StickinessDurationSeconds > 604800 {
+				return fmt.Errorf("invalid ALB stickiness duration %d seconds (must be 1 to 604800)", cfg.ALBIngressController.StickinessDurationSeconds)
+			}
+		}
+
+		if cfg.ALBIngressController.TestEcho && cfg.ALBIngressController.TestMode != "ingress-test-server" {
+			return fmt.Errorf("ALB echo test is not supported in test mode %q", cfg.ALBIngressController.TestMode)
+		}
+
+		if cfg.ALBIngressController.TestWebSocket || cfg.ALBIngressController.TestGRPC {
+			if cfg.ALBIngressController.TestMode != "ingress-test-server" {
+				return fmt.Errorf("ALB WebSocket and gRPC tests are not supported in test mode %q", cfg.ALBIngressController.TestMode)
+			}
+			if cfg.ALBIngressController.TestGRPC && !cfg.ALBIngressController.EnableHTTPS {
+				return errors.New("ALB gRPC test requires HTTPS listener")
+			}
+			if cfg.ALBIngressController.StreamIdleTimeoutSeconds < 1 || cfg.ALBIngressController.StreamIdleTimeoutSeconds > 4000 {
+				return fmt.Errorf("invalid ALB stream idle timeout %d seconds (must be 1 to 4000)", cfg.ALBIngressController.StreamIdleTimeoutSeconds)
+			}
+			if cfg.ALBIngressController.StreamIntervalSeconds < 1 {
+				return fmt.Errorf("invalid ALB stream interval %d seconds", cfg.ALBIngressController.StreamIntervalSeconds)
+			}
+			if cfg.ALBIngressController.StreamMessages*cfg.ALBIngressController.StreamIntervalSeconds <= cfg.ALBIngressController.StreamIdleTimeoutSeconds {
+				return fmt.Errorf("ALB stream messages %d x interval %d seconds must exceed idle timeout %d seconds",
+					cfg.ALBIngressController.StreamMessages,
+					cfg.ALBIngressController.StreamIntervalSeconds,
+					cfg.ALBIngressController.StreamIdleTimeoutSeconds,
+				)
+			}
+		}
+
+		if cfg.ALBIngressController.TestWeightedRouting {
+			if cfg.ALBIngressController.TestMode != "ingress-test-server" {
+				return fmt.Errorf("ALB weighted routing test is not supported in test mode %q", cfg.ALBIngressController.TestMode)
+			}
+			if cfg.ALBIngressController.WeightedRoutingWeight < 1 || cfg.ALBIngressController.WeightedRoutingWeight > 99 {
+				return fmt.Errorf("invalid ALB weighted routing weight %d (must be 1 to 99)", cfg.ALBIngressController.WeightedRoutingWeight)
+			}
+			if cfg.ALBIngressController.WeightedRoutingTolerance <= 0 || cfg.ALBIngressController.WeightedRoutingTolerance >= 1 {
+				return fmt.Errorf("invalid ALB weighted routing tolerance %f", cfg.ALBIngressController.WeightedRoutingTolerance)
+			}
+		}
+
+		if len(cfg.ALBIngressController.TestRollingUpdateDeregistrationDelays) > 0 {
+			if cfg.ALBIngressController.TestMode != "ingress-test-server" {
+				return fmt.Errorf("ALB rolling update test is not supported in test mode %q", cfg.ALBIngressController.TestMode)
+			}
+			for _, d := range cfg.ALBIngressController.TestRollingUpdateDeregistrationDelays {
+				if d < 0 || d > 3600 {
+					return fmt.Errorf("invalid ALB deregistration delay %d seconds (must be 0 to 3600)", d)
+				}
+			}
+			if cfg.ALBIngressController.TestRollingUpdateReadinessGate && cfg.ALBIngressController.TargetType != "ip" {
+				return fmt.Errorf("ALB readiness gate is not supported with target type %q", cfg.ALBIngressController.TargetType)
+			}
+		}
+
+		if cfg.ALBIngressController.TestLoadBalancerServices && cfg.ALBIngressController.TestMode != "ingress-test-server" {
+			return fmt.Errorf("load balancer Service test is not supported in test mode %q", cfg.ALBIngressController.TestMode)
+		}
+
+		if cfg.ALBIngressController.TestDistributedClients < 0 || cfg.ALBIngressController.TestDistributedClients > maxTestDistributedClients {
+			return fmt.Errorf("invalid ALB distributed clients %d (must be 0 to %d)", cfg.ALBIngressController.TestDistributedClients, maxTestDistributedClients)
+		}
+		if cfg.ALBIngressController.TestDistributedClients > 0 {
+			if cfg.ALBIngressController.TestMode != "ingress-test-server" {
+				return fmt.Errorf("ALB distributed QPS test is not supported in test mode %q", cfg.ALBIngressController.TestMode)
+			}
+			if cfg.ALBIngressController.TestAllowedSourceCIDRs {
+				return errors.New("ALB distributed QPS test requires worker nodes in allowed source CIDRs, while allowed source CIDRs test requires them outside")
+			}
+		}
+
+		if cfg.ALBIngressController.UpgradeIngressControllerImage != "" {
+			if cfg.ALBIngressController.TestMode != "ingress-test-server" {
+				return fmt.Errorf("ALB Ingress Controller upgrade test is not supported in test mode %q", cfg.ALBIngressController.TestMode)
+			}
+			if cfg.ALBIngressController.UpgradeIngressControllerImage == cfg.ALBIngressController.IngressControllerImage {
+				return fmt.Errorf("ALB Ingress Controller upgrade image %q is same as current image", cfg.ALBIngressController.UpgradeIngressControllerImage)
+			}
+		}
+
 		if cfg.ALBIngressController.TestServerRoutes == 0 {
 			return fmt.Errorf("cannot create AWS ALB Ingress Controller with empty test response size %d", cfg.ALBIngressController.TestServerRoutes)
 		}
 		if cfg.ALBIngressController.TestServerRoutes > maxTestServerRoutes {
 			return fmt.Errorf("cannot create AWS ALB Ingress Controller with test routes %d (> max size %d)", cfg.ALBIngressController.TestServerRoutes, maxTestServerRoutes)
 		}
+		if cfg.ALBIngressController.TestServerRoutesPerIngress == 0 {
+			cfg.ALBIngressController.TestServerRoutesPerIngress = maxTestServerRoutesPerIngress
+		}
+		if cfg.ALBIngressController.TestServerRoutesPerIngress > maxTestServerRoutesPerIngress {
+			return fmt.Errorf("cannot create AWS ALB Ingress Controller with test routes per Ingress %d (> max size %d)", cfg.ALBIngressController.TestServerRoutesPerIngress, maxTestServerRoutesPerIngress)
+		}
+		if cfg.ALBIngressController.TestServerNamespaces == 0 {
+			cfg.ALBIngressController.TestServerNamespaces = 1
+		}
+		shards := (cfg.ALBIngressController.TestServerRoutes + cfg.ALBIngressController.TestServerRoutesPerIngress - 1) / cfg.ALBIngressController.TestServerRoutesPerIngress
+		if cfg.ALBIngressController.TestServerNamespaces > shards {
+			return fmt.Errorf("cannot spread %d Ingress objects across %d namespaces", shards, cfg.ALBIngressController.TestServerNamespaces)
+		}
 
 		if cfg.ALBIngressController.TestClients == 0 {
 			return fmt.Errorf("cannot create AWS ALB Ingress Controller with empty test response size %d", cfg.ALBIngressController.TestClients)
@@ -837,6 +1736,109 @@ func (cfg *Config) ValidateAndSetDefaults() error {
 			return fmt.Errorf("cannot create AWS ALB Ingress Controller with test requests %d (> max size %d)", cfg.ALBIngressController.TestClientRequests, maxTestClientRequests)
 		}
 
+		if cfg.ALBIngressController.TestClientRate < 0 {
+			return fmt.Errorf("invalid test client rate %f", cfg.ALBIngressController.TestClientRate)
+		}
+		if cfg.ALBIngressController.TestClientRateStep < 0 {
+			return fmt.Errorf("invalid test client rate step %f", cfg.ALBIngressController.TestClientRateStep)
+		}
+		if cfg.ALBIngressController.TestClientRateStep > 0 {
+			if cfg.ALBIngressController.TestClientRate == 0 {
+				return errors.New("test client rate step requires test client rate")
+			}
+			if cfg.ALBIngressController.TestClientRateStepSeconds <= 0 {
+				return fmt.Errorf("invalid test client rate step seconds %d", cfg.ALBIngressController.TestClientRateStepSeconds)
+			}
+		}
+
+		switch cfg.ALBIngressController.TestClientProfile {
+		case "":
+		case "constant", "ramp", "step", "spike":
+			if cfg.ALBIngressController.TestClientProfileDurationSeconds <= 0 {
+				return fmt.Errorf("invalid test client profile duration seconds %d", cfg.ALBIngressController.TestClientProfileDurationSeconds)
+			}
+			if !cfg.ALBIngressController.TestClientProfileOpenLoop &&
+				(cfg.ALBIngressController.TestClientProfileStart > float64(cfg.ALBIngressController.TestClients) ||
+					cfg.ALBIngressController.TestClientProfilePeak > float64(cfg.ALBIngressController.TestClients)) {
+				return fmt.Errorf("test client profile levels (start %f, peak %f) exceed test clients %d",
+					cfg.ALBIngressController.TestClientProfileStart,
+					cfg.ALBIngressController.TestClientProfilePeak,
+					cfg.ALBIngressController.TestClients,
+				)
+			}
+		default:
+			return fmt.Errorf("unknown test client profile %q", cfg.ALBIngressController.TestClientProfile)
+		}
+		if cfg.ALBIngressController.TestClientProfile == "step" && cfg.ALBIngressController.TestClientProfileSteps < 2 {
+			return fmt.Errorf("invalid test client profile steps %d (must be >= 2)", cfg.ALBIngressController.TestClientProfileSteps)
+		}
+		if cfg.ALBIngressController.TestClientProfile == "spike" &&
+			(cfg.ALBIngressController.TestClientProfileSpikeSeconds <= 0 ||
+				cfg.ALBIngressController.TestClientProfileSpikeStartSeconds+cfg.ALBIngressController.TestClientProfileSpikeSeconds > cfg.ALBIngressController.TestClientProfileDurationSeconds) {
+			return fmt.Errorf("invalid test client profile spike (start %ds, duration %ds) for duration %ds",
+				cfg.ALBIngressController.TestClientProfileSpikeStartSeconds,
+				cfg.ALBIngressController.TestClientProfileSpikeSeconds,
+				cfg.ALBIngressController.TestClientProfileDurationSeconds,
+			)
+		}
+		if cfg.ALBIngressController.TestClientBucketSeconds < 0 {
+			return fmt.Errorf("invalid test client bucket seconds %d", cfg.ALBIngressController.TestClientBucketSeconds)
+		}
+		if cfg.ALBIngressController.TestClientMaxConnsPerHost < 0 {
+			return fmt.Errorf("invalid test client max connections per host %d", cfg.ALBIngressController.TestClientMaxConnsPerHost)
+		}
+		if cfg.ALBIngressController.TestClientTimeoutSeconds < 0 {
+			return fmt.Errorf("invalid test client timeout seconds %d", cfg.ALBIngressController.TestClientTimeoutSeconds)
+		}
+		if cfg.ALBIngressController.TestClientHTTP2 && !cfg.ALBIngressController.EnableHTTPS {
+			return errors.New("test client HTTP/2 requires HTTPS listener")
+		}
+		switch cfg.ALBIngressController.TestClientMethod {
+		case "", "GET":
+			if cfg.ALBIngressController.TestClientBody != "" {
+				return errors.New("test client GET request cannot have body")
+			}
+		case "POST", "PUT":
+		default:
+			return fmt.Errorf("unsupported test client method %q", cfg.ALBIngressController.TestClientMethod)
+		}
+		if cfg.ALBIngressController.TestMode != "ingress-test-server" &&
+			(cfg.ALBIngressController.TestClientMethod != "" || len(cfg.ALBIngressController.TestClientHeaders) > 0) {
+			return fmt.Errorf("test client method and headers are not supported in test mode %q", cfg.ALBIngressController.TestMode)
+		}
+		for _, h := range cfg.ALBIngressController.TestClientHeaders {
+			if strings.Index(h, ":") < 1 {
+				return fmt.Errorf("invalid test client header %q (expected 'Key: Value')", h)
+			}
+		}
+
+		if len(cfg.ALBIngressController.TestServerFaults) > 0 && cfg.ALBIngressController.TestMode != "ingress-test-server" {
+			return fmt.Errorf("test server faults are not supported in test mode %q", cfg.ALBIngressController.TestMode)
+		}
+		for _, f := range cfg.ALBIngressController.TestServerFaults {
+			if f.Route != "*" && !strings.HasPrefix(f.Route, "/") {
+				return fmt.Errorf("invalid test server fault route %q", f.Route)
+			}
+			switch f.LatencyDistribution {
+			case "", "fixed", "uniform", "exponential":
+			default:
+				return fmt.Errorf("unknown test server fault latency distribution %q", f.LatencyDistribution)
+			}
+			if f.ErrorRate < 0 || f.ErrorRate > 1 || f.ResetRate < 0 || f.ResetRate > 1 {
+				return fmt.Errorf("invalid test server fault rates (error %f, reset %f)", f.ErrorRate, f.ResetRate)
+			}
+			if f.ResponseSizeMax > maxTestResponseSize {
+				return fmt.Errorf("invalid test server fault response size %d (> max size %d)", f.ResponseSizeMax, maxTestResponseSize)
+			}
+		}
+
+		if cfg.ALBIngressController.TestServerDrainSeconds != 0 && cfg.ALBIngressController.TestMode != "ingress-test-server" {
+			return fmt.Errorf("test server drain is not supported in test mode %q", cfg.ALBIngressController.TestMode)
+		}
+		if cfg.ALBIngressController.TestServerDrainSeconds < 0 || cfg.ALBIngressController.TestServerDrainSeconds > 3600 {
+			return fmt.Errorf("invalid test server drain %d seconds (must be 0 to 3600)", cfg.ALBIngressController.TestServerDrainSeconds)
+		}
+
 		if cfg.ALBIngressController.TestResponseSize == 0 {
 			return fmt.Errorf("cannot create AWS ALB Ingress Controller with empty test response size %d", cfg.ALBIngressController.TestResponseSize)
 		}
@@ -860,11 +1862,36 @@ func (cfg *Config) SetIngressUpTook(d time.Duration) {
 	cfg.ALBIngressController.IngressUpTook = d.String()
 }
 
+// ServiceEndpoints returns the custom endpoint of each AWS service,
+// including the pre-release EKS endpoint in 'AWSCustomEndpoint'.
+func (cfg *Config) ServiceEndpoints() map[string]string {
+	eps := make(map[string]string, len(cfg.AWSEndpoints)+1)
+	for k, v := range cfg.AWSEndpoints {
+		eps[k] = v
+	}
+	if cfg.AWSCustomEndpoint != "" {
+		eps["eks"] = cfg.AWSCustomEndpoint
+	}
+	return eps
+}
+
 const (
 	envPfx    = "AWS_K8S_TESTER_EKS_"
 	envPfxALB = "AWS_K8S_TESTER_EKS_ALB_"
 )
 
+// splitEnvList splits the comma-separated env value,
+// trimming spaces and dropping empty elements (e.g. "a, b,").
+func splitEnvList(sv string) []string {
+	var ss []string
+	for _, v := range strings.Split(sv, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			ss = append(ss, v)
+		}
+	}
+	return ss
+}
+
 // UpdateFromEnvs updates fields from environmental variables.
 func (cfg *Config) UpdateFromEnvs() error {
 	cc := *cfg
@@ -927,13 +1954,28 @@ func (cfg *Config) UpdateFromEnvs() error {
 			vv1.Field(i).SetFloat(fv)
 
 		case reflect.Slice:
-			ss := strings.Split(sv, ",")
+			ss := splitEnvList(sv)
 			slice := reflect.MakeSlice(reflect.TypeOf([]string{}), len(ss), len(ss))
-			for i := range ss {
-				slice.Index(i).SetString(ss[i])
+			for j := range ss {
+				slice.Index(j).SetString(ss[j])
 			}
 			vv1.Field(i).Set(slice)
 
+		case reflect.Map:
+			// e.g. "eks=https://eks-beta.us-west-2.amazonaws.com,s3=http://localhost:4566"
+			if vv1.Field(i).Type() != reflect.TypeOf(map[string]string{}) {
+				return fmt.Errorf("%q (%v) is not supported as an env", env, vv1.Field(i).Type())
+			}
+			mm := make(map[string]string)
+			for _, kv := range strings.Split(sv, ",") {
+				ss := strings.SplitN(kv, "=", 2)
+				if len(ss) != 2 {
+					return fmt.Errorf("failed to parse %q (%q, expected 'key=value')", sv, env)
+				}
+				mm[ss[0]] = ss[1]
+			}
+			vv1.Field(i).Set(reflect.ValueOf(mm))
+
 		default:
 			return fmt.Errorf("%q (%v) is not supported as an env", env, vv1.Field(i).Type())
 		}
@@ -987,6 +2029,39 @@ func (cfg *Config) UpdateFromEnvs() error {
 			}
 			vv2.Field(i).SetFloat(fv)
 
+		case reflect.Slice:
+			ss := splitEnvList(sv)
+			switch vv2.Field(i).Type() {
+			case reflect.TypeOf([]string{}):
+				slice := reflect.MakeSlice(reflect.TypeOf([]string{}), len(ss), len(ss))
+				for j := range ss {
+					slice.Index(j).SetString(ss[j])
+				}
+				vv2.Field(i).Set(slice)
+
+			case reflect.TypeOf([]int{}):
+				slice := reflect.MakeSlice(reflect.TypeOf([]int{}), len(ss), len(ss))
+				for j := range ss {
+					iv, err := strconv.ParseInt(ss[j], 10, 64)
+					if err != nil {
+						return fmt.Errorf("failed to parse %q (%q, %v)", ss[j], env, err)
+					}
+					slice.Index(j).SetInt(iv)
+				}
+				vv2.Field(i).Set(slice)
+
+			case reflect.TypeOf([]ServerFault{}):
+				// e.g. '[{"route":"*","error-rate":0.1}]'
+				var fs []ServerFault
+				if err := json.Unmarshal([]byte(sv), &fs); err != nil {
+					return fmt.Errorf("failed to parse %q (%q, %v)", sv, env, err)
+				}
+				vv2.Field(i).Set(reflect.ValueOf(fs))
+
+			default:
+				return fmt.Errorf("%q (%v) is not supported as an env", env, vv2.Field(i).Type())
+			}
+
 		default:
 			return fmt.Errorf("%q (%v) is not supported as an env", env, vv2.Field(i).Type())
 		}
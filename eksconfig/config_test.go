@@ -3,6 +3,7 @@ package eksconfig
 import (
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -32,6 +33,13 @@ func TestEnv(t *testing.T) {
 	os.Setenv("AWS_K8S_TESTER_EKS_VPC_ID", "my-vpc-id")
 	os.Setenv("AWS_K8S_TESTER_EKS_SUBNET_IDS", "a,b,c")
 	os.Setenv("AWS_K8S_TESTER_EKS_SECURITY_GROUP_ID", "my-security-id")
+	os.Setenv("AWS_K8S_TESTER_EKS_AWS_PROFILE", "dev")
+	os.Setenv("AWS_K8S_TESTER_EKS_AWS_ROLE_ARN", "arn:aws:iam::123456789012:role/eks-test")
+	os.Setenv("AWS_K8S_TESTER_EKS_AWS_MOUNT_ROLE", "true")
+	os.Setenv("AWS_K8S_TESTER_EKS_AWS_ROLE_EXTERNAL_ID", "my-external-id")
+	os.Setenv("AWS_K8S_TESTER_EKS_AWS_ROLE_SESSION_NAME", "my-session")
+	os.Setenv("AWS_K8S_TESTER_EKS_AWS_WEB_IDENTITY_TOKEN_FILE", "/var/run/secrets/token")
+	os.Setenv("AWS_K8S_TESTER_EKS_AWS_ENDPOINTS", "eks=https://eks-beta.us-west-2.amazonaws.com,s3=http://localhost:4566")
 	os.Setenv("AWS_K8S_TESTER_EKS_ENABLE_WORKER_NODE_HA", "false")
 	os.Setenv("AWS_K8S_TESTER_EKS_ENABLE_WORKER_NODE_SSH", "true")
 	os.Setenv("AWS_K8S_TESTER_EKS_CONFIG_PATH", "test-path")
@@ -51,6 +59,37 @@ func TestEnv(t *testing.T) {
 	os.Setenv("AWS_K8S_TESTER_EKS_ALB_TEST_SCALABILITY", "false")
 	os.Setenv("AWS_K8S_TESTER_EKS_ALB_TEST_METRICS", "false")
 	os.Setenv("AWS_K8S_TESTER_EKS_ALB_INGRESS_CONTROLLER_IMAGE", "quay.io/coreos/alb-ingress-controller:1.0-beta.7")
+	os.Setenv("AWS_K8S_TESTER_EKS_ALB_UPGRADE_INGRESS_CONTROLLER_IMAGE", "quay.io/coreos/alb-ingress-controller:1.0.0")
+	os.Setenv("AWS_K8S_TESTER_EKS_ALB_ENABLE_HTTPS", "true")
+	os.Setenv("AWS_K8S_TESTER_EKS_ALB_COMPARE_TARGET_TYPES", "true")
+	os.Setenv("AWS_K8S_TESTER_EKS_ALB_ALLOWED_SOURCE_CIDRS", " 1.2.3.4/32, 10.0.0.0/8,")
+	os.Setenv("AWS_K8S_TESTER_EKS_ALB_TEST_ALLOWED_SOURCE_CIDRS", "true")
+	os.Setenv("AWS_K8S_TESTER_EKS_ALB_TEST_RECONCILE_LATENCY_RUNS", "5")
+	os.Setenv("AWS_K8S_TESTER_EKS_ALB_TEST_STICKINESS", "true")
+	os.Setenv("AWS_K8S_TESTER_EKS_ALB_TEST_CLIENT_RATE", "500.5")
+	os.Setenv("AWS_K8S_TESTER_EKS_ALB_TEST_CLIENT_RATE_STEP", "100")
+	os.Setenv("AWS_K8S_TESTER_EKS_ALB_TEST_CLIENT_RATE_STEP_SECONDS", "30")
+	os.Setenv("AWS_K8S_TESTER_EKS_ALB_TEST_CLIENT_PROFILE", "ramp")
+	os.Setenv("AWS_K8S_TESTER_EKS_ALB_TEST_CLIENT_PROFILE_DURATION_SECONDS", "600")
+	os.Setenv("AWS_K8S_TESTER_EKS_ALB_TEST_CLIENT_PROFILE_PEAK", "150")
+	os.Setenv("AWS_K8S_TESTER_EKS_ALB_TEST_LOAD_BALANCER_SERVICES", "true")
+	os.Setenv("AWS_K8S_TESTER_EKS_ALB_TEST_DISTRIBUTED_CLIENTS", "5")
+	os.Setenv("AWS_K8S_TESTER_EKS_ALB_TEST_CLIENT_DISABLE_KEEP_ALIVE", "true")
+	os.Setenv("AWS_K8S_TESTER_EKS_ALB_TEST_CLIENT_MAX_CONNS_PER_HOST", "50")
+	os.Setenv("AWS_K8S_TESTER_EKS_ALB_TEST_CLIENT_HEADERS", "X-A: 1,X-B: 2")
+	os.Setenv("AWS_K8S_TESTER_EKS_ALB_TEST_ROLLING_UPDATE_DEREGISTRATION_DELAYS", "0,30")
+	os.Setenv("AWS_K8S_TESTER_EKS_ALB_TEST_ROLLING_UPDATE_READINESS_GATE", "true")
+	os.Setenv("AWS_K8S_TESTER_EKS_ALB_TEST_WEIGHTED_ROUTING", "true")
+	os.Setenv("AWS_K8S_TESTER_EKS_ALB_TEST_ECHO", "true")
+	os.Setenv("AWS_K8S_TESTER_EKS_ALB_TEST_WEBSOCKET", "true")
+	os.Setenv("AWS_K8S_TESTER_EKS_ALB_STREAM_IDLE_TIMEOUT_SECONDS", "20")
+	os.Setenv("AWS_K8S_TESTER_EKS_ALB_WEIGHTED_ROUTING_WEIGHT", "30")
+	os.Setenv("AWS_K8S_TESTER_EKS_ALB_WEIGHTED_ROUTING_TOLERANCE", "0.1")
+	os.Setenv("AWS_K8S_TESTER_EKS_ALB_TEST_SERVER_ROUTES_PER_INGRESS", "10")
+	os.Setenv("AWS_K8S_TESTER_EKS_ALB_TEST_SERVER_NAMESPACES", "3")
+	os.Setenv("AWS_K8S_TESTER_EKS_ALB_TEST_SERVER_FAULTS", `[{"route":"*","latency-ms":100},{"route":"/ingress-test-00000","error-rate":0.5}]`)
+	os.Setenv("AWS_K8S_TESTER_EKS_ALB_TEST_SERVER_DRAIN_SECONDS", "30")
+	os.Setenv("AWS_K8S_TESTER_EKS_ALB_HTTPS_CERTIFICATE_ARN", "arn:aws:acm:us-west-2:123456789012:certificate/test")
 
 	defer func() {
 		os.Unsetenv("AWS_K8S_TESTER_EKS_TEST_MODE")
@@ -59,6 +98,13 @@ func TestEnv(t *testing.T) {
 		os.Unsetenv("AWS_K8S_TESTER_EKS_VPC_ID")
 		os.Unsetenv("AWS_K8S_TESTER_EKS_SUBNET_IDs")
 		os.Unsetenv("AWS_K8S_TESTER_EKS_SECURITY_GROUP_ID")
+		os.Unsetenv("AWS_K8S_TESTER_EKS_AWS_PROFILE")
+		os.Unsetenv("AWS_K8S_TESTER_EKS_AWS_ROLE_ARN")
+		os.Unsetenv("AWS_K8S_TESTER_EKS_AWS_MOUNT_ROLE")
+		os.Unsetenv("AWS_K8S_TESTER_EKS_AWS_ROLE_EXTERNAL_ID")
+		os.Unsetenv("AWS_K8S_TESTER_EKS_AWS_ROLE_SESSION_NAME")
+		os.Unsetenv("AWS_K8S_TESTER_EKS_AWS_WEB_IDENTITY_TOKEN_FILE")
+		os.Unsetenv("AWS_K8S_TESTER_EKS_AWS_ENDPOINTS")
 		os.Unsetenv("AWS_K8S_TESTER_EKS_ENABLE_WORKER_NODE_HA")
 		os.Unsetenv("AWS_K8S_TESTER_EKS_ENABLE_WORKER_NODE_SSH")
 		os.Unsetenv("AWS_K8S_TESTER_EKS_CONFIG_PATH")
@@ -78,6 +124,37 @@ func TestEnv(t *testing.T) {
 		os.Unsetenv("AWS_K8S_TESTER_EKS_ALB_TEST_SCALABILITY")
 		os.Unsetenv("AWS_K8S_TESTER_EKS_ALB_TEST_METRICS")
 		os.Unsetenv("AWS_K8S_TESTER_EKS_ALB_INGRESS_CONTROLLER_IMAGE")
+		os.Unsetenv("AWS_K8S_TESTER_EKS_ALB_UPGRADE_INGRESS_CONTROLLER_IMAGE")
+		os.Unsetenv("AWS_K8S_TESTER_EKS_ALB_ENABLE_HTTPS")
+		os.Unsetenv("AWS_K8S_TESTER_EKS_ALB_COMPARE_TARGET_TYPES")
+		os.Unsetenv("AWS_K8S_TESTER_EKS_ALB_ALLOWED_SOURCE_CIDRS")
+		os.Unsetenv("AWS_K8S_TESTER_EKS_ALB_TEST_ALLOWED_SOURCE_CIDRS")
+		os.Unsetenv("AWS_K8S_TESTER_EKS_ALB_TEST_RECONCILE_LATENCY_RUNS")
+		os.Unsetenv("AWS_K8S_TESTER_EKS_ALB_TEST_STICKINESS")
+		os.Unsetenv("AWS_K8S_TESTER_EKS_ALB_TEST_CLIENT_RATE")
+		os.Unsetenv("AWS_K8S_TESTER_EKS_ALB_TEST_CLIENT_RATE_STEP")
+		os.Unsetenv("AWS_K8S_TESTER_EKS_ALB_TEST_CLIENT_RATE_STEP_SECONDS")
+		os.Unsetenv("AWS_K8S_TESTER_EKS_ALB_TEST_CLIENT_PROFILE")
+		os.Unsetenv("AWS_K8S_TESTER_EKS_ALB_TEST_CLIENT_PROFILE_DURATION_SECONDS")
+		os.Unsetenv("AWS_K8S_TESTER_EKS_ALB_TEST_CLIENT_PROFILE_PEAK")
+		os.Unsetenv("AWS_K8S_TESTER_EKS_ALB_TEST_LOAD_BALANCER_SERVICES")
+		os.Unsetenv("AWS_K8S_TESTER_EKS_ALB_TEST_DISTRIBUTED_CLIENTS")
+		os.Unsetenv("AWS_K8S_TESTER_EKS_ALB_TEST_CLIENT_DISABLE_KEEP_ALIVE")
+		os.Unsetenv("AWS_K8S_TESTER_EKS_ALB_TEST_CLIENT_MAX_CONNS_PER_HOST")
+		os.Unsetenv("AWS_K8S_TESTER_EKS_ALB_TEST_CLIENT_HEADERS")
+		os.Unsetenv("AWS_K8S_TESTER_EKS_ALB_TEST_ROLLING_UPDATE_DEREGISTRATION_DELAYS")
+		os.Unsetenv("AWS_K8S_TESTER_EKS_ALB_TEST_ROLLING_UPDATE_READINESS_GATE")
+		os.Unsetenv("AWS_K8S_TESTER_EKS_ALB_TEST_WEIGHTED_ROUTING")
+		os.Unsetenv("AWS_K8S_TESTER_EKS_ALB_TEST_ECHO")
+		os.Unsetenv("AWS_K8S_TESTER_EKS_ALB_TEST_WEBSOCKET")
+		os.Unsetenv("AWS_K8S_TESTER_EKS_ALB_STREAM_IDLE_TIMEOUT_SECONDS")
+		os.Unsetenv("AWS_K8S_TESTER_EKS_ALB_WEIGHTED_ROUTING_WEIGHT")
+		os.Unsetenv("AWS_K8S_TESTER_EKS_ALB_WEIGHTED_ROUTING_TOLERANCE")
+		os.Unsetenv("AWS_K8S_TESTER_EKS_ALB_TEST_SERVER_ROUTES_PER_INGRESS")
+		os.Unsetenv("AWS_K8S_TESTER_EKS_ALB_TEST_SERVER_NAMESPACES")
+		os.Unsetenv("AWS_K8S_TESTER_EKS_ALB_TEST_SERVER_FAULTS")
+		os.Unsetenv("AWS_K8S_TESTER_EKS_ALB_TEST_SERVER_DRAIN_SECONDS")
+		os.Unsetenv("AWS_K8S_TESTER_EKS_ALB_HTTPS_CERTIFICATE_ARN")
 	}()
 
 	if err := cfg.UpdateFromEnvs(); err != nil {
@@ -102,6 +179,34 @@ func TestEnv(t *testing.T) {
 	if cfg.SecurityGroupID != "my-security-id" {
 		t.Fatalf("SecurityGroupID my-id, got %q", cfg.SecurityGroupID)
 	}
+	if cfg.AWSProfile != "dev" {
+		t.Fatalf("AWSProfile unexpected %q", cfg.AWSProfile)
+	}
+	if cfg.AWSRoleARN != "arn:aws:iam::123456789012:role/eks-test" {
+		t.Fatalf("AWSRoleARN unexpected %q", cfg.AWSRoleARN)
+	}
+	if !cfg.AWSMountRole {
+		t.Fatalf("AWSMountRole unexpected %v", cfg.AWSMountRole)
+	}
+	if cfg.AWSRoleExternalID != "my-external-id" {
+		t.Fatalf("AWSRoleExternalID unexpected %q", cfg.AWSRoleExternalID)
+	}
+	if cfg.AWSRoleSessionName != "my-session" {
+		t.Fatalf("AWSRoleSessionName unexpected %q", cfg.AWSRoleSessionName)
+	}
+	if cfg.AWSWebIdentityTokenFile != "/var/run/secrets/token" {
+		t.Fatalf("AWSWebIdentityTokenFile unexpected %q", cfg.AWSWebIdentityTokenFile)
+	}
+	expEps := map[string]string{
+		"eks": "https://eks-beta.us-west-2.amazonaws.com",
+		"s3":  "http://localhost:4566",
+	}
+	if !reflect.DeepEqual(cfg.AWSEndpoints, expEps) {
+		t.Fatalf("AWSEndpoints expected %v, got %v", expEps, cfg.AWSEndpoints)
+	}
+	if !reflect.DeepEqual(cfg.ServiceEndpoints(), expEps) {
+		t.Fatalf("ServiceEndpoints expected %v, got %v", expEps, cfg.ServiceEndpoints())
+	}
 	if cfg.ConfigPath != "test-path" {
 		t.Fatalf("alb configuration path expected 'test-path', got %q", cfg.ConfigPath)
 	}
@@ -144,6 +249,9 @@ func TestEnv(t *testing.T) {
 	if cfg.ALBIngressController.IngressControllerImage != "quay.io/coreos/alb-ingress-controller:1.0-beta.7" {
 		t.Fatalf("cfg.ALBIngressController.IngressControllerImage expected 'quay.io/coreos/alb-ingress-controller:1.0-beta.7', got %q", cfg.ALBIngressController.IngressControllerImage)
 	}
+	if cfg.ALBIngressController.UpgradeIngressControllerImage != "quay.io/coreos/alb-ingress-controller:1.0.0" {
+		t.Fatalf("cfg.ALBIngressController.UpgradeIngressControllerImage expected 'quay.io/coreos/alb-ingress-controller:1.0.0', got %q", cfg.ALBIngressController.UpgradeIngressControllerImage)
+	}
 	if cfg.ALBIngressController.TestExpectQPS != 123.45 {
 		t.Fatalf("cfg.ALBIngressController.TestExpectQPS expected 123.45, got %v", cfg.ALBIngressController.TestExpectQPS)
 	}
@@ -159,4 +267,168 @@ func TestEnv(t *testing.T) {
 	if cfg.ALBIngressController.TestMetrics {
 		t.Fatalf("cfg.ALBIngressController.TestMetrics expected 'false', got %v", cfg.ALBIngressController.TestMetrics)
 	}
+	if !cfg.ALBIngressController.EnableHTTPS {
+		t.Fatalf("cfg.ALBIngressController.EnableHTTPS expected 'true', got %v", cfg.ALBIngressController.EnableHTTPS)
+	}
+	if !cfg.ALBIngressController.CompareTargetTypes {
+		t.Fatalf("cfg.ALBIngressController.CompareTargetTypes expected 'true', got %v", cfg.ALBIngressController.CompareTargetTypes)
+	}
+	if !reflect.DeepEqual(cfg.ALBIngressController.AllowedSourceCIDRs, []string{"1.2.3.4/32", "10.0.0.0/8"}) {
+		t.Fatalf("unexpected cfg.ALBIngressController.AllowedSourceCIDRs %v", cfg.ALBIngressController.AllowedSourceCIDRs)
+	}
+	if !cfg.ALBIngressController.TestAllowedSourceCIDRs {
+		t.Fatalf("cfg.ALBIngressController.TestAllowedSourceCIDRs expected 'true', got %v", cfg.ALBIngressController.TestAllowedSourceCIDRs)
+	}
+	if !cfg.ALBIngressController.TestStickiness {
+		t.Fatalf("cfg.ALBIngressController.TestStickiness expected 'true', got %v", cfg.ALBIngressController.TestStickiness)
+	}
+	if !reflect.DeepEqual(cfg.ALBIngressController.TestRollingUpdateDeregistrationDelays, []int{0, 30}) {
+		t.Fatalf("unexpected cfg.ALBIngressController.TestRollingUpdateDeregistrationDelays %v", cfg.ALBIngressController.TestRollingUpdateDeregistrationDelays)
+	}
+	if !cfg.ALBIngressController.TestRollingUpdateReadinessGate {
+		t.Fatalf("cfg.ALBIngressController.TestRollingUpdateReadinessGate expected 'true', got %v", cfg.ALBIngressController.TestRollingUpdateReadinessGate)
+	}
+	if cfg.ALBIngressController.TestClientRate != 500.5 {
+		t.Fatalf("cfg.ALBIngressController.TestClientRate expected 500.5, got %f", cfg.ALBIngressController.TestClientRate)
+	}
+	if cfg.ALBIngressController.TestClientRateStep != 100 {
+		t.Fatalf("cfg.ALBIngressController.TestClientRateStep expected 100, got %f", cfg.ALBIngressController.TestClientRateStep)
+	}
+	if cfg.ALBIngressController.TestClientRateStepSeconds != 30 {
+		t.Fatalf("cfg.ALBIngressController.TestClientRateStepSeconds expected 30, got %d", cfg.ALBIngressController.TestClientRateStepSeconds)
+	}
+	if cfg.ALBIngressController.TestClientProfile != "ramp" {
+		t.Fatalf("cfg.ALBIngressController.TestClientProfile expected 'ramp', got %q", cfg.ALBIngressController.TestClientProfile)
+	}
+	if cfg.ALBIngressController.TestClientProfileDurationSeconds != 600 {
+		t.Fatalf("cfg.ALBIngressController.TestClientProfileDurationSeconds expected 600, got %d", cfg.ALBIngressController.TestClientProfileDurationSeconds)
+	}
+	if cfg.ALBIngressController.TestClientProfilePeak != 150 {
+		t.Fatalf("cfg.ALBIngressController.TestClientProfilePeak expected 150, got %f", cfg.ALBIngressController.TestClientProfilePeak)
+	}
+	if !cfg.ALBIngressController.TestLoadBalancerServices {
+		t.Fatalf("cfg.ALBIngressController.TestLoadBalancerServices expected 'true', got %v", cfg.ALBIngressController.TestLoadBalancerServices)
+	}
+	if !cfg.ALBIngressController.TestClientDisableKeepAlive {
+		t.Fatalf("cfg.ALBIngressController.TestClientDisableKeepAlive expected 'true', got %v", cfg.ALBIngressController.TestClientDisableKeepAlive)
+	}
+	if cfg.ALBIngressController.TestClientMaxConnsPerHost != 50 {
+		t.Fatalf("cfg.ALBIngressController.TestClientMaxConnsPerHost expected 50, got %d", cfg.ALBIngressController.TestClientMaxConnsPerHost)
+	}
+	if !reflect.DeepEqual(cfg.ALBIngressController.TestClientHeaders, []string{"X-A: 1", "X-B: 2"}) {
+		t.Fatalf("unexpected cfg.ALBIngressController.TestClientHeaders %v", cfg.ALBIngressController.TestClientHeaders)
+	}
+	if cfg.ALBIngressController.TestDistributedClients != 5 {
+		t.Fatalf("cfg.ALBIngressController.TestDistributedClients expected 5, got %d", cfg.ALBIngressController.TestDistributedClients)
+	}
+	if !cfg.ALBIngressController.TestWeightedRouting {
+		t.Fatalf("cfg.ALBIngressController.TestWeightedRouting expected 'true', got %v", cfg.ALBIngressController.TestWeightedRouting)
+	}
+	if !cfg.ALBIngressController.TestEcho {
+		t.Fatalf("cfg.ALBIngressController.TestEcho expected 'true', got %v", cfg.ALBIngressController.TestEcho)
+	}
+	if !cfg.ALBIngressController.TestWebSocket {
+		t.Fatalf("cfg.ALBIngressController.TestWebSocket expected 'true', got %v", cfg.ALBIngressController.TestWebSocket)
+	}
+	if cfg.ALBIngressController.StreamIdleTimeoutSeconds != 20 {
+		t.Fatalf("cfg.ALBIngressController.StreamIdleTimeoutSeconds expected 20, got %d", cfg.ALBIngressController.StreamIdleTimeoutSeconds)
+	}
+	if cfg.ALBIngressController.WeightedRoutingWeight != 30 {
+		t.Fatalf("cfg.ALBIngressController.WeightedRoutingWeight expected 30, got %d", cfg.ALBIngressController.WeightedRoutingWeight)
+	}
+	if cfg.ALBIngressController.WeightedRoutingTolerance != 0.1 {
+		t.Fatalf("cfg.ALBIngressController.WeightedRoutingTolerance expected 0.1, got %f", cfg.ALBIngressController.WeightedRoutingTolerance)
+	}
+	if cfg.ALBIngressController.TestReconcileLatencyRuns != 5 {
+		t.Fatalf("cfg.ALBIngressController.TestReconcileLatencyRuns expected 5, got %d", cfg.ALBIngressController.TestReconcileLatencyRuns)
+	}
+	if cfg.ALBIngressController.TestServerRoutesPerIngress != 10 {
+		t.Fatalf("cfg.ALBIngressController.TestServerRoutesPerIngress expected 10, got %d", cfg.ALBIngressController.TestServerRoutesPerIngress)
+	}
+	if cfg.ALBIngressController.TestServerNamespaces != 3 {
+		t.Fatalf("cfg.ALBIngressController.TestServerNamespaces expected 3, got %d", cfg.ALBIngressController.TestServerNamespaces)
+	}
+	expFaults := []ServerFault{{Route: "*", LatencyMs: 100}, {Route: "/ingress-test-00000", ErrorRate: 0.5}}
+	if !reflect.DeepEqual(cfg.ALBIngressController.TestServerFaults, expFaults) {
+		t.Fatalf("unexpected cfg.ALBIngressController.TestServerFaults %+v", cfg.ALBIngressController.TestServerFaults)
+	}
+	if cfg.ALBIngressController.TestServerDrainSeconds != 30 {
+		t.Fatalf("cfg.ALBIngressController.TestServerDrainSeconds expected 30, got %d", cfg.ALBIngressController.TestServerDrainSeconds)
+	}
+	if cfg.ALBIngressController.HTTPSCertificateARN != "arn:aws:acm:us-west-2:123456789012:certificate/test" {
+		t.Fatalf("unexpected cfg.ALBIngressController.HTTPSCertificateARN %q", cfg.ALBIngressController.HTTPSCertificateARN)
+	}
+}
+
+func TestValidateCredentials(t *testing.T) {
+	cfg := NewDefault()
+	cfg.AWSRoleARN = "arn:aws:iam::123456789012:role/eks-test"
+	cfg.AWSRoleExternalID = "my-external-id"
+	cfg.AWSWebIdentityTokenFile = "/var/run/secrets/token"
+	cfg.ALBIngressController.TestServerReplicas = 1
+
+	err := cfg.ValidateAndSetDefaults()
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if !strings.Contains(err.Error(), "external ID") {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	// kubeconfig cannot pass the session name without web identity
+	cfg = NewDefault()
+	cfg.AWSRoleARN = "arn:aws:iam::123456789012:role/eks-test"
+	cfg.AWSRoleSessionName = "my-session"
+	cfg.ALBIngressController.TestServerReplicas = 1
+
+	err = cfg.ValidateAndSetDefaults()
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if !strings.Contains(err.Error(), "AWSRoleSessionName") {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestValidateMountRole(t *testing.T) {
+	cfg := NewDefault()
+	cfg.AWSRoleARN = "arn:aws:iam::123456789012:role/eks-test"
+	cfg.AWSMountRole = true
+	cfg.ALBIngressController.Enable = true
+	cfg.ALBIngressController.TestServerReplicas = 1
+
+	if err := cfg.ValidateAndSetDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(cfg.ConfigPath)
+	if cfg.AWSCredentialToMountPath != "" {
+		t.Fatalf("expected empty AWSCredentialToMountPath with role mounted, got %q", cfg.AWSCredentialToMountPath)
+	}
+
+	// explicit credential file must not be discarded
+	cfg = NewDefault()
+	cfg.AWSCredentialToMountPath = "/does-not-exist/credentials"
+	cfg.AWSRoleARN = "arn:aws:iam::123456789012:role/eks-test"
+	cfg.AWSMountRole = true
+	cfg.ALBIngressController.TestServerReplicas = 1
+	if err := cfg.ValidateAndSetDefaults(); err == nil {
+		t.Fatal("expected error with both AWSCredentialToMountPath and AWSMountRole")
+	}
+
+	cfg = NewDefault()
+	cfg.AWSMountRole = true
+	cfg.ALBIngressController.TestServerReplicas = 1
+	if err := cfg.ValidateAndSetDefaults(); err == nil {
+		t.Fatal("expected error with AWSMountRole but no AWSRoleARN")
+	}
+
+	// role is not mounted unless opted in
+	cfg = NewDefault()
+	cfg.AWSCredentialToMountPath = ""
+	cfg.AWSRoleARN = "arn:aws:iam::123456789012:role/eks-test"
+	cfg.ALBIngressController.Enable = true
+	cfg.ALBIngressController.TestServerReplicas = 1
+	if err := cfg.ValidateAndSetDefaults(); err == nil {
+		t.Fatal("expected error with ALB Ingress Controller but no credential to mount")
+	}
 }
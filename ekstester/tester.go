@@ -49,6 +49,29 @@ type ALB interface {
 	// TestALBQPS runs ingress load testing.
 	// And returns an error if QPS is less than expected QPS.
 	TestALBQPS() error
+	// TestALBTargetTypes runs correctness and QPS tests with
+	// both "instance" and "ip" target types, and writes
+	// a side-by-side comparison report.
+	TestALBTargetTypes() error
+	// TestALBUpgrade rolls ALB Ingress Controller to the upgrade image
+	// while sending test traffic, and returns an error if ALB resources
+	// were re-created or client errors exceed the threshold.
+	TestALBUpgrade() error
+	// TestALBReconcileLatency measures the latency of each reconcile phase
+	// from Ingress object creation to the first successful response.
+	TestALBReconcileLatency() error
+	// TestALBRollingUpdate rolls the ingress test server Deployment
+	// with each deregistration delay and readiness gate setting while
+	// sending test traffic, and reports failed requests, ALB 502/503
+	// counts and time-to-stable.
+	TestALBRollingUpdate() error
+	// TestELBServices creates "LoadBalancer" type Services with classic ELB
+	// and NLB for the ingress test server, and runs target validation,
+	// correctness and QPS tests against each load balancer.
+	TestELBServices() error
+	// TestALBDistributedQPS sends QPS test traffic from ingress client pods
+	// on worker nodes at the same time, and merges their results.
+	TestALBDistributedQPS() error
 	// TestALBMetrics checks if ALB Ingress Controller
 	// is serving /metrics endpoint.
 	TestALBMetrics() error
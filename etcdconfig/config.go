@@ -66,6 +66,8 @@ type Config struct {
 	UploadTesterLogs bool `json:"upload-tester-logs"`
 
 	// EC2 defines ec2 instance configuration.
+	// AWS credential sources (e.g. "aws-profile", "aws-role-arn",
+	// "aws-web-identity-token-file") are configured here.
 	// Ignored for local tests.
 	EC2 *ec2config.Config `json:"ec2"`
 
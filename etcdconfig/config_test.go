@@ -79,6 +79,8 @@ func TestEnv(t *testing.T) {
 	cfg := NewDefault()
 
 	os.Setenv("AWS_K8S_TESTER_EC2_COUNT", "100")
+	os.Setenv("AWS_K8S_TESTER_EC2_AWS_ROLE_ARN", "arn:aws:iam::123456789012:role/etcd-test")
+	os.Setenv("AWS_K8S_TESTER_EC2_AWS_ROLE_EXTERNAL_ID", "my-external-id")
 	os.Setenv("AWS_K8S_TESTER_ETCD_TAG", "my-test")
 	os.Setenv("AWS_K8S_TESTER_ETCD_CLUSTER_NAME", "my-cluster")
 	os.Setenv("AWS_K8S_TESTER_ETCD_DOWN", "false")
@@ -89,6 +91,8 @@ func TestEnv(t *testing.T) {
 	os.Setenv("AWS_K8S_TESTER_ETCD_CLUSTER_TOP_LEVEL", "true")
 
 	defer func() {
+		os.Unsetenv("AWS_K8S_TESTER_EC2_AWS_ROLE_ARN")
+		os.Unsetenv("AWS_K8S_TESTER_EC2_AWS_ROLE_EXTERNAL_ID")
 		os.Unsetenv("AWS_K8S_TESTER_ETCD_TAG")
 		os.Unsetenv("AWS_K8S_TESTER_ETCD_CLUSTER_NAME")
 		os.Unsetenv("AWS_K8S_TESTER_ETCD_DOWN")
@@ -106,6 +110,12 @@ func TestEnv(t *testing.T) {
 	if cfg.EC2.Count != 100 {
 		t.Fatalf("EC2.Count expected 100, got %d", cfg.EC2.Count)
 	}
+	if cfg.EC2.AWSRoleARN != "arn:aws:iam::123456789012:role/etcd-test" {
+		t.Fatalf("unexpected EC2.AWSRoleARN, got %q", cfg.EC2.AWSRoleARN)
+	}
+	if cfg.EC2.AWSRoleExternalID != "my-external-id" {
+		t.Fatalf("unexpected EC2.AWSRoleExternalID, got %q", cfg.EC2.AWSRoleExternalID)
+	}
 	if cfg.Tag != "my-test" {
 		t.Fatalf("unexpected Tag, got %q", cfg.Tag)
 	}
@@ -38,7 +38,7 @@ require (
 	google.golang.org/api v0.0.0-20181021000519-a2651947f503 // indirect
 	google.golang.org/appengine v1.2.0 // indirect
 	google.golang.org/genproto v0.0.0-20181016170114-94acd270e44e // indirect
-	google.golang.org/grpc v1.15.0 // indirect
+	google.golang.org/grpc v1.15.0
 	gopkg.in/inf.v0 v0.9.1 // indirect
 	gopkg.in/robfig/cron.v2 v2.0.0-20150107220207-be2e0b0deed5 // indirect
 	k8s.io/api v0.0.0-20181018013834-843ad2d9b9ae
@@ -2,6 +2,7 @@ package alblog
 
 import (
 	"bufio"
+	"compress/gzip"
 	"encoding/csv"
 	"fmt"
 	"io"
@@ -86,15 +87,16 @@ type Log struct {
 }
 
 // Parse parses ALB access logs.
+// Gzipped log files (with ".gz" extension), as delivered to S3, are supported.
 // https://docs.aws.amazon.com/elasticloadbalancing/latest/application/load-balancer-access-logs.html
 func Parse(p string) (logs []Log, err error) {
-	f, err := os.OpenFile(p, os.O_RDONLY, 0444)
+	rd, err := open(p)
 	if err != nil {
 		return nil, err
 	}
-	defer f.Close()
+	defer rd.Close()
 	logs = make([]Log, 0)
-	br := bufio.NewReader(f)
+	br := bufio.NewReader(rd)
 	for {
 		l, err := br.ReadString('\n')
 		if err == io.EOF {
@@ -150,13 +152,13 @@ func ConvertToCSV(output string, ps ...string) error {
 	rows := make([][]string, 0)
 
 	for _, p := range ps {
-		f, err := os.OpenFile(p, os.O_RDONLY, 0444)
+		rd, err := open(p)
 		if err != nil {
 			return err
 		}
-		defer f.Close()
+		defer rd.Close()
 
-		br := bufio.NewReader(f)
+		br := bufio.NewReader(rd)
 		for {
 			l, err := br.ReadString('\n')
 			if err == io.EOF {
@@ -180,6 +182,33 @@ func ConvertToCSV(output string, ps ...string) error {
 	return csvutil.Save(logHeader, rows, output)
 }
 
+// open opens the log file, decompressing it if gzipped.
+func open(p string) (io.ReadCloser, error) {
+	f, err := os.OpenFile(p, os.O_RDONLY, 0444)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(p, ".gz") {
+		return f, nil
+	}
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &gzipReadCloser{Reader: gr, f: f}, nil
+}
+
+type gzipReadCloser struct {
+	*gzip.Reader
+	f *os.File
+}
+
+func (rc *gzipReadCloser) Close() error {
+	rc.Reader.Close()
+	return rc.f.Close()
+}
+
 func splitLog(l string) (fields []string, err error) {
 	rd := csv.NewReader(strings.NewReader(l))
 
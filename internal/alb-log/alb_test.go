@@ -1,9 +1,12 @@
 package alblog
 
 import (
+	"bytes"
+	"compress/gzip"
 	"fmt"
 	"io/ioutil"
 	"os"
+	"reflect"
 	"testing"
 )
 
@@ -46,3 +49,42 @@ func TestALB(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestParseGzip(t *testing.T) {
+	d, err := ioutil.ReadFile("alb.log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := ioutil.TempFile(os.TempDir(), "elblog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := f.Name() + ".log.gz"
+	f.Close()
+	os.RemoveAll(f.Name())
+	defer os.RemoveAll(p)
+
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	if _, err = gw.Write(d); err != nil {
+		t.Fatal(err)
+	}
+	if err = gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(p, buf.Bytes(), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	expected, err := Parse("alb.log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	logs, err := Parse(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expected, logs) {
+		t.Fatalf("expected %d logs, got %d", len(expected), len(logs))
+	}
+}
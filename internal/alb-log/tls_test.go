@@ -0,0 +1,21 @@
+package alblog
+
+import (
+	"crypto/tls"
+	"testing"
+)
+
+func TestTLS(t *testing.T) {
+	if v := TLSProtocol(tls.VersionTLS12); v != "TLSv1.2" {
+		t.Fatalf("expected 'TLSv1.2', got %q", v)
+	}
+	if v := TLSProtocol(0x0200); v != "unknown-0x0200" {
+		t.Fatalf("expected 'unknown-0x0200', got %q", v)
+	}
+	if v := TLSCipher(tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256); v != "ECDHE-RSA-AES128-GCM-SHA256" {
+		t.Fatalf("expected 'ECDHE-RSA-AES128-GCM-SHA256', got %q", v)
+	}
+	if v := TLSCipher(tls.TLS_AES_128_GCM_SHA256); v != "TLS_AES_128_GCM_SHA256" {
+		t.Fatalf("expected 'TLS_AES_128_GCM_SHA256', got %q", v)
+	}
+}
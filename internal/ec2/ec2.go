@@ -50,10 +50,14 @@ type embedded struct {
 	lg  *zap.Logger
 	cfg *ec2config.Config
 
-	ss  *session.Session
-	sts stsiface.STSAPI
-	cf  cloudformationiface.CloudFormationAPI
-	ec2 ec2iface.EC2API
+	ss *session.Session
+	// stats counts calls, retries and throttles of each AWS API
+	stats *awsapi.Stats
+	sts   stsiface.STSAPI
+	cf    cloudformationiface.CloudFormationAPI
+	ec2   ec2iface.EC2API
+	// recorder records AWS API calls, if "AWSAPIRecordPath" is set
+	recorder *awsapi.Recorder
 
 	s3        s3iface.S3API
 	s3Buckets map[string]struct{}
@@ -79,13 +83,30 @@ func NewDeployer(cfg *ec2config.Config) (Deployer, error) {
 		lg:        lg,
 		cfg:       cfg,
 		s3Buckets: make(map[string]struct{}),
+		stats:     awsapi.NewStats(),
+	}
+
+	if cfg.AWSAPIRecordPath != "" {
+		md.recorder, err = awsapi.NewRecorder(cfg.AWSAPIRecordPath)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	awsCfg := &awsapi.Config{
-		Logger:         md.lg,
-		DebugAPICalls:  cfg.LogDebug,
-		Region:         cfg.AWSRegion,
-		CustomEndpoint: "",
+		Logger:               md.lg,
+		DebugAPICalls:        cfg.LogDebug,
+		Region:               cfg.AWSRegion,
+		Endpoints:            cfg.AWSEndpoints,
+		Profile:              cfg.AWSProfile,
+		RoleARN:              cfg.AWSRoleARN,
+		RoleExternalID:       cfg.AWSRoleExternalID,
+		RoleSessionName:      cfg.AWSRoleSessionName,
+		WebIdentityTokenFile: cfg.AWSWebIdentityTokenFile,
+		Recorder:             md.recorder,
+		Retry:                cfg.AWSAPIRetry,
+		ServiceRetry:         cfg.AWSAPIServiceRetry,
+		Stats:                md.stats,
 	}
 	md.ss, err = awsapi.New(awsCfg)
 	if err != nil {
@@ -118,6 +139,7 @@ func (md *embedded) Create() (err error) {
 
 	now := time.Now().UTC()
 	md.lg.Info("creating", zap.String("cluster-name", md.cfg.ClusterName))
+	defer md.syncAWSAPIStats()
 
 	defer func() {
 		if err != nil {
@@ -216,9 +238,14 @@ func (md *embedded) Stop() { close(md.stopc) }
 func (md *embedded) Delete() (err error) {
 	md.mu.Lock()
 	defer md.mu.Unlock()
+	if md.recorder != nil {
+		// deletion is the last step of the run
+		defer md.recorder.Close()
+	}
 
 	now := time.Now().UTC()
 	md.lg.Info("deleting", zap.String("cluster-name", md.cfg.ClusterName))
+	defer md.syncAWSAPIStats()
 
 	var errs []string
 	if err = md.deleteInstances(); err != nil {
@@ -265,6 +292,14 @@ func (md *embedded) Delete() (err error) {
 	return nil
 }
 
+// syncAWSAPIStats writes AWS API call statistics to the config file.
+func (md *embedded) syncAWSAPIStats() {
+	md.cfg.AWSAPIStats = awsapi.StatsSnapshot(md.lg, md.stats)
+	if err := md.cfg.Sync(); err != nil {
+		md.lg.Warn("failed to sync AWS API stats", zap.Error(err))
+	}
+}
+
 func (md *embedded) uploadTesterLogs() (err error) {
 	if err = md.UploadToBucketForTests(
 		md.cfg.ConfigPath,
@@ -3,9 +3,13 @@ package ec2
 import (
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/aws/aws-k8s-tester/ec2config"
+	"github.com/aws/aws-k8s-tester/pkg/awsapi"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"go.uber.org/zap"
 )
 
 func TestVPC(t *testing.T) {
@@ -31,3 +35,31 @@ func TestVPC(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+// TestVPCReplay replays the eventual consistency error returned when
+// tagging a newly created VPC, without calling AWS.
+func TestVPCReplay(t *testing.T) {
+	lg := zap.NewExample()
+	ss, err := awsapi.New(&awsapi.Config{
+		Logger:     lg,
+		Region:     "us-west-2",
+		ReplayPath: "testdata/create-vpc-tags-not-found.json",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	md := &embedded{
+		lg:  lg,
+		cfg: ec2config.NewDefault(),
+		ss:  ss,
+		ec2: ec2.New(ss),
+	}
+
+	err = md.createVPC()
+	if err == nil || !strings.Contains(err.Error(), "InvalidVpcID.NotFound") {
+		t.Fatalf("expected 'InvalidVpcID.NotFound' error, got %v", err)
+	}
+	if md.cfg.VPCID != "vpc-0a1b2c3d4e5f67890" {
+		t.Fatalf("unexpected VPC ID %q", md.cfg.VPCID)
+	}
+}
@@ -0,0 +1,228 @@
+package alb
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-k8s-tester/eksconfig"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	gyaml "github.com/ghodss/yaml"
+	"k8s.io/api/extensions/v1beta1"
+)
+
+// elbv2NameFromDNSName returns the ALB name from its DNS name.
+// e.g. address is 431f09fb-default-ingressfo-0222-899555794.us-west-2.elb.amazonaws.com,
+// then AWS ELBv2 name is 431f09fb-default-ingressfo-0222.
+func elbv2NameFromDNSName(h string) string {
+	ss := strings.Split(h, "-")
+	if len(ss) < 4 {
+		return h
+	}
+	return strings.Join(ss[:4], "-")
+}
+
+// ingressELBv2Names maps each Ingress object "namespace/name"
+// to the name of ALB created for it.
+func ingressELBv2Names(cfg *eksconfig.Config) map[string]string {
+	names := make(map[string]string)
+	if h, ok := cfg.ALBIngressController.ELBv2NamespaceToDNSName["kube-system"]; ok {
+		names["kube-system/ingress-for-alb-ingress-controller-service"] = elbv2NameFromDNSName(h)
+	}
+	for _, shard := range cfg.ALBIngressController.IngressShards {
+		if shard.ELBv2Name != "" {
+			names[shard.Namespace+"/"+shard.Name] = shard.ELBv2Name
+		}
+	}
+	return names
+}
+
+// parseIngressSpecs parses the multi-document Ingress object YAML spec.
+func parseIngressSpecs(d []byte) (ings []v1beta1.Ingress, err error) {
+	var docs []string
+	var cur []string
+	for _, line := range strings.Split(string(d), "\n") {
+		if strings.TrimSpace(line) == "---" {
+			docs = append(docs, strings.Join(cur, "\n"))
+			cur = nil
+			continue
+		}
+		cur = append(cur, line)
+	}
+	docs = append(docs, strings.Join(cur, "\n"))
+
+	for _, doc := range docs {
+		if strings.TrimSpace(doc) == "" {
+			continue
+		}
+		var ing v1beta1.Ingress
+		if err = gyaml.Unmarshal([]byte(doc), &ing); err != nil {
+			return nil, err
+		}
+		if ing.Kind != "Ingress" {
+			continue
+		}
+		ings = append(ings, ing)
+	}
+	return ings, nil
+}
+
+// ingressRulePaths returns the Ingress rule paths in order.
+// ALB Ingress Controller creates one listener rule per path,
+// with priority in the same order starting from 1.
+func ingressRulePaths(ing v1beta1.Ingress) (ps []string) {
+	for _, rule := range ing.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, p := range rule.HTTP.Paths {
+			ps = append(ps, p.Path)
+		}
+	}
+	return ps
+}
+
+// parseListenPorts parses "alb.ingress.kubernetes.io/listen-ports"
+// annotation (e.g. '[{"HTTP":80,"HTTPS": 443}]') into sorted
+// "PROTOCOL:PORT" strings.
+func parseListenPorts(s string) (ps []string, err error) {
+	var ms []map[string]int64
+	if err = json.Unmarshal([]byte(s), &ms); err != nil {
+		return nil, fmt.Errorf("failed to parse listen-ports %q (%v)", s, err)
+	}
+	for _, m := range ms {
+		for proto, port := range m {
+			ps = append(ps, fmt.Sprintf("%s:%d", strings.ToUpper(proto), port))
+		}
+	}
+	sort.Strings(ps)
+	return ps, nil
+}
+
+// checkListeners returns an error for each listener that is
+// missing or unexpected, compared to the listen-ports annotation.
+func checkListeners(lbName string, ls []*elbv2.Listener, listenPorts string) (errs []error) {
+	exp, err := parseListenPorts(listenPorts)
+	if err != nil {
+		return []error{fmt.Errorf("ALB %q: %v", lbName, err)}
+	}
+	found := make(map[string]struct{})
+	for _, l := range ls {
+		found[fmt.Sprintf("%s:%d", aws.StringValue(l.Protocol), aws.Int64Value(l.Port))] = struct{}{}
+	}
+	expm := make(map[string]struct{})
+	for _, p := range exp {
+		expm[p] = struct{}{}
+		if _, ok := found[p]; !ok {
+			errs = append(errs, fmt.Errorf("ALB %q: listener %q not found", lbName, p))
+		}
+	}
+	var unexpected []string
+	for p := range found {
+		if _, ok := expm[p]; !ok {
+			unexpected = append(unexpected, p)
+		}
+	}
+	sort.Strings(unexpected)
+	for _, p := range unexpected {
+		errs = append(errs, fmt.Errorf("ALB %q: unexpected listener %q", lbName, p))
+	}
+	return errs
+}
+
+// checkListenerRules returns an error for each Ingress path without
+// a listener rule, with a wrong priority, or for each unexpected rule.
+func checkListenerRules(lbName string, listener string, rules []*elbv2.Rule, paths []string) (errs []error) {
+	found := make(map[string]string)
+	for _, r := range rules {
+		if aws.BoolValue(r.IsDefault) {
+			continue
+		}
+		for _, c := range r.Conditions {
+			if aws.StringValue(c.Field) != "path-pattern" || len(c.Values) == 0 {
+				continue
+			}
+			found[aws.StringValue(c.Values[0])] = aws.StringValue(r.Priority)
+		}
+	}
+	expm := make(map[string]struct{})
+	for i, p := range paths {
+		expm[p] = struct{}{}
+		priority, ok := found[p]
+		if !ok {
+			errs = append(errs, fmt.Errorf("ALB %q listener %q: rule for path %q not found", lbName, listener, p))
+			continue
+		}
+		if exp := fmt.Sprintf("%d", i+1); priority != exp {
+			errs = append(errs, fmt.Errorf("ALB %q listener %q: rule for path %q expected priority %s, got %s", lbName, listener, p, exp, priority))
+		}
+	}
+	var unexpected []string
+	for p := range found {
+		if _, ok := expm[p]; !ok {
+			unexpected = append(unexpected, p)
+		}
+	}
+	sort.Strings(unexpected)
+	for _, p := range unexpected {
+		errs = append(errs, fmt.Errorf("ALB %q listener %q: unexpected rule for path %q", lbName, listener, p))
+	}
+	return errs
+}
+
+// checkSecurityGroups returns an error for each security group that is
+// missing or unexpected, compared to the security-groups annotation.
+// If the annotation is empty, ALB Ingress Controller creates one.
+func checkSecurityGroups(lbName string, sgs []*string, annotation string) (errs []error) {
+	if annotation == "" {
+		if len(sgs) == 0 {
+			errs = append(errs, fmt.Errorf("ALB %q: no security group found", lbName))
+		}
+		return errs
+	}
+	found := make(map[string]struct{})
+	for _, sg := range sgs {
+		found[aws.StringValue(sg)] = struct{}{}
+	}
+	expm := make(map[string]struct{})
+	for _, sg := range strings.Split(annotation, ",") {
+		sg = strings.TrimSpace(sg)
+		expm[sg] = struct{}{}
+		if _, ok := found[sg]; !ok {
+			errs = append(errs, fmt.Errorf("ALB %q: security group %q not found", lbName, sg))
+		}
+	}
+	for _, sg := range sgs {
+		if _, ok := expm[aws.StringValue(sg)]; !ok {
+			errs = append(errs, fmt.Errorf("ALB %q: unexpected security group %q", lbName, aws.StringValue(sg)))
+		}
+	}
+	return errs
+}
+
+// checkTargetGroup returns an error for each target group setting
+// that does not match the Ingress annotations.
+func checkTargetGroup(lbName string, tg *elbv2.TargetGroup, annotations map[string]string) (errs []error) {
+	checks := []struct {
+		name       string
+		annotation string
+		got        string
+	}{
+		{"target type", "alb.ingress.kubernetes.io/target-type", aws.StringValue(tg.TargetType)},
+		{"health check protocol", "alb.ingress.kubernetes.io/healthcheck-protocol", aws.StringValue(tg.HealthCheckProtocol)},
+		{"health check path", "alb.ingress.kubernetes.io/healthcheck-path", aws.StringValue(tg.HealthCheckPath)},
+	}
+	for _, c := range checks {
+		exp, ok := annotations[c.annotation]
+		if !ok {
+			continue
+		}
+		if !strings.EqualFold(exp, c.got) {
+			errs = append(errs, fmt.Errorf("ALB %q target group %q: expected %s %q, got %q", lbName, aws.StringValue(tg.TargetGroupName), c.name, exp, c.got))
+		}
+	}
+	return errs
+}
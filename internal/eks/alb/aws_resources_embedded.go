@@ -0,0 +1,124 @@
+package alb
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"k8s.io/api/extensions/v1beta1"
+)
+
+// validateAWSResources compares each ALB with its Ingress object spec,
+// and returns an error for each mismatch.
+func (md *embedded) validateAWSResources() (errs []error) {
+	d, err := ioutil.ReadFile(md.cfg.ALBIngressController.IngressObjectSpecPath)
+	if err != nil {
+		return []error{err}
+	}
+	ings, err := parseIngressSpecs(d)
+	if err != nil {
+		return []error{err}
+	}
+
+	names := ingressELBv2Names(md.cfg)
+	for _, ing := range ings {
+		key := ing.Namespace + "/" + ing.Name
+		lbName, ok := names[key]
+		if !ok {
+			errs = append(errs, fmt.Errorf("Ingress %q: ALB not found", key))
+			continue
+		}
+		lbARN, ok := md.cfg.ALBIngressController.ELBv2NameToARN[lbName]
+		if !ok {
+			errs = append(errs, fmt.Errorf("Ingress %q: ALB %q ARN not found", key, lbName))
+			continue
+		}
+		errs = append(errs, md.validateALB(ing, lbName, lbARN)...)
+	}
+	return errs
+}
+
+func (md *embedded) validateALB(ing v1beta1.Ingress, lbName, lbARN string) (errs []error) {
+	lo, err := md.elbv2.DescribeLoadBalancers(&elbv2.DescribeLoadBalancersInput{
+		LoadBalancerArns: aws.StringSlice([]string{lbARN}),
+	})
+	if err != nil {
+		return []error{fmt.Errorf("ALB %q: %v", lbName, err)}
+	}
+	for _, lb := range lo.LoadBalancers {
+		errs = append(errs, checkSecurityGroups(lbName, lb.SecurityGroups, ing.Annotations["alb.ingress.kubernetes.io/security-groups"])...)
+	}
+
+	lso, err := md.elbv2.DescribeListeners(&elbv2.DescribeListenersInput{
+		LoadBalancerArn: aws.String(lbARN),
+	})
+	if err != nil {
+		return append(errs, fmt.Errorf("ALB %q: %v", lbName, err))
+	}
+	errs = append(errs, checkListeners(lbName, lso.Listeners, ing.Annotations["alb.ingress.kubernetes.io/listen-ports"])...)
+
+	paths := ingressRulePaths(ing)
+	for _, l := range lso.Listeners {
+		listener := fmt.Sprintf("%s:%d", aws.StringValue(l.Protocol), aws.Int64Value(l.Port))
+		var rules []*elbv2.Rule
+		input := &elbv2.DescribeRulesInput{ListenerArn: l.ListenerArn}
+		for {
+			ro, rerr := md.elbv2.DescribeRules(input)
+			if rerr != nil {
+				errs = append(errs, fmt.Errorf("ALB %q listener %q: %v", lbName, listener, rerr))
+				break
+			}
+			rules = append(rules, ro.Rules...)
+			if aws.StringValue(ro.NextMarker) == "" {
+				errs = append(errs, checkListenerRules(lbName, listener, rules, paths)...)
+				break
+			}
+			input.Marker = ro.NextMarker
+		}
+	}
+
+	to, err := md.elbv2.DescribeTargetGroups(&elbv2.DescribeTargetGroupsInput{
+		LoadBalancerArn: aws.String(lbARN),
+	})
+	if err != nil {
+		return append(errs, fmt.Errorf("ALB %q: %v", lbName, err))
+	}
+	expTargets := md.expectedHealthyTargets(ing.Namespace)
+	for _, tg := range to.TargetGroups {
+		errs = append(errs, checkTargetGroup(lbName, tg, ing.Annotations)...)
+
+		ho, herr := md.elbv2.DescribeTargetHealth(&elbv2.DescribeTargetHealthInput{
+			TargetGroupArn: tg.TargetGroupArn,
+		})
+		if herr != nil {
+			errs = append(errs, fmt.Errorf("ALB %q target group %q: %v", lbName, aws.StringValue(tg.TargetGroupName), herr))
+			continue
+		}
+		healthy := 0
+		for _, hv := range ho.TargetHealthDescriptions {
+			if hv.TargetHealth != nil && aws.StringValue(hv.TargetHealth.State) == elbv2.TargetHealthStateEnumHealthy {
+				healthy++
+			}
+		}
+		if healthy != expTargets {
+			errs = append(errs, fmt.Errorf("ALB %q target group %q: expected %d healthy targets, got %d", lbName, aws.StringValue(tg.TargetGroupName), expTargets, healthy))
+		}
+	}
+	return errs
+}
+
+// expectedHealthyTargets returns the number of healthy targets
+// expected in each target group of the Ingress object in the namespace.
+// With "instance" target type, every worker node is registered.
+// With "ip" target type, every backend pod is registered.
+func (md *embedded) expectedHealthyTargets(namespace string) int {
+	if md.cfg.ALBIngressController.TargetType == "instance" {
+		return len(md.cfg.ClusterState.WorkerNodes)
+	}
+	if namespace == "kube-system" {
+		// ALB Ingress Controller runs with 1 replica
+		return 1
+	}
+	return md.cfg.ALBIngressController.TestServerReplicas
+}
@@ -0,0 +1,170 @@
+package alb
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-k8s-tester/eksconfig"
+	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"k8s.io/api/extensions/v1beta1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func Test_elbv2NameFromDNSName(t *testing.T) {
+	if n := elbv2NameFromDNSName("431f09fb-default-ingressfo-0222-899555794.us-west-2.elb.amazonaws.com"); n != "431f09fb-default-ingressfo-0222" {
+		t.Fatalf("unexpected name %q", n)
+	}
+}
+
+func Test_ingressELBv2Names(t *testing.T) {
+	cfg := eksconfig.NewDefault()
+	cfg.ALBIngressController.ELBv2NamespaceToDNSName = map[string]string{
+		"kube-system": "431f09fb-kubesystem-ingressfo-0222-899555794.us-west-2.elb.amazonaws.com",
+	}
+	cfg.ALBIngressController.IngressShards = []eksconfig.IngressShard{
+		{Namespace: "default", Name: "ingress-for-ingress-test-server-service", ELBv2Name: "a-default-ingressfo-1"},
+		{Namespace: "ingress-test-server-1", Name: "ingress-for-ingress-test-server-service-1", ELBv2Name: "b-ingressteststr-ingressfo-2"},
+	}
+	exp := map[string]string{
+		"kube-system/ingress-for-alb-ingress-controller-service":          "431f09fb-kubesystem-ingressfo-0222",
+		"default/ingress-for-ingress-test-server-service":                 "a-default-ingressfo-1",
+		"ingress-test-server-1/ingress-for-ingress-test-server-service-1": "b-ingressteststr-ingressfo-2",
+	}
+	if names := ingressELBv2Names(cfg); !reflect.DeepEqual(names, exp) {
+		t.Fatalf("expected %v, got %v", exp, names)
+	}
+}
+
+func Test_parseIngressSpecs(t *testing.T) {
+	var docs []string
+	for _, name := range []string{"a", "b"} {
+		d, err := ingress.CreateIngressTestServerIngressSpec(ingress.ConfigIngressTestServerIngressSpec{
+			MetadataName:      name,
+			MetadataNamespace: "default",
+			Annotations:       map[string]string{"alb.ingress.kubernetes.io/listen-ports": `[{"HTTP":80}]`},
+			IngressPaths: []v1beta1.HTTPIngressPath{
+				{
+					Path: "/metrics",
+					Backend: v1beta1.IngressBackend{
+						ServiceName: "svc",
+						ServicePort: intstr.IntOrString{Type: intstr.Int, IntVal: int32(80)},
+					},
+				},
+			},
+			GenTargetServiceName:    "svc",
+			GenTargetServicePort:    80,
+			GenTargetServiceRoutesN: 2,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		docs = append(docs, d)
+	}
+	ings, err := parseIngressSpecs([]byte("---\n" + strings.Join(docs, "\n\n\n---\n") + "\n\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ings) != 2 {
+		t.Fatalf("expected 2 Ingress objects, got %d", len(ings))
+	}
+	if ings[1].Name != "b" || ings[1].Annotations["alb.ingress.kubernetes.io/listen-ports"] != `[{"HTTP":80}]` {
+		t.Fatalf("unexpected Ingress %+v", ings[1].ObjectMeta)
+	}
+	exp := []string{"/ingress-test-0000000", "/ingress-test-0000001", "/metrics"}
+	if ps := ingressRulePaths(ings[0]); !reflect.DeepEqual(ps, exp) {
+		t.Fatalf("expected paths %v, got %v", exp, ps)
+	}
+}
+
+func Test_checkListeners(t *testing.T) {
+	ls := []*elbv2.Listener{
+		{Protocol: aws.String("HTTP"), Port: aws.Int64(80)},
+		{Protocol: aws.String("HTTP"), Port: aws.Int64(8080)},
+	}
+	errs := checkListeners("alb", ls, `[{"HTTP":80,"HTTPS": 443}]`)
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got %v", errs)
+	}
+	if !strings.Contains(errs[0].Error(), `"HTTPS:443" not found`) {
+		t.Fatalf("unexpected error %v", errs[0])
+	}
+	if !strings.Contains(errs[1].Error(), `unexpected listener "HTTP:8080"`) {
+		t.Fatalf("unexpected error %v", errs[1])
+	}
+	if errs = checkListeners("alb", ls[:1], `[{"HTTP":80}]`); len(errs) != 0 {
+		t.Fatalf("unexpected errors %v", errs)
+	}
+}
+
+func newRule(p, priority string) *elbv2.Rule {
+	return &elbv2.Rule{
+		IsDefault: aws.Bool(false),
+		Priority:  aws.String(priority),
+		Conditions: []*elbv2.RuleCondition{
+			{Field: aws.String("path-pattern"), Values: aws.StringSlice([]string{p})},
+		},
+	}
+}
+
+func Test_checkListenerRules(t *testing.T) {
+	rules := []*elbv2.Rule{
+		{IsDefault: aws.Bool(true), Priority: aws.String("default")},
+		newRule("/a", "1"),
+		newRule("/b", "3"),
+		newRule("/x", "4"),
+	}
+	errs := checkListenerRules("alb", "HTTP:80", rules, []string{"/a", "/b", "/c"})
+	if len(errs) != 3 {
+		t.Fatalf("expected 3 errors, got %v", errs)
+	}
+	for i, exp := range []string{
+		`path "/b" expected priority 2, got 3`,
+		`rule for path "/c" not found`,
+		`unexpected rule for path "/x"`,
+	} {
+		if !strings.Contains(errs[i].Error(), exp) {
+			t.Fatalf("#%d: expected %q, got %v", i, exp, errs[i])
+		}
+	}
+	if errs = checkListenerRules("alb", "HTTP:80", rules[:2], []string{"/a"}); len(errs) != 0 {
+		t.Fatalf("unexpected errors %v", errs)
+	}
+}
+
+func Test_checkSecurityGroups(t *testing.T) {
+	sgs := aws.StringSlice([]string{"sg-1", "sg-3"})
+	errs := checkSecurityGroups("alb", sgs, "sg-1,sg-2")
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got %v", errs)
+	}
+	if errs = checkSecurityGroups("alb", sgs, ""); len(errs) != 0 {
+		t.Fatalf("unexpected errors %v", errs)
+	}
+	if errs = checkSecurityGroups("alb", nil, ""); len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %v", errs)
+	}
+}
+
+func Test_checkTargetGroup(t *testing.T) {
+	tg := &elbv2.TargetGroup{
+		TargetGroupName:     aws.String("tg"),
+		TargetType:          aws.String("instance"),
+		HealthCheckProtocol: aws.String("HTTP"),
+		HealthCheckPath:     aws.String("/"),
+	}
+	errs := checkTargetGroup("alb", tg, map[string]string{
+		"alb.ingress.kubernetes.io/target-type":          "ip",
+		"alb.ingress.kubernetes.io/healthcheck-protocol": "HTTP",
+		"alb.ingress.kubernetes.io/healthcheck-path":     "/metrics",
+	})
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got %v", errs)
+	}
+	if errs = checkTargetGroup("alb", tg, map[string]string{"alb.ingress.kubernetes.io/target-type": "instance"}); len(errs) != 0 {
+		t.Fatalf("unexpected errors %v", errs)
+	}
+}
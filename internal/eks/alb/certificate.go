@@ -0,0 +1,84 @@
+package alb
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress/client"
+)
+
+// genSelfSignedCertificate generates a PEM-encoded self-signed certificate
+// and its RSA private key for the ALB DNS names in the region
+// (e.g. "*.us-west-2.elb.amazonaws.com").
+// ACM only supports RSA 1024/2048 bit keys for imported certificates.
+func genSelfSignedCertificate(region string, validFor time.Duration) (certPEM, keyPEM []byte, err error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	host := fmt.Sprintf("*.%s.elb.amazonaws.com", region)
+	now := time.Now().UTC()
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject: pkix.Name{
+			CommonName:   host,
+			Organization: []string{"aws-k8s-tester"},
+		},
+		DNSNames:              []string{host},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(validFor),
+		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	return certPEM, keyPEM, nil
+}
+
+// newTLSConfig returns the TLS configuration that trusts the PEM-encoded
+// certificate. If the certificate is empty (e.g. certificate ARN was
+// provided), server certificate is not verified, since ALB DNS name
+// does not match the certificate domain.
+func newTLSConfig(certPEM []byte) (*tls.Config, error) {
+	cfg := &tls.Config{}
+	if len(certPEM) == 0 {
+		cfg.InsecureSkipVerify = true
+		return cfg, nil
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(certPEM) {
+		return nil, fmt.Errorf("failed to parse certificate %q", string(certPEM))
+	}
+	cfg.RootCAs = pool
+	return cfg, nil
+}
+
+// newHTTPSClient returns an HTTP client with the TLS configuration
+// from "newTLSConfig".
+func newHTTPSClient(certPEM []byte, opts client.TransportOptions) (*http.Client, error) {
+	cfg, err := newTLSConfig(certPEM)
+	if err != nil {
+		return nil, err
+	}
+	return client.NewHTTPClient(opts, cfg), nil
+}
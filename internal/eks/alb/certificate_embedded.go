@@ -0,0 +1,114 @@
+package alb
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/acm"
+	humanize "github.com/dustin/go-humanize"
+	"go.uber.org/zap"
+)
+
+// CreateCertificate imports a self-signed certificate into ACM
+// for the ALB HTTPS listener, unless a certificate ARN is provided.
+func (md *embedded) CreateCertificate() error {
+	if md.cfg.ALBIngressController.HTTPSCertificateARN != "" {
+		md.lg.Info("using existing certificate",
+			zap.String("certificate-arn", md.cfg.ALBIngressController.HTTPSCertificateARN),
+		)
+		return nil
+	}
+
+	certPEM, keyPEM, err := genSelfSignedCertificate(md.cfg.AWSRegion, 7*24*time.Hour)
+	if err != nil {
+		return err
+	}
+	// only the certificate is written to disk, the private key never leaves memory
+	if err = ioutil.WriteFile(md.cfg.ALBIngressController.HTTPSCertificatePath, certPEM, 0600); err != nil {
+		return err
+	}
+
+	out, err := md.acm.ImportCertificate(&acm.ImportCertificateInput{
+		Certificate: certPEM,
+		PrivateKey:  keyPEM,
+	})
+	if err != nil {
+		md.lg.Warn("failed to import certificate", zap.Error(err))
+		return err
+	}
+	md.cfg.ALBIngressController.HTTPSCertificateARN = *out.CertificateArn
+	md.cfg.ALBIngressController.HTTPSCertificateImported = true
+	md.cfg.Sync()
+
+	h, _ := os.Hostname()
+	_, err = md.acm.AddTagsToCertificate(&acm.AddTagsToCertificateInput{
+		CertificateArn: out.CertificateArn,
+		Tags: []*acm.Tag{
+			{Key: aws.String(md.cfg.Tag), Value: aws.String(md.cfg.ClusterName)},
+			{Key: aws.String("HOSTNAME"), Value: aws.String(h)},
+		},
+	})
+	if err != nil {
+		md.lg.Warn("failed to tag certificate", zap.Error(err))
+	}
+
+	md.lg.Info("imported certificate",
+		zap.String("certificate-arn", md.cfg.ALBIngressController.HTTPSCertificateARN),
+		zap.String("certificate-path", md.cfg.ALBIngressController.HTTPSCertificatePath),
+	)
+	return nil
+}
+
+// DeleteCertificate deletes the certificate imported by "CreateCertificate".
+// The certificate is in use until its ALB listeners are deleted.
+func (md *embedded) DeleteCertificate() error {
+	if !md.cfg.ALBIngressController.HTTPSCertificateImported {
+		return nil
+	}
+	if md.cfg.ALBIngressController.HTTPSCertificateARN == "" {
+		return errors.New("imported certificate ARN does not exist")
+	}
+
+	now := time.Now().UTC()
+
+	var err error
+	retryStart := time.Now().UTC()
+	for time.Now().UTC().Sub(retryStart) < 5*time.Minute {
+		_, err = md.acm.DeleteCertificate(&acm.DeleteCertificateInput{
+			CertificateArn: aws.String(md.cfg.ALBIngressController.HTTPSCertificateARN),
+		})
+		if err == nil {
+			break
+		}
+		if aerr, ok := err.(awserr.Error); ok {
+			switch aerr.Code() {
+			case acm.ErrCodeResourceNotFoundException:
+				err = nil
+			case acm.ErrCodeResourceInUseException:
+				md.lg.Warn("certificate still in use", zap.Error(err))
+				select {
+				case <-md.stopc:
+					return errors.New("certificate deletion aborted")
+				case <-time.After(10 * time.Second):
+				}
+				continue
+			}
+		}
+		break
+	}
+	if err != nil {
+		md.lg.Warn("failed to delete certificate", zap.Error(err))
+		return err
+	}
+
+	md.lg.Info("deleted certificate",
+		zap.String("certificate-arn", md.cfg.ALBIngressController.HTTPSCertificateARN),
+		zap.String("request-started", humanize.RelTime(now, time.Now().UTC(), "ago", "from now")),
+	)
+	md.cfg.ALBIngressController.HTTPSCertificateImported = false
+	return md.cfg.Sync()
+}
@@ -0,0 +1,61 @@
+package alb
+
+import (
+	"crypto/tls"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress/client"
+)
+
+func TestSelfSignedCertificate(t *testing.T) {
+	certPEM, keyPEM, err := genSelfSignedCertificate("us-west-2", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte("hello"))
+	}))
+	ts.TLS = &tls.Config{Certificates: []tls.Certificate{cert}}
+	ts.StartTLS()
+	defer ts.Close()
+
+	cli, err := newHTTPSClient(certPEM, client.TransportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := cli.Transport.(*http.Transport)
+	tr.TLSClientConfig.ServerName = "abc-default-ingressfo-af34-1499232465.us-west-2.elb.amazonaws.com"
+
+	resp, err := cli.Get(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(d) != "hello" {
+		t.Fatalf("expected 'hello', got %q", string(d))
+	}
+	if resp.TLS == nil {
+		t.Fatal("expected TLS connection state")
+	}
+
+	// certificate for other regions must be rejected
+	tr.TLSClientConfig.ServerName = "abc-default-ingressfo-af34-1499232465.us-east-1.elb.amazonaws.com"
+	tr.CloseIdleConnections()
+	if _, err = cli.Get(ts.URL); err == nil || !strings.Contains(err.Error(), "certificate") {
+		t.Fatalf("expected certificate error, got %v", err)
+	}
+}
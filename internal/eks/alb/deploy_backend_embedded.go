@@ -2,7 +2,7 @@ package alb
 
 import (
 	"context"
-	"errors"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -21,19 +21,43 @@ func (md *embedded) DeployBackend() error {
 	var name string
 	var d string
 	var err error
+	namespaces := []string{"default"}
+	var faults []byte
+	if len(md.cfg.ALBIngressController.TestServerFaults) > 0 {
+		faults, err = json.Marshal(md.cfg.ALBIngressController.TestServerFaults)
+		if err != nil {
+			return err
+		}
+	}
 	switch md.cfg.ALBIngressController.TestMode {
 	case "ingress-test-server":
-		cfg := ingress.ConfigDeploymentServiceIngressTestServer{
-			Name:         "ingress-test-server",
-			ServiceName:  "ingress-test-server-service",
-			Namespace:    "default",
-			Image:        md.cfg.AWSK8sTesterImage,
-			Replicas:     md.cfg.ALBIngressController.TestServerReplicas,
-			Routes:       md.cfg.ALBIngressController.TestServerRoutes,
-			ResponseSize: md.cfg.ALBIngressController.TestResponseSize,
+		// Ingress object only routes to services in the same namespace,
+		// so every namespace with Ingress objects needs its own test server
+		name = "ingress-test-server"
+		for i := 0; i < md.cfg.ALBIngressController.TestServerNamespaces; i++ {
+			ns := testServerNamespace(i)
+			if i > 0 {
+				namespaces = append(namespaces, ns)
+			}
+			var dn string
+			dn, err = ingress.CreateDeploymentServiceIngressTestServer(ingress.ConfigDeploymentServiceIngressTestServer{
+				Name:            name,
+				ServiceName:     "ingress-test-server-service",
+				Namespace:       ns,
+				CreateNamespace: ns != "default",
+				Image:           md.cfg.AWSK8sTesterImage,
+				Replicas:        md.cfg.ALBIngressController.TestServerReplicas,
+				Routes:          md.cfg.ALBIngressController.TestServerRoutes,
+				ResponseSize:    md.cfg.ALBIngressController.TestResponseSize,
+				Faults:          string(faults),
+				GRPC:            md.cfg.ALBIngressController.TestGRPC,
+				DrainSeconds:    md.cfg.ALBIngressController.TestServerDrainSeconds,
+			})
+			if err != nil {
+				break
+			}
+			d += dn
 		}
-		d, err = ingress.CreateDeploymentServiceIngressTestServer(cfg)
-		name = cfg.Name
 
 	case "nginx":
 		// create config map for nginx config and response body
@@ -99,14 +123,34 @@ func (md *embedded) DeployBackend() error {
 		break
 	}
 
-	retryStart = time.Now().UTC()
+	for _, ns := range namespaces {
+		if err = md.waitBackend(name, ns); err != nil {
+			return err
+		}
+	}
+
+	md.lg.Info(
+		"created ingress test server",
+		zap.String("name", name),
+		zap.Strings("namespaces", namespaces),
+		zap.String("request-started", humanize.RelTime(now, time.Now().UTC(), "ago", "from now")),
+	)
+	return nil
+}
+
+// waitBackend waits until the backend pods in the namespace are ready.
+func (md *embedded) waitBackend(name, ns string) (err error) {
+	kcfgPath := md.cfg.KubeConfigPath
+
+	var kexo []byte
+	retryStart := time.Now().UTC()
 	for time.Now().UTC().Sub(retryStart) < 10*time.Minute {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		cmd := md.kubectl.CommandContext(ctx,
 			md.kubectlPath,
 			"--kubeconfig="+kcfgPath,
 			"get", "pods", "--output=yaml",
-			"--namespace="+"default",
+			"--namespace="+ns,
 		)
 		kexo, err = cmd.CombinedOutput()
 		cancel()
@@ -116,7 +160,7 @@ func (md *embedded) DeployBackend() error {
 			continue
 		}
 		if findReadyPodsFromKubectlGetPodsOutputYAML(kexo, name) {
-			md.lg.Info("ingress test server deployment is ready", zap.String("name", name))
+			md.lg.Info("ingress test server deployment is ready", zap.String("name", name), zap.String("namespace", ns))
 			break
 		}
 
@@ -125,7 +169,7 @@ func (md *embedded) DeployBackend() error {
 			md.kubectlPath,
 			"--kubeconfig="+kcfgPath,
 			"get", "pods",
-			"--namespace="+"default",
+			"--namespace="+ns,
 		)
 		kexo, err = cmd.CombinedOutput()
 		cancel()
@@ -140,15 +184,8 @@ func (md *embedded) DeployBackend() error {
 		continue
 	}
 	if !strings.Contains(string(kexo), name) {
-		return errors.New("cannot get pod objects")
+		return fmt.Errorf("cannot get pod objects in namespace %q", ns)
 	}
-
-	md.lg.Info(
-		"created ingress test server",
-		zap.String("name", name),
-		zap.String("namespace", "default"),
-		zap.String("request-started", humanize.RelTime(now, time.Now().UTC(), "ago", "from now")),
-	)
 	return nil
 }
 
@@ -0,0 +1,50 @@
+package alb
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/aws/aws-k8s-tester/ec2config"
+)
+
+// distributedClientName is the name of ingress client pods and
+// the start barrier config map.
+const distributedClientName = "ingress-client"
+
+// workerNodesOutsideCIDRs returns the public IPs of worker nodes
+// outside the allowed source CIDRs, whose requests ALB would refuse.
+func workerNodesOutsideCIDRs(nodes []ec2config.Instance, cidrs []string) (ips []string) {
+	for _, node := range nodes {
+		if node.PublicIP == "" {
+			continue
+		}
+		if !cidrsContainIP(cidrs, node.PublicIP) {
+			ips = append(ips, node.PublicIP)
+		}
+	}
+	return ips
+}
+
+// podPhases counts the pods in each phase,
+// from "kubectl get pods --output=jsonpath={.items[*].status.phase}".
+func podPhases(out string) map[string]int {
+	phases := make(map[string]int)
+	for _, p := range strings.Fields(out) {
+		phases[p]++
+	}
+	return phases
+}
+
+// barrierPatch returns the merge patch that releases the start barrier
+// config map at the start time.
+func barrierPatch(key, startAt string) (string, error) {
+	d, err := json.Marshal(map[string]interface{}{
+		"data": map[string]string{
+			key: startAt,
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	return string(d), nil
+}
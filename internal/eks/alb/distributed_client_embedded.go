@@ -0,0 +1,126 @@
+package alb
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress"
+
+	"go.uber.org/zap"
+)
+
+// barrierPropagation is the delay from the start barrier config map update
+// to the start time, long enough for kubelet to sync the mounted volume
+// on all worker nodes.
+const barrierPropagation = 2 * time.Minute
+
+// RunDistributedClients deploys ingress client pods with the client flags,
+// releases the start barrier once all pods are running, waits until all
+// pods exit or timeout, and returns the logs of each pod.
+func (md *embedded) RunDistributedClients(pods int, clientArgs []string, timeout time.Duration) (logs []string, err error) {
+	if md.cfg.AWSK8sTesterImage == "" {
+		return nil, errors.New("empty AWSK8sTesterImage")
+	}
+	if len(md.cfg.ALBIngressController.AllowedSourceCIDRs) > 0 {
+		ips := workerNodesOutsideCIDRs(md.cfg.ClusterState.WorkerNodes, md.cfg.ALBIngressController.AllowedSourceCIDRs)
+		if len(ips) > 0 {
+			return nil, fmt.Errorf("worker node public IPs %v are outside allowed source CIDRs %v", ips, md.cfg.ALBIngressController.AllowedSourceCIDRs)
+		}
+	}
+
+	d, err := ingress.CreatePodIngressClient(ingress.ConfigPodIngressClient{
+		Name:       distributedClientName,
+		Namespace:  "default",
+		Image:      md.cfg.AWSK8sTesterImage,
+		Pods:       pods,
+		ClientArgs: clientArgs,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err = ioutil.WriteFile(md.cfg.ALBIngressController.DistributedClientSpecPath, []byte(d), 0600); err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(md.cfg.ALBIngressController.DistributedClientSpecPath)
+
+	if err = md.kubectlSpec("apply", md.cfg.ALBIngressController.DistributedClientSpecPath); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if derr := md.kubectlSpec("delete", md.cfg.ALBIngressController.DistributedClientSpecPath); derr != nil {
+			md.lg.Warn("failed to delete ingress client pods", zap.Error(derr))
+		}
+	}()
+	md.lg.Info("created ingress client pods", zap.Int("pods", pods))
+
+	if err = md.waitClientPods(pods, 10*time.Minute, "Running"); err != nil {
+		return nil, err
+	}
+
+	startAt := time.Now().UTC().Add(barrierPropagation).Format(time.RFC3339Nano)
+	patch, err := barrierPatch(ingress.BarrierStartAtKey, startAt)
+	if err != nil {
+		return nil, err
+	}
+	kexo, err := md.kubectlCommand(time.Minute,
+		"patch", "configmap", distributedClientName,
+		"--namespace=default",
+		"--type=merge",
+		"--patch="+patch,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to release start barrier (%v, %q)", err, string(kexo))
+	}
+	md.lg.Info("released start barrier", zap.String("start-at", startAt))
+
+	if err = md.waitClientPods(pods, barrierPropagation+timeout, "Succeeded", "Failed"); err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < pods; i++ {
+		name := ingress.PodIngressClientName(distributedClientName, i)
+		kexo, err = md.kubectlCommand(time.Minute, "logs", name, "--namespace=default")
+		if err != nil {
+			return nil, fmt.Errorf("failed to get ingress client pod %q logs (%v)", name, err)
+		}
+		logs = append(logs, string(kexo))
+	}
+	return logs, nil
+}
+
+// waitClientPods waits until all ingress client pods are in the phases.
+func (md *embedded) waitClientPods(pods int, timeout time.Duration, phases ...string) error {
+	retryStart := time.Now().UTC()
+	for time.Now().UTC().Sub(retryStart) < timeout {
+		kexo, err := md.kubectlCommand(30*time.Second,
+			"get", "pods",
+			"--namespace=default",
+			"--selector=app="+distributedClientName,
+			"--output=jsonpath={.items[*].status.phase}",
+		)
+		if err != nil {
+			md.lg.Warn("failed to get ingress client pods", zap.String("output", string(kexo)), zap.Error(err))
+		} else {
+			counts, n := podPhases(string(kexo)), 0
+			for _, p := range phases {
+				n += counts[p]
+			}
+			if n == pods {
+				md.lg.Info("ingress client pods are ready", zap.Strings("phases", phases), zap.Any("counts", counts))
+				return nil
+			}
+			md.lg.Info("waiting for ingress client pods", zap.Strings("phases", phases), zap.Any("counts", counts), zap.Int("expected", pods))
+		}
+
+		select {
+		case <-md.stopc:
+			return errors.New("waiting for ingress client pods interrupted")
+		case <-time.After(10 * time.Second):
+		}
+	}
+	return fmt.Errorf("ingress client pods not %s in %v", strings.Join(phases, " or "), timeout)
+}
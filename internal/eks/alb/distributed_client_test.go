@@ -0,0 +1,44 @@
+package alb
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-k8s-tester/ec2config"
+)
+
+func TestWorkerNodesOutsideCIDRs(t *testing.T) {
+	nodes := []ec2config.Instance{
+		{PublicIP: "1.2.3.4"},
+		{PublicIP: "5.6.7.8"},
+		{},
+	}
+	ips := workerNodesOutsideCIDRs(nodes, []string{"1.2.3.0/24"})
+	if !reflect.DeepEqual(ips, []string{"5.6.7.8"}) {
+		t.Fatalf("unexpected IPs %v", ips)
+	}
+	if ips = workerNodesOutsideCIDRs(nodes, []string{"0.0.0.0/0"}); len(ips) != 0 {
+		t.Fatalf("unexpected IPs %v", ips)
+	}
+}
+
+func TestPodPhases(t *testing.T) {
+	phases := podPhases("Running Running Pending\n")
+	if phases["Running"] != 2 || phases["Pending"] != 1 || len(phases) != 2 {
+		t.Fatalf("unexpected phases %v", phases)
+	}
+	if phases = podPhases(""); len(phases) != 0 {
+		t.Fatalf("unexpected phases %v", phases)
+	}
+}
+
+func TestBarrierPatch(t *testing.T) {
+	p, err := barrierPatch("start-at", "2018-11-01T00:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := `{"data":{"start-at":"2018-11-01T00:00:00Z"}}`
+	if p != exp {
+		t.Fatalf("expected %q, got %q", exp, p)
+	}
+}
@@ -0,0 +1,84 @@
+package alb
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	alblog "github.com/aws/aws-k8s-tester/internal/alb-log"
+	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress/server"
+)
+
+// clientIP returns the client IP that ALB appended to "X-Forwarded-For".
+func clientIP(forwardedFor string) string {
+	ss := strings.Split(forwardedFor, ",")
+	return strings.TrimSpace(ss[len(ss)-1])
+}
+
+// hostOf returns the host of "host:port" address.
+func hostOf(addr string) string {
+	h, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return h
+}
+
+// checkEcho returns an error if the request received by ingress test server
+// does not have the headers that ALB adds to the request sent to the scheme.
+func checkEcho(ev server.Echo, scheme string) error {
+	port := "80"
+	if scheme == "https" {
+		port = "443"
+	}
+	if ev.ForwardedProto != scheme {
+		return fmt.Errorf("expected X-Forwarded-Proto %q, got %q", scheme, ev.ForwardedProto)
+	}
+	if ev.ForwardedPort != port {
+		return fmt.Errorf("expected X-Forwarded-Port %q, got %q", port, ev.ForwardedPort)
+	}
+	if ev.ForwardedFor == "" {
+		return fmt.Errorf("empty X-Forwarded-For")
+	}
+	ip := clientIP(ev.ForwardedFor)
+	if net.ParseIP(ip) == nil {
+		return fmt.Errorf("invalid client IP %q in X-Forwarded-For %q", ip, ev.ForwardedFor)
+	}
+	if hostOf(ev.RemoteAddr) == ip {
+		return fmt.Errorf("request from client %q is not forwarded by ALB", ev.RemoteAddr)
+	}
+	if !strings.HasPrefix(ev.TraceID, "Root=1-") {
+		return fmt.Errorf("invalid X-Amzn-Trace-Id %q", ev.TraceID)
+	}
+	if ev.TLS != nil {
+		// ALB terminates TLS, and forwards to targets over HTTP
+		return fmt.Errorf("unexpected TLS connection to target %+v", *ev.TLS)
+	}
+	return nil
+}
+
+// checkEchoLog returns an error if the access log entry
+// does not match the request received by ingress test server.
+// With "ip" target type, ALB sends the request directly to the pod.
+// With "instance" target type, ALB sends the request to the node port.
+func checkEchoLog(ev server.Echo, entry alblog.Log, targetType string) error {
+	if entry.TraceID != ev.TraceID {
+		return fmt.Errorf("expected access log trace ID %q, got %q", ev.TraceID, entry.TraceID)
+	}
+	if ip := clientIP(ev.ForwardedFor); hostOf(entry.ClientPort) != ip {
+		return fmt.Errorf("expected access log client %q, got %q", ip, entry.ClientPort)
+	}
+	switch targetType {
+	case "ip":
+		if entry.TargetPort != ev.LocalAddr {
+			return fmt.Errorf("expected access log target %q, got %q", ev.LocalAddr, entry.TargetPort)
+		}
+	case "instance":
+		if hostOf(entry.TargetPort) == hostOf(ev.LocalAddr) {
+			return fmt.Errorf("expected access log target node, got pod %q", entry.TargetPort)
+		}
+	default:
+		return fmt.Errorf("unknown target type %q", targetType)
+	}
+	return nil
+}
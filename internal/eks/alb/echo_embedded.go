@@ -0,0 +1,100 @@
+package alb
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress/path"
+	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress/server"
+
+	"go.uber.org/zap"
+)
+
+// TestEcho sends a request to the ingress test server echo route,
+// and verifies the headers that ALB adds to the request.
+// If access logs are enabled, the request is correlated with
+// the access log entry by its trace ID.
+func (md *embedded) TestEcho() error {
+	cli, err := HTTPClient(md.cfg)
+	if err != nil {
+		return err
+	}
+	ep := Endpoint(md.cfg, "default") + path.PathEcho
+	scheme := "http"
+	if md.cfg.ALBIngressController.EnableHTTPS {
+		scheme = "https"
+	}
+	// unique user agent to find the request in access logs
+	userAgent := fmt.Sprintf("aws-k8s-tester-echo-%d", time.Now().UTC().UnixNano())
+
+	var ev server.Echo
+	for i := 0; i < 30; i++ {
+		select {
+		case <-md.stopc:
+			return errors.New("echo test aborted")
+		default:
+		}
+		ev, err = getEcho(cli, ep, userAgent)
+		if err == nil {
+			break
+		}
+		md.lg.Warn("echo Get failed", zap.String("endpoint", ep), zap.Error(err))
+		time.Sleep(10 * time.Second)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to Get %q (%v)", ep, err)
+	}
+	md.lg.Info("echo Get success",
+		zap.String("endpoint", ep),
+		zap.String("target-type", md.cfg.ALBIngressController.TargetType),
+		zap.String("pod", ev.Pod),
+		zap.String("remote-addr", ev.RemoteAddr),
+		zap.String("local-addr", ev.LocalAddr),
+		zap.String("forwarded-for", ev.ForwardedFor),
+		zap.String("forwarded-proto", ev.ForwardedProto),
+		zap.String("forwarded-port", ev.ForwardedPort),
+		zap.String("trace-id", ev.TraceID),
+	)
+	if err = checkEcho(ev, scheme); err != nil {
+		return err
+	}
+	md.cfg.ALBIngressController.TestResultTraceID = ev.TraceID
+	md.cfg.Sync()
+
+	if !md.cfg.LogAccess {
+		md.lg.Info("skipping ALB access log verification (access logs disabled)")
+		return nil
+	}
+	entry, err := md.waitAccessLog(userAgent)
+	if err != nil {
+		return err
+	}
+	md.lg.Info("found access log",
+		zap.String("trace-id", entry.TraceID),
+		zap.String("client-port", entry.ClientPort),
+		zap.String("target-port", entry.TargetPort),
+	)
+	return checkEchoLog(ev, *entry, md.cfg.ALBIngressController.TargetType)
+}
+
+// getEcho returns the request received by ingress test server.
+func getEcho(cli *http.Client, ep, userAgent string) (ev server.Echo, err error) {
+	req, err := http.NewRequest(http.MethodGet, ep, nil)
+	if err != nil {
+		return ev, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	resp, err := cli.Do(req)
+	if err != nil {
+		return ev, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return ev, fmt.Errorf("%q returned %q", ep, resp.Status)
+	}
+	err = json.NewDecoder(resp.Body).Decode(&ev)
+	return ev, err
+}
@@ -0,0 +1,66 @@
+package alb
+
+import (
+	"testing"
+
+	alblog "github.com/aws/aws-k8s-tester/internal/alb-log"
+	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress/server"
+)
+
+func Test_checkEcho(t *testing.T) {
+	ev := server.Echo{
+		RemoteAddr:     "192.168.10.5:43210",
+		LocalAddr:      "192.168.20.7:32030",
+		ForwardedFor:   "10.0.0.1, 54.1.2.3",
+		ForwardedProto: "https",
+		ForwardedPort:  "443",
+		TraceID:        "Root=1-5759e988-bd862e3fe1be46a994272793",
+	}
+	if err := checkEcho(ev, "https"); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkEcho(ev, "http"); err == nil {
+		t.Fatal("expected X-Forwarded-Proto error")
+	}
+
+	ev2 := ev
+	ev2.ForwardedFor = ""
+	if err := checkEcho(ev2, "https"); err == nil {
+		t.Fatal("expected X-Forwarded-For error")
+	}
+	ev2 = ev
+	ev2.RemoteAddr = "54.1.2.3:43210"
+	if err := checkEcho(ev2, "https"); err == nil {
+		t.Fatal("expected error for request not forwarded by ALB")
+	}
+	ev2 = ev
+	ev2.TraceID = ""
+	if err := checkEcho(ev2, "https"); err == nil {
+		t.Fatal("expected X-Amzn-Trace-Id error")
+	}
+	ev2 = ev
+	ev2.TLS = &server.EchoTLS{Version: 0x0303}
+	if err := checkEcho(ev2, "https"); err == nil {
+		t.Fatal("expected TLS error")
+	}
+
+	entry := alblog.Log{
+		ClientPort: "54.1.2.3:56789",
+		TargetPort: "192.168.20.7:32030",
+		TraceID:    ev.TraceID,
+	}
+	if err := checkEchoLog(ev, entry, "ip"); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkEchoLog(ev, entry, "instance"); err == nil {
+		t.Fatal("expected target error with instance target type")
+	}
+	entry.TargetPort = "192.168.30.9:31234"
+	if err := checkEchoLog(ev, entry, "instance"); err != nil {
+		t.Fatal(err)
+	}
+	entry.TraceID = "Root=1-00000000-000000000000000000000000"
+	if err := checkEchoLog(ev, entry, "instance"); err == nil {
+		t.Fatal("expected trace ID error")
+	}
+}
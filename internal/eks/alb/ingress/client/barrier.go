@@ -0,0 +1,47 @@
+package client
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// WaitBarrier waits until the file at the path has the start time
+// in RFC3339 format, and then until the start time, so that clients
+// on multiple hosts start sending requests together.
+// The file is a ConfigMap volume, updated by the coordinator
+// once all clients are running.
+func WaitBarrier(lg *zap.Logger, p string, timeout time.Duration, stopc chan struct{}) error {
+	lg.Info("waiting for start barrier", zap.String("path", p), zap.Duration("timeout", timeout))
+	deadline := time.Now().UTC().Add(timeout)
+	for time.Now().UTC().Before(deadline) {
+		d, err := ioutil.ReadFile(p)
+		if err == nil && strings.TrimSpace(string(d)) != "" {
+			startAt, perr := time.Parse(time.RFC3339Nano, strings.TrimSpace(string(d)))
+			if perr != nil {
+				return fmt.Errorf("failed to parse start time %q (%v)", string(d), perr)
+			}
+			wait := startAt.Sub(time.Now().UTC())
+			if wait < 0 {
+				lg.Warn("start barrier already passed", zap.Time("start-at", startAt), zap.Duration("late", -wait))
+				return nil
+			}
+			lg.Info("found start time", zap.Time("start-at", startAt), zap.Duration("wait", wait))
+			select {
+			case <-stopc:
+				return fmt.Errorf("waiting for start barrier interrupted")
+			case <-time.After(wait):
+			}
+			return nil
+		}
+		select {
+		case <-stopc:
+			return fmt.Errorf("waiting for start barrier interrupted")
+		case <-time.After(time.Second):
+		}
+	}
+	return fmt.Errorf("start barrier %q not released in %v", p, timeout)
+}
@@ -0,0 +1,36 @@
+package client
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestWaitBarrier(t *testing.T) {
+	f, err := ioutil.TempFile(os.TempDir(), "barrier")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	defer os.RemoveAll(f.Name())
+
+	lg := zap.NewExample()
+	if err = WaitBarrier(lg, f.Name(), 1500*time.Millisecond, make(chan struct{})); err == nil {
+		t.Fatal("expected timeout")
+	}
+
+	startAt := time.Now().UTC().Add(2 * time.Second)
+	go func() {
+		time.Sleep(500 * time.Millisecond)
+		ioutil.WriteFile(f.Name(), []byte(startAt.Format(time.RFC3339Nano)), 0600)
+	}()
+	if err = WaitBarrier(lg, f.Name(), 10*time.Second, make(chan struct{})); err != nil {
+		t.Fatal(err)
+	}
+	if now := time.Now().UTC(); now.Before(startAt) {
+		t.Fatalf("returned at %v before start time %v", now, startAt)
+	}
+}
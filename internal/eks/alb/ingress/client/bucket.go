@@ -0,0 +1,131 @@
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Bucket is the test result aggregated over a time window.
+type Bucket struct {
+	// Start is the offset of the time window from the test start.
+	Start time.Duration
+	// Level is the load level at the start of the time window.
+	Level float64
+	// Requests is the number of requests sent in the time window.
+	Requests int64
+	// Success is the number of successful requests.
+	Success int64
+	// Failure is the number of failed requests.
+	Failure int64
+	// QPS is the number of successful requests per second.
+	QPS float64
+	// LatencyP50 is the 50th percentile latency of successful requests.
+	LatencyP50 time.Duration
+	// LatencyP99 is the 99th percentile latency of successful requests.
+	LatencyP99 time.Duration
+}
+
+// sample is a request result, recorded for aggregation.
+type sample struct {
+	// at is the offset of the request from the test start.
+	at       time.Duration
+	endpoint string
+	route    string
+	// code is the response status code, or zero if no response.
+	code int
+	// proto is the response protocol (e.g. "HTTP/1.1", "HTTP/2.0").
+	proto   string
+	latency time.Duration
+	ok      bool
+	// category is the error category of failed request.
+	category string
+}
+
+// bucketAggregate is the online aggregation of the samples in a time window.
+type bucketAggregate struct {
+	requests int64
+	success  int64
+	failure  int64
+	hist     Histogram
+	max      time.Duration
+}
+
+func (b *bucketAggregate) add(sv sample) {
+	b.requests++
+	if !sv.ok {
+		b.failure++
+		return
+	}
+	b.success++
+	if b.hist == nil {
+		b.hist = make(Histogram)
+	}
+	b.hist.Add(sv.latency)
+	if sv.latency > b.max {
+		b.max = sv.latency
+	}
+}
+
+func (b *bucketAggregate) merge(other bucketAggregate) {
+	b.requests += other.requests
+	b.success += other.success
+	b.failure += other.failure
+	if len(other.hist) > 0 {
+		if b.hist == nil {
+			b.hist = make(Histogram)
+		}
+		b.hist.Merge(other.hist)
+	}
+	if other.max > b.max {
+		b.max = other.max
+	}
+}
+
+// toBuckets returns the aggregated time windows.
+// "level" returns the load level at the offset, if not nil.
+func (agg *aggregator) toBuckets(level func(time.Duration) float64) []Bucket {
+	if agg.interval <= 0 || len(agg.buckets) == 0 {
+		return nil
+	}
+	buckets := make([]Bucket, len(agg.buckets))
+	for i, ba := range agg.buckets {
+		ls := histogramSummary(ba.hist, ba.max)
+		buckets[i] = Bucket{
+			Start:      time.Duration(i) * agg.interval,
+			Requests:   ba.requests,
+			Success:    ba.success,
+			Failure:    ba.failure,
+			QPS:        float64(ba.success) / agg.interval.Seconds(),
+			LatencyP50: ls.P50,
+			LatencyP99: ls.P99,
+		}
+		if level != nil {
+			buckets[i].Level = level(buckets[i].Start)
+		}
+	}
+	return buckets
+}
+
+// bucketsTable returns the buckets in a table.
+func bucketsTable(buckets []Bucket) string {
+	buf := new(bytes.Buffer)
+	tw := tabwriter.NewWriter(buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join([]string{"START", "LEVEL", "REQUESTS", "SUCCESS", "FAILURE", "QPS", "LATENCY-P50", "LATENCY-P99"}, "\t"))
+	for _, b := range buckets {
+		fmt.Fprintln(tw, strings.Join([]string{
+			b.Start.String(),
+			fmt.Sprintf("%.1f", b.Level),
+			fmt.Sprintf("%d", b.Requests),
+			fmt.Sprintf("%d", b.Success),
+			fmt.Sprintf("%d", b.Failure),
+			fmt.Sprintf("%.1f", b.QPS),
+			b.LatencyP50.String(),
+			b.LatencyP99.String(),
+		}, "\t"))
+	}
+	tw.Flush()
+	return buf.String()
+}
@@ -0,0 +1,36 @@
+package client
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestToBuckets(t *testing.T) {
+	samples := []sample{
+		{at: 0, latency: 10 * time.Millisecond, ok: true},
+		{at: 500 * time.Millisecond, latency: 30 * time.Millisecond, ok: true},
+		{at: 900 * time.Millisecond, ok: false},
+		{at: 2500 * time.Millisecond, latency: 50 * time.Millisecond, ok: true},
+	}
+	p := Profile{Type: ProfileRamp, Duration: 4 * time.Second, Start: 0, Peak: 40}
+	buckets := aggregateSamples(time.Second, samples...).toBuckets(p.Level)
+	if len(buckets) != 3 {
+		t.Fatalf("expected 3 buckets, got %+v", buckets)
+	}
+	if b := buckets[0]; b.Requests != 3 || b.Success != 2 || b.Failure != 1 || b.QPS != 2 || b.LatencyP99 != 30*time.Millisecond {
+		t.Fatalf("unexpected first bucket %+v", b)
+	}
+	if b := buckets[1]; b.Requests != 0 || b.Level != 10 {
+		t.Fatalf("unexpected second bucket %+v", b)
+	}
+	if b := buckets[2]; b.Success != 1 || b.LatencyP50 != 50*time.Millisecond || b.Start != 2*time.Second {
+		t.Fatalf("unexpected third bucket %+v", b)
+	}
+	if s := bucketsTable(buckets); !strings.Contains(s, "LATENCY-P99") || !strings.Contains(s, "2s") {
+		t.Fatalf("unexpected table %s", s)
+	}
+	if aggregateSamples(0, samples...).toBuckets(nil) != nil {
+		t.Fatal("expected no buckets without interval")
+	}
+}
@@ -1,18 +1,21 @@
 package client
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"math"
 	"math/rand"
 	"net/http"
-	"net/http/httptest"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress/path"
+	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress/payload"
 
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.uber.org/atomic"
 	"go.uber.org/zap"
 )
@@ -23,6 +26,25 @@ type Client struct {
 
 	Endpoint string
 	Routes   []string
+	// endpoints is the endpoint of each route in "Routes",
+	// when routes are sharded across multiple load balancers.
+	endpoints []string
+
+	// HTTPClient is the client to send requests.
+	// Defaults to "http.DefaultClient".
+	// Use "NewHTTPClient" to configure connections.
+	HTTPClient *http.Client
+	// Method is the HTTP method of requests. Defaults to "GET".
+	Method string
+	// Header is the HTTP header added to each request.
+	Header http.Header
+	// Body is the body sent with each request.
+	Body []byte
+	// Verify verifies each 2xx response with its header and body.
+	// Defaults to "payload.Verify", that only verifies the responses
+	// with payload integrity headers. Set to "payload.VerifyStrict"
+	// to count responses without the headers as integrity errors.
+	Verify func(http.Header, []byte) error
 
 	// ClientsN is the number of concurrent clients.
 	ClientsN int
@@ -32,6 +54,29 @@ type Client struct {
 	RequestsN int64
 	requestsN *atomic.Int64
 
+	// Rate is the target number of requests per second in open-loop mode,
+	// where requests are scheduled at the intended send times regardless of
+	// the previous responses, and latency is measured from the intended send time.
+	// If zero, each client sends requests back-to-back (closed-loop mode).
+	Rate float64
+	// RateStep is the number of requests per second to add to "Rate"
+	// every "RateStepInterval" in open-loop mode.
+	// If zero, requests are sent at the fixed "Rate".
+	RateStep float64
+	// RateStepInterval is the interval to increase the rate by "RateStep".
+	RateStepInterval time.Duration
+	// MaxLateness is the maximum delay from the intended send time,
+	// after which a request is counted as late in open-loop mode.
+	// Defaults to "DefaultMaxLateness".
+	MaxLateness time.Duration
+
+	// Profile is the load profile over the test duration.
+	// If nil, the load is constant until "RequestsN" requests are sent.
+	Profile *Profile
+	// BucketInterval is the time window to aggregate results into "TestResult.Buckets".
+	// If zero, results are not bucketed.
+	BucketInterval time.Duration
+
 	stopc chan struct{}
 }
 
@@ -51,39 +96,137 @@ func New(lg *zap.Logger, ep string, routesN int, clientsN int, requestsN int) (c
 		routes[i] = path.Create(i)
 	}
 	return &Client{
-		lg:        lg,
-		Endpoint:  ep,
-		Routes:    routes,
-		ClientsN:  clientsN,
-		wg:        sync.WaitGroup{},
-		RequestsN: int64(requestsN),
-		requestsN: atomic.NewInt64(int64(requestsN)),
-		stopc:     make(chan struct{}),
+		lg:         lg,
+		Endpoint:   ep,
+		Routes:     routes,
+		HTTPClient: http.DefaultClient,
+		ClientsN:   clientsN,
+		wg:         sync.WaitGroup{},
+		RequestsN:  int64(requestsN),
+		requestsN:  atomic.NewInt64(int64(requestsN)),
+		stopc:      make(chan struct{}),
+	}, nil
+}
+
+// Target is a load balancer endpoint and the routes it serves.
+type Target struct {
+	Endpoint string
+	Routes   []string
+}
+
+// ShardFlag returns the "--shard" flag value of "aws-k8s-tester eks ingress client"
+// for the endpoint serving routes from "routeStart" up to "routeEnd" (exclusive).
+func ShardFlag(ep string, routeStart, routeEnd int) string {
+	return fmt.Sprintf("%s=%d-%d", ep, routeStart, routeEnd)
+}
+
+// ParseShardFlag parses the "--shard" flag value in "endpoint=start-end" format.
+func ParseShardFlag(s string) (tg Target, err error) {
+	idx := strings.LastIndex(s, "=")
+	if idx < 1 {
+		return tg, fmt.Errorf("invalid shard %q (expected 'endpoint=start-end')", s)
+	}
+	var start, end int
+	if _, err = fmt.Sscanf(s[idx+1:], "%d-%d", &start, &end); err != nil {
+		return tg, fmt.Errorf("invalid shard routes %q (%v)", s[idx+1:], err)
+	}
+	if start < 0 || end <= start {
+		return tg, fmt.Errorf("invalid shard routes [%d, %d)", start, end)
+	}
+	tg.Endpoint = s[:idx]
+	for i := start; i < end; i++ {
+		tg.Routes = append(tg.Routes, path.Create(i))
+	}
+	return tg, nil
+}
+
+// NewSharded creates the client configuration for routes
+// that are sharded across multiple load balancer endpoints.
+func NewSharded(lg *zap.Logger, targets []Target, clientsN int, requestsN int) (cli *Client, err error) {
+	var routes, endpoints []string
+	for _, tg := range targets {
+		for _, route := range tg.Routes {
+			routes = append(routes, route)
+			endpoints = append(endpoints, tg.Endpoint)
+		}
+	}
+	if len(routes) == 0 {
+		return nil, errors.New("no routes found")
+	}
+	lg.Info("creating sharded client",
+		zap.Int("targets", len(targets)),
+		zap.Int("routes", len(routes)),
+		zap.Int("clients", clientsN),
+		zap.Int("requests", requestsN),
+	)
+	return &Client{
+		lg:         lg,
+		Endpoint:   targets[0].Endpoint,
+		Routes:     routes,
+		endpoints:  endpoints,
+		HTTPClient: http.DefaultClient,
+		ClientsN:   clientsN,
+		wg:         sync.WaitGroup{},
+		RequestsN:  int64(requestsN),
+		requestsN:  atomic.NewInt64(int64(requestsN)),
+		stopc:      make(chan struct{}),
 	}, nil
 }
 
+// MaxErrors is the maximum number of errors kept in "TestResult.Errors".
+// Every failure is still counted in "TestResult.ErrorCategories".
+const MaxErrors = 100
+
 // TestResult contains test results.
 type TestResult struct {
 	mu       *sync.RWMutex
-	Routes   int
-	Clients  int
-	Requests int64
-	Success  int64
-	Failure  int64
-	Errors   []error
-	QPS      float64
-	Result   string
+	Routes   int     `json:"routes"`
+	Clients  int     `json:"clients"`
+	Requests int64   `json:"requests"`
+	Success  int64   `json:"success"`
+	Failure  int64   `json:"failure"`
+	Errors   []error `json:"-"` // first "MaxErrors" errors
+	QPS      float64 `json:"qps"`
+	Result   string  `json:"-"`
+
+	// Dropped is the number of requests not sent in open-loop mode,
+	// because all clients were busy at the intended send time.
+	Dropped int64 `json:"dropped"`
+	// Late is the number of requests sent later than "MaxLateness"
+	// after the intended send time in open-loop mode.
+	Late int64 `json:"late"`
+	// Buckets is the results aggregated per "BucketInterval" time window.
+	Buckets []Bucket `json:"buckets,omitempty"`
+
+	// Latency is the latency distribution of successful requests.
+	Latency LatencySummary `json:"latency"`
+	// LatencyP50 is the 50th percentile latency of successful requests.
+	LatencyP50 time.Duration `json:"-"`
+	// LatencyP99 is the 99th percentile latency of successful requests.
+	LatencyP99 time.Duration `json:"-"`
+	// RouteResults is the test result of each route.
+	RouteResults []RouteResult `json:"route-results"`
+	// StatusCodes is the number of responses of each HTTP status code.
+	StatusCodes map[int]int64 `json:"status-codes"`
+	// ErrorCategories is the number of failed requests of each error category
+	// (e.g. "dns", "connect", "timeout", "reset", "status", "integrity").
+	ErrorCategories map[string]int64 `json:"error-categories"`
+	// Protocols is the number of responses of each protocol
+	// (e.g. "HTTP/1.1", "HTTP/2.0").
+	Protocols map[string]int64 `json:"protocols,omitempty"`
+	// Throughput is the number of requests sent in each second.
+	Throughput []Throughput `json:"throughput"`
+	// Histogram is the latency histogram of successful requests,
+	// to merge results from multiple clients.
+	Histogram Histogram `json:"histogram,omitempty"`
+
+	agg *aggregator
 }
 
 // Run runs load testing.
 // It returns the result output and error count.
 func (cli *Client) Run() (testResult TestResult) {
 	now := time.Now().UTC()
-	p := fmt.Sprintf("/%d", now.Nanosecond())
-	mux := http.NewServeMux()
-	mux.Handle(p, promhttp.Handler())
-	ts := httptest.NewServer(mux)
-	defer ts.Close()
 
 	cli.lg.Info("started client load tester",
 		zap.String("endpoint", cli.Endpoint),
@@ -97,12 +240,77 @@ func (cli *Client) Run() (testResult TestResult) {
 		Routes:   len(cli.Routes),
 		Clients:  cli.ClientsN,
 		Requests: cli.RequestsN,
+		agg:      newAggregator(cli.BucketInterval),
+	}
+
+	if cli.Rate > 0 || (cli.Profile != nil && cli.Profile.OpenLoop) {
+		cli.runOpenLoop(&testResult, now)
+	} else {
+		cli.runClosedLoop(&testResult, now)
+	}
+
+	took := time.Now().UTC().Sub(now)
+	cli.lg.Info("finished client load tester",
+		zap.Int("clients", cli.ClientsN),
+		zap.Int64("requests", cli.RequestsN),
+	)
+
+	testResult.aggregate()
+	if testResult.Success > 0 {
+		testResult.QPS = float64(testResult.Success) / took.Seconds()
+	}
+
+	var level func(time.Duration) float64
+	if cli.Profile != nil {
+		level = cli.Profile.Level
+	}
+	testResult.Buckets = testResult.agg.toBuckets(level)
+
+	testResult.Result = fmt.Sprintf("\nEndpoint: %q\n\n", cli.Endpoint) +
+		fmt.Sprintf("Success: %d\n", testResult.Success) +
+		fmt.Sprintf("   Fail: %d\n\n", testResult.Failure) +
+		fmt.Sprintf("Total Concurrent Clients: %d\n\n", cli.ClientsN) +
+		fmt.Sprintf("Total ALB Targets: %d\n\n", len(cli.Routes)) +
+		routesTable(testResult.RouteResults) + "\n" +
+		fmt.Sprintf("Took: %v\n", took) +
+		fmt.Sprintf("QPS: %3.f successful requests per second\n", testResult.QPS) +
+		fmt.Sprintf("Latency p50: %v, p90: %v, p99: %v, p999: %v, max: %v\n",
+			testResult.Latency.P50, testResult.Latency.P90, testResult.Latency.P99, testResult.Latency.P999, testResult.Latency.Max) +
+		fmt.Sprintf("Status codes: %s\n", statusCodesString(testResult.StatusCodes)) +
+		fmt.Sprintf("Error categories: %s\n", countsString(testResult.ErrorCategories)) +
+		fmt.Sprintf("Protocols: %s\n", countsString(testResult.Protocols)) +
+		fmt.Sprintf("Error count: %d\n", testResult.Failure)
+	if cli.Rate > 0 {
+		testResult.Result += fmt.Sprintf("Open-loop rate: %.1f requests per second (step %.1f every %v), dropped: %d, late: %d\n",
+			cli.Rate, cli.RateStep, cli.RateStepInterval, testResult.Dropped, testResult.Late)
+	}
+	if cli.Profile != nil {
+		testResult.Result += fmt.Sprintf("Profile: %s (start %.1f, peak %.1f, duration %v)\n",
+			cli.Profile.Type, cli.Profile.Start, cli.Profile.Peak, cli.Profile.Duration)
 	}
+	if len(testResult.Buckets) > 0 {
+		testResult.Result += "\n" + bucketsTable(testResult.Buckets)
+	}
+
+	return testResult
+}
 
+// runClosedLoop runs "ClientsN" clients that each send requests
+// back-to-back until "RequestsN" requests are sent, or until the profile
+// duration elapses. With a profile, only as many clients as the load level
+// at the time are active.
+func (cli *Client) runClosedLoop(testResult *TestResult, start time.Time) {
 	cli.wg.Add(cli.ClientsN)
 	for i := 0; i < cli.ClientsN; i++ {
-		go func() {
-			defer cli.wg.Done()
+		go func(idx int) {
+			agg := newAggregator(cli.BucketInterval)
+			rnd := newRand()
+			defer func() {
+				testResult.mu.Lock()
+				testResult.agg.merge(agg)
+				testResult.mu.Unlock()
+				cli.wg.Done()
+			}()
 			for {
 				select {
 				case <-cli.stopc:
@@ -110,106 +318,145 @@ func (cli *Client) Run() (testResult TestResult) {
 				default:
 				}
 
-				left := cli.requestsN.Dec()
-				if left < 0 {
-					return
-				}
-				if left%100 == 0 {
-					cli.lg.Info("request progress", zap.Int64("left", left), zap.Int64("total", cli.RequestsN))
-				}
-
-				route := cli.ChooseRoute()
-				start := time.Now().UTC()
-
-				// request
-				rs, err := http.Get(cli.Endpoint + route)
-				if err != nil {
-					testResult.mu.Lock()
-					testResult.Errors = append(testResult.Errors, err)
-					testResult.mu.Unlock()
-					cli.lg.Warn("request failed", zap.Error(err))
-					promFailure.WithLabelValues(cli.Endpoint, route).Inc()
-					if cli.requestsN.Dec() <= 0 {
+				reqStart := time.Now().UTC()
+				if cli.Profile != nil {
+					at := reqStart.Sub(start)
+					if at >= cli.Profile.Duration {
 						return
 					}
-					continue
-				}
-				if _, err = ioutil.ReadAll(rs.Body); err != nil {
-					testResult.mu.Lock()
-					testResult.Errors = append(testResult.Errors, err)
-					testResult.mu.Unlock()
-					cli.lg.Warn("request failed", zap.Error(err))
-					promFailure.WithLabelValues(cli.Endpoint, route).Inc()
-					if cli.requestsN.Dec() <= 0 {
+					if float64(idx) >= math.Round(cli.Profile.Level(at)) {
+						// inactive at current load level
+						time.Sleep(10 * time.Millisecond)
+						continue
+					}
+				} else {
+					left := cli.requestsN.Dec()
+					if left < 0 {
 						return
 					}
-					continue
+					if left%100 == 0 {
+						cli.lg.Info("request progress", zap.Int64("left", left), zap.Int64("total", cli.RequestsN))
+					}
 				}
-				if err = rs.Body.Close(); err != nil {
-					testResult.mu.Lock()
-					testResult.Errors = append(testResult.Errors, err)
-					testResult.mu.Unlock()
-					cli.lg.Warn("request failed", zap.Error(err))
-					promFailure.WithLabelValues(cli.Endpoint, route).Inc()
-					if cli.requestsN.Dec() <= 0 {
+
+				ep, route := cli.chooseTarget(rnd)
+				code, proto, err := cli.send(ep, route)
+				if err != nil {
+					testResult.addError(err)
+					agg.add(sample{at: reqStart.Sub(start), endpoint: ep, route: route, code: code, proto: proto, category: errorCategory(err)})
+					if cli.Profile == nil && cli.requestsN.Dec() <= 0 {
 						return
 					}
 					continue
 				}
 
-				promLat.WithLabelValues(cli.Endpoint, route).Observe(time.Now().UTC().Sub(start).Seconds())
-				promSuccess.WithLabelValues(cli.Endpoint, route).Inc()
+				lat := time.Now().UTC().Sub(reqStart)
+				agg.add(sample{at: reqStart.Sub(start), endpoint: ep, route: route, code: code, proto: proto, latency: lat, ok: true})
+				promLat.WithLabelValues(ep, route).Observe(lat.Seconds())
+				promSuccess.WithLabelValues(ep, route).Inc()
 			}
-		}()
+		}(i)
 	}
 	cli.wg.Wait()
+}
 
-	took := time.Now().UTC().Sub(now)
-	cli.lg.Info("finished client load tester",
-		zap.Int("clients", cli.ClientsN),
-		zap.Int64("requests", cli.RequestsN),
-	)
+// addError keeps the error as a sample, up to "MaxErrors".
+func (rs *TestResult) addError(err error) {
+	rs.mu.Lock()
+	if len(rs.Errors) < MaxErrors {
+		rs.Errors = append(rs.Errors, err)
+	}
+	rs.mu.Unlock()
+}
 
-	// fetch metrics
-	rs, err := http.Get(ts.URL + p)
-	if err != nil {
-		cli.lg.Warn("metrics fetch failed", zap.Error(err))
+// send sends a request to the route, and reads the response.
+// It returns the response status code and protocol, and an error for
+// non-2xx responses or payloads that do not match their integrity headers.
+// The failure is logged and counted.
+func (cli *Client) send(ep, route string) (code int, proto string, err error) {
+	method := cli.Method
+	if method == "" {
+		method = http.MethodGet
 	}
-	d, err := ioutil.ReadAll(rs.Body)
+	var body io.Reader
+	if len(cli.Body) > 0 {
+		body = bytes.NewReader(cli.Body)
+	}
+	req, err := http.NewRequest(method, ep+route, body)
 	if err != nil {
-		cli.lg.Warn("metrics fetch failed", zap.Error(err))
+		return 0, "", err
 	}
-	if err = rs.Body.Close(); err != nil {
-		cli.lg.Warn("metrics fetch failed", zap.Error(err))
+	for k, vs := range cli.Header {
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+	if host := req.Header.Get("Host"); host != "" {
+		req.Host = host
 	}
 
-	r := toResult(string(d))
-	r.endpoint = cli.Endpoint
-	r.routesN = len(cli.Routes)
-	r.requestsN = cli.RequestsN
-	r.clientsN = cli.ClientsN
-
-	if r.successN > 0 {
-		testResult.QPS = float64(r.successN) / took.Seconds()
+	rs, err := cli.HTTPClient.Do(req)
+	if err == nil {
+		code, proto = rs.StatusCode, rs.Proto
+		var d []byte
+		d, err = ioutil.ReadAll(rs.Body)
+		if cerr := rs.Body.Close(); err == nil {
+			err = cerr
+		}
+		if err == nil && (code < 200 || code >= 300) {
+			err = &statusError{url: ep + route, status: rs.Status}
+		}
+		if err == nil {
+			verify := cli.Verify
+			if verify == nil {
+				verify = payload.Verify
+			}
+			err = verify(rs.Header, d)
+		}
+	}
+	if err != nil {
+		cli.lg.Warn("request failed", zap.Error(err))
+		promFailure.WithLabelValues(ep, route).Inc()
 	}
+	return code, proto, err
+}
 
-	testResult.Success = int64(r.successN)
-	testResult.Failure = int64(r.failureN)
-	testResult.Result = string(d) +
-		r.String() +
-		fmt.Sprintf("Took: %v\n", took) +
-		fmt.Sprintf("QPS: %3.f successful requests per second\n", testResult.QPS) +
-		fmt.Sprintf("Error count: %d\n", len(testResult.Errors))
+// percentile returns the p-th percentile (0 < p <= 1) of the sorted latencies.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	return sorted[idx]
+}
 
-	return testResult
+func init() {
+	rand.Seed(time.Now().UTC().UnixNano())
 }
 
 // ChooseRoute chooses a random route.
 func (cli *Client) ChooseRoute() string {
-	rand.Seed(time.Now().UTC().UnixNano())
 	return cli.Routes[rand.Intn(len(cli.Routes))]
 }
 
+// newRand returns a random source for a client goroutine,
+// so that clients do not contend on the global source.
+func newRand() *rand.Rand {
+	return rand.New(rand.NewSource(rand.Int63()))
+}
+
+// chooseTarget chooses a random route and its endpoint.
+func (cli *Client) chooseTarget(rnd *rand.Rand) (ep string, route string) {
+	i := rnd.Intn(len(cli.Routes))
+	if len(cli.endpoints) == len(cli.Routes) {
+		return cli.endpoints[i], cli.Routes[i]
+	}
+	return cli.Endpoint, cli.Routes[i]
+}
+
 // Stop stops load testing.
 func (cli *Client) Stop() {
 	cli.lg.Info("stopping")
@@ -0,0 +1,167 @@
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress/path"
+	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress/payload"
+
+	"go.uber.org/zap"
+)
+
+func TestPercentile(t *testing.T) {
+	lats := make([]time.Duration, 100)
+	for i := range lats {
+		lats[i] = time.Duration(i+1) * time.Millisecond
+	}
+	tests := []struct {
+		p   float64
+		exp time.Duration
+	}{
+		{0.5, 50 * time.Millisecond},
+		{0.99, 99 * time.Millisecond},
+		{1.0, 100 * time.Millisecond},
+		{0.001, time.Millisecond},
+	}
+	for i, tt := range tests {
+		if v := percentile(lats, tt.p); v != tt.exp {
+			t.Fatalf("#%d: expected %v, got %v", i, tt.exp, v)
+		}
+	}
+	if v := percentile(nil, 0.5); v != 0 {
+		t.Fatalf("expected 0, got %v", v)
+	}
+}
+
+func TestNewSharded(t *testing.T) {
+	cli, err := NewSharded(zap.NewExample(), []Target{
+		{Endpoint: "http://a", Routes: []string{"/0", "/1"}},
+		{Endpoint: "http://b", Routes: []string{"/2"}},
+	}, 1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cli.Routes, []string{"/0", "/1", "/2"}) {
+		t.Fatalf("unexpected routes %v", cli.Routes)
+	}
+	exp := map[string]string{"/0": "http://a", "/1": "http://a", "/2": "http://b"}
+	rnd := newRand()
+	for i := 0; i < 30; i++ {
+		ep, route := cli.chooseTarget(rnd)
+		if exp[route] != ep {
+			t.Fatalf("route %q expected endpoint %q, got %q", route, exp[route], ep)
+		}
+	}
+	if _, err = NewSharded(zap.NewExample(), []Target{{Endpoint: "http://a"}}, 1, 10); err == nil {
+		t.Fatal("expected error for no routes")
+	}
+}
+
+func TestParseShardFlag(t *testing.T) {
+	tg, err := ParseShardFlag(ShardFlag("http://a.us-west-2.elb.amazonaws.com:80", 2, 4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := Target{Endpoint: "http://a.us-west-2.elb.amazonaws.com:80", Routes: []string{path.Create(2), path.Create(3)}}
+	if !reflect.DeepEqual(tg, exp) {
+		t.Fatalf("expected %+v, got %+v", exp, tg)
+	}
+	for _, s := range []string{"http://a", "http://a=3", "http://a=3-3", "=0-1"} {
+		if _, err = ParseShardFlag(s); err == nil {
+			t.Fatalf("expected error for %q", s)
+		}
+	}
+}
+
+func TestClientPayloadIntegrity(t *testing.T) {
+	// every 2nd response is truncated, every 3rd is corrupted
+	var n int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		i := atomic.AddInt64(&n, 1)
+		d := payload.Generate(i, 100)
+		payload.SetHeader(w.Header(), i, d)
+		switch {
+		case i%2 == 0:
+			d = d[:50]
+		case i%3 == 0:
+			d = append([]byte(nil), d...)
+			d[0] ^= 0xff
+		}
+		w.Write(d)
+	}))
+	defer ts.Close()
+
+	cli, err := New(zap.NewExample(), ts.URL, 1, 1, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs := cli.Run()
+	if rs.Success == 0 || rs.Failure == 0 || rs.ErrorCategories[ErrorCategoryIntegrity] != rs.Failure {
+		t.Fatalf("unexpected result %+v", rs)
+	}
+	var truncated, corrupted int
+	for _, err := range rs.Errors {
+		pe, ok := err.(*payload.Error)
+		if !ok {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if pe.Length != pe.ExpectedLength {
+			truncated++
+		} else {
+			corrupted++
+		}
+	}
+	if truncated == 0 || corrupted == 0 {
+		t.Fatalf("expected truncated and corrupted payloads, got %v", rs.Errors)
+	}
+}
+
+func TestClientMaxErrors(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	cli, err := New(zap.NewNop(), ts.URL, 1, 2, 4*MaxErrors)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs := cli.Run()
+	if rs.Failure <= MaxErrors || rs.ErrorCategories[ErrorCategoryStatus] != rs.Failure {
+		t.Fatalf("unexpected result %+v", rs)
+	}
+	if len(rs.Errors) != MaxErrors {
+		t.Fatalf("expected %d errors, got %d", MaxErrors, len(rs.Errors))
+	}
+}
+
+func TestClientVerifyStrict(t *testing.T) {
+	// responds without payload headers (e.g. error page from load balancer)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte("0"))
+	}))
+	defer ts.Close()
+
+	cli, err := New(zap.NewNop(), ts.URL, 1, 1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rs := cli.Run(); rs.Failure != 0 {
+		t.Fatalf("expected no failure without payload headers, got %+v", rs)
+	}
+
+	cli, err = New(zap.NewNop(), ts.URL, 1, 1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cli.Verify = payload.VerifyStrict
+	rs := cli.Run()
+	if rs.Failure == 0 || rs.ErrorCategories[ErrorCategoryIntegrity] != rs.Failure {
+		t.Fatalf("expected integrity errors without payload headers, got %+v", rs)
+	}
+}
@@ -0,0 +1,66 @@
+package client
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// histogramGrowth is the growth factor of histogram bucket upper bounds,
+// so that percentiles from a histogram are within 1% of the exact values.
+const histogramGrowth = 1.01
+
+// Histogram is the latency histogram, mapping the bucket index to the number
+// of requests with latency up to "histogramGrowth^index" microseconds.
+// Unlike percentiles, histograms from multiple clients can be merged.
+type Histogram map[int]int64
+
+func histogramBucket(d time.Duration) int {
+	us := float64(d) / float64(time.Microsecond)
+	if us <= 1 {
+		return 0
+	}
+	return int(math.Ceil(math.Log(us) / math.Log(histogramGrowth)))
+}
+
+func histogramUpperBound(idx int) time.Duration {
+	return time.Duration(math.Pow(histogramGrowth, float64(idx)) * float64(time.Microsecond))
+}
+
+// Add adds the latency to the histogram.
+func (h Histogram) Add(d time.Duration) {
+	h[histogramBucket(d)]++
+}
+
+// Merge adds the counts of the other histogram.
+func (h Histogram) Merge(other Histogram) {
+	for k, v := range other {
+		h[k] += v
+	}
+}
+
+// Percentile returns the p-th percentile (0 < p <= 1) upper bound.
+func (h Histogram) Percentile(p float64) time.Duration {
+	var total int64
+	keys := make([]int, 0, len(h))
+	for k, v := range h {
+		keys = append(keys, k)
+		total += v
+	}
+	if total == 0 {
+		return 0
+	}
+	sort.Ints(keys)
+	rank := int64(math.Ceil(p * float64(total)))
+	if rank < 1 {
+		rank = 1
+	}
+	var cnt int64
+	for _, k := range keys {
+		cnt += h[k]
+		if cnt >= rank {
+			return histogramUpperBound(k)
+		}
+	}
+	return histogramUpperBound(keys[len(keys)-1])
+}
@@ -0,0 +1,33 @@
+package client
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHistogram(t *testing.T) {
+	h1, h2 := make(Histogram), make(Histogram)
+	for i := 1; i <= 100; i++ {
+		h1.Add(time.Duration(i) * time.Millisecond)
+		h2.Add(time.Duration(100+i) * time.Millisecond)
+	}
+	h1.Merge(h2)
+
+	tests := []struct {
+		p   float64
+		exp time.Duration
+	}{
+		{0.5, 100 * time.Millisecond},
+		{0.99, 198 * time.Millisecond},
+		{1.0, 200 * time.Millisecond},
+	}
+	for i, tt := range tests {
+		v := h1.Percentile(tt.p)
+		if v < tt.exp || float64(v) > float64(tt.exp)*histogramGrowth {
+			t.Fatalf("#%d: expected p%v within 1%% of %v, got %v", i, tt.p, tt.exp, v)
+		}
+	}
+	if v := make(Histogram).Percentile(0.5); v != 0 {
+		t.Fatalf("expected 0, got %v", v)
+	}
+}
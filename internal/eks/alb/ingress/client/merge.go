@@ -0,0 +1,149 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// ResultBegin marks the beginning of JSON result in client output.
+	ResultBegin = "----- INGRESS CLIENT RESULT BEGIN -----"
+	// ResultEnd marks the end of JSON result in client output.
+	ResultEnd = "----- INGRESS CLIENT RESULT END -----"
+)
+
+// WriteResult writes the JSON result between the markers,
+// so that it can be parsed from the client logs.
+func WriteResult(w io.Writer, testResult TestResult) error {
+	d, err := testResult.JSON()
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "\n%s\n%s\n%s\n", ResultBegin, string(d), ResultEnd)
+	return err
+}
+
+// ReadResult parses the JSON result between the markers in the client logs.
+func ReadResult(logs string) (testResult TestResult, err error) {
+	begin := strings.Index(logs, ResultBegin)
+	if begin < 0 {
+		return testResult, fmt.Errorf("%q not found", ResultBegin)
+	}
+	logs = logs[begin+len(ResultBegin):]
+	end := strings.Index(logs, ResultEnd)
+	if end < 0 {
+		return testResult, fmt.Errorf("%q not found", ResultEnd)
+	}
+	err = json.NewDecoder(bytes.NewReader([]byte(logs[:end]))).Decode(&testResult)
+	return testResult, err
+}
+
+// Merge merges the results of clients that ran at the same time.
+// Percentiles are computed from the merged latency histograms,
+// and per-second throughput is summed by the offset from the start.
+func Merge(rs []TestResult) (merged TestResult) {
+	merged.StatusCodes = make(map[int]int64)
+	merged.ErrorCategories = make(map[string]int64)
+	merged.Protocols = make(map[string]int64)
+	merged.Histogram = make(Histogram)
+
+	type routeKey struct{ ep, route string }
+	routes := make(map[routeKey]*RouteResult)
+	for _, rv := range rs {
+		merged.Routes = rv.Routes
+		merged.Clients += rv.Clients
+		merged.Requests += rv.Requests
+		merged.Success += rv.Success
+		merged.Failure += rv.Failure
+		merged.QPS += rv.QPS
+		merged.Dropped += rv.Dropped
+		merged.Late += rv.Late
+		if rv.Latency.Max > merged.Latency.Max {
+			merged.Latency.Max = rv.Latency.Max
+		}
+		merged.Histogram.Merge(rv.Histogram)
+		for k, v := range rv.StatusCodes {
+			merged.StatusCodes[k] += v
+		}
+		for k, v := range rv.ErrorCategories {
+			merged.ErrorCategories[k] += v
+		}
+		for k, v := range rv.Protocols {
+			merged.Protocols[k] += v
+		}
+		for _, tv := range rv.Throughput {
+			for len(merged.Throughput) <= tv.Second {
+				merged.Throughput = append(merged.Throughput, Throughput{Second: len(merged.Throughput)})
+			}
+			merged.Throughput[tv.Second].Success += tv.Success
+			merged.Throughput[tv.Second].Failure += tv.Failure
+		}
+
+		for _, rr := range rv.RouteResults {
+			k := routeKey{rr.Endpoint, rr.Route}
+			mr, ok := routes[k]
+			if !ok {
+				mr = &RouteResult{Endpoint: rr.Endpoint, Route: rr.Route, StatusCodes: make(map[int]int64), Histogram: make(Histogram)}
+				routes[k] = mr
+			}
+			mr.Requests += rr.Requests
+			mr.Success += rr.Success
+			mr.Failure += rr.Failure
+			if rr.Latency.Max > mr.Latency.Max {
+				mr.Latency.Max = rr.Latency.Max
+			}
+			for code, v := range rr.StatusCodes {
+				mr.StatusCodes[code] += v
+			}
+			mr.Histogram.Merge(rr.Histogram)
+		}
+	}
+
+	merged.Latency = histogramSummary(merged.Histogram, merged.Latency.Max)
+	merged.LatencyP50 = merged.Latency.P50
+	merged.LatencyP99 = merged.Latency.P99
+	for _, mr := range routes {
+		mr.Latency = histogramSummary(mr.Histogram, mr.Latency.Max)
+		merged.RouteResults = append(merged.RouteResults, *mr)
+	}
+	sort.Slice(merged.RouteResults, func(i, j int) bool {
+		if merged.RouteResults[i].Endpoint != merged.RouteResults[j].Endpoint {
+			return merged.RouteResults[i].Endpoint < merged.RouteResults[j].Endpoint
+		}
+		return merged.RouteResults[i].Route < merged.RouteResults[j].Route
+	})
+
+	merged.Result = fmt.Sprintf("Merged %d client results\n\n", len(rs)) +
+		routesTable(merged.RouteResults) + "\n" +
+		fmt.Sprintf("Success: %d, Fail: %d\n", merged.Success, merged.Failure) +
+		fmt.Sprintf("QPS: %3.f successful requests per second\n", merged.QPS) +
+		fmt.Sprintf("Latency p50: %v, p90: %v, p99: %v, p999: %v, max: %v\n",
+			merged.Latency.P50, merged.Latency.P90, merged.Latency.P99, merged.Latency.P999, merged.Latency.Max) +
+		fmt.Sprintf("Status codes: %s\n", statusCodesString(merged.StatusCodes)) +
+		fmt.Sprintf("Error categories: %s\n", countsString(merged.ErrorCategories)) +
+		fmt.Sprintf("Protocols: %s\n", countsString(merged.Protocols))
+	return merged
+}
+
+// histogramSummary returns the latency distribution from the histogram.
+// Percentiles are capped by the exact maximum latency.
+func histogramSummary(h Histogram, max time.Duration) LatencySummary {
+	capped := func(d time.Duration) time.Duration {
+		if max > 0 && d > max {
+			return max
+		}
+		return d
+	}
+	return LatencySummary{
+		P50:  capped(h.Percentile(0.5)),
+		P90:  capped(h.Percentile(0.9)),
+		P99:  capped(h.Percentile(0.99)),
+		P999: capped(h.Percentile(0.999)),
+		Max:  max,
+	}
+}
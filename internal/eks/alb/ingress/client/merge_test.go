@@ -0,0 +1,72 @@
+package client
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestMerge(t *testing.T) {
+	r1 := TestResult{Routes: 2, Clients: 10, Requests: 100, QPS: 50, agg: aggregateSamples(0,
+		sample{at: 100 * time.Millisecond, endpoint: "http://a", route: "/0", code: 200, latency: 10 * time.Millisecond, ok: true},
+		sample{at: 1100 * time.Millisecond, endpoint: "http://a", route: "/1", code: 503, category: ErrorCategoryStatus},
+	)}
+	r1.aggregate()
+	r2 := TestResult{Routes: 2, Clients: 10, Requests: 100, QPS: 70, agg: aggregateSamples(0,
+		sample{at: 200 * time.Millisecond, endpoint: "http://a", route: "/0", code: 200, latency: 30 * time.Millisecond, ok: true},
+		sample{at: 300 * time.Millisecond, endpoint: "http://a", route: "/0", code: 200, latency: 40 * time.Millisecond, ok: true},
+		sample{at: 2100 * time.Millisecond, endpoint: "http://a", route: "/1", category: ErrorCategoryReset},
+	)}
+	r2.aggregate()
+
+	// round-trip through the client logs
+	buf := new(bytes.Buffer)
+	buf.WriteString("INFO started client load tester\n")
+	if err := WriteResult(buf, r2); err != nil {
+		t.Fatal(err)
+	}
+	buf.WriteString("INFO done\n")
+	decoded, err := ReadResult(buf.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = ReadResult("no result"); err == nil {
+		t.Fatal("expected error")
+	}
+
+	merged := Merge([]TestResult{r1, decoded})
+	if merged.Clients != 20 || merged.Requests != 200 || merged.QPS != 120 {
+		t.Fatalf("unexpected merged totals %+v", merged)
+	}
+	if merged.Success != 3 || merged.Failure != 2 {
+		t.Fatalf("unexpected success %d, failure %d", merged.Success, merged.Failure)
+	}
+	if merged.Latency.Max != 40*time.Millisecond {
+		t.Fatalf("unexpected max latency %v", merged.Latency.Max)
+	}
+	// p50 of 10ms, 30ms, 40ms is 30ms, which neither client reported
+	if merged.Latency.P50 < 30*time.Millisecond || float64(merged.Latency.P50) > float64(30*time.Millisecond)*histogramGrowth {
+		t.Fatalf("unexpected p50 latency %v", merged.Latency.P50)
+	}
+	if merged.Latency.P99 != 40*time.Millisecond {
+		t.Fatalf("expected p99 capped by max latency, got %v", merged.Latency.P99)
+	}
+	if merged.StatusCodes[200] != 3 || merged.StatusCodes[503] != 1 {
+		t.Fatalf("unexpected status codes %v", merged.StatusCodes)
+	}
+	if merged.ErrorCategories[ErrorCategoryStatus] != 1 || merged.ErrorCategories[ErrorCategoryReset] != 1 {
+		t.Fatalf("unexpected error categories %v", merged.ErrorCategories)
+	}
+	if len(merged.RouteResults) != 2 || merged.RouteResults[0].Requests != 3 || merged.RouteResults[1].Failure != 2 {
+		t.Fatalf("unexpected route results %+v", merged.RouteResults)
+	}
+	exp := []Throughput{{0, 3, 0}, {1, 0, 1}, {2, 0, 1}}
+	if len(merged.Throughput) != len(exp) {
+		t.Fatalf("unexpected throughput %+v", merged.Throughput)
+	}
+	for i := range exp {
+		if merged.Throughput[i] != exp[i] {
+			t.Fatalf("#%d: expected throughput %+v, got %+v", i, exp[i], merged.Throughput[i])
+		}
+	}
+}
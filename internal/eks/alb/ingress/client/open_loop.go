@@ -0,0 +1,137 @@
+package client
+
+import (
+	"math"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// DefaultMaxLateness is the default maximum delay from the intended send time,
+// after which a request is counted as late.
+const DefaultMaxLateness = 10 * time.Millisecond
+
+// idleInterval is the interval to check the rate again,
+// when the rate is zero (e.g. at the start of ramp profile).
+const idleInterval = 10 * time.Millisecond
+
+// rateAt returns the request rate at the offset from start in open-loop mode.
+// The profile takes precedence over "Rate" and "RateStep".
+func (cli *Client) rateAt(off time.Duration) float64 {
+	if cli.Profile != nil {
+		return cli.Profile.Level(off)
+	}
+	r := cli.Rate
+	if cli.RateStep > 0 && cli.RateStepInterval > 0 {
+		r += cli.RateStep * math.Floor(off.Seconds()/cli.RateStepInterval.Seconds())
+	}
+	return r
+}
+
+// nextOffset returns the offset of the intended send time of the request
+// following the one at the offset, sent at the rate.
+func nextOffset(off time.Duration, rate float64) time.Duration {
+	return off + time.Duration(float64(time.Second)/rate)
+}
+
+// job is a request scheduled in open-loop mode.
+type job struct {
+	intended time.Time
+}
+
+// runOpenLoop schedules requests at the intended send times, until
+// "RequestsN" requests are scheduled or the profile duration elapses,
+// and "ClientsN" clients send the scheduled requests. Latency is measured
+// from the intended send time, so that the queueing delay of a slow server
+// is not omitted. A request is dropped when all clients are busy and
+// "ClientsN" requests are already queued at its intended send time.
+func (cli *Client) runOpenLoop(testResult *TestResult, start time.Time) {
+	maxLateness := cli.MaxLateness
+	if maxLateness == 0 {
+		maxLateness = DefaultMaxLateness
+	}
+	cli.lg.Info("scheduling open-loop requests",
+		zap.Float64("rate", cli.Rate),
+		zap.Float64("rate-step", cli.RateStep),
+		zap.Duration("rate-step-interval", cli.RateStepInterval),
+		zap.Int64("requests", cli.RequestsN),
+	)
+
+	jobc := make(chan job, cli.ClientsN)
+	cli.wg.Add(cli.ClientsN)
+	for i := 0; i < cli.ClientsN; i++ {
+		go func() {
+			agg := newAggregator(cli.BucketInterval)
+			rnd := newRand()
+			var late int64
+			defer func() {
+				testResult.mu.Lock()
+				testResult.agg.merge(agg)
+				testResult.Late += late
+				testResult.mu.Unlock()
+				cli.wg.Done()
+			}()
+			for jv := range jobc {
+				if time.Now().UTC().Sub(jv.intended) > maxLateness {
+					late++
+				}
+
+				ep, route := cli.chooseTarget(rnd)
+				code, proto, err := cli.send(ep, route)
+				if err != nil {
+					testResult.addError(err)
+					agg.add(sample{at: jv.intended.Sub(start), endpoint: ep, route: route, code: code, proto: proto, category: errorCategory(err)})
+					continue
+				}
+
+				lat := time.Now().UTC().Sub(jv.intended)
+				agg.add(sample{at: jv.intended.Sub(start), endpoint: ep, route: route, code: code, proto: proto, latency: lat, ok: true})
+				promLat.WithLabelValues(ep, route).Observe(lat.Seconds())
+				promSuccess.WithLabelValues(ep, route).Inc()
+			}
+		}()
+	}
+
+	var scheduled, dropped int64
+	var off time.Duration
+scheduler:
+	for {
+		if cli.Profile != nil {
+			if off >= cli.Profile.Duration {
+				break
+			}
+		} else if scheduled >= cli.RequestsN {
+			break
+		}
+
+		intended := start.Add(off)
+		select {
+		case <-cli.stopc:
+			break scheduler
+		case <-time.After(intended.Sub(time.Now().UTC())):
+		}
+
+		rate := cli.rateAt(off)
+		if rate <= 0 {
+			off += idleInterval
+			continue
+		}
+		off = nextOffset(off, rate)
+
+		if scheduled%100 == 0 {
+			cli.lg.Info("request progress", zap.Int64("scheduled", scheduled), zap.Float64("rate", rate))
+		}
+		scheduled++
+		select {
+		case jobc <- job{intended: intended}:
+		default:
+			dropped++
+		}
+	}
+	close(jobc)
+	cli.wg.Wait()
+
+	testResult.mu.Lock()
+	testResult.Dropped = dropped
+	testResult.mu.Unlock()
+}
@@ -0,0 +1,99 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestNextOffset(t *testing.T) {
+	cli := &Client{Rate: 10}
+	var off time.Duration
+	for i := 1; i < 5; i++ {
+		off = nextOffset(off, cli.rateAt(off))
+		if exp := time.Duration(i) * 100 * time.Millisecond; off != exp {
+			t.Fatalf("#%d: expected offset %v, got %v", i, exp, off)
+		}
+	}
+
+	// 2 requests per second for the first second, then 4
+	cli = &Client{Rate: 2, RateStep: 2, RateStepInterval: time.Second}
+	off = 0
+	for i, exp := range []time.Duration{500 * time.Millisecond, time.Second, 1250 * time.Millisecond, 1500 * time.Millisecond} {
+		off = nextOffset(off, cli.rateAt(off))
+		if off != exp {
+			t.Fatalf("#%d: expected offset %v, got %v", i, exp, off)
+		}
+	}
+
+	// profile takes precedence
+	cli.Profile = &Profile{Type: ProfileConstant, OpenLoop: true, Duration: time.Minute, Start: 20}
+	if r := cli.rateAt(10 * time.Second); r != 20 {
+		t.Fatalf("expected rate 20, got %f", r)
+	}
+}
+
+func TestRunOpenLoop(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		fmt.Fprint(w, "0")
+	}))
+	defer ts.Close()
+
+	cli, err := New(zap.NewExample(), ts.URL, 1, 1, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 100 requests per second with 1 client that takes 50 ms per request
+	cli.Rate = 100
+	rs := cli.Run()
+	if rs.Dropped == 0 {
+		t.Fatalf("expected dropped requests, got %+v", rs)
+	}
+	if rs.Late == 0 {
+		t.Fatalf("expected late requests, got %+v", rs)
+	}
+	if rs.LatencyP99 < 50*time.Millisecond {
+		t.Fatalf("expected p99 latency >= 50ms, got %v", rs.LatencyP99)
+	}
+	if len(rs.Errors) > 0 {
+		t.Fatalf("unexpected errors %v", rs.Errors)
+	}
+}
+
+func TestRunProfile(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		fmt.Fprint(w, "0")
+	}))
+	defer ts.Close()
+
+	for _, openLoop := range []bool{false, true} {
+		cli, err := New(zap.NewExample(), ts.URL, 1, 2, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cli.Profile = &Profile{Type: ProfileStep, OpenLoop: openLoop, Duration: time.Second, Start: 1, Peak: 2, Steps: 2}
+		if openLoop {
+			cli.Profile.Start, cli.Profile.Peak = 10, 20
+		}
+		cli.BucketInterval = 500 * time.Millisecond
+		rs := cli.Run()
+		if len(rs.Errors) > 0 {
+			t.Fatalf("unexpected errors %v", rs.Errors)
+		}
+		// runs for the duration, regardless of requests
+		if rs.Success <= 1 {
+			t.Fatalf("expected more than 1 request, got %d", rs.Success)
+		}
+		if len(rs.Buckets) != 2 {
+			t.Fatalf("expected 2 buckets, got %+v", rs.Buckets)
+		}
+		if rs.Buckets[1].Level != cli.Profile.Peak {
+			t.Fatalf("expected second bucket level %f, got %f", cli.Profile.Peak, rs.Buckets[1].Level)
+		}
+	}
+}
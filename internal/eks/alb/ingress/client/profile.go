@@ -0,0 +1,99 @@
+package client
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	// ProfileConstant keeps the load at "Start" for the duration.
+	ProfileConstant = "constant"
+	// ProfileRamp increases the load linearly from "Start" to "Peak".
+	ProfileRamp = "ramp"
+	// ProfileStep increases the load from "Start" to "Peak"
+	// in "Steps" equal-length steps.
+	ProfileStep = "step"
+	// ProfileSpike keeps the load at "Start", except at "Peak"
+	// from "SpikeStart" for "SpikeDuration".
+	ProfileSpike = "spike"
+)
+
+// Profile defines how the load changes over the test duration.
+// The load level is the number of active clients in closed-loop mode,
+// or the number of requests per second in open-loop mode.
+type Profile struct {
+	// Type is the profile type.
+	Type string
+	// OpenLoop is true to apply the load level to the request rate.
+	// Otherwise, the load level is the number of active clients (up to "ClientsN").
+	OpenLoop bool
+	// Duration is the test duration. Requests are sent until the duration
+	// elapses, regardless of "RequestsN".
+	Duration time.Duration
+	// Start is the load level at start.
+	Start float64
+	// Peak is the load level at the end of ramp and step profiles,
+	// or during the spike.
+	Peak float64
+	// Steps is the number of steps in step profile.
+	Steps int
+	// SpikeStart is the offset from start when the spike begins.
+	SpikeStart time.Duration
+	// SpikeDuration is the duration of the spike.
+	SpikeDuration time.Duration
+}
+
+// Validate returns an error if the profile is invalid.
+func (p Profile) Validate() error {
+	if p.Duration <= 0 {
+		return fmt.Errorf("invalid profile duration %v", p.Duration)
+	}
+	if p.Start < 0 || p.Peak < 0 {
+		return fmt.Errorf("invalid profile load levels (start %f, peak %f)", p.Start, p.Peak)
+	}
+	switch p.Type {
+	case ProfileConstant:
+		if p.Start == 0 {
+			return fmt.Errorf("invalid %q profile start %f", p.Type, p.Start)
+		}
+	case ProfileRamp:
+		if p.Peak == 0 {
+			return fmt.Errorf("invalid %q profile peak %f", p.Type, p.Peak)
+		}
+	case ProfileStep:
+		if p.Steps < 2 {
+			return fmt.Errorf("invalid %q profile steps %d (must be >= 2)", p.Type, p.Steps)
+		}
+	case ProfileSpike:
+		if p.SpikeDuration <= 0 || p.SpikeStart < 0 || p.SpikeStart+p.SpikeDuration > p.Duration {
+			return fmt.Errorf("invalid %q profile spike (start %v, duration %v) for duration %v", p.Type, p.SpikeStart, p.SpikeDuration, p.Duration)
+		}
+	default:
+		return fmt.Errorf("unknown profile type %q", p.Type)
+	}
+	return nil
+}
+
+// Level returns the load level at the offset from start.
+func (p Profile) Level(at time.Duration) float64 {
+	switch p.Type {
+	case ProfileRamp:
+		if at >= p.Duration {
+			return p.Peak
+		}
+		return p.Start + (p.Peak-p.Start)*at.Seconds()/p.Duration.Seconds()
+	case ProfileStep:
+		k := math.Floor(at.Seconds() / (p.Duration.Seconds() / float64(p.Steps)))
+		if k > float64(p.Steps-1) {
+			k = float64(p.Steps - 1)
+		}
+		return p.Start + (p.Peak-p.Start)*k/float64(p.Steps-1)
+	case ProfileSpike:
+		if at >= p.SpikeStart && at < p.SpikeStart+p.SpikeDuration {
+			return p.Peak
+		}
+		return p.Start
+	}
+	return p.Start
+}
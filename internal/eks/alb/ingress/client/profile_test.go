@@ -0,0 +1,46 @@
+package client
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProfileLevel(t *testing.T) {
+	tests := []struct {
+		p   Profile
+		at  time.Duration
+		exp float64
+	}{
+		{Profile{Type: ProfileConstant, Duration: time.Minute, Start: 10}, 30 * time.Second, 10},
+		{Profile{Type: ProfileRamp, Duration: 100 * time.Second, Start: 0, Peak: 100}, 25 * time.Second, 25},
+		{Profile{Type: ProfileRamp, Duration: 100 * time.Second, Start: 0, Peak: 100}, 200 * time.Second, 100},
+		{Profile{Type: ProfileStep, Duration: 40 * time.Second, Start: 10, Peak: 40, Steps: 4}, 5 * time.Second, 10},
+		{Profile{Type: ProfileStep, Duration: 40 * time.Second, Start: 10, Peak: 40, Steps: 4}, 15 * time.Second, 20},
+		{Profile{Type: ProfileStep, Duration: 40 * time.Second, Start: 10, Peak: 40, Steps: 4}, 40 * time.Second, 40},
+		{Profile{Type: ProfileSpike, Duration: time.Minute, Start: 10, Peak: 100, SpikeStart: 20 * time.Second, SpikeDuration: 10 * time.Second}, 19 * time.Second, 10},
+		{Profile{Type: ProfileSpike, Duration: time.Minute, Start: 10, Peak: 100, SpikeStart: 20 * time.Second, SpikeDuration: 10 * time.Second}, 25 * time.Second, 100},
+		{Profile{Type: ProfileSpike, Duration: time.Minute, Start: 10, Peak: 100, SpikeStart: 20 * time.Second, SpikeDuration: 10 * time.Second}, 30 * time.Second, 10},
+	}
+	for i, tt := range tests {
+		if err := tt.p.Validate(); err != nil {
+			t.Fatalf("#%d: %v", i, err)
+		}
+		if v := tt.p.Level(tt.at); v != tt.exp {
+			t.Fatalf("#%d: expected level %f at %v, got %f", i, tt.exp, tt.at, v)
+		}
+	}
+}
+
+func TestProfileValidate(t *testing.T) {
+	for i, p := range []Profile{
+		{Type: ProfileConstant, Start: 10},
+		{Type: ProfileConstant, Duration: time.Minute},
+		{Type: ProfileStep, Duration: time.Minute, Peak: 10, Steps: 1},
+		{Type: ProfileSpike, Duration: time.Minute, Peak: 10, SpikeStart: 50 * time.Second, SpikeDuration: 20 * time.Second},
+		{Type: "unknown", Duration: time.Minute, Start: 10},
+	} {
+		if err := p.Validate(); err == nil {
+			t.Fatalf("#%d: expected error for %+v", i, p)
+		}
+	}
+}
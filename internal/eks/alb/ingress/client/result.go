@@ -0,0 +1,395 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress/payload"
+	"github.com/aws/aws-k8s-tester/pkg/csvutil"
+)
+
+const (
+	// ErrorCategoryDNS is the error category of DNS resolution failures.
+	ErrorCategoryDNS = "dns"
+	// ErrorCategoryConnect is the error category of connection failures.
+	ErrorCategoryConnect = "connect"
+	// ErrorCategoryTimeout is the error category of request timeouts.
+	ErrorCategoryTimeout = "timeout"
+	// ErrorCategoryReset is the error category of connections reset by peer.
+	ErrorCategoryReset = "reset"
+	// ErrorCategoryStatus is the error category of non-2xx responses.
+	ErrorCategoryStatus = "status"
+	// ErrorCategoryIntegrity is the error category of truncated or corrupted
+	// responses, whose payloads do not match their length or checksum headers,
+	// or miss the headers with "payload.VerifyStrict".
+	ErrorCategoryIntegrity = "integrity"
+	// ErrorCategoryOther is the error category of all other failures.
+	ErrorCategoryOther = "other"
+)
+
+// statusError is returned for non-2xx responses.
+type statusError struct {
+	url    string
+	status string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("%q returned %q", e.url, e.status)
+}
+
+// errorCategory returns the category of the request error.
+func errorCategory(err error) string {
+	if err == payload.ErrMissingChecksum {
+		return ErrorCategoryIntegrity
+	}
+	switch err.(type) {
+	case *statusError:
+		return ErrorCategoryStatus
+	case *payload.Error:
+		return ErrorCategoryIntegrity
+	}
+	if ue, ok := err.(*url.Error); ok {
+		if ue.Timeout() {
+			return ErrorCategoryTimeout
+		}
+		err = ue.Err
+	}
+	switch ev := err.(type) {
+	case *net.DNSError:
+		return ErrorCategoryDNS
+	case *net.OpError:
+		if _, ok := ev.Err.(*net.DNSError); ok {
+			return ErrorCategoryDNS
+		}
+		if ev.Timeout() {
+			return ErrorCategoryTimeout
+		}
+		if strings.Contains(ev.Err.Error(), "connection reset") {
+			return ErrorCategoryReset
+		}
+		if ev.Op == "dial" {
+			return ErrorCategoryConnect
+		}
+	}
+	if ne, ok := err.(net.Error); ok && ne.Timeout() {
+		return ErrorCategoryTimeout
+	}
+	if strings.Contains(err.Error(), "connection reset") {
+		return ErrorCategoryReset
+	}
+	return ErrorCategoryOther
+}
+
+// LatencySummary is the latency distribution of successful requests.
+type LatencySummary struct {
+	P50  time.Duration `json:"p50"`
+	P90  time.Duration `json:"p90"`
+	P99  time.Duration `json:"p99"`
+	P999 time.Duration `json:"p999"`
+	Max  time.Duration `json:"max"`
+}
+
+// summarize returns the latency distribution of the sorted latencies.
+func summarize(sorted []time.Duration) (ls LatencySummary) {
+	if len(sorted) == 0 {
+		return ls
+	}
+	return LatencySummary{
+		P50:  percentile(sorted, 0.5),
+		P90:  percentile(sorted, 0.9),
+		P99:  percentile(sorted, 0.99),
+		P999: percentile(sorted, 0.999),
+		Max:  sorted[len(sorted)-1],
+	}
+}
+
+// RouteResult is the test result of a route.
+type RouteResult struct {
+	Endpoint    string         `json:"endpoint"`
+	Route       string         `json:"route"`
+	Requests    int64          `json:"requests"`
+	Success     int64          `json:"success"`
+	Failure     int64          `json:"failure"`
+	Latency     LatencySummary `json:"latency"`
+	StatusCodes map[int]int64  `json:"status-codes,omitempty"`
+	// Histogram is the latency histogram of successful requests,
+	// to merge results from multiple clients.
+	Histogram Histogram `json:"histogram,omitempty"`
+}
+
+// Throughput is the number of requests sent in a second.
+type Throughput struct {
+	// Second is the offset in seconds from the test start.
+	Second  int   `json:"second"`
+	Success int64 `json:"success"`
+	Failure int64 `json:"failure"`
+}
+
+type routeKey struct{ ep, route string }
+
+// aggregator aggregates the request samples online, without keeping them,
+// so that memory does not grow with the number of requests. Each client
+// aggregates its own samples, and the aggregators are merged at the end.
+// Percentiles are computed from the latency histograms.
+type aggregator struct {
+	// interval is the time window of buckets, or zero to skip buckets.
+	interval time.Duration
+
+	success         int64
+	failure         int64
+	hist            Histogram
+	max             time.Duration
+	statusCodes     map[int]int64
+	errorCategories map[string]int64
+	protocols       map[string]int64
+	routes          map[routeKey]*RouteResult
+	throughput      []Throughput
+	buckets         []bucketAggregate
+}
+
+func newAggregator(interval time.Duration) *aggregator {
+	return &aggregator{
+		interval:        interval,
+		hist:            make(Histogram),
+		statusCodes:     make(map[int]int64),
+		errorCategories: make(map[string]int64),
+		protocols:       make(map[string]int64),
+		routes:          make(map[routeKey]*RouteResult),
+	}
+}
+
+func (agg *aggregator) route(ep, route string) *RouteResult {
+	k := routeKey{ep, route}
+	rr, ok := agg.routes[k]
+	if !ok {
+		rr = &RouteResult{Endpoint: ep, Route: route, StatusCodes: make(map[int]int64), Histogram: make(Histogram)}
+		agg.routes[k] = rr
+	}
+	return rr
+}
+
+func (agg *aggregator) second(sec int) *Throughput {
+	for len(agg.throughput) <= sec {
+		agg.throughput = append(agg.throughput, Throughput{Second: len(agg.throughput)})
+	}
+	return &agg.throughput[sec]
+}
+
+func (agg *aggregator) bucket(idx int) *bucketAggregate {
+	for len(agg.buckets) <= idx {
+		agg.buckets = append(agg.buckets, bucketAggregate{})
+	}
+	return &agg.buckets[idx]
+}
+
+// add aggregates the request sample.
+func (agg *aggregator) add(sv sample) {
+	rr := agg.route(sv.endpoint, sv.route)
+	rr.Requests++
+	if sv.code > 0 {
+		rr.StatusCodes[sv.code]++
+		agg.statusCodes[sv.code]++
+	}
+	if sv.proto != "" {
+		agg.protocols[sv.proto]++
+	}
+	if agg.interval > 0 {
+		agg.bucket(int(sv.at / agg.interval)).add(sv)
+	}
+
+	tv := agg.second(int(sv.at / time.Second))
+	if !sv.ok {
+		rr.Failure++
+		agg.failure++
+		agg.errorCategories[sv.category]++
+		tv.Failure++
+		return
+	}
+	rr.Success++
+	agg.success++
+	tv.Success++
+	rr.Histogram.Add(sv.latency)
+	agg.hist.Add(sv.latency)
+	if sv.latency > rr.Latency.Max {
+		rr.Latency.Max = sv.latency
+	}
+	if sv.latency > agg.max {
+		agg.max = sv.latency
+	}
+}
+
+// merge adds the aggregation of the other client of the same test.
+func (agg *aggregator) merge(other *aggregator) {
+	agg.success += other.success
+	agg.failure += other.failure
+	agg.hist.Merge(other.hist)
+	if other.max > agg.max {
+		agg.max = other.max
+	}
+	for k, v := range other.statusCodes {
+		agg.statusCodes[k] += v
+	}
+	for k, v := range other.errorCategories {
+		agg.errorCategories[k] += v
+	}
+	for k, v := range other.protocols {
+		agg.protocols[k] += v
+	}
+	for k, or := range other.routes {
+		rr := agg.route(k.ep, k.route)
+		rr.Requests += or.Requests
+		rr.Success += or.Success
+		rr.Failure += or.Failure
+		for code, v := range or.StatusCodes {
+			rr.StatusCodes[code] += v
+		}
+		rr.Histogram.Merge(or.Histogram)
+		if or.Latency.Max > rr.Latency.Max {
+			rr.Latency.Max = or.Latency.Max
+		}
+	}
+	for _, ov := range other.throughput {
+		tv := agg.second(ov.Second)
+		tv.Success += ov.Success
+		tv.Failure += ov.Failure
+	}
+	for i, ob := range other.buckets {
+		agg.bucket(i).merge(ob)
+	}
+}
+
+// aggregate computes the structured results from the aggregated samples.
+func (testResult *TestResult) aggregate() {
+	agg := testResult.agg
+	if agg == nil {
+		agg = newAggregator(0)
+	}
+
+	testResult.Success, testResult.Failure = agg.success, agg.failure
+	testResult.Histogram = agg.hist
+	testResult.StatusCodes = agg.statusCodes
+	testResult.ErrorCategories = agg.errorCategories
+	testResult.Protocols = agg.protocols
+	testResult.Latency = histogramSummary(agg.hist, agg.max)
+	testResult.LatencyP50 = testResult.Latency.P50
+	testResult.LatencyP99 = testResult.Latency.P99
+
+	testResult.RouteResults = make([]RouteResult, 0, len(agg.routes))
+	for _, rr := range agg.routes {
+		rv := *rr
+		rv.Latency = histogramSummary(rr.Histogram, rr.Latency.Max)
+		testResult.RouteResults = append(testResult.RouteResults, rv)
+	}
+	sort.Slice(testResult.RouteResults, func(i, j int) bool {
+		if testResult.RouteResults[i].Endpoint != testResult.RouteResults[j].Endpoint {
+			return testResult.RouteResults[i].Endpoint < testResult.RouteResults[j].Endpoint
+		}
+		return testResult.RouteResults[i].Route < testResult.RouteResults[j].Route
+	})
+	testResult.Throughput = agg.throughput
+}
+
+// JSON returns the test result in JSON.
+func (testResult TestResult) JSON() ([]byte, error) {
+	return json.MarshalIndent(testResult, "", "  ")
+}
+
+// SaveCSV writes the per-route results and the per-second throughput to CSV.
+func (testResult TestResult) SaveCSV(routesPath, throughputPath string) error {
+	rows := make([][]string, 0, len(testResult.RouteResults))
+	for _, rr := range testResult.RouteResults {
+		rows = append(rows, []string{
+			rr.Endpoint,
+			rr.Route,
+			strconv.FormatInt(rr.Requests, 10),
+			strconv.FormatInt(rr.Success, 10),
+			strconv.FormatInt(rr.Failure, 10),
+			strconv.FormatFloat(ms(rr.Latency.P50), 'f', 3, 64),
+			strconv.FormatFloat(ms(rr.Latency.P90), 'f', 3, 64),
+			strconv.FormatFloat(ms(rr.Latency.P99), 'f', 3, 64),
+			strconv.FormatFloat(ms(rr.Latency.P999), 'f', 3, 64),
+			strconv.FormatFloat(ms(rr.Latency.Max), 'f', 3, 64),
+			statusCodesString(rr.StatusCodes),
+		})
+	}
+	if err := csvutil.Save(
+		[]string{"endpoint", "route", "requests", "success", "failure", "p50-ms", "p90-ms", "p99-ms", "p999-ms", "max-ms", "status-codes"},
+		rows,
+		routesPath,
+	); err != nil {
+		return err
+	}
+
+	rows = make([][]string, 0, len(testResult.Throughput))
+	for _, tv := range testResult.Throughput {
+		rows = append(rows, []string{
+			strconv.Itoa(tv.Second),
+			strconv.FormatInt(tv.Success, 10),
+			strconv.FormatInt(tv.Failure, 10),
+		})
+	}
+	return csvutil.Save([]string{"second", "success", "failure"}, rows, throughputPath)
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// statusCodesString returns the status code counts in "200:10 503:1" format.
+func statusCodesString(codes map[int]int64) string {
+	keys := make([]int, 0, len(codes))
+	for k := range codes {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	ss := make([]string, 0, len(keys))
+	for _, k := range keys {
+		ss = append(ss, fmt.Sprintf("%d:%d", k, codes[k]))
+	}
+	return strings.Join(ss, " ")
+}
+
+// countsString returns the counts by name in "reset:1 timeout:2" format.
+func countsString(counts map[string]int64) string {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	ss := make([]string, 0, len(keys))
+	for _, k := range keys {
+		ss = append(ss, fmt.Sprintf("%s:%d", k, counts[k]))
+	}
+	return strings.Join(ss, " ")
+}
+
+// routesTable returns the per-route results in a table.
+func routesTable(rrs []RouteResult) string {
+	buf := new(bytes.Buffer)
+	tw := tabwriter.NewWriter(buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join([]string{"ENDPOINT", "ROUTE", "REQUESTS", "FAILURE", "P50", "P90", "P99", "P999", "MAX", "STATUS-CODES"}, "\t"))
+	for _, rr := range rrs {
+		fmt.Fprintln(tw, strings.Join([]string{
+			rr.Endpoint,
+			rr.Route,
+			strconv.FormatInt(rr.Requests, 10),
+			strconv.FormatInt(rr.Failure, 10),
+			rr.Latency.P50.String(),
+			rr.Latency.P90.String(),
+			rr.Latency.P99.String(),
+			rr.Latency.P999.String(),
+			rr.Latency.Max.String(),
+			statusCodesString(rr.StatusCodes),
+		}, "\t"))
+	}
+	tw.Flush()
+	return buf.String()
+}
@@ -0,0 +1,151 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress/payload"
+)
+
+func TestErrorCategory(t *testing.T) {
+	tests := []struct {
+		err error
+		exp string
+	}{
+		{&statusError{url: "http://a/", status: "503 Service Unavailable"}, ErrorCategoryStatus},
+		{&payload.Error{Seed: "1", ExpectedLength: 10, Length: 5}, ErrorCategoryIntegrity},
+		{&url.Error{Op: "Get", URL: "http://a/", Err: &net.OpError{Op: "dial", Err: &net.DNSError{Err: "no such host", Name: "a"}}}, ErrorCategoryDNS},
+		{&url.Error{Op: "Get", URL: "http://a/", Err: &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}}, ErrorCategoryConnect},
+		{&url.Error{Op: "Get", URL: "http://a/", Err: &net.OpError{Op: "read", Err: syscall.ECONNRESET}}, ErrorCategoryReset},
+		{&url.Error{Op: "Get", URL: "http://a/", Err: &net.DNSError{Err: "i/o timeout", IsTimeout: true}}, ErrorCategoryTimeout},
+		{errors.New("unexpected EOF"), ErrorCategoryOther},
+	}
+	for i, tt := range tests {
+		if c := errorCategory(tt.err); c != tt.exp {
+			t.Fatalf("#%d: expected %q, got %q (%v)", i, tt.exp, c, tt.err)
+		}
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	rs := TestResult{agg: aggregateSamples(0,
+		sample{at: 100 * time.Millisecond, endpoint: "http://a", route: "/0", code: 200, latency: 10 * time.Millisecond, ok: true},
+		sample{at: 200 * time.Millisecond, endpoint: "http://a", route: "/0", code: 200, latency: 30 * time.Millisecond, ok: true},
+		sample{at: 1100 * time.Millisecond, endpoint: "http://a", route: "/1", code: 503, category: ErrorCategoryStatus},
+		sample{at: 2100 * time.Millisecond, endpoint: "http://a", route: "/1", category: ErrorCategoryReset},
+		sample{at: 2200 * time.Millisecond, endpoint: "http://a", route: "/1", code: 200, latency: 20 * time.Millisecond, ok: true},
+	)}
+	rs.aggregate()
+
+	if rs.Success != 3 || rs.Failure != 2 {
+		t.Fatalf("unexpected success %d, failure %d", rs.Success, rs.Failure)
+	}
+	if rs.Latency.P50 < 20*time.Millisecond || float64(rs.Latency.P50) > float64(20*time.Millisecond)*histogramGrowth {
+		t.Fatalf("unexpected p50 latency %v", rs.Latency.P50)
+	}
+	if rs.Latency.Max != 30*time.Millisecond || rs.LatencyP99 != 30*time.Millisecond {
+		t.Fatalf("unexpected latency %+v", rs.Latency)
+	}
+	if rs.StatusCodes[200] != 3 || rs.StatusCodes[503] != 1 {
+		t.Fatalf("unexpected status codes %v", rs.StatusCodes)
+	}
+	if rs.ErrorCategories[ErrorCategoryStatus] != 1 || rs.ErrorCategories[ErrorCategoryReset] != 1 {
+		t.Fatalf("unexpected error categories %v", rs.ErrorCategories)
+	}
+	if len(rs.RouteResults) != 2 || rs.RouteResults[0].Route != "/0" || rs.RouteResults[1].Failure != 2 || rs.RouteResults[1].Latency.P99 != 20*time.Millisecond {
+		t.Fatalf("unexpected route results %+v", rs.RouteResults)
+	}
+	exp := []Throughput{{0, 2, 0}, {1, 0, 1}, {2, 1, 1}}
+	if len(rs.Throughput) != len(exp) {
+		t.Fatalf("unexpected throughput %+v", rs.Throughput)
+	}
+	for i := range exp {
+		if rs.Throughput[i] != exp[i] {
+			t.Fatalf("#%d: expected throughput %+v, got %+v", i, exp[i], rs.Throughput[i])
+		}
+	}
+
+	d, err := rs.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded TestResult
+	if err = json.Unmarshal(d, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.RouteResults[1].StatusCodes[503] != 1 || decoded.Latency.Max != 30*time.Millisecond {
+		t.Fatalf("unexpected decoded result %+v", decoded)
+	}
+
+	dir, err := ioutil.TempDir(os.TempDir(), "client-result")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	routesPath, throughputPath := filepath.Join(dir, "routes.csv"), filepath.Join(dir, "throughput.csv")
+	if err = rs.SaveCSV(routesPath, throughputPath); err != nil {
+		t.Fatal(err)
+	}
+	d, err = ioutil.ReadFile(routesPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(d), "http://a,/1,3,1,2,20.000,20.000,20.000,20.000,20.000,200:1 503:1") {
+		t.Fatalf("unexpected routes CSV:\n%s", string(d))
+	}
+	d, err = ioutil.ReadFile(throughputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(d), "second,success,failure\n0,2,0\n") {
+		t.Fatalf("unexpected throughput CSV:\n%s", string(d))
+	}
+}
+
+func TestAggregatorMerge(t *testing.T) {
+	samples := []sample{
+		{at: 100 * time.Millisecond, endpoint: "http://a", route: "/0", code: 200, proto: "HTTP/1.1", latency: 10 * time.Millisecond, ok: true},
+		{at: 1100 * time.Millisecond, endpoint: "http://a", route: "/1", code: 503, proto: "HTTP/1.1", category: ErrorCategoryStatus},
+		{at: 1200 * time.Millisecond, endpoint: "http://a", route: "/0", code: 200, proto: "HTTP/2.0", latency: 30 * time.Millisecond, ok: true},
+		{at: 2100 * time.Millisecond, endpoint: "http://a", route: "/1", category: ErrorCategoryReset},
+		{at: 3500 * time.Millisecond, endpoint: "http://b", route: "/0", code: 200, proto: "HTTP/1.1", latency: 20 * time.Millisecond, ok: true},
+	}
+	all := aggregateSamples(time.Second, samples...)
+	merged := newAggregator(time.Second)
+	merged.merge(aggregateSamples(time.Second, samples[:2]...))
+	merged.merge(aggregateSamples(time.Second, samples[2:]...))
+	if !reflect.DeepEqual(all, merged) {
+		t.Fatalf("expected merged aggregator %+v, got %+v", all, merged)
+	}
+
+	rs := TestResult{agg: merged}
+	rs.aggregate()
+	if rs.Success != 3 || rs.Failure != 2 || rs.Protocols["HTTP/2.0"] != 1 {
+		t.Fatalf("unexpected result %+v", rs)
+	}
+	if len(rs.RouteResults) != 3 || rs.RouteResults[0].Latency.Max != 30*time.Millisecond || rs.RouteResults[2].Endpoint != "http://b" {
+		t.Fatalf("unexpected route results %+v", rs.RouteResults)
+	}
+	if bs := merged.toBuckets(nil); len(bs) != 4 || bs[1].Requests != 2 || bs[1].LatencyP99 != 30*time.Millisecond || bs[2].Failure != 1 {
+		t.Fatalf("unexpected buckets %+v", bs)
+	}
+}
+
+// aggregateSamples returns the aggregator of the samples.
+func aggregateSamples(interval time.Duration, samples ...sample) *aggregator {
+	agg := newAggregator(interval)
+	for _, sv := range samples {
+		agg.add(sv)
+	}
+	return agg
+}
@@ -0,0 +1,269 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress/grpcecho"
+	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress/path"
+	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress/websocket"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+const (
+	// ProtocolWebSocket is the WebSocket connection test.
+	ProtocolWebSocket = "websocket"
+	// ProtocolGRPC is the gRPC bidirectional stream test.
+	ProtocolGRPC = "grpc"
+)
+
+// StreamOptions configures the long-lived connection tests,
+// which send messages over one WebSocket connection or gRPC stream.
+type StreamOptions struct {
+	// Messages is the number of messages to send.
+	Messages int
+	// Interval is the interval between messages.
+	Interval time.Duration
+	// Idle is the duration to keep the connection idle after the messages,
+	// before sending one last message. If zero, the connection is closed
+	// right after the messages.
+	Idle time.Duration
+	// PayloadSize is the message payload size.
+	PayloadSize int
+	// Timeout is the timeout to connect, and to receive each echoed message.
+	Timeout time.Duration
+}
+
+// StreamResult is the result of long-lived connection test.
+type StreamResult struct {
+	// Protocol is either "websocket" or "grpc".
+	Protocol string `json:"protocol"`
+	// Messages is the number of messages sent.
+	Messages int64 `json:"messages"`
+	// Failures is the number of messages not echoed back,
+	// excluding the message after idle.
+	Failures int64 `json:"failures"`
+	// Latency is the round-trip latency distribution of echoed messages.
+	Latency LatencySummary `json:"latency"`
+	// Duration is the duration from connect to the last echoed message.
+	Duration time.Duration `json:"duration"`
+	// Idle is the idle duration before the last message.
+	Idle time.Duration `json:"idle,omitempty"`
+	// IdleClosed is true if the connection was closed while idle
+	// (e.g. by the load balancer idle timeout).
+	IdleClosed bool `json:"idle-closed"`
+	// Error is the first message error.
+	Error string `json:"error,omitempty"`
+}
+
+// streamConn is a connection that echoes messages.
+type streamConn interface {
+	roundTrip(payload []byte) error
+	Close() error
+}
+
+// WebSocketURL returns the WebSocket URL of the ingress test server
+// endpoint (e.g. "https://a.com" to "wss://a.com/ingress-test-websocket").
+func WebSocketURL(ep string) (string, error) {
+	u, err := url.Parse(ep)
+	if err != nil {
+		return "", err
+	}
+	switch u.Scheme {
+	case "http":
+		u.Scheme = "ws"
+	case "https":
+		u.Scheme = "wss"
+	default:
+		return "", fmt.Errorf("unknown scheme %q", u.Scheme)
+	}
+	u.Path = path.PathWebSocket
+	return u.String(), nil
+}
+
+// GRPCTarget returns the gRPC target "host:port" of the endpoint
+// (e.g. "https://a.com" to "a.com:443").
+func GRPCTarget(ep string) (string, error) {
+	u, err := url.Parse(ep)
+	if err != nil {
+		return "", err
+	}
+	if u.Port() != "" {
+		return u.Host, nil
+	}
+	switch u.Scheme {
+	case "http":
+		return net.JoinHostPort(u.Hostname(), "80"), nil
+	case "https":
+		return net.JoinHostPort(u.Hostname(), "443"), nil
+	}
+	return "", fmt.Errorf("unknown scheme %q", u.Scheme)
+}
+
+// RunWebSocket opens a WebSocket connection to the URL,
+// and sends messages to be echoed by the ingress test server.
+func RunWebSocket(lg *zap.Logger, wsURL string, tlsCfg *tls.Config, opts StreamOptions) (StreamResult, error) {
+	start := time.Now().UTC()
+	conn, _, err := websocket.Dial(wsURL, nil, tlsCfg, opts.Timeout)
+	if err != nil {
+		return StreamResult{Protocol: ProtocolWebSocket}, err
+	}
+	lg.Info("connected websocket", zap.String("url", wsURL), zap.Duration("took", time.Now().UTC().Sub(start)))
+	return runStream(lg, ProtocolWebSocket, &webSocketConn{conn: conn, timeout: opts.Timeout}, start, opts), nil
+}
+
+type webSocketConn struct {
+	conn    *websocket.Conn
+	timeout time.Duration
+}
+
+func (c *webSocketConn) roundTrip(payload []byte) error {
+	if c.timeout > 0 {
+		c.conn.SetDeadline(time.Now().Add(c.timeout))
+		defer c.conn.SetDeadline(time.Time{})
+	}
+	if err := c.conn.WriteMessage(websocket.BinaryMessage, payload); err != nil {
+		return err
+	}
+	_, d, err := c.conn.ReadMessage()
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(d, payload) {
+		return fmt.Errorf("echoed message size %d != sent %d", len(d), len(payload))
+	}
+	return nil
+}
+
+func (c *webSocketConn) Close() error { return c.conn.Close() }
+
+// RunGRPC opens a gRPC bidirectional stream to the target ("host:port"),
+// and sends messages to be echoed by the ingress test server.
+// If tlsCfg is nil, the connection is not encrypted.
+func RunGRPC(lg *zap.Logger, target string, tlsCfg *tls.Config, opts StreamOptions) (StreamResult, error) {
+	start := time.Now().UTC()
+	cc, err := grpcecho.Dial(context.Background(), target, tlsCfg, opts.Timeout)
+	if err != nil {
+		return StreamResult{Protocol: ProtocolGRPC}, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := grpcecho.NewStream(ctx, cc)
+	if err != nil {
+		cancel()
+		cc.Close()
+		return StreamResult{Protocol: ProtocolGRPC}, err
+	}
+	lg.Info("connected gRPC stream", zap.String("target", target), zap.Duration("took", time.Now().UTC().Sub(start)))
+	return runStream(lg, ProtocolGRPC, &grpcConn{cc: cc, stream: stream, cancel: cancel, timeout: opts.Timeout}, start, opts), nil
+}
+
+type grpcConn struct {
+	cc      *grpc.ClientConn
+	stream  grpc.ClientStream
+	cancel  context.CancelFunc
+	timeout time.Duration
+}
+
+func (c *grpcConn) roundTrip(payload []byte) error {
+	errc := make(chan error, 1)
+	go func() {
+		if err := c.stream.SendMsg(&grpcecho.Message{Payload: payload}); err != nil {
+			errc <- err
+			return
+		}
+		resp := new(grpcecho.Message)
+		if err := c.stream.RecvMsg(resp); err != nil {
+			errc <- err
+			return
+		}
+		if !bytes.Equal(resp.Payload, payload) {
+			errc <- fmt.Errorf("echoed message size %d != sent %d", len(resp.Payload), len(payload))
+			return
+		}
+		errc <- nil
+	}()
+	var timeoutc <-chan time.Time
+	if c.timeout > 0 {
+		timeoutc = time.After(c.timeout)
+	}
+	select {
+	case err := <-errc:
+		return err
+	case <-timeoutc:
+		// stream is no longer usable
+		c.cancel()
+		return errors.New("gRPC stream message timed out")
+	}
+}
+
+func (c *grpcConn) Close() error {
+	c.stream.CloseSend()
+	c.cancel()
+	return c.cc.Close()
+}
+
+// runStream sends the messages over the connection, and closes it.
+func runStream(lg *zap.Logger, protocol string, conn streamConn, start time.Time, opts StreamOptions) (result StreamResult) {
+	defer conn.Close()
+	result.Protocol = protocol
+	payload := bytes.Repeat([]byte("0"), opts.PayloadSize)
+	last := start
+
+	var lats []time.Duration
+	for i := 0; i < opts.Messages; i++ {
+		if i > 0 && opts.Interval > 0 {
+			time.Sleep(opts.Interval)
+		}
+		now := time.Now().UTC()
+		err := conn.roundTrip(payload)
+		result.Messages++
+		if err != nil {
+			result.Failures++
+			result.Error = err.Error()
+			lg.Warn("stream message failed", zap.String("protocol", protocol), zap.Int("message", i), zap.Error(err))
+			// connection is broken
+			break
+		}
+		last = time.Now().UTC()
+		lats = append(lats, last.Sub(now))
+	}
+
+	if opts.Idle > 0 && result.Failures == 0 {
+		lg.Info("keeping connection idle", zap.String("protocol", protocol), zap.Duration("idle", opts.Idle))
+		time.Sleep(opts.Idle)
+		result.Idle = opts.Idle
+		now := time.Now().UTC()
+		err := conn.roundTrip(payload)
+		result.Messages++
+		if err != nil {
+			result.IdleClosed = true
+			lg.Info("connection closed while idle", zap.String("protocol", protocol), zap.Duration("idle", opts.Idle), zap.Error(err))
+		} else {
+			last = time.Now().UTC()
+			lats = append(lats, last.Sub(now))
+		}
+	}
+
+	sort.Slice(lats, func(i, j int) bool { return lats[i] < lats[j] })
+	result.Latency = summarize(lats)
+	result.Duration = last.Sub(start)
+	lg.Info("finished stream test",
+		zap.String("protocol", protocol),
+		zap.Int64("messages", result.Messages),
+		zap.Int64("failures", result.Failures),
+		zap.Duration("latency-p50", result.Latency.P50),
+		zap.Duration("latency-p99", result.Latency.P99),
+		zap.Duration("duration", result.Duration),
+		zap.Bool("idle-closed", result.IdleClosed),
+	)
+	return result
+}
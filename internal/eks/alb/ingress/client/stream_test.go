@@ -0,0 +1,128 @@
+package client
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress/grpcecho"
+	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress/websocket"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+func TestStreamEndpoints(t *testing.T) {
+	u, err := WebSocketURL("https://a.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u != "wss://a.com/ingress-test-websocket" {
+		t.Fatalf("unexpected WebSocket URL %q", u)
+	}
+	if u, err = WebSocketURL("http://a.com:8080"); err != nil || u != "ws://a.com:8080/ingress-test-websocket" {
+		t.Fatalf("unexpected WebSocket URL %q (%v)", u, err)
+	}
+	if _, err = WebSocketURL("ftp://a.com"); err == nil {
+		t.Fatal("expected error for unknown scheme")
+	}
+
+	target, err := GRPCTarget("https://a.com")
+	if err != nil || target != "a.com:443" {
+		t.Fatalf("unexpected gRPC target %q (%v)", target, err)
+	}
+	if target, err = GRPCTarget("http://127.0.0.1:32031"); err != nil || target != "127.0.0.1:32031" {
+		t.Fatalf("unexpected gRPC target %q (%v)", target, err)
+	}
+}
+
+func TestRunWebSocket(t *testing.T) {
+	// echoes "echoes" messages, and then closes the connection
+	echoes := 3
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		conn, err := websocket.Upgrade(w, req)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for i := 0; i < echoes; i++ {
+			typ, d, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if err = conn.WriteMessage(typ, d); err != nil {
+				return
+			}
+		}
+	}))
+	defer ts.Close()
+	wsURL := strings.Replace(ts.URL, "http://", "ws://", 1)
+
+	opts := StreamOptions{Messages: 2, Interval: 10 * time.Millisecond, Idle: 10 * time.Millisecond, PayloadSize: 100, Timeout: 5 * time.Second}
+	rs, err := RunWebSocket(zap.NewExample(), wsURL, nil, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rs.Protocol != ProtocolWebSocket || rs.Messages != 3 || rs.Failures != 0 || rs.IdleClosed {
+		t.Fatalf("unexpected result %+v", rs)
+	}
+	if rs.Latency.Max == 0 || rs.Duration < 10*time.Millisecond {
+		t.Fatalf("unexpected latency %+v, duration %v", rs.Latency, rs.Duration)
+	}
+
+	// connection is closed before the message after idle
+	opts.Messages = 3
+	rs, err = RunWebSocket(zap.NewExample(), wsURL, nil, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rs.Messages != 4 || rs.Failures != 0 || !rs.IdleClosed {
+		t.Fatalf("expected connection closed while idle, got %+v", rs)
+	}
+
+	// connection is closed before all messages are sent
+	opts.Messages, opts.Idle = 5, 0
+	rs, err = RunWebSocket(zap.NewExample(), wsURL, nil, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rs.Messages != 4 || rs.Failures != 1 || rs.Error == "" {
+		t.Fatalf("expected 1 failure, got %+v", rs)
+	}
+}
+
+func TestRunGRPC(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gs := grpc.NewServer()
+	grpcecho.Register(gs, &grpcecho.Server{Pod: "test"})
+	go gs.Serve(ln)
+	defer gs.Stop()
+
+	opts := StreamOptions{Messages: 3, Interval: 10 * time.Millisecond, PayloadSize: 100, Timeout: 5 * time.Second}
+	rs, err := RunGRPC(zap.NewExample(), ln.Addr().String(), nil, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rs.Protocol != ProtocolGRPC || rs.Messages != 3 || rs.Failures != 0 {
+		t.Fatalf("unexpected result %+v", rs)
+	}
+
+	// server goes away while idle
+	opts.Idle = 200 * time.Millisecond
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		gs.Stop()
+	}()
+	rs, err = RunGRPC(zap.NewExample(), ln.Addr().String(), nil, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rs.IdleClosed || rs.Failures != 0 {
+		t.Fatalf("expected stream closed while idle, got %+v", rs)
+	}
+}
@@ -0,0 +1,91 @@
+package client
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// TransportOptions configures the connections of the client.
+type TransportOptions struct {
+	// MaxConnsPerHost is the maximum number of connections per load balancer,
+	// including connections in use. If zero, connections are not limited.
+	MaxConnsPerHost int
+	// DisableKeepAlive is true to open a new connection per request,
+	// instead of reusing persistent connections.
+	DisableKeepAlive bool
+	// HTTP2 is true to send requests only over HTTP/2, which requires TLS.
+	// Only "h2" is offered in TLS ALPN, so the handshake fails
+	// if the load balancer does not support HTTP/2.
+	HTTP2 bool
+	// Timeout is the request timeout, including reading the response body.
+	// If zero, requests do not time out.
+	Timeout time.Duration
+	// InsecureSkipVerify is true to skip the server certificate verification,
+	// for self-signed certificates or load balancer DNS names not in the certificate.
+	InsecureSkipVerify bool
+}
+
+// NewHTTPClient creates the HTTP client with the transport options.
+// The TLS configuration is used to verify the server certificate, if not nil.
+func NewHTTPClient(opts TransportOptions, tlsCfg *tls.Config) *http.Client {
+	if tlsCfg == nil {
+		tlsCfg = &tls.Config{}
+	} else {
+		tlsCfg = tlsCfg.Clone()
+	}
+	if opts.InsecureSkipVerify {
+		tlsCfg.InsecureSkipVerify = true
+	}
+
+	if opts.HTTP2 {
+		tlsCfg.NextProtos = []string{"h2"}
+	}
+
+	maxIdle := 100
+	if opts.MaxConnsPerHost > 0 && opts.MaxConnsPerHost < maxIdle {
+		maxIdle = opts.MaxConnsPerHost
+	}
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy:               http.ProxyFromEnvironment,
+			TLSClientConfig:     tlsCfg,
+			ForceAttemptHTTP2:   opts.HTTP2,
+			MaxConnsPerHost:     opts.MaxConnsPerHost,
+			MaxIdleConnsPerHost: maxIdle,
+			DisableKeepAlives:   opts.DisableKeepAlive,
+		},
+		Timeout: opts.Timeout,
+	}
+}
+
+// ParseHeaders parses the request headers in "Key: Value" format.
+func ParseHeaders(ss []string) (http.Header, error) {
+	h := make(http.Header)
+	for _, s := range ss {
+		idx := strings.Index(s, ":")
+		if idx < 1 {
+			return nil, fmt.Errorf("invalid header %q (expected 'Key: Value')", s)
+		}
+		h.Add(strings.TrimSpace(s[:idx]), strings.TrimSpace(s[idx+1:]))
+	}
+	return h, nil
+}
+
+// ValidateMethod returns an error if the ingress test server
+// does not serve the request method, or if the method has no body.
+func ValidateMethod(method string, body []byte) error {
+	switch method {
+	case "", http.MethodGet:
+		if len(body) > 0 {
+			return errors.New("GET request cannot have body")
+		}
+	case http.MethodPost, http.MethodPut:
+	default:
+		return fmt.Errorf("unsupported method %q (must be GET, POST or PUT)", method)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package client
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestNewHTTPClient(t *testing.T) {
+	var conns int64
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte(req.Proto))
+	}))
+	ts.Config.ConnState = func(_ net.Conn, st http.ConnState) {
+		if st == http.StateNew {
+			atomic.AddInt64(&conns, 1)
+		}
+	}
+	ts.EnableHTTP2 = true
+	ts.StartTLS()
+	defer ts.Close()
+
+	tests := []struct {
+		opts  TransportOptions
+		proto string
+		conns int64
+	}{
+		{TransportOptions{InsecureSkipVerify: true}, "HTTP/1.1", 1},
+		{TransportOptions{InsecureSkipVerify: true, DisableKeepAlive: true}, "HTTP/1.1", 3},
+		{TransportOptions{InsecureSkipVerify: true, HTTP2: true}, "HTTP/2.0", 1},
+	}
+	for i, tt := range tests {
+		atomic.StoreInt64(&conns, 0)
+		cli := NewHTTPClient(tt.opts, nil)
+		for j := 0; j < 3; j++ {
+			rs, err := cli.Get(ts.URL)
+			if err != nil {
+				t.Fatalf("#%d: %v", i, err)
+			}
+			d, _ := ioutil.ReadAll(rs.Body)
+			rs.Body.Close()
+			if string(d) != tt.proto || rs.Proto != tt.proto {
+				t.Fatalf("#%d: expected %q, got %q (%q)", i, tt.proto, rs.Proto, string(d))
+			}
+		}
+		if n := atomic.LoadInt64(&conns); n != tt.conns {
+			t.Fatalf("#%d: expected %d connections, got %d", i, tt.conns, n)
+		}
+	}
+
+	if _, err := NewHTTPClient(TransportOptions{}, nil).Get(ts.URL); err == nil {
+		t.Fatal("expected certificate verification error")
+	}
+
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		time.Sleep(time.Second)
+	}))
+	defer slow.Close()
+	if _, err := NewHTTPClient(TransportOptions{Timeout: 100 * time.Millisecond}, nil).Get(slow.URL); err == nil || errorCategory(err) != ErrorCategoryTimeout {
+		t.Fatalf("expected timeout, got %v", err)
+	}
+}
+
+func TestClientRequestOptions(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		d, _ := ioutil.ReadAll(req.Body)
+		if req.Method != http.MethodPost || req.Header.Get("X-Test") != "a" || req.Host != "example.com" || string(d) != "hello" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer ts.Close()
+
+	cli, err := New(zap.NewExample(), ts.URL, 1, 1, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cli.Method = http.MethodPost
+	cli.Header, err = ParseHeaders([]string{"X-Test: a", "Host: example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cli.Body = []byte("hello")
+	rs := cli.Run()
+	if rs.Success != 3 || rs.Failure != 0 || rs.Protocols["HTTP/1.1"] != 3 {
+		t.Fatalf("unexpected result %+v", rs)
+	}
+
+	if _, err = ParseHeaders([]string{"X-Test"}); err == nil {
+		t.Fatal("expected error for invalid header")
+	}
+	for i, tt := range []struct {
+		method string
+		body   string
+		ok     bool
+	}{
+		{"", "", true},
+		{"GET", "a", false},
+		{"POST", "a", true},
+		{"DELETE", "", false},
+	} {
+		if err = ValidateMethod(tt.method, []byte(tt.body)); (err == nil) != tt.ok {
+			t.Fatalf("#%d: unexpected error %v", i, err)
+		}
+	}
+}
@@ -26,6 +26,10 @@ type ConfigDeploymentServiceALBIngressController struct {
 	Image string
 	// ClusterName is the EKS cluster name.
 	ClusterName string
+	// AWSRoleARN is the ARN of the IAM role for the controller to assume.
+	// If set, the mounted secret is an AWS config file that assumes the role
+	// with the instance profile credentials, instead of a shared credentials file.
+	AWSRoleARN string
 }
 
 // CreateDeploymentServiceALBIngressController generates deployment and service for ALB Ingress Controller.
@@ -50,6 +54,22 @@ func CreateDeploymentServiceALBIngressController(cfg ConfigDeploymentServiceALBI
 		return "", errors.New("empty Region")
 	}
 
+	credEnv := []v1.EnvVar{
+		{
+			Name:  "AWS_SHARED_CREDENTIALS_FILE",
+			Value: "/etc/aws-cred-aws-k8s-tester/aws-cred-aws-k8s-tester",
+		},
+	}
+	if cfg.AWSRoleARN != "" {
+		credEnv = []v1.EnvVar{
+			{
+				Name:  "AWS_CONFIG_FILE",
+				Value: "/etc/aws-cred-aws-k8s-tester/aws-cred-aws-k8s-tester",
+			},
+			{Name: "AWS_SDK_LOAD_CONFIG", Value: "true"},
+		}
+	}
+
 	oneV := intstr.FromInt(1)
 	dp := v1beta1.Deployment{
 		TypeMeta: metav1.TypeMeta{
@@ -140,7 +160,7 @@ func CreateDeploymentServiceALBIngressController(cfg ConfigDeploymentServiceALBI
 								PeriodSeconds:       60,
 								TimeoutSeconds:      30,
 							},
-							Env: []v1.EnvVar{
+							Env: append([]v1.EnvVar{
 								{Name: "AWS_REGION", Value: cfg.AWSRegion},
 								{Name: "AWS_DEBUG", Value: "false"},
 								{
@@ -161,11 +181,7 @@ func CreateDeploymentServiceALBIngressController(cfg ConfigDeploymentServiceALBI
 										},
 									},
 								},
-								{
-									Name:  "AWS_SHARED_CREDENTIALS_FILE",
-									Value: "/etc/aws-cred-aws-k8s-tester/aws-cred-aws-k8s-tester",
-								},
-							},
+							}, credEnv...),
 							VolumeMounts: []v1.VolumeMount{
 								{
 									Name:      "aws-cred-aws-k8s-tester",
@@ -22,5 +22,20 @@ func TestCreateDeploymentServiceALBIngressController(t *testing.T) {
 	if !strings.Contains(d, "--cluster-name=EKS-PROW-CLUSTER") {
 		t.Fatalf("expected '--cluster-name=EKS-PROW-CLUSTER', got %q", d)
 	}
+	if !strings.Contains(d, "AWS_SHARED_CREDENTIALS_FILE") {
+		t.Fatalf("expected 'AWS_SHARED_CREDENTIALS_FILE', got %q", d)
+	}
 	fmt.Println(d)
+
+	cfg.AWSRoleARN = "arn:aws:iam::123456789012:role/alb-test"
+	d, err = CreateDeploymentServiceALBIngressController(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(d, "AWS_SHARED_CREDENTIALS_FILE") {
+		t.Fatalf("unexpected 'AWS_SHARED_CREDENTIALS_FILE' with role, got %q", d)
+	}
+	if !strings.Contains(d, "AWS_CONFIG_FILE") || !strings.Contains(d, "AWS_SDK_LOAD_CONFIG") {
+		t.Fatalf("expected 'AWS_CONFIG_FILE' and 'AWS_SDK_LOAD_CONFIG', got %q", d)
+	}
 }
@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress/path"
+
 	gyaml "github.com/ghodss/yaml"
 	"k8s.io/api/core/v1"
 	"k8s.io/api/extensions/v1beta1"
@@ -20,6 +22,8 @@ type ConfigDeploymentServiceIngressTestServer struct {
 	// Namespace is the name space to deploy ingress-test to.
 	// If empty, defaults to the "default" namespace.
 	Namespace string
+	// CreateNamespace is true to create the namespace as well.
+	CreateNamespace bool
 	// Image is the ingress-test docker image.
 	Image string
 	// Replicas is the number of pods to deploy.
@@ -28,8 +32,24 @@ type ConfigDeploymentServiceIngressTestServer struct {
 	Routes int
 	// ResponseSize is the server response size.
 	ResponseSize int
+	// Faults is the faults to inject in JSON array.
+	// If empty, no fault is injected.
+	Faults string
+	// GRPC is true to serve the gRPC echo service on "GRPCServicePort".
+	GRPC bool
+	// DrainSeconds is the period in seconds to fail readiness and keep
+	// serving requests on pod termination. If zero, the server shuts down
+	// right away.
+	DrainSeconds int
 }
 
+// GRPCServicePort is the service port of ingress test server gRPC echo service.
+const GRPCServicePort = 81
+
+// shutdownTimeoutSeconds is the timeout to wait for in-flight requests
+// after the drain period.
+const shutdownTimeoutSeconds = 10
+
 // CreateDeploymentServiceIngressTestServer generates deployment and service for ALB Ingress Controller.
 // Reference https://godoc.org/k8s.io/apimachinery/pkg/apis/meta/v1#ObjectMeta.
 func CreateDeploymentServiceIngressTestServer(cfg ConfigDeploymentServiceIngressTestServer) (string, error) {
@@ -52,6 +72,56 @@ func CreateDeploymentServiceIngressTestServer(cfg ConfigDeploymentServiceIngress
 		return "", errors.New("zero Routes")
 	}
 
+	args := []string{
+		"aws-k8s-tester",
+		"eks",
+		"ingress",
+		"server",
+		"--port=:32030",
+		fmt.Sprintf("--routes=%d", cfg.Routes),
+		fmt.Sprintf("--response-size=%d", cfg.ResponseSize),
+	}
+	if cfg.Faults != "" {
+		args = append(args, fmt.Sprintf("--faults=%s", cfg.Faults))
+	}
+	var gracePeriod *int64
+	if cfg.DrainSeconds > 0 {
+		args = append(args,
+			fmt.Sprintf("--drain-period=%ds", cfg.DrainSeconds),
+			fmt.Sprintf("--shutdown-timeout=%ds", shutdownTimeoutSeconds),
+		)
+		// kubelet kills the container after the grace period
+		gp := int64(cfg.DrainSeconds + shutdownTimeoutSeconds + 5)
+		gracePeriod = &gp
+	}
+	containerPorts := []v1.ContainerPort{
+		{
+			ContainerPort: 32030, // use default
+			Protocol:      v1.ProtocolTCP,
+		},
+	}
+	servicePorts := []v1.ServicePort{
+		{
+			Name:       "ingress-test-server-web",
+			Port:       80,
+			TargetPort: intstr.FromInt(32030),
+			Protocol:   v1.ProtocolTCP,
+		},
+	}
+	if cfg.GRPC {
+		args = append(args, "--grpc-port=:32031")
+		containerPorts = append(containerPorts, v1.ContainerPort{
+			ContainerPort: 32031,
+			Protocol:      v1.ProtocolTCP,
+		})
+		servicePorts = append(servicePorts, v1.ServicePort{
+			Name:       "ingress-test-server-grpc",
+			Port:       GRPCServicePort,
+			TargetPort: intstr.FromInt(32031),
+			Protocol:   v1.ProtocolTCP,
+		})
+	}
+
 	oneV := intstr.FromInt(1)
 	dp := v1beta1.Deployment{
 		TypeMeta: metav1.TypeMeta{
@@ -94,33 +164,34 @@ func CreateDeploymentServiceIngressTestServer(cfg ConfigDeploymentServiceIngress
 							Name:            cfg.Name,
 							Image:           cfg.Image,
 							ImagePullPolicy: v1.PullAlways,
-							Args: []string{
-								"aws-k8s-tester",
-								"eks",
-								"ingress",
-								"server",
-								"--port=:32030",
-								fmt.Sprintf("--routes=%d", cfg.Routes),
-								fmt.Sprintf("--response-size=%d", cfg.ResponseSize),
-							},
-							Ports: []v1.ContainerPort{
-								{
-									ContainerPort: 32030, // use default
-									Protocol:      v1.ProtocolTCP,
+							Args:            args,
+							Ports:           containerPorts,
+							ReadinessProbe: &v1.Probe{
+								Handler: v1.Handler{
+									HTTPGet: &v1.HTTPGetAction{
+										Path: path.PathReady,
+										Port: intstr.FromInt(32030),
+									},
 								},
+								InitialDelaySeconds: 10,
+								PeriodSeconds:       5,
+								TimeoutSeconds:      5,
 							},
-							ReadinessProbe: &v1.Probe{
+							LivenessProbe: &v1.Probe{
 								Handler: v1.Handler{
-									TCPSocket: &v1.TCPSocketAction{
+									HTTPGet: &v1.HTTPGetAction{
+										Path: path.PathLive,
 										Port: intstr.FromInt(32030),
 									},
 								},
 								InitialDelaySeconds: 10,
-								PeriodSeconds:       20,
-								TimeoutSeconds:      30,
+								PeriodSeconds:       10,
+								TimeoutSeconds:      5,
+								FailureThreshold:    3,
 							},
 						},
 					},
+					TerminationGracePeriodSeconds: gracePeriod,
 				},
 			},
 		},
@@ -136,14 +207,7 @@ func CreateDeploymentServiceIngressTestServer(cfg ConfigDeploymentServiceIngress
 			Namespace: cfg.Namespace,
 		},
 		Spec: v1.ServiceSpec{
-			Ports: []v1.ServicePort{
-				{
-					Name:       "ingress-test-server-web",
-					Port:       80,
-					TargetPort: intstr.FromInt(32030),
-					Protocol:   v1.ProtocolTCP,
-				},
-			},
+			Ports: servicePorts,
 			Selector: map[string]string{
 				"app": cfg.Name,
 			},
@@ -160,7 +224,7 @@ func CreateDeploymentServiceIngressTestServer(cfg ConfigDeploymentServiceIngress
 	if err != nil {
 		return "", err
 	}
-	return fmt.Sprintf(`---
+	d := fmt.Sprintf(`---
 %s
 
 
@@ -168,5 +232,27 @@ func CreateDeploymentServiceIngressTestServer(cfg ConfigDeploymentServiceIngress
 %s
 
 
-`, string(d1), string(d2)), nil
+`, string(d1), string(d2))
+
+	if cfg.CreateNamespace {
+		ns := v1.Namespace{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: "v1",
+				Kind:       "Namespace",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name: cfg.Namespace,
+			},
+		}
+		d0, err := gyaml.Marshal(ns)
+		if err != nil {
+			return "", err
+		}
+		d = fmt.Sprintf(`---
+%s
+
+
+`, string(d0)) + d
+	}
+	return d, nil
 }
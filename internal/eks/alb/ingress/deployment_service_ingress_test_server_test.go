@@ -26,5 +26,54 @@ func TestCreateDeploymentServiceIngressTestServer(t *testing.T) {
 	if !strings.Contains(d, "--routes=10") {
 		t.Fatalf("expected '--routes=10', got %q", d)
 	}
+	if strings.Contains(d, "--faults") {
+		t.Fatalf("unexpected '--faults', got %q", d)
+	}
 	fmt.Println(d)
+	if strings.Contains(d, "kind: Namespace") {
+		t.Fatalf("unexpected Namespace object, got %q", d)
+	}
+
+	cfg.Namespace = "ingress-test-server-1"
+	cfg.CreateNamespace = true
+	d, err = CreateDeploymentServiceIngressTestServer(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(d, "kind: Namespace") || !strings.Contains(d, "namespace: ingress-test-server-1") {
+		t.Fatalf("expected Namespace object 'ingress-test-server-1', got %q", d)
+	}
+
+	cfg.Faults = `[{"route":"*","error-rate":0.1}]`
+	d, err = CreateDeploymentServiceIngressTestServer(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(d, `--faults=[{"route":"*","error-rate":0.1}]`) {
+		t.Fatalf("expected '--faults', got %q", d)
+	}
+
+	cfg.GRPC = true
+	d, err = CreateDeploymentServiceIngressTestServer(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(d, "--grpc-port=:32031") || !strings.Contains(d, "name: ingress-test-server-grpc") {
+		t.Fatalf("expected gRPC port, got %q", d)
+	}
+	if !strings.Contains(d, "path: /ingress-test-ready") || !strings.Contains(d, "path: /ingress-test-live") {
+		t.Fatalf("expected readiness and liveness probes, got %q", d)
+	}
+	if strings.Contains(d, "--drain-period") || strings.Contains(d, "terminationGracePeriodSeconds") {
+		t.Fatalf("unexpected drain period, got %q", d)
+	}
+
+	cfg.DrainSeconds = 30
+	d, err = CreateDeploymentServiceIngressTestServer(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(d, "--drain-period=30s") || !strings.Contains(d, "terminationGracePeriodSeconds: 45") {
+		t.Fatalf("expected drain period, got %q", d)
+	}
 }
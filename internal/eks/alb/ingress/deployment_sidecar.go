@@ -0,0 +1,111 @@
+package ingress
+
+import (
+	"errors"
+	"fmt"
+
+	gyaml "github.com/ghodss/yaml"
+	"k8s.io/api/core/v1"
+	"k8s.io/api/extensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// SidecarPort is the port that sidecar server listens on.
+const SidecarPort = 32050
+
+// ConfigDeploymentSidecar defines sidecar deployment configuration.
+type ConfigDeploymentSidecar struct {
+	// Name is used for metadata name and for pod selector.
+	Name string
+	// Namespace is the name space to deploy sidecar to.
+	Namespace string
+	// Image is the aws-k8s-tester docker image.
+	Image string
+}
+
+// CreateDeploymentSidecar generates deployment for sidecar server,
+// which forwards requests from inside the cluster.
+func CreateDeploymentSidecar(cfg ConfigDeploymentSidecar) (string, error) {
+	if cfg.Name == "" {
+		return "", errors.New("empty Name")
+	}
+	if cfg.Namespace == "" {
+		return "", errors.New("empty Namespace")
+	}
+	if cfg.Image == "" {
+		return "", errors.New("empty Image")
+	}
+
+	dp := v1beta1.Deployment{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "extensions/v1beta1",
+			Kind:       "Deployment",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cfg.Name,
+			Namespace: cfg.Namespace,
+			Labels: map[string]string{
+				"app": cfg.Name,
+			},
+		},
+		Spec: v1beta1.DeploymentSpec{
+			Replicas: newInt32(1),
+
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"app": cfg.Name,
+				},
+			},
+
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						"app": cfg.Name,
+					},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{
+							Name:            cfg.Name,
+							Image:           cfg.Image,
+							ImagePullPolicy: v1.PullAlways,
+							Args: []string{
+								"aws-k8s-tester",
+								"eks",
+								"sidecar",
+								fmt.Sprintf("--port=:%d", SidecarPort),
+							},
+							Ports: []v1.ContainerPort{
+								{
+									ContainerPort: SidecarPort,
+									Protocol:      v1.ProtocolTCP,
+								},
+							},
+							ReadinessProbe: &v1.Probe{
+								Handler: v1.Handler{
+									TCPSocket: &v1.TCPSocketAction{
+										Port: intstr.FromInt(SidecarPort),
+									},
+								},
+								InitialDelaySeconds: 5,
+								PeriodSeconds:       10,
+								TimeoutSeconds:      30,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	d, err := gyaml.Marshal(dp)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(`---
+%s
+
+
+`, string(d)), nil
+}
@@ -0,0 +1,24 @@
+package ingress
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCreateDeploymentSidecar(t *testing.T) {
+	d, err := CreateDeploymentSidecar(ConfigDeploymentSidecar{
+		Name:      "sidecar",
+		Namespace: "default",
+		Image:     "000000000000.dkr.ecr.us-west-2.amazonaws.com/aws-k8s-tester:latest",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(d, "--port=:32050") {
+		t.Fatalf("expected sidecar port, got %s", d)
+	}
+
+	if _, err = CreateDeploymentSidecar(ConfigDeploymentSidecar{Name: "sidecar", Namespace: "default"}); err == nil {
+		t.Fatal("expected error for empty Image")
+	}
+}
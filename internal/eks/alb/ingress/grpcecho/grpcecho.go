@@ -0,0 +1,154 @@
+// Package grpcecho implements the gRPC echo service of ingress test server.
+// Messages are encoded in JSON, so the service does not require
+// generated protobuf code.
+package grpcecho
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"io"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/encoding"
+)
+
+const (
+	// ServiceName is the gRPC service name.
+	ServiceName = "ingress.Echo"
+	// MethodEcho is the full name of unary echo method.
+	MethodEcho = "/" + ServiceName + "/Echo"
+	// MethodStream is the full name of bidirectional streaming echo method.
+	MethodStream = "/" + ServiceName + "/Stream"
+	// PathPrefix is the HTTP/2 path prefix of all service methods,
+	// to route to the service in Ingress rules.
+	PathPrefix = "/" + ServiceName + "/"
+)
+
+// Message is the echo request and response.
+type Message struct {
+	// Payload is the message payload.
+	Payload []byte `json:"payload,omitempty"`
+	// Pod is the name of pod that echoed the message.
+	Pod string `json:"pod,omitempty"`
+}
+
+// codecName is the content subtype of messages ("application/grpc+json").
+const codecName = "json"
+
+type codec struct{}
+
+func (codec) Marshal(v interface{}) ([]byte, error)   { return json.Marshal(v) }
+func (codec) Unmarshal(d []byte, v interface{}) error { return json.Unmarshal(d, v) }
+func (codec) Name() string                            { return codecName }
+
+func init() {
+	encoding.RegisterCodec(codec{})
+}
+
+// Server is the echo service.
+type Server struct {
+	// Pod is the name of pod to set in response messages.
+	Pod string
+}
+
+// Echo returns the request message.
+func (s *Server) Echo(ctx context.Context, req *Message) (*Message, error) {
+	return &Message{Payload: req.Payload, Pod: s.Pod}, nil
+}
+
+// Stream echoes every received message until the client closes the stream.
+func (s *Server) Stream(stream grpc.ServerStream) error {
+	for {
+		req := new(Message)
+		if err := stream.RecvMsg(req); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if err := stream.SendMsg(&Message{Payload: req.Payload, Pod: s.Pod}); err != nil {
+			return err
+		}
+	}
+}
+
+type echoServer interface {
+	Echo(ctx context.Context, req *Message) (*Message, error)
+	Stream(stream grpc.ServerStream) error
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*echoServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Echo",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+				req := new(Message)
+				if err := dec(req); err != nil {
+					return nil, err
+				}
+				if interceptor == nil {
+					return srv.(echoServer).Echo(ctx, req)
+				}
+				info := &grpc.UnaryServerInfo{Server: srv, FullMethod: MethodEcho}
+				return interceptor(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+					return srv.(echoServer).Echo(ctx, req.(*Message))
+				})
+			},
+		},
+	},
+	Streams: []grpc.StreamDesc{streamDesc},
+}
+
+var streamDesc = grpc.StreamDesc{
+	StreamName: "Stream",
+	Handler: func(srv interface{}, stream grpc.ServerStream) error {
+		return srv.(echoServer).Stream(stream)
+	},
+	ServerStreams: true,
+	ClientStreams: true,
+}
+
+// Register registers the echo service to the gRPC server.
+func Register(gs *grpc.Server, s *Server) {
+	gs.RegisterService(&serviceDesc, s)
+}
+
+// Dial connects to the echo service at the target ("host:port").
+// If tlsConfig is nil, the connection is not encrypted.
+func Dial(ctx context.Context, target string, tlsConfig *tls.Config, timeout time.Duration) (*grpc.ClientConn, error) {
+	opts := []grpc.DialOption{
+		grpc.WithBlock(),
+		grpc.WithDefaultCallOptions(grpc.CallContentSubtype(codecName)),
+	}
+	if tlsConfig != nil {
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+	} else {
+		opts = append(opts, grpc.WithInsecure())
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return grpc.DialContext(ctx, target, opts...)
+}
+
+// Echo sends the message, and returns the echoed message.
+func Echo(ctx context.Context, cc *grpc.ClientConn, req *Message) (*Message, error) {
+	resp := new(Message)
+	if err := cc.Invoke(ctx, MethodEcho, req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// NewStream opens the bidirectional echo stream.
+// Each message sent with "SendMsg" is echoed, to receive with "RecvMsg".
+func NewStream(ctx context.Context, cc *grpc.ClientConn) (grpc.ClientStream, error) {
+	return cc.NewStream(ctx, &streamDesc, MethodStream)
+}
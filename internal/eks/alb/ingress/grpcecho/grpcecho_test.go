@@ -0,0 +1,59 @@
+package grpcecho
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+func TestEcho(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gs := grpc.NewServer()
+	Register(gs, &Server{Pod: "test-pod"})
+	go gs.Serve(ln)
+	defer gs.Stop()
+
+	cc, err := Dial(context.Background(), ln.Addr().String(), nil, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cc.Close()
+
+	resp, err := Echo(context.Background(), cc, &Message{Payload: []byte("hello")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(resp.Payload) != "hello" || resp.Pod != "test-pod" {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := NewStream(ctx, cc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		d := bytes.Repeat([]byte{byte('a' + i)}, 100*(i+1))
+		if err = stream.SendMsg(&Message{Payload: d}); err != nil {
+			t.Fatal(err)
+		}
+		rm := new(Message)
+		if err = stream.RecvMsg(rm); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(rm.Payload, d) {
+			t.Fatalf("#%d: unexpected payload size %d", i, len(rm.Payload))
+		}
+	}
+	if err = stream.CloseSend(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -28,6 +28,9 @@ type ConfigIngressTestServerIngressSpec struct {
 
 	// GenTargetServiceRoutesN is the number of ingress rule paths to generate.
 	GenTargetServiceRoutesN int
+	// GenTargetServiceRoutesStart is the index of first generated path,
+	// to shard generated paths across multiple Ingress objects.
+	GenTargetServiceRoutesStart int
 	// GenTargetServiceName is the name of target backend service.
 	GenTargetServiceName string
 	// GenTargetServicePort is the target service port to the backend service.
@@ -59,7 +62,7 @@ func CreateIngressTestServerIngressSpec(cfg ConfigIngressTestServerIngressSpec)
 	if cfg.GenTargetServiceRoutesN > 0 {
 		for i := 0; i < cfg.GenTargetServiceRoutesN; i++ {
 			copied := sampleIngressPath
-			copied.Path = path.Create(i)
+			copied.Path = path.Create(cfg.GenTargetServiceRoutesStart + i)
 			copied.Backend.ServiceName = cfg.GenTargetServiceName
 			copied.Backend.ServicePort.IntVal = int32(cfg.GenTargetServicePort)
 			iss = append(iss, copied)
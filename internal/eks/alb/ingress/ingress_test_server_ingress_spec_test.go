@@ -87,4 +87,15 @@ func TestCreateIngressTestServerIngressSpec(t *testing.T) {
 		t.Fatalf("%q expected but not found", path.PathMetrics)
 	}
 	fmt.Println(d2)
+
+	cfg2.MetadataName = "ingress-for-ingress-test-server-service-1"
+	cfg2.IngressPaths = nil
+	cfg2.GenTargetServiceRoutesStart = 30
+	d3, err := CreateIngressTestServerIngressSpec(cfg2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(d3, path.Create(32)) || strings.Contains(d3, path.Create(0)) {
+		t.Fatalf("expected routes from %q, got %s", path.Create(30), d3)
+	}
 }
@@ -16,7 +16,7 @@ func TestIngress(t *testing.T) {
 	routesN := 3
 
 	// start server
-	mux, err := server.NewMux(context.Background(), zap.NewExample(), routesN, 10)
+	mux, err := server.NewMux(context.Background(), zap.NewExample(), routesN, 10, nil, server.NewHealth())
 	if err != nil {
 		t.Fatal(err)
 	}
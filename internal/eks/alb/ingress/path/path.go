@@ -7,6 +7,17 @@ const (
 	Path = "/ingress-test"
 	// PathMetrics serves ELB ingress workload metrics.
 	PathMetrics = "/ingress-test-metrics"
+	// PathEcho returns the request received by ingress test server in JSON,
+	// to inspect headers added by the load balancer.
+	PathEcho = "/ingress-test-echo"
+	// PathWebSocket echoes WebSocket messages.
+	PathWebSocket = "/ingress-test-websocket"
+	// PathReady is the readiness probe, which fails while draining.
+	// "POST" with "state=pass" or "state=fail" toggles the readiness.
+	PathReady = "/ingress-test-ready"
+	// PathLive is the liveness probe.
+	// "POST" with "state=pass" or "state=fail" toggles the liveness.
+	PathLive = "/ingress-test-live"
 )
 
 // Create creates a path with index.
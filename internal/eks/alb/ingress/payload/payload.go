@@ -0,0 +1,96 @@
+// Package payload generates deterministic pseudo-random response payloads
+// of ingress test server, and verifies them in ingress test client,
+// to detect truncated or corrupted responses through the load balancer.
+package payload
+
+import (
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"math/rand"
+	"net/http"
+	"strconv"
+)
+
+const (
+	// HeaderSeed is the response header of the seed that generated the payload.
+	HeaderSeed = "X-Ingress-Test-Payload-Seed"
+	// HeaderLength is the response header of the payload length in bytes.
+	HeaderLength = "X-Ingress-Test-Payload-Length"
+	// HeaderChecksum is the response header of the payload CRC-32C checksum in hex.
+	HeaderChecksum = "X-Ingress-Test-Payload-Checksum"
+)
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// ErrMissingChecksum is returned by VerifyStrict when the response
+// has no checksum header.
+var ErrMissingChecksum = errors.New("missing " + HeaderChecksum + " header")
+
+// Generate returns the pseudo-random payload of the size.
+// The same seed and size always generate the same payload.
+func Generate(seed int64, size int) []byte {
+	d := make([]byte, size)
+	rand.New(rand.NewSource(seed)).Read(d)
+	return d
+}
+
+// Checksum returns the CRC-32C checksum of the payload in hex.
+func Checksum(d []byte) string {
+	return fmt.Sprintf("%08x", crc32.Checksum(d, castagnoli))
+}
+
+// SetHeader sets the seed, length and checksum headers of the payload.
+func SetHeader(h http.Header, seed int64, d []byte) {
+	h.Set(HeaderSeed, strconv.FormatInt(seed, 10))
+	h.Set(HeaderLength, strconv.Itoa(len(d)))
+	h.Set(HeaderChecksum, Checksum(d))
+}
+
+// Error is returned when the received payload does not match
+// its length or checksum header.
+type Error struct {
+	Seed             string
+	ExpectedLength   int
+	Length           int
+	ExpectedChecksum string
+	Checksum         string
+}
+
+func (e *Error) Error() string {
+	if e.Length != e.ExpectedLength {
+		return fmt.Sprintf("payload (seed %s) length %d != expected %d", e.Seed, e.Length, e.ExpectedLength)
+	}
+	return fmt.Sprintf("payload (seed %s) checksum %q != expected %q", e.Seed, e.Checksum, e.ExpectedChecksum)
+}
+
+// Verify verifies the received payload against its length and checksum
+// headers. It returns nil if the response has no checksum header
+// (e.g. not from ingress test server), or an *Error on mismatch.
+func Verify(h http.Header, d []byte) error {
+	exp := h.Get(HeaderChecksum)
+	if exp == "" {
+		return nil
+	}
+	n, err := strconv.Atoi(h.Get(HeaderLength))
+	if err != nil {
+		return fmt.Errorf("invalid %s header %q (%v)", HeaderLength, h.Get(HeaderLength), err)
+	}
+	e := &Error{Seed: h.Get(HeaderSeed), ExpectedLength: n, Length: len(d), ExpectedChecksum: exp}
+	if e.Length != e.ExpectedLength {
+		return e
+	}
+	if e.Checksum = Checksum(d); e.Checksum != e.ExpectedChecksum {
+		return e
+	}
+	return nil
+}
+
+// VerifyStrict is Verify, but also returns an error if the response has
+// no checksum header (e.g. an error page from the load balancer).
+func VerifyStrict(h http.Header, d []byte) error {
+	if h.Get(HeaderChecksum) == "" {
+		return ErrMissingChecksum
+	}
+	return Verify(h, d)
+}
@@ -0,0 +1,60 @@
+package payload
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	d1, d2 := Generate(1, 100), Generate(1, 100)
+	if !bytes.Equal(d1, d2) {
+		t.Fatal("expected same payload from same seed")
+	}
+	if bytes.Equal(d1, Generate(2, 100)) {
+		t.Fatal("expected different payload from different seed")
+	}
+	if len(Generate(1, 0)) != 0 {
+		t.Fatal("expected empty payload")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	d := Generate(7, 1024)
+	h := make(http.Header)
+	SetHeader(h, 7, d)
+	if h.Get(HeaderSeed) != "7" || h.Get(HeaderLength) != "1024" || len(h.Get(HeaderChecksum)) != 8 {
+		t.Fatalf("unexpected header %v", h)
+	}
+	if err := Verify(h, d); err != nil {
+		t.Fatal(err)
+	}
+
+	// truncated
+	err := Verify(h, d[:1000])
+	if pe, ok := err.(*Error); !ok || pe.Length != 1000 || pe.ExpectedLength != 1024 {
+		t.Fatalf("expected length mismatch, got %v", err)
+	}
+
+	// corrupted
+	c := append([]byte(nil), d...)
+	c[512] ^= 0xff
+	err = Verify(h, c)
+	if pe, ok := err.(*Error); !ok || pe.Checksum == pe.ExpectedChecksum {
+		t.Fatalf("expected checksum mismatch, got %v", err)
+	}
+
+	// no checksum header
+	if err = Verify(make(http.Header), []byte("0")); err != nil {
+		t.Fatalf("expected no verification without checksum header, got %v", err)
+	}
+	if err = VerifyStrict(make(http.Header), []byte("0")); err != ErrMissingChecksum {
+		t.Fatalf("expected %v, got %v", ErrMissingChecksum, err)
+	}
+	if err = VerifyStrict(h, d); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := VerifyStrict(h, d[:1000]).(*Error); !ok {
+		t.Fatal("expected length mismatch")
+	}
+}
@@ -0,0 +1,157 @@
+package ingress
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	gyaml "github.com/ghodss/yaml"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// BarrierStartAtKey is the start barrier config map key
+	// of the start time in RFC3339 format.
+	BarrierStartAtKey = "start-at"
+	// barrierMountPath is the directory to mount the start barrier config map.
+	barrierMountPath = "/etc/ingress-client-barrier"
+)
+
+// ConfigPodIngressClient defines ingress client pods configuration.
+type ConfigPodIngressClient struct {
+	// Name is used for pod name prefix, pod label, and start barrier config map name.
+	Name string
+	// Namespace is the name space to deploy ingress client pods to.
+	Namespace string
+	// Image is the aws-k8s-tester docker image.
+	Image string
+	// Pods is the number of ingress client pods.
+	Pods int
+	// ClientArgs is the flags to "aws-k8s-tester eks ingress client".
+	ClientArgs []string
+}
+
+// PodIngressClientName returns the name of the ingress client pod.
+func PodIngressClientName(name string, idx int) string {
+	return fmt.Sprintf("%s-%d", name, idx)
+}
+
+// CreatePodIngressClient generates the start barrier config map and the
+// ingress client pods, which run "aws-k8s-tester eks ingress client"
+// once the start time is written to the config map.
+func CreatePodIngressClient(cfg ConfigPodIngressClient) (string, error) {
+	if cfg.Name == "" {
+		return "", errors.New("empty Name")
+	}
+	if cfg.Namespace == "" {
+		return "", errors.New("empty Namespace")
+	}
+	if cfg.Image == "" {
+		return "", errors.New("empty Image")
+	}
+	if cfg.Pods < 1 {
+		return "", fmt.Errorf("invalid Pods %d", cfg.Pods)
+	}
+
+	cm := v1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "ConfigMap",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cfg.Name,
+			Namespace: cfg.Namespace,
+		},
+		Data: map[string]string{
+			BarrierStartAtKey: "",
+		},
+	}
+	d, err := gyaml.Marshal(cm)
+	if err != nil {
+		return "", err
+	}
+	ss := []string{string(d)}
+
+	args := append([]string{
+		"aws-k8s-tester",
+		"eks",
+		"ingress",
+		"client",
+		"--barrier-path=" + filepath.Join(barrierMountPath, BarrierStartAtKey),
+		"--output=json",
+	}, cfg.ClientArgs...)
+	for i := 0; i < cfg.Pods; i++ {
+		pod := v1.Pod{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: "v1",
+				Kind:       "Pod",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      PodIngressClientName(cfg.Name, i),
+				Namespace: cfg.Namespace,
+				Labels: map[string]string{
+					"app": cfg.Name,
+				},
+			},
+			Spec: v1.PodSpec{
+				RestartPolicy: v1.RestartPolicyNever,
+				// spread across worker nodes, to send requests from multiple hosts
+				Affinity: &v1.Affinity{
+					PodAntiAffinity: &v1.PodAntiAffinity{
+						PreferredDuringSchedulingIgnoredDuringExecution: []v1.WeightedPodAffinityTerm{
+							{
+								Weight: 100,
+								PodAffinityTerm: v1.PodAffinityTerm{
+									LabelSelector: &metav1.LabelSelector{
+										MatchLabels: map[string]string{
+											"app": cfg.Name,
+										},
+									},
+									TopologyKey: "kubernetes.io/hostname",
+								},
+							},
+						},
+					},
+				},
+				Containers: []v1.Container{
+					{
+						Name:            cfg.Name,
+						Image:           cfg.Image,
+						ImagePullPolicy: v1.PullAlways,
+						Args:            args,
+						VolumeMounts: []v1.VolumeMount{
+							{
+								Name:      "barrier",
+								MountPath: barrierMountPath,
+								ReadOnly:  true,
+							},
+						},
+					},
+				},
+				Volumes: []v1.Volume{
+					{
+						Name: "barrier",
+						VolumeSource: v1.VolumeSource{
+							ConfigMap: &v1.ConfigMapVolumeSource{
+								LocalObjectReference: v1.LocalObjectReference{Name: cfg.Name},
+							},
+						},
+					},
+				},
+			},
+		}
+		d, err = gyaml.Marshal(pod)
+		if err != nil {
+			return "", err
+		}
+		ss = append(ss, string(d))
+	}
+
+	return fmt.Sprintf(`---
+%s
+
+
+`, strings.Join(ss, "\n---\n")), nil
+}
@@ -0,0 +1,38 @@
+package ingress
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCreatePodIngressClient(t *testing.T) {
+	d, err := CreatePodIngressClient(ConfigPodIngressClient{
+		Name:       "ingress-client",
+		Namespace:  "default",
+		Image:      "000000000000.dkr.ecr.us-west-2.amazonaws.com/aws-k8s-tester:latest",
+		Pods:       3,
+		ClientArgs: []string{"--endpoint=http://a", "--requests=100"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(d, "kind: Pod"); n != 3 {
+		t.Fatalf("expected 3 pods, got %d\n%s", n, d)
+	}
+	for _, s := range []string{
+		"kind: ConfigMap",
+		"start-at: \"\"",
+		"name: ingress-client-2",
+		"--barrier-path=/etc/ingress-client-barrier/start-at",
+		"--endpoint=http://a",
+		"restartPolicy: Never",
+	} {
+		if !strings.Contains(d, s) {
+			t.Fatalf("expected %q, got %s", s, d)
+		}
+	}
+
+	if _, err = CreatePodIngressClient(ConfigPodIngressClient{Name: "ingress-client", Namespace: "default", Image: "a"}); err == nil {
+		t.Fatal("expected error for no pods")
+	}
+}
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"net"
+	"net/http"
+)
+
+// Echo is the request received by ingress test server.
+type Echo struct {
+	// Pod is the name of pod that served the request.
+	Pod string `json:"pod"`
+	// Method is the request method.
+	Method string `json:"method"`
+	// URI is the request URI.
+	URI string `json:"uri"`
+	// Host is the request host.
+	Host string `json:"host"`
+	// Proto is the request protocol (e.g. "HTTP/1.1").
+	Proto string `json:"proto"`
+	// RemoteAddr is the network address that sent the request,
+	// which is the load balancer or the node when behind ALB.
+	RemoteAddr string `json:"remote-addr"`
+	// LocalAddr is the network address that received the request.
+	LocalAddr string `json:"local-addr"`
+	// Header is the request header.
+	Header http.Header `json:"header"`
+	// TLS is the TLS connection state, or nil
+	// if TLS is terminated before the server.
+	TLS *EchoTLS `json:"tls,omitempty"`
+
+	// ForwardedFor is the "X-Forwarded-For" header.
+	ForwardedFor string `json:"forwarded-for,omitempty"`
+	// ForwardedProto is the "X-Forwarded-Proto" header.
+	ForwardedProto string `json:"forwarded-proto,omitempty"`
+	// ForwardedPort is the "X-Forwarded-Port" header.
+	ForwardedPort string `json:"forwarded-port,omitempty"`
+	// TraceID is the "X-Amzn-Trace-Id" header, as in ALB access log "trace_id" field.
+	TraceID string `json:"trace-id,omitempty"`
+}
+
+// EchoTLS is the TLS connection state of the request.
+type EchoTLS struct {
+	Version     uint16 `json:"version"`
+	CipherSuite uint16 `json:"cipher-suite"`
+	ServerName  string `json:"server-name,omitempty"`
+}
+
+// NewEcho returns the request in echo format.
+func NewEcho(req *http.Request) Echo {
+	ev := Echo{
+		Pod:            podName,
+		Method:         req.Method,
+		URI:            req.RequestURI,
+		Host:           req.Host,
+		Proto:          req.Proto,
+		RemoteAddr:     req.RemoteAddr,
+		Header:         req.Header,
+		ForwardedFor:   req.Header.Get("X-Forwarded-For"),
+		ForwardedProto: req.Header.Get("X-Forwarded-Proto"),
+		ForwardedPort:  req.Header.Get("X-Forwarded-Port"),
+		TraceID:        req.Header.Get("X-Amzn-Trace-Id"),
+	}
+	if addr, ok := req.Context().Value(http.LocalAddrContextKey).(net.Addr); ok {
+		ev.LocalAddr = addr.String()
+	}
+	if req.TLS != nil {
+		ev.TLS = newEchoTLS(req.TLS)
+	}
+	return ev
+}
+
+func newEchoTLS(st *tls.ConnectionState) *EchoTLS {
+	return &EchoTLS{
+		Version:     st.Version,
+		CipherSuite: st.CipherSuite,
+		ServerName:  st.ServerName,
+	}
+}
+
+// EchoHandler returns the received request in JSON.
+func EchoHandler(ctx context.Context, w http.ResponseWriter, req *http.Request) error {
+	promRecv.WithLabelValues(req.RemoteAddr, req.Method, req.RequestURI).Inc()
+	d, err := json.Marshal(NewEcho(req))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return err
+	}
+	w.Header().Set(HeaderPodName, podName)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(d)
+	return err
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress/path"
+	"go.uber.org/zap"
+)
+
+func TestEchoHandler(t *testing.T) {
+	mux, err := NewMux(context.Background(), zap.NewExample(), 1, 10, nil, NewHealth())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	req, err := http.NewRequest(http.MethodGet, ts.URL+path.PathEcho+"?a=b", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("X-Forwarded-For", "1.2.3.4")
+	req.Header.Set("X-Forwarded-Proto", "https")
+	req.Header.Set("X-Forwarded-Port", "443")
+	req.Header.Set("X-Amzn-Trace-Id", "Root=1-5759e988-bd862e3fe1be46a994272793")
+	rs, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rs.Body.Close()
+
+	var ev Echo
+	if err = json.NewDecoder(rs.Body).Decode(&ev); err != nil {
+		t.Fatal(err)
+	}
+	if ev.Method != http.MethodGet || ev.URI != path.PathEcho+"?a=b" {
+		t.Fatalf("unexpected request %q %q", ev.Method, ev.URI)
+	}
+	if ev.ForwardedFor != "1.2.3.4" || ev.ForwardedProto != "https" || ev.ForwardedPort != "443" {
+		t.Fatalf("unexpected forwarded headers %+v", ev)
+	}
+	if ev.TraceID != "Root=1-5759e988-bd862e3fe1be46a994272793" {
+		t.Fatalf("unexpected trace ID %q", ev.TraceID)
+	}
+	if ev.RemoteAddr == "" || ev.LocalAddr != ts.Listener.Addr().String() {
+		t.Fatalf("unexpected addresses %q, %q", ev.RemoteAddr, ev.LocalAddr)
+	}
+	if ev.TLS != nil {
+		t.Fatalf("unexpected TLS state %+v", ev.TLS)
+	}
+	if ev.Header.Get("X-Forwarded-Port") != "443" {
+		t.Fatalf("unexpected header %v", ev.Header)
+	}
+
+	tts := httptest.NewTLSServer(mux)
+	defer tts.Close()
+	rs, err = tts.Client().Get(tts.URL + path.PathEcho)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rs.Body.Close()
+	ev = Echo{}
+	if err = json.NewDecoder(rs.Body).Decode(&ev); err != nil {
+		t.Fatal(err)
+	}
+	if ev.TLS == nil || ev.TLS.Version == 0 {
+		t.Fatalf("expected TLS state, got %+v", ev.TLS)
+	}
+}
@@ -0,0 +1,156 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+const (
+	// LatencyFixed is the latency distribution of always "LatencyMs".
+	LatencyFixed = "fixed"
+	// LatencyUniform is the latency distribution uniform
+	// between "LatencyMs" and "LatencyMaxMs".
+	LatencyUniform = "uniform"
+	// LatencyExponential is the exponential latency distribution
+	// with the mean "LatencyMs", capped at "LatencyMaxMs" if non-zero.
+	LatencyExponential = "exponential"
+
+	// FaultAllRoutes is the fault route that matches all generated routes.
+	FaultAllRoutes = "*"
+)
+
+// Fault is the fault-injection behavior of routes.
+// Faults are applied in order: latency, connection reset, error status
+// code, and then the response with varied size and streaming rate.
+type Fault struct {
+	// Route is the route path (e.g. "/ingress-test-00000"),
+	// or "*" for all generated routes.
+	Route string `json:"route"`
+
+	// LatencyDistribution is "fixed", "uniform" or "exponential".
+	// Defaults to "fixed".
+	LatencyDistribution string `json:"latency-distribution,omitempty"`
+	// LatencyMs is the latency in milliseconds to add before the response,
+	// or the mean of the exponential distribution.
+	LatencyMs int `json:"latency-ms,omitempty"`
+	// LatencyMaxMs is the upper bound of the uniform distribution,
+	// or the cap of the exponential distribution.
+	LatencyMaxMs int `json:"latency-max-ms,omitempty"`
+
+	// ErrorRate is the ratio of requests (0 to 1) to respond with "ErrorStatusCode".
+	ErrorRate float64 `json:"error-rate,omitempty"`
+	// ErrorStatusCode is the error status code. Defaults to 503.
+	ErrorStatusCode int `json:"error-status-code,omitempty"`
+
+	// ResetRate is the ratio of requests (0 to 1) to close the connection
+	// abruptly without a response.
+	ResetRate float64 `json:"reset-rate,omitempty"`
+
+	// SlowBytesPerSecond is the rate to stream the response body.
+	// If zero, the response body is written at once.
+	SlowBytesPerSecond int `json:"slow-bytes-per-second,omitempty"`
+
+	// ResponseSizeMin and ResponseSizeMax are the range of response size,
+	// chosen uniformly per request. If zero, the response size is fixed.
+	ResponseSizeMin int `json:"response-size-min,omitempty"`
+	ResponseSizeMax int `json:"response-size-max,omitempty"`
+}
+
+// Validate returns an error if the fault is invalid.
+func (f Fault) Validate() error {
+	if f.Route == "" {
+		return fmt.Errorf("empty fault route")
+	}
+	if f.Route != FaultAllRoutes && !strings.HasPrefix(f.Route, "/") {
+		return fmt.Errorf("invalid fault route %q", f.Route)
+	}
+	switch f.LatencyDistribution {
+	case "", LatencyFixed:
+	case LatencyUniform:
+		if f.LatencyMaxMs < f.LatencyMs {
+			return fmt.Errorf("uniform latency max %dms < min %dms", f.LatencyMaxMs, f.LatencyMs)
+		}
+	case LatencyExponential:
+		if f.LatencyMs == 0 {
+			return fmt.Errorf("exponential latency requires mean latency")
+		}
+	default:
+		return fmt.Errorf("unknown latency distribution %q", f.LatencyDistribution)
+	}
+	if f.LatencyMs < 0 || f.LatencyMaxMs < 0 {
+		return fmt.Errorf("invalid latency %dms, max %dms", f.LatencyMs, f.LatencyMaxMs)
+	}
+	if f.ErrorRate < 0 || f.ErrorRate > 1 {
+		return fmt.Errorf("invalid error rate %f", f.ErrorRate)
+	}
+	if f.ErrorStatusCode != 0 && (f.ErrorStatusCode < 400 || f.ErrorStatusCode > 599) {
+		return fmt.Errorf("invalid error status code %d", f.ErrorStatusCode)
+	}
+	if f.ResetRate < 0 || f.ResetRate > 1 {
+		return fmt.Errorf("invalid reset rate %f", f.ResetRate)
+	}
+	if f.SlowBytesPerSecond < 0 {
+		return fmt.Errorf("invalid slow bytes per second %d", f.SlowBytesPerSecond)
+	}
+	if f.ResponseSizeMin < 0 || f.ResponseSizeMax < f.ResponseSizeMin {
+		return fmt.Errorf("invalid response size range [%d, %d]", f.ResponseSizeMin, f.ResponseSizeMax)
+	}
+	return nil
+}
+
+// ParseFaults parses the faults in JSON array.
+func ParseFaults(d []byte) (fs []Fault, err error) {
+	if err = json.Unmarshal(d, &fs); err != nil {
+		return nil, err
+	}
+	for _, f := range fs {
+		if err = f.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return fs, nil
+}
+
+// ReadFaults reads the faults in JSON array from the file.
+func ReadFaults(p string) ([]Fault, error) {
+	d, err := ioutil.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+	return ParseFaults(d)
+}
+
+// latency returns the latency to inject.
+func (f Fault) latency() time.Duration {
+	ms := float64(f.LatencyMs)
+	switch f.LatencyDistribution {
+	case LatencyUniform:
+		ms += rand.Float64() * float64(f.LatencyMaxMs-f.LatencyMs)
+	case LatencyExponential:
+		ms = rand.ExpFloat64() * ms
+		if f.LatencyMaxMs > 0 && ms > float64(f.LatencyMaxMs) {
+			ms = float64(f.LatencyMaxMs)
+		}
+	}
+	return time.Duration(ms * float64(time.Millisecond))
+}
+
+// responseSize returns the response size, or -1 to use the default size.
+func (f Fault) responseSize() int {
+	if f.ResponseSizeMax == 0 {
+		return -1
+	}
+	return f.ResponseSizeMin + rand.Intn(f.ResponseSizeMax-f.ResponseSizeMin+1)
+}
+
+// errorStatusCode returns the error status code.
+func (f Fault) errorStatusCode() int {
+	if f.ErrorStatusCode == 0 {
+		return 503
+	}
+	return f.ErrorStatusCode
+}
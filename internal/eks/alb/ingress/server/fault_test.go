@@ -0,0 +1,133 @@
+package server
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress/path"
+	"go.uber.org/zap"
+)
+
+func TestParseFaults(t *testing.T) {
+	fs, err := ParseFaults([]byte(`[{"route":"*","latency-ms":10},{"route":"/ingress-test-00000","error-rate":0.5,"error-status-code":500}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fs) != 2 {
+		t.Fatalf("expected 2 faults, got %+v", fs)
+	}
+	if fs[0].Route != FaultAllRoutes || fs[0].LatencyMs != 10 {
+		t.Fatalf("unexpected fault %+v", fs[0])
+	}
+	if fs[1].ErrorRate != 0.5 || fs[1].ErrorStatusCode != 500 {
+		t.Fatalf("unexpected fault %+v", fs[1])
+	}
+
+	for i, f := range []Fault{
+		{},
+		{Route: "ingress-test-00000"},
+		{Route: "*", LatencyDistribution: "normal"},
+		{Route: "*", LatencyDistribution: LatencyUniform, LatencyMs: 10, LatencyMaxMs: 5},
+		{Route: "*", LatencyDistribution: LatencyExponential},
+		{Route: "*", ErrorRate: 1.5},
+		{Route: "*", ErrorStatusCode: 200},
+		{Route: "*", ResetRate: -1},
+		{Route: "*", ResponseSizeMin: 10, ResponseSizeMax: 5},
+	} {
+		if err = f.Validate(); err == nil {
+			t.Fatalf("#%d: expected error for %+v", i, f)
+		}
+	}
+}
+
+func TestFaultLatency(t *testing.T) {
+	f := Fault{Route: "*", LatencyDistribution: LatencyUniform, LatencyMs: 10, LatencyMaxMs: 20}
+	for i := 0; i < 100; i++ {
+		d := f.latency()
+		if d < 10*time.Millisecond || d > 20*time.Millisecond {
+			t.Fatalf("latency %v out of range", d)
+		}
+	}
+	f = Fault{Route: "*", LatencyDistribution: LatencyExponential, LatencyMs: 10, LatencyMaxMs: 30}
+	for i := 0; i < 100; i++ {
+		if d := f.latency(); d > 30*time.Millisecond {
+			t.Fatalf("latency %v over cap", d)
+		}
+	}
+}
+
+func TestNewMuxFaults(t *testing.T) {
+	if _, err := NewMux(context.Background(), zap.NewExample(), 1, 10, []Fault{{Route: "/not-found"}}, NewHealth()); err == nil {
+		t.Fatal("expected error for unknown fault route")
+	}
+
+	mux, err := NewMux(context.Background(), zap.NewExample(), 4, 10, []Fault{
+		{Route: FaultAllRoutes, LatencyMs: 100},
+		{Route: path.Create(1), ErrorRate: 1},
+		{Route: path.Create(2), ResetRate: 1},
+		{Route: path.Create(3), SlowBytesPerSecond: 100, ResponseSizeMin: 20, ResponseSizeMax: 30},
+	}, NewHealth())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	// fault on all routes does not apply to the correctness check path
+	start := time.Now()
+	rs, err := http.Get(ts.URL + path.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs.Body.Close()
+	if took := time.Since(start); took >= 100*time.Millisecond {
+		t.Fatalf("unexpected latency %v on %q", took, path.Path)
+	}
+
+	start = time.Now()
+	rs, err = http.Get(ts.URL + path.Create(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs.Body.Close()
+	if took := time.Since(start); took < 100*time.Millisecond {
+		t.Fatalf("expected latency >=100ms, got %v", took)
+	}
+
+	// route-specific fault overrides fault on all routes
+	rs, err = http.Get(ts.URL + path.Create(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs.Body.Close()
+	if rs.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected status %d, got %d", http.StatusServiceUnavailable, rs.StatusCode)
+	}
+
+	if rs, err = http.Get(ts.URL + path.Create(2)); err == nil {
+		rs.Body.Close()
+		t.Fatalf("expected connection reset, got %q", rs.Status)
+	}
+
+	start = time.Now()
+	rs, err = http.Get(ts.URL + path.Create(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := ioutil.ReadAll(rs.Body)
+	rs.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d) < 20 || len(d) > 30 {
+		t.Fatalf("expected response size in [20, 30], got %d", len(d))
+	}
+	// 20 bytes at 100 bytes per second takes at least 100ms
+	if took := time.Since(start); took < 100*time.Millisecond {
+		t.Fatalf("expected slow response, got %v", took)
+	}
+}
@@ -1,62 +1,122 @@
 package server
 
 import (
-	"bytes"
 	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net"
 	"net/http"
+	"os"
 	"time"
 
 	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress/path"
+	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress/payload"
 	"github.com/aws/aws-k8s-tester/pkg/ctxhandler"
 
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.uber.org/zap"
 )
 
-// responseBody is the default response body.
-var responseBody = bytes.Repeat([]byte("0"), 10)
+// responseSize is the default response payload size.
+var responseSize = 10
+
+// HeaderPodName is the response header that identifies
+// the pod that served the request.
+const HeaderPodName = "X-Ingress-Test-Server-Pod"
+
+// podName is the name of pod that runs this server.
+// Kubernetes sets the hostname of a pod to its name.
+var podName, _ = os.Hostname()
 
 // NewMux returns a new HTTP request multiplexer with registered handlers.
-func NewMux(ctx context.Context, lg *zap.Logger, routesN, responseN int) (*http.ServeMux, error) {
-	responseBody = bytes.Repeat([]byte("0"), responseN)
+// Routes with faults are served by "FaultHandler".
+// Readiness and liveness probes are served from the health state.
+func NewMux(ctx context.Context, lg *zap.Logger, routesN, responseN int, faults []Fault, health *Health) (*http.ServeMux, error) {
+	responseSize = responseN
+
+	handlers := make(map[string]ctxhandler.ContextHandlerFunc, routesN+1)
+	handlers[path.Path] = Handler
+	for i := 0; i < routesN; i++ {
+		handlers[path.Create(i)] = Handler
+	}
+	// route-specific faults take precedence over faults on all routes
+	for _, all := range []bool{true, false} {
+		for _, f := range faults {
+			if err := f.Validate(); err != nil {
+				return nil, err
+			}
+			if (f.Route == FaultAllRoutes) != all {
+				continue
+			}
+			if all {
+				for i := 0; i < routesN; i++ {
+					handlers[path.Create(i)] = FaultHandler(f)
+				}
+				lg.Info("registered fault", zap.String("path", f.Route), zap.Any("fault", f))
+				continue
+			}
+			if _, ok := handlers[f.Route]; !ok {
+				return nil, fmt.Errorf("fault route %q not found", f.Route)
+			}
+			handlers[f.Route] = FaultHandler(f)
+			lg.Info("registered fault", zap.String("path", f.Route), zap.Any("fault", f))
+		}
+	}
 
 	mux := http.NewServeMux()
-	mux.Handle(path.Path, &ctxhandler.ContextAdapter{
+	mux.Handle(path.PathMetrics, promhttp.Handler())
+	mux.Handle(path.PathEcho, &ctxhandler.ContextAdapter{
 		Logger:  lg,
 		Ctx:     ctx,
-		Handler: ctxhandler.ContextHandlerFunc(Handler),
+		Handler: ctxhandler.ContextHandlerFunc(EchoHandler),
 	})
-	mux.Handle(path.PathMetrics, promhttp.Handler())
-
-	if routesN > 0 {
-		handlers := make(map[string]ctxhandler.ContextHandlerFunc, routesN)
-		for i := 0; i < routesN; i++ {
-			handlers[path.Create(i)] = Handler
-		}
-		for p, h := range handlers {
-			mux.Handle(p, &ctxhandler.ContextAdapter{
-				Logger:  lg,
-				Ctx:     ctx,
-				Handler: h,
-			})
-			lg.Info("registered handler", zap.String("path", p))
-		}
-		lg.Info("finished handler registration", zap.Int("handlers", routesN))
+	mux.Handle(path.PathWebSocket, &ctxhandler.ContextAdapter{
+		Logger:  lg,
+		Ctx:     ctx,
+		Handler: ctxhandler.ContextHandlerFunc(WebSocketHandler),
+	})
+	mux.Handle(path.PathReady, &ctxhandler.ContextAdapter{
+		Logger:  lg,
+		Ctx:     ctx,
+		Handler: ctxhandler.ContextHandlerFunc(health.ReadyHandler),
+	})
+	mux.Handle(path.PathLive, &ctxhandler.ContextAdapter{
+		Logger:  lg,
+		Ctx:     ctx,
+		Handler: ctxhandler.ContextHandlerFunc(health.LiveHandler),
+	})
+	for p, h := range handlers {
+		mux.Handle(p, &ctxhandler.ContextAdapter{
+			Logger:  lg,
+			Ctx:     ctx,
+			Handler: h,
+		})
+		lg.Info("registered handler", zap.String("path", p))
 	}
+	lg.Info("finished handler registration", zap.Int("handlers", len(handlers)), zap.Int("faults", len(faults)))
 	return mux, nil
 }
 
 // Handler handles ingress traffic.
+// Request body of "POST" and "PUT" is read and discarded.
 func Handler(ctx context.Context, w http.ResponseWriter, req *http.Request) (err error) {
 	switch req.Method {
-	case http.MethodGet:
+	case http.MethodGet, http.MethodPost, http.MethodPut:
 		start := time.Now().UTC()
+		if _, err = io.Copy(ioutil.Discard, req.Body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return err
+		}
 
 		// From, Method, Path
 		promRecv.WithLabelValues(req.RemoteAddr, req.Method, req.RequestURI).Inc()
 
+		w.Header().Set(HeaderPodName, podName)
+		body := writePayloadHeader(w, responseSize)
 		w.WriteHeader(http.StatusOK)
-		_, err = w.Write(responseBody)
+		_, err = w.Write(body)
 
 		// Path, To
 		promSent.WithLabelValues(req.RequestURI, req.RemoteAddr).Observe(time.Now().UTC().Sub(start).Seconds())
@@ -66,3 +126,120 @@ func Handler(ctx context.Context, w http.ResponseWriter, req *http.Request) (err
 	}
 	return err
 }
+
+// FaultHandler returns the handler that injects the fault
+// while serving the ingress traffic.
+func FaultHandler(f Fault) ctxhandler.ContextHandlerFunc {
+	return func(ctx context.Context, w http.ResponseWriter, req *http.Request) (err error) {
+		switch req.Method {
+		case http.MethodGet, http.MethodPost, http.MethodPut:
+		default:
+			return Handler(ctx, w, req)
+		}
+		start := time.Now().UTC()
+		if _, err = io.Copy(ioutil.Discard, req.Body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return err
+		}
+		promRecv.WithLabelValues(req.RemoteAddr, req.Method, req.RequestURI).Inc()
+
+		if d := f.latency(); d > 0 {
+			promFault.WithLabelValues(req.URL.Path, "latency").Inc()
+			select {
+			case <-req.Context().Done():
+				return req.Context().Err()
+			case <-time.After(d):
+			}
+		}
+
+		if f.ResetRate > 0 && rand.Float64() < f.ResetRate {
+			promFault.WithLabelValues(req.URL.Path, "reset").Inc()
+			return resetConnection(w)
+		}
+
+		w.Header().Set(HeaderPodName, podName)
+		if f.ErrorRate > 0 && rand.Float64() < f.ErrorRate {
+			promFault.WithLabelValues(req.URL.Path, "error").Inc()
+			code := f.errorStatusCode()
+			http.Error(w, http.StatusText(code), code)
+			return nil
+		}
+
+		size := responseSize
+		if n := f.responseSize(); n >= 0 {
+			size = n
+		}
+		body := writePayloadHeader(w, size)
+		w.WriteHeader(http.StatusOK)
+		if f.SlowBytesPerSecond > 0 {
+			promFault.WithLabelValues(req.URL.Path, "slow").Inc()
+			err = writeSlow(req.Context(), w, body, f.SlowBytesPerSecond)
+		} else {
+			_, err = w.Write(body)
+		}
+
+		promSent.WithLabelValues(req.RequestURI, req.RemoteAddr).Observe(time.Now().UTC().Sub(start).Seconds())
+		return err
+	}
+}
+
+// writePayloadHeader generates the pseudo-random payload of the size
+// with a new seed, and sets its integrity headers to the response.
+func writePayloadHeader(w http.ResponseWriter, size int) []byte {
+	seed := rand.Int63()
+	body := payload.Generate(seed, size)
+	payload.SetHeader(w.Header(), seed, body)
+	return body
+}
+
+// slowInterval is the interval to write chunks of slow response.
+const slowInterval = 100 * time.Millisecond
+
+// writeSlow writes the body in chunks at the bytes per second rate.
+func writeSlow(ctx context.Context, w http.ResponseWriter, body []byte, bytesPerSecond int) error {
+	chunk := bytesPerSecond * int(slowInterval) / int(time.Second)
+	if chunk < 1 {
+		chunk = 1
+	}
+	flusher, _ := w.(http.Flusher)
+	for len(body) > 0 {
+		n := chunk
+		if n > len(body) {
+			n = len(body)
+		}
+		if _, err := w.Write(body[:n]); err != nil {
+			return err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		body = body[n:]
+		if len(body) == 0 {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(n) * time.Second / time.Duration(bytesPerSecond)):
+		}
+	}
+	return nil
+}
+
+// resetConnection closes the client connection abruptly,
+// with TCP RST if possible, instead of sending a response.
+func resetConnection(w http.ResponseWriter) error {
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		// e.g. HTTP/2 stream
+		panic(http.ErrAbortHandler)
+	}
+	conn, _, err := hj.Hijack()
+	if err != nil {
+		return err
+	}
+	if tc, ok := conn.(*net.TCPConn); ok {
+		tc.SetLinger(0)
+	}
+	return conn.Close()
+}
@@ -6,15 +6,18 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"strconv"
 	"strings"
 	"testing"
 
 	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress/path"
+	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress/payload"
 	"go.uber.org/zap"
 )
 
 func TestNewMux(t *testing.T) {
-	mux, err := NewMux(context.Background(), zap.NewExample(), 1, 10)
+	mux, err := NewMux(context.Background(), zap.NewExample(), 1, 10, nil, NewHealth())
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -33,8 +36,31 @@ func TestNewMux(t *testing.T) {
 	if err = rs.Body.Close(); err != nil {
 		t.Fatal(err)
 	}
-	if !bytes.Equal(d, []byte("0000000000")) {
-		t.Fatalf("expected %q, got %q", "0000000000", string(d))
+	if len(d) != 10 {
+		t.Fatalf("expected 10-byte payload, got %d bytes", len(d))
+	}
+	if err = payload.Verify(rs.Header, d); err != nil {
+		t.Fatal(err)
+	}
+	seed, err := strconv.ParseInt(rs.Header.Get(payload.HeaderSeed), 10, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(d, payload.Generate(seed, 10)) {
+		t.Fatalf("expected payload generated from seed %d", seed)
+	}
+	if h, _ := os.Hostname(); rs.Header.Get(HeaderPodName) != h {
+		t.Fatalf("expected %s %q, got %q", HeaderPodName, h, rs.Header.Get(HeaderPodName))
+	}
+
+	// send request with body
+	rs, err = http.Post(ts.URL+path.Create(0), "text/plain", strings.NewReader("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs.Body.Close()
+	if rs.StatusCode != http.StatusOK {
+		t.Fatalf("expected POST status %d, got %d", http.StatusOK, rs.StatusCode)
 	}
 
 	// check response metrics
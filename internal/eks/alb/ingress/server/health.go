@@ -0,0 +1,146 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Health is the readiness and liveness state of ingress test server.
+// Readiness fails while draining, so that the pod is removed from
+// service endpoints while in-flight requests are still being served.
+type Health struct {
+	ready    int32
+	live     int32
+	draining int32
+
+	inflight int64
+	// drained is the number of requests received while draining.
+	drained int64
+}
+
+// NewHealth returns a new Health that is ready and live.
+func NewHealth() *Health {
+	return &Health{ready: 1, live: 1}
+}
+
+// SetReady toggles the readiness.
+func (h *Health) SetReady(ready bool) { atomic.StoreInt32(&h.ready, boolToInt32(ready)) }
+
+// SetLive toggles the liveness.
+func (h *Health) SetLive(live bool) { atomic.StoreInt32(&h.live, boolToInt32(live)) }
+
+// Ready returns true if ready and not draining.
+func (h *Health) Ready() bool {
+	return atomic.LoadInt32(&h.ready) == 1 && !h.Draining()
+}
+
+// Live returns true if live.
+func (h *Health) Live() bool { return atomic.LoadInt32(&h.live) == 1 }
+
+// Draining returns true once "Drain" is called.
+func (h *Health) Draining() bool { return atomic.LoadInt32(&h.draining) == 1 }
+
+// Inflight returns the number of requests being served.
+func (h *Health) Inflight() int64 { return atomic.LoadInt64(&h.inflight) }
+
+// Drained returns the number of requests received while draining.
+func (h *Health) Drained() int64 { return atomic.LoadInt64(&h.drained) }
+
+func boolToInt32(b bool) int32 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// Track wraps the handler to count in-flight requests,
+// and requests received while draining.
+func (h *Health) Track(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if h.Draining() {
+			atomic.AddInt64(&h.drained, 1)
+		}
+		promInflight.Inc()
+		atomic.AddInt64(&h.inflight, 1)
+		defer func() {
+			atomic.AddInt64(&h.inflight, -1)
+			promInflight.Dec()
+		}()
+		next.ServeHTTP(w, req)
+	})
+}
+
+// ReadyHandler serves the readiness probe.
+func (h *Health) ReadyHandler(ctx context.Context, w http.ResponseWriter, req *http.Request) error {
+	return probeHandler(w, req, h.Ready, h.SetReady)
+}
+
+// LiveHandler serves the liveness probe.
+func (h *Health) LiveHandler(ctx context.Context, w http.ResponseWriter, req *http.Request) error {
+	return probeHandler(w, req, h.Live, h.SetLive)
+}
+
+// probeHandler returns 200 if the probe passes, or 503 if it fails.
+// "POST" and "PUT" with form value "state" ("pass" or "fail") toggle the state.
+func probeHandler(w http.ResponseWriter, req *http.Request, get func() bool, set func(bool)) error {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead:
+	case http.MethodPost, http.MethodPut:
+		switch state := req.FormValue("state"); state {
+		case "pass":
+			set(true)
+		case "fail":
+			set(false)
+		default:
+			http.Error(w, fmt.Sprintf("unknown state %q (expected 'pass' or 'fail')", state), http.StatusBadRequest)
+			return nil
+		}
+	default:
+		http.Error(w, "Method Not Allowed", 405)
+		return nil
+	}
+	w.Header().Set(HeaderPodName, podName)
+	if !get() {
+		http.Error(w, "fail", http.StatusServiceUnavailable)
+		return nil
+	}
+	_, err := w.Write([]byte("pass"))
+	return err
+}
+
+// Drain fails the readiness and keeps serving requests for the period,
+// so that the load balancer deregisters the pod before the server stops
+// accepting connections. Then it shuts down the server, waiting up to
+// the timeout for in-flight requests to complete. Drain returns early
+// when stopc is closed (e.g. on a second signal).
+func Drain(lg *zap.Logger, srv *http.Server, h *Health, period, timeout time.Duration, stopc <-chan struct{}) error {
+	atomic.StoreInt32(&h.draining, 1)
+	lg.Info("draining server", zap.Duration("drain-period", period), zap.Int64("inflight", h.Inflight()))
+	if period > 0 {
+		select {
+		case <-stopc:
+			lg.Warn("drain interrupted")
+		case <-time.After(period):
+		}
+	}
+
+	lg.Info("shutting down server",
+		zap.Int64("inflight", h.Inflight()),
+		zap.Int64("received-while-draining", h.Drained()),
+		zap.Duration("shutdown-timeout", timeout),
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	err := srv.Shutdown(ctx)
+	cancel()
+	lg.Info("drained server",
+		zap.Int64("inflight", h.Inflight()),
+		zap.Int64("received-while-draining", h.Drained()),
+		zap.Error(err),
+	)
+	return err
+}
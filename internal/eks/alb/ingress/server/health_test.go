@@ -0,0 +1,131 @@
+package server
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress/path"
+	"go.uber.org/zap"
+)
+
+func TestHealthToggle(t *testing.T) {
+	h := NewHealth()
+	mux, err := NewMux(context.Background(), zap.NewExample(), 1, 10, nil, h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	expectStatus := func(p string, code int) {
+		t.Helper()
+		rs, err := http.Get(ts.URL + p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rs.Body.Close()
+		if rs.StatusCode != code {
+			t.Fatalf("%s: expected status %d, got %d", p, code, rs.StatusCode)
+		}
+	}
+	toggle := func(p, state string, code int) {
+		t.Helper()
+		rs, err := http.PostForm(ts.URL+p, url.Values{"state": []string{state}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		rs.Body.Close()
+		if rs.StatusCode != code {
+			t.Fatalf("%s %s: expected status %d, got %d", p, state, code, rs.StatusCode)
+		}
+	}
+
+	expectStatus(path.PathReady, http.StatusOK)
+	expectStatus(path.PathLive, http.StatusOK)
+
+	toggle(path.PathReady, "fail", http.StatusServiceUnavailable)
+	expectStatus(path.PathReady, http.StatusServiceUnavailable)
+	expectStatus(path.PathLive, http.StatusOK)
+	toggle(path.PathReady, "pass", http.StatusOK)
+	expectStatus(path.PathReady, http.StatusOK)
+
+	toggle(path.PathLive, "fail", http.StatusServiceUnavailable)
+	expectStatus(path.PathLive, http.StatusServiceUnavailable)
+	if h.Live() {
+		t.Fatal("expected not live")
+	}
+	toggle(path.PathLive, "pass", http.StatusOK)
+	toggle(path.PathLive, "unknown", http.StatusBadRequest)
+}
+
+func TestDrain(t *testing.T) {
+	h := NewHealth()
+	mux, err := NewMux(context.Background(), zap.NewExample(), 1, 10, nil, h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// slow handler to keep a request in-flight during shutdown
+	startedc := make(chan struct{})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, req *http.Request) {
+		close(startedc)
+		time.Sleep(300 * time.Millisecond)
+		w.Write([]byte("done"))
+	})
+	ts := httptest.NewServer(h.Track(mux))
+	defer ts.Close()
+
+	slowc := make(chan string, 1)
+	go func() {
+		rs, err := http.Get(ts.URL + "/slow")
+		if err != nil {
+			slowc <- err.Error()
+			return
+		}
+		d, _ := ioutil.ReadAll(rs.Body)
+		rs.Body.Close()
+		slowc <- string(d)
+	}()
+	<-startedc
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- Drain(zap.NewExample(), ts.Config, h, 200*time.Millisecond, 5*time.Second, nil)
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	// readiness fails, while requests are still served during the drain period
+	if h.Ready() || !h.Draining() {
+		t.Fatal("expected not ready while draining")
+	}
+	rs, err := http.Get(ts.URL + path.PathReady)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs.Body.Close()
+	if rs.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected readiness status %d, got %d", http.StatusServiceUnavailable, rs.StatusCode)
+	}
+	rs, err = http.Get(ts.URL + path.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs.Body.Close()
+	if rs.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d while draining, got %d", http.StatusOK, rs.StatusCode)
+	}
+
+	if err = <-errc; err != nil {
+		t.Fatal(err)
+	}
+	if s := <-slowc; s != "done" {
+		t.Fatalf("expected in-flight request to complete, got %q", s)
+	}
+	if h.Drained() != 2 || h.Inflight() != 0 {
+		t.Fatalf("unexpected drained %d, in-flight %d", h.Drained(), h.Inflight())
+	}
+}
@@ -21,9 +21,31 @@ var (
 	},
 		[]string{"Path", "To"},
 	)
+	promFault = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "ingress_test_server",
+		Name:      "count_fault",
+		Help:      "total number of injected faults",
+	},
+		[]string{"Path", "Fault"},
+	)
+	promStreamMessages = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "ingress_test_server",
+		Name:      "count_stream_messages",
+		Help:      "total number of received WebSocket and gRPC messages",
+	},
+		[]string{"Protocol"},
+	)
+	promInflight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "ingress_test_server",
+		Name:      "inflight_requests",
+		Help:      "number of requests being served",
+	})
 )
 
 func init() {
 	prometheus.MustRegister(promRecv)
 	prometheus.MustRegister(promSent)
+	prometheus.MustRegister(promFault)
+	prometheus.MustRegister(promStreamMessages)
+	prometheus.MustRegister(promInflight)
 }
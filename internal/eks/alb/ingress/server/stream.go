@@ -0,0 +1,64 @@
+package server
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress/grpcecho"
+	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress/websocket"
+
+	"google.golang.org/grpc"
+)
+
+// WebSocketHandler upgrades the request to WebSocket,
+// and echoes every message until the client closes the connection.
+func WebSocketHandler(ctx context.Context, w http.ResponseWriter, req *http.Request) error {
+	promRecv.WithLabelValues(req.RemoteAddr, req.Method, req.RequestURI).Inc()
+	w.Header().Set(HeaderPodName, podName)
+	conn, err := websocket.Upgrade(w, req)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	for {
+		typ, d, err := conn.ReadMessage()
+		if err != nil {
+			if err == websocket.ErrClosed {
+				return nil
+			}
+			return err
+		}
+		promStreamMessages.WithLabelValues("websocket").Inc()
+		if err = conn.WriteMessage(typ, d); err != nil {
+			return err
+		}
+	}
+}
+
+// NewGRPCServer returns a new gRPC server with the echo service.
+func NewGRPCServer() *grpc.Server {
+	gs := grpc.NewServer(
+		grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			promStreamMessages.WithLabelValues("grpc").Inc()
+			return handler(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			return handler(srv, &countingStream{ServerStream: ss})
+		}),
+	)
+	grpcecho.Register(gs, &grpcecho.Server{Pod: podName})
+	return gs
+}
+
+// countingStream counts the received stream messages.
+type countingStream struct {
+	grpc.ServerStream
+}
+
+func (s *countingStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		promStreamMessages.WithLabelValues("grpc").Inc()
+	}
+	return err
+}
@@ -0,0 +1,63 @@
+package server
+
+import (
+	"context"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress/grpcecho"
+	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress/path"
+	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress/websocket"
+	"go.uber.org/zap"
+)
+
+func TestWebSocketHandler(t *testing.T) {
+	mux, err := NewMux(context.Background(), zap.NewExample(), 1, 10, nil, NewHealth())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	conn, _, err := websocket.Dial(strings.Replace(ts.URL, "http://", "ws://", 1)+path.PathWebSocket, nil, nil, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if err = conn.WriteMessage(websocket.TextMessage, []byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	typ, d, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if typ != websocket.TextMessage || string(d) != "hello" {
+		t.Fatalf("unexpected echo %d %q", typ, string(d))
+	}
+}
+
+func TestNewGRPCServer(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gs := NewGRPCServer()
+	go gs.Serve(ln)
+	defer gs.Stop()
+
+	cc, err := grpcecho.Dial(context.Background(), ln.Addr().String(), nil, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cc.Close()
+	resp, err := grpcecho.Echo(context.Background(), cc, &grpcecho.Message{Payload: []byte("hello")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(resp.Payload) != "hello" || resp.Pod != podName {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+}
@@ -0,0 +1,84 @@
+package ingress
+
+import (
+	"errors"
+	"fmt"
+
+	gyaml "github.com/ghodss/yaml"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// AnnotationLoadBalancerType is the Service annotation to choose
+// the AWS load balancer type ("nlb" for Network Load Balancer).
+const AnnotationLoadBalancerType = "service.beta.kubernetes.io/aws-load-balancer-type"
+
+// ConfigServiceLoadBalancer defines "LoadBalancer" type Service configuration.
+type ConfigServiceLoadBalancer struct {
+	// Name is the Service name.
+	Name string
+	// Namespace is the name space to create the Service in.
+	// If empty, defaults to the "default" namespace.
+	Namespace string
+	// Selector is the "app" label value of pods to route to.
+	Selector string
+	// NLB is true to provision Network Load Balancer instead of classic ELB.
+	NLB bool
+	// SourceRanges is the list of CIDRs allowed to access the load balancer.
+	SourceRanges []string
+}
+
+// CreateServiceLoadBalancer generates "LoadBalancer" type Service for ingress-test-server.
+func CreateServiceLoadBalancer(cfg ConfigServiceLoadBalancer) (string, error) {
+	if cfg.Name == "" {
+		return "", errors.New("empty Name")
+	}
+	if cfg.Selector == "" {
+		return "", errors.New("empty Selector")
+	}
+	if cfg.Namespace == "" {
+		cfg.Namespace = "default"
+	}
+
+	svc := v1.Service{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Service",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cfg.Name,
+			Namespace: cfg.Namespace,
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{
+					Name:       "ingress-test-server-web",
+					Port:       80,
+					TargetPort: intstr.FromInt(32030),
+					Protocol:   v1.ProtocolTCP,
+				},
+			},
+			Selector: map[string]string{
+				"app": cfg.Selector,
+			},
+			Type:                     v1.ServiceTypeLoadBalancer,
+			LoadBalancerSourceRanges: cfg.SourceRanges,
+		},
+	}
+	if cfg.NLB {
+		svc.ObjectMeta.Annotations = map[string]string{
+			AnnotationLoadBalancerType: "nlb",
+		}
+	}
+
+	d, err := gyaml.Marshal(svc)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(`---
+%s
+
+
+`, string(d)), nil
+}
@@ -0,0 +1,43 @@
+package ingress
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCreateServiceLoadBalancer(t *testing.T) {
+	d, err := CreateServiceLoadBalancer(ConfigServiceLoadBalancer{
+		Name:         "ingress-test-server-nlb",
+		Selector:     "ingress-test-server",
+		NLB:          true,
+		SourceRanges: []string{"0.0.0.0/0"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		"type: LoadBalancer",
+		"namespace: default",
+		AnnotationLoadBalancerType + ": nlb",
+		"- 0.0.0.0/0",
+	} {
+		if !strings.Contains(d, s) {
+			t.Fatalf("expected %q, got %s", s, d)
+		}
+	}
+
+	d, err = CreateServiceLoadBalancer(ConfigServiceLoadBalancer{
+		Name:     "ingress-test-server-classic-elb",
+		Selector: "ingress-test-server",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(d, AnnotationLoadBalancerType) {
+		t.Fatalf("unexpected load balancer type annotation, got %s", d)
+	}
+
+	if _, err = CreateServiceLoadBalancer(ConfigServiceLoadBalancer{Name: "svc"}); err == nil {
+		t.Fatal("expected error for empty Selector")
+	}
+}
@@ -0,0 +1,335 @@
+// Package websocket implements the minimal WebSocket protocol (RFC 6455)
+// to echo messages through ALB.
+package websocket
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Message types, as defined in RFC 6455 opcodes.
+const (
+	TextMessage   = 1
+	BinaryMessage = 2
+	closeMessage  = 8
+	pingMessage   = 9
+	pongMessage   = 10
+)
+
+// maxMessageSize is the maximum size of a message to read.
+const maxMessageSize = 16 * 1024 * 1024
+
+// acceptGUID is the GUID to compute "Sec-WebSocket-Accept".
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// ErrClosed is returned when the peer closed the connection.
+var ErrClosed = errors.New("websocket: connection closed")
+
+// Conn is a WebSocket connection.
+type Conn struct {
+	conn net.Conn
+	br   *bufio.Reader
+	// client is true to mask the frames sent to server.
+	client bool
+
+	wmu sync.Mutex
+}
+
+func acceptKey(key string) string {
+	h := sha1.New()
+	h.Write([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// IsUpgrade returns true if the request is a WebSocket handshake.
+func IsUpgrade(req *http.Request) bool {
+	return strings.EqualFold(req.Header.Get("Upgrade"), "websocket") &&
+		strings.Contains(strings.ToLower(req.Header.Get("Connection")), "upgrade")
+}
+
+// Upgrade completes the WebSocket handshake of the request,
+// and returns the server side connection.
+func Upgrade(w http.ResponseWriter, req *http.Request) (*Conn, error) {
+	if req.Method != http.MethodGet || !IsUpgrade(req) {
+		http.Error(w, "not a websocket handshake", http.StatusBadRequest)
+		return nil, errors.New("websocket: not a websocket handshake")
+	}
+	if req.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "unsupported websocket version", http.StatusBadRequest)
+		return nil, fmt.Errorf("websocket: unsupported version %q", req.Header.Get("Sec-WebSocket-Version"))
+	}
+	key := req.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		http.Error(w, "empty Sec-WebSocket-Key", http.StatusBadRequest)
+		return nil, errors.New("websocket: empty Sec-WebSocket-Key")
+	}
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "websocket not supported", http.StatusInternalServerError)
+		return nil, errors.New("websocket: response does not support hijack")
+	}
+	conn, rw, err := hj.Hijack()
+	if err != nil {
+		return nil, err
+	}
+	resp := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + acceptKey(key) + "\r\n\r\n"
+	if _, err = conn.Write([]byte(resp)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &Conn{conn: conn, br: rw.Reader}, nil
+}
+
+// Dial opens a WebSocket connection to the "ws://" or "wss://" URL.
+// The header is added to the handshake request.
+func Dial(rawURL string, header http.Header, tlsConfig *tls.Config, timeout time.Duration) (*Conn, *http.Response, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	host := u.Host
+	switch u.Scheme {
+	case "ws":
+		if u.Port() == "" {
+			host += ":80"
+		}
+	case "wss":
+		if u.Port() == "" {
+			host += ":443"
+		}
+	default:
+		return nil, nil, fmt.Errorf("websocket: unknown scheme %q", u.Scheme)
+	}
+
+	dialer := &net.Dialer{Timeout: timeout}
+	var conn net.Conn
+	if u.Scheme == "wss" {
+		cfg := &tls.Config{}
+		if tlsConfig != nil {
+			cfg = tlsConfig.Clone()
+		}
+		if cfg.ServerName == "" {
+			cfg.ServerName = u.Hostname()
+		}
+		// ALB supports WebSocket only over HTTP/1.1
+		cfg.NextProtos = []string{"http/1.1"}
+		conn, err = tls.DialWithDialer(dialer, "tcp", host, cfg)
+	} else {
+		conn, err = dialer.Dial("tcp", host)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if timeout > 0 {
+		conn.SetDeadline(time.Now().Add(timeout))
+	}
+
+	b := make([]byte, 16)
+	if _, err = rand.Read(b); err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(b)
+
+	req := &http.Request{
+		Method:     http.MethodGet,
+		URL:        u,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     make(http.Header),
+		Host:       u.Host,
+	}
+	for k, vs := range header {
+		req.Header[k] = vs
+	}
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	if err = req.Write(conn); err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		conn.Close()
+		return nil, resp, fmt.Errorf("websocket: handshake %q returned %q", rawURL, resp.Status)
+	}
+	if resp.Header.Get("Sec-WebSocket-Accept") != acceptKey(key) {
+		conn.Close()
+		return nil, resp, errors.New("websocket: invalid Sec-WebSocket-Accept")
+	}
+	conn.SetDeadline(time.Time{})
+	return &Conn{conn: conn, br: br, client: true}, resp, nil
+}
+
+// SetDeadline sets the read and write deadlines of the connection.
+func (c *Conn) SetDeadline(t time.Time) error {
+	return c.conn.SetDeadline(t)
+}
+
+// WriteMessage writes a text or binary message in a single frame.
+func (c *Conn) WriteMessage(messageType int, data []byte) error {
+	if messageType != TextMessage && messageType != BinaryMessage {
+		return fmt.Errorf("websocket: unknown message type %d", messageType)
+	}
+	return c.writeFrame(messageType, data)
+}
+
+func (c *Conn) writeFrame(opcode int, data []byte) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+
+	hdr := make([]byte, 2, 14)
+	hdr[0] = 0x80 | byte(opcode) // FIN
+	n := len(data)
+	switch {
+	case n < 126:
+		hdr[1] = byte(n)
+	case n <= 0xFFFF:
+		hdr[1] = 126
+		hdr = append(hdr, 0, 0)
+		binary.BigEndian.PutUint16(hdr[2:], uint16(n))
+	default:
+		hdr[1] = 127
+		hdr = append(hdr, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(hdr[2:], uint64(n))
+	}
+	payload := data
+	if c.client {
+		hdr[1] |= 0x80
+		mask := make([]byte, 4)
+		if _, err := rand.Read(mask); err != nil {
+			return err
+		}
+		hdr = append(hdr, mask...)
+		payload = make([]byte, n)
+		for i := range data {
+			payload[i] = data[i] ^ mask[i%4]
+		}
+	}
+	if _, err := c.conn.Write(append(hdr, payload...)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ReadMessage reads the next text or binary message.
+// Ping frames are answered with pong, and fragmented messages
+// are reassembled. It returns "ErrClosed" if the peer closed
+// the connection.
+func (c *Conn) ReadMessage() (messageType int, data []byte, err error) {
+	for {
+		fin, opcode, payload, err := c.readFrame()
+		if err != nil {
+			return 0, nil, err
+		}
+		switch opcode {
+		case pingMessage:
+			if err = c.writeFrame(pongMessage, payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case pongMessage:
+			continue
+		case closeMessage:
+			c.writeFrame(closeMessage, payload)
+			return 0, nil, ErrClosed
+		case TextMessage, BinaryMessage:
+			messageType = opcode
+			data = payload
+		case 0: // continuation
+			if messageType == 0 {
+				return 0, nil, errors.New("websocket: unexpected continuation frame")
+			}
+			data = append(data, payload...)
+		default:
+			return 0, nil, fmt.Errorf("websocket: unknown opcode %d", opcode)
+		}
+		if len(data) > maxMessageSize {
+			return 0, nil, fmt.Errorf("websocket: message size %d exceeds %d", len(data), maxMessageSize)
+		}
+		if fin {
+			return messageType, data, nil
+		}
+	}
+}
+
+func (c *Conn) readFrame() (fin bool, opcode int, payload []byte, err error) {
+	hdr := make([]byte, 2)
+	if _, err = io.ReadFull(c.br, hdr); err != nil {
+		if err == io.EOF {
+			err = ErrClosed
+		}
+		return false, 0, nil, err
+	}
+	fin = hdr[0]&0x80 != 0
+	opcode = int(hdr[0] & 0x0F)
+	masked := hdr[1]&0x80 != 0
+	n := uint64(hdr[1] & 0x7F)
+	switch n {
+	case 126:
+		b := make([]byte, 2)
+		if _, err = io.ReadFull(c.br, b); err != nil {
+			return false, 0, nil, err
+		}
+		n = uint64(binary.BigEndian.Uint16(b))
+	case 127:
+		b := make([]byte, 8)
+		if _, err = io.ReadFull(c.br, b); err != nil {
+			return false, 0, nil, err
+		}
+		n = binary.BigEndian.Uint64(b)
+	}
+	if n > maxMessageSize {
+		return false, 0, nil, fmt.Errorf("websocket: frame size %d exceeds %d", n, maxMessageSize)
+	}
+	var mask []byte
+	if masked {
+		mask = make([]byte, 4)
+		if _, err = io.ReadFull(c.br, mask); err != nil {
+			return false, 0, nil, err
+		}
+	}
+	payload = make([]byte, n)
+	if _, err = io.ReadFull(c.br, payload); err != nil {
+		return false, 0, nil, err
+	}
+	for i := range payload {
+		if masked {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return fin, opcode, payload, nil
+}
+
+// Close sends the close frame, and closes the connection.
+func (c *Conn) Close() error {
+	c.conn.SetWriteDeadline(time.Now().Add(time.Second))
+	c.writeFrame(closeMessage, []byte{0x03, 0xE8}) // 1000 normal closure
+	return c.conn.Close()
+}
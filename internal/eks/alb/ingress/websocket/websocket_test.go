@@ -0,0 +1,95 @@
+package websocket
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEcho(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		conn, err := Upgrade(w, req)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			typ, d, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if err = conn.WriteMessage(typ, d); err != nil {
+				return
+			}
+		}
+	}))
+	defer ts.Close()
+
+	conn, resp, err := Dial(strings.Replace(ts.URL, "http://", "ws://", 1), http.Header{"X-Test": []string{"1"}}, nil, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("expected status %d, got %d", http.StatusSwitchingProtocols, resp.StatusCode)
+	}
+
+	for _, d := range [][]byte{
+		[]byte("hello"),
+		bytes.Repeat([]byte("a"), 200),
+		bytes.Repeat([]byte("b"), 70000),
+	} {
+		if err = conn.WriteMessage(BinaryMessage, d); err != nil {
+			t.Fatal(err)
+		}
+		typ, rd, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if typ != BinaryMessage || !bytes.Equal(rd, d) {
+			t.Fatalf("unexpected echo type %d, size %d (expected %d)", typ, len(rd), len(d))
+		}
+	}
+
+	// ping is answered by pong, which is skipped by reader
+	if err = conn.writeFrame(pingMessage, []byte("ping")); err != nil {
+		t.Fatal(err)
+	}
+	if err = conn.WriteMessage(TextMessage, []byte("after-ping")); err != nil {
+		t.Fatal(err)
+	}
+	typ, rd, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if typ != TextMessage || string(rd) != "after-ping" {
+		t.Fatalf("unexpected echo %d %q", typ, string(rd))
+	}
+}
+
+func TestUpgradeNotWebSocket(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		Upgrade(w, req)
+	}))
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+
+	hs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer hs.Close()
+	if _, _, err = Dial(strings.Replace(hs.URL, "http://", "ws://", 1), nil, nil, 5*time.Second); err == nil {
+		t.Fatal("expected handshake error")
+	}
+}
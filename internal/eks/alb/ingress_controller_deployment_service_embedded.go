@@ -18,28 +18,10 @@ func (md *embedded) DeployIngressController() error {
 
 	// TODO: git pull from PR and build test image
 	// and push to ECR with AWS account ID + AWS region
-	cfg := ingress.ConfigDeploymentServiceALBIngressController{
-		AWSRegion:   md.cfg.AWSRegion,
-		Name:        "alb-ingress-controller",
-		ServiceName: "alb-ingress-controller-service",
-		Namespace:   "kube-system",
-		Image:       md.cfg.ALBIngressController.IngressControllerImage,
-		ClusterName: md.cfg.ClusterName,
-	}
-	d, err := ingress.CreateDeploymentServiceALBIngressController(cfg)
-	if err != nil {
-		return err
-	}
-
-	f, err := os.OpenFile(md.cfg.ALBIngressController.IngressControllerSpecPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	cfg, err := md.writeIngressControllerSpec(md.cfg.ALBIngressController.IngressControllerImage)
 	if err != nil {
 		return err
 	}
-	_, err = f.Write([]byte(d))
-	if err != nil {
-		return err
-	}
-	f.Close()
 
 	kcfgPath := md.cfg.KubeConfigPath
 	md.lg.Info("kubectl apply alb-ingress-controller")
@@ -145,3 +127,33 @@ func (md *embedded) DeployIngressController() error {
 	)
 	return md.cfg.Sync()
 }
+
+// writeIngressControllerSpec writes the ALB Ingress Controller
+// deployment and service spec with the image.
+func (md *embedded) writeIngressControllerSpec(image string) (cfg ingress.ConfigDeploymentServiceALBIngressController, err error) {
+	cfg = ingress.ConfigDeploymentServiceALBIngressController{
+		AWSRegion:   md.cfg.AWSRegion,
+		Name:        "alb-ingress-controller",
+		ServiceName: "alb-ingress-controller-service",
+		Namespace:   "kube-system",
+		Image:       image,
+		ClusterName: md.cfg.ClusterName,
+	}
+	if md.cfg.AWSMountRole {
+		cfg.AWSRoleARN = md.cfg.AWSRoleARN
+	}
+	d, err := ingress.CreateDeploymentServiceALBIngressController(cfg)
+	if err != nil {
+		return cfg, err
+	}
+
+	f, err := os.OpenFile(md.cfg.ALBIngressController.IngressControllerSpecPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return cfg, err
+	}
+	_, err = f.Write([]byte(d))
+	if err != nil {
+		return cfg, err
+	}
+	return cfg, f.Close()
+}
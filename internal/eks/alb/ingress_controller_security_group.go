@@ -2,9 +2,68 @@ package alb
 
 import (
 	"fmt"
+	"net"
 	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
 )
 
+// checkIPURL returns the public IP of the requester.
+const checkIPURL = "https://checkip.amazonaws.com"
+
+// albPorts is the list of ALB listener ports to open.
+var albPorts = []int64{80, 443}
+
+// albIngressPermissions returns the security group ingress permissions
+// that allow the source CIDRs to access ALB listener ports.
+func albIngressPermissions(cidrs []string) (ps []*ec2.IpPermission) {
+	for _, port := range albPorts {
+		p := &ec2.IpPermission{
+			IpProtocol: aws.String("tcp"),
+			FromPort:   aws.Int64(port),
+			ToPort:     aws.Int64(port),
+		}
+		for _, cidr := range cidrs {
+			p.IpRanges = append(p.IpRanges, &ec2.IpRange{CidrIp: aws.String(cidr)})
+		}
+		ps = append(ps, p)
+	}
+	return ps
+}
+
+// parsePublicIP parses the public IP lookup response
+// (e.g. "1.2.3.4\n") into a single host CIDR (e.g. "1.2.3.4/32").
+func parsePublicIP(body string) (string, error) {
+	s := strings.TrimSpace(body)
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return "", fmt.Errorf("invalid public IP %q", s)
+	}
+	if ip.To4() != nil {
+		return ip.String() + "/32", nil
+	}
+	return ip.String() + "/128", nil
+}
+
+// cidrsContainIP returns true if any of the CIDRs contains the IP.
+func cidrsContainIP(cidrs []string, s string) bool {
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return false
+	}
+	for _, cidr := range cidrs {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			continue
+		}
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
 // isSecurityGroupHasDependencyGoClient returns true if error indicates that
 // the security group has dependency thus cannot be deleted at the moment.
 func isSecurityGroupHasDependencyGoClient(err error, sgID string) bool {
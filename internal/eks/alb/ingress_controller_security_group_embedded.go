@@ -2,6 +2,9 @@ package alb
 
 import (
 	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -38,22 +41,32 @@ func (md *embedded) CreateSecurityGroup() error {
 		zap.String("created-security-group-id", md.cfg.ALBIngressController.ELBv2SecurityGroupIDPortOpen),
 	)
 
-	for _, port := range []int64{80, 443} {
-		_, err = md.ec2.AuthorizeSecurityGroupIngress(&ec2.AuthorizeSecurityGroupIngressInput{
-			GroupId:    aws.String(md.cfg.ALBIngressController.ELBv2SecurityGroupIDPortOpen),
-			IpProtocol: aws.String("tcp"),
-			CidrIp:     aws.String("0.0.0.0/0"),
-			FromPort:   aws.Int64(port),
-			ToPort:     aws.Int64(port),
-		})
+	if len(md.cfg.ALBIngressController.AllowedSourceCIDRs) == 0 {
+		var cidr string
+		cidr, err = detectPublicIP()
 		if err != nil {
 			md.cfg.ALBIngressController.ELBv2SecurityGroupStatus = err.Error()
 			md.cfg.Sync()
 			return err
 		}
-		md.lg.Info("authorized ingress for ALB", zap.Int64("port", port))
+		md.cfg.ALBIngressController.AllowedSourceCIDRs = []string{cidr}
+		md.lg.Info("detected public IP", zap.String("cidr", cidr))
 	}
 
+	_, err = md.ec2.AuthorizeSecurityGroupIngress(&ec2.AuthorizeSecurityGroupIngressInput{
+		GroupId:       aws.String(md.cfg.ALBIngressController.ELBv2SecurityGroupIDPortOpen),
+		IpPermissions: albIngressPermissions(md.cfg.ALBIngressController.AllowedSourceCIDRs),
+	})
+	if err != nil {
+		md.cfg.ALBIngressController.ELBv2SecurityGroupStatus = err.Error()
+		md.cfg.Sync()
+		return err
+	}
+	md.lg.Info("authorized ingress for ALB",
+		zap.Int64s("ports", albPorts),
+		zap.Strings("cidrs", md.cfg.ALBIngressController.AllowedSourceCIDRs),
+	)
+
 	md.cfg.ALBIngressController.ELBv2SecurityGroupStatus = "READY"
 	return md.cfg.Sync()
 }
@@ -67,29 +80,14 @@ func (md *embedded) DeleteSecurityGroup() error {
 
 	md.cfg.ALBIngressController.ELBv2SecurityGroupStatus = "DELETING"
 
-	_, err := md.ec2.RevokeSecurityGroupIngress(&ec2.RevokeSecurityGroupIngressInput{
-		GroupId:    aws.String(md.cfg.ALBIngressController.ELBv2SecurityGroupIDPortOpen),
-		IpProtocol: aws.String("tcp"),
-		CidrIp:     aws.String("0.0.0.0/0"),
-		FromPort:   aws.Int64(80),
-		ToPort:     aws.Int64(80),
-	})
-	if err != nil {
-		// do not fail the whole function, just logging errors
-		// following delete security group or ALB Ingress Controller
-		// should clean up this resources anyway
-		if !isSecurityGroupHasDependencyGoClient(err, md.cfg.ALBIngressController.ELBv2SecurityGroupIDPortOpen) &&
-			!isSecurityGroupDeletedGoClient(err) {
-			md.cfg.ALBIngressController.ELBv2SecurityGroupStatus = err.Error() + " for port 80"
-			md.cfg.Sync()
-		}
+	// security groups created before source CIDRs were configurable are open to the world
+	cidrs := md.cfg.ALBIngressController.AllowedSourceCIDRs
+	if len(cidrs) == 0 {
+		cidrs = []string{"0.0.0.0/0"}
 	}
-	_, err = md.ec2.RevokeSecurityGroupIngress(&ec2.RevokeSecurityGroupIngressInput{
-		GroupId:    aws.String(md.cfg.ALBIngressController.ELBv2SecurityGroupIDPortOpen),
-		IpProtocol: aws.String("tcp"),
-		CidrIp:     aws.String("0.0.0.0/0"),
-		FromPort:   aws.Int64(443),
-		ToPort:     aws.Int64(443),
+	_, err := md.ec2.RevokeSecurityGroupIngress(&ec2.RevokeSecurityGroupIngressInput{
+		GroupId:       aws.String(md.cfg.ALBIngressController.ELBv2SecurityGroupIDPortOpen),
+		IpPermissions: albIngressPermissions(cidrs),
 	})
 	if err != nil {
 		// do not fail the whole function, just logging errors
@@ -97,7 +95,7 @@ func (md *embedded) DeleteSecurityGroup() error {
 		// should clean up this resources anyway
 		if !isSecurityGroupHasDependencyGoClient(err, md.cfg.ALBIngressController.ELBv2SecurityGroupIDPortOpen) &&
 			!isSecurityGroupDeletedGoClient(err) {
-			md.cfg.ALBIngressController.ELBv2SecurityGroupStatus = err.Error() + " for port 443"
+			md.cfg.ALBIngressController.ELBv2SecurityGroupStatus = err.Error()
 			md.cfg.Sync()
 		}
 	}
@@ -125,3 +123,18 @@ func (md *embedded) DeleteSecurityGroup() error {
 	md.cfg.ALBIngressController.ELBv2SecurityGroupStatus = "DELETE_COMPLETE"
 	return md.cfg.Sync()
 }
+
+// detectPublicIP returns the public IP of the tester in CIDR notation.
+func detectPublicIP() (string, error) {
+	cli := &http.Client{Timeout: 10 * time.Second}
+	rs, err := cli.Get(checkIPURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to detect public IP (%v)", err)
+	}
+	defer rs.Body.Close()
+	d, err := ioutil.ReadAll(rs.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to detect public IP (%v)", err)
+	}
+	return parsePublicIP(string(d))
+}
@@ -0,0 +1,56 @@
+package alb
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+func Test_albIngressPermissions(t *testing.T) {
+	ps := albIngressPermissions([]string{"1.2.3.4/32", "10.0.0.0/8"})
+	if len(ps) != 2 {
+		t.Fatalf("expected 2 permissions, got %d", len(ps))
+	}
+	for i, port := range []int64{80, 443} {
+		if aws.Int64Value(ps[i].FromPort) != port || aws.Int64Value(ps[i].ToPort) != port {
+			t.Fatalf("#%d: expected port %d, got %+v", i, port, ps[i])
+		}
+		if len(ps[i].IpRanges) != 2 || aws.StringValue(ps[i].IpRanges[1].CidrIp) != "10.0.0.0/8" {
+			t.Fatalf("#%d: unexpected IP ranges %+v", i, ps[i].IpRanges)
+		}
+	}
+}
+
+func Test_parsePublicIP(t *testing.T) {
+	tests := []struct {
+		body string
+		exp  string
+		err  bool
+	}{
+		{"1.2.3.4\n", "1.2.3.4/32", false},
+		{"2001:db8::1", "2001:db8::1/128", false},
+		{"<html>", "", true},
+	}
+	for i, tt := range tests {
+		cidr, err := parsePublicIP(tt.body)
+		if (err != nil) != tt.err {
+			t.Fatalf("#%d: unexpected error %v", i, err)
+		}
+		if cidr != tt.exp {
+			t.Fatalf("#%d: expected %q, got %q", i, tt.exp, cidr)
+		}
+	}
+}
+
+func Test_cidrsContainIP(t *testing.T) {
+	cidrs := []string{"1.2.3.4/32", "10.0.0.0/8"}
+	if !cidrsContainIP(cidrs, "10.1.2.3") {
+		t.Fatal("expected 10.1.2.3 in range")
+	}
+	if cidrsContainIP(cidrs, "1.2.3.5") {
+		t.Fatal("unexpected 1.2.3.5 in range")
+	}
+	if cidrsContainIP(cidrs, "invalid") {
+		t.Fatal("unexpected invalid IP in range")
+	}
+}
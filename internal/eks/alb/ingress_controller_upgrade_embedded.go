@@ -0,0 +1,139 @@
+package alb
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	humanize "github.com/dustin/go-humanize"
+	"go.uber.org/zap"
+)
+
+// UpgradeIngressController rolls the ALB Ingress Controller Deployment
+// to the image, and waits until the rollout completes.
+func (md *embedded) UpgradeIngressController(image string) error {
+	now := time.Now().UTC()
+
+	cfg, err := md.writeIngressControllerSpec(image)
+	if err != nil {
+		return err
+	}
+
+	md.cfg.ALBIngressController.DeploymentStatus = "UPGRADING"
+	md.cfg.Sync()
+
+	var kexo []byte
+	applied := false
+	retryStart := time.Now().UTC()
+	for time.Now().UTC().Sub(retryStart) < 5*time.Minute {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		cmd := md.kubectl.CommandContext(ctx,
+			md.kubectlPath,
+			"--kubeconfig="+md.cfg.KubeConfigPath,
+			"apply",
+			"--filename="+md.cfg.ALBIngressController.IngressControllerSpecPath,
+		)
+		kexo, err = cmd.CombinedOutput()
+		cancel()
+		if err != nil {
+			md.lg.Warn("failed to apply alb-ingress-controller deployment and service",
+				zap.String("output", string(kexo)),
+				zap.Error(err),
+			)
+			time.Sleep(5 * time.Second)
+			continue
+		}
+		md.lg.Info("applied", zap.String("output", string(kexo)), zap.String("image", image))
+		applied = true
+		break
+	}
+	if !applied {
+		md.cfg.ALBIngressController.DeploymentStatus = err.Error()
+		md.cfg.Sync()
+		return fmt.Errorf("failed to apply alb-ingress-controller with %q (%v)", image, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	cmd := md.kubectl.CommandContext(ctx,
+		md.kubectlPath,
+		"--kubeconfig="+md.cfg.KubeConfigPath,
+		"rollout", "status",
+		"deployment/"+cfg.Name,
+		"--namespace="+cfg.Namespace,
+	)
+	kexo, err = cmd.CombinedOutput()
+	cancel()
+	if err != nil {
+		md.cfg.ALBIngressController.DeploymentStatus = err.Error()
+		md.cfg.Sync()
+		return fmt.Errorf("failed to roll out alb-ingress-controller with %q (%v, %q)", image, err, string(kexo))
+	}
+
+	md.cfg.ALBIngressController.IngressControllerImage = image
+	md.cfg.ALBIngressController.DeploymentStatus = "READY"
+	md.lg.Info(
+		"upgraded alb-ingress-controller",
+		zap.String("name", cfg.Name),
+		zap.String("namespace", cfg.Namespace),
+		zap.String("image", image),
+		zap.String("output", string(kexo)),
+		zap.String("request-started", humanize.RelTime(now, time.Now().UTC(), "ago", "from now")),
+	)
+	return md.cfg.Sync()
+}
+
+// DescribeResourceARNs returns the sorted ARNs of listeners,
+// listener rules and target groups of all ALBs.
+func (md *embedded) DescribeResourceARNs() (arns []string, err error) {
+	for name, lbARN := range md.cfg.ALBIngressController.ELBv2NameToARN {
+		var listenerARNs []*string
+		err = md.elbv2.DescribeListenersPages(
+			&elbv2.DescribeListenersInput{LoadBalancerArn: aws.String(lbARN)},
+			func(out *elbv2.DescribeListenersOutput, lastPage bool) bool {
+				for _, l := range out.Listeners {
+					listenerARNs = append(listenerARNs, l.ListenerArn)
+				}
+				return true
+			},
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe listeners of %q (%v)", name, err)
+		}
+
+		for _, listenerARN := range listenerARNs {
+			arns = append(arns, *listenerARN)
+			input := &elbv2.DescribeRulesInput{ListenerArn: listenerARN}
+			for {
+				out, derr := md.elbv2.DescribeRules(input)
+				if derr != nil {
+					return nil, fmt.Errorf("failed to describe rules of %q (%v)", *listenerARN, derr)
+				}
+				for _, r := range out.Rules {
+					arns = append(arns, *r.RuleArn)
+				}
+				if out.NextMarker == nil || *out.NextMarker == "" {
+					break
+				}
+				input.Marker = out.NextMarker
+			}
+		}
+
+		err = md.elbv2.DescribeTargetGroupsPages(
+			&elbv2.DescribeTargetGroupsInput{LoadBalancerArn: aws.String(lbARN)},
+			func(out *elbv2.DescribeTargetGroupsOutput, lastPage bool) bool {
+				for _, tg := range out.TargetGroups {
+					arns = append(arns, *tg.TargetGroupArn)
+				}
+				return true
+			},
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe target groups of %q (%v)", name, err)
+		}
+	}
+	sort.Strings(arns)
+	return arns, nil
+}
@@ -1,6 +1,10 @@
 package alb
 
 import (
+	"fmt"
+
+	"github.com/aws/aws-k8s-tester/eksconfig"
+
 	gyaml "github.com/ghodss/yaml"
 	"k8s.io/api/core/v1"
 	"k8s.io/api/extensions/v1beta1"
@@ -68,3 +72,71 @@ func getHostnameFromKubectlGetIngressOutput(kubectlOutput []byte, serviceName st
 	}
 	return "*"
 }
+
+// testServerNamespace returns the i-th namespace of ingress test server.
+func testServerNamespace(i int) string {
+	if i == 0 {
+		return "default"
+	}
+	return fmt.Sprintf("ingress-test-server-%d", i)
+}
+
+// shardRoutes splits the generated routes into Ingress objects with
+// at most "routesPerIngress" routes each, spread across namespaces
+// in round-robin order. The first Ingress object is in "default" namespace.
+func shardRoutes(routesN, routesPerIngress, namespacesN int) (shards []eksconfig.IngressShard) {
+	if namespacesN < 1 {
+		namespacesN = 1
+	}
+	for start, i := 0, 0; start < routesN; start, i = start+routesPerIngress, i+1 {
+		end := start + routesPerIngress
+		if end > routesN {
+			end = routesN
+		}
+		name := "ingress-for-ingress-test-server-service"
+		if i > 0 {
+			name = fmt.Sprintf("%s-%d", name, i)
+		}
+		shards = append(shards, eksconfig.IngressShard{
+			Namespace:  testServerNamespace(i % namespacesN),
+			Name:       name,
+			RouteStart: start,
+			RouteEnd:   end,
+		})
+	}
+	return shards
+}
+
+// getHostnamesFromKubectlGetIngressOutput returns the load balancer
+// hostname of each Ingress object name, if assigned.
+func getHostnamesFromKubectlGetIngressOutput(kubectlOutput []byte) map[string]string {
+	hs := make(map[string]string)
+	ls := new(unstructured.UnstructuredList)
+	if err := gyaml.Unmarshal(kubectlOutput, ls); err != nil {
+		return hs
+	}
+	for _, item := range ls.Items {
+		if item.GetKind() != "Ingress" {
+			continue
+		}
+		svv, ok := item.UnstructuredContent()["status"]
+		if !ok {
+			continue
+		}
+		d, err := gyaml.Marshal(svv)
+		if err != nil {
+			continue
+		}
+		st := new(v1.ServiceStatus)
+		if err = gyaml.Unmarshal(d, st); err != nil {
+			continue
+		}
+		if len(st.LoadBalancer.Ingress) < 1 {
+			continue
+		}
+		if h := st.LoadBalancer.Ingress[0].Hostname; h != "" && h != "*" {
+			hs[item.GetName()] = h
+		}
+	}
+	return hs
+}
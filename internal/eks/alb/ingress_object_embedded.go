@@ -4,10 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"strings"
 	"time"
 
+	"github.com/aws/aws-k8s-tester/eksconfig"
 	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress"
 	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress/path"
 	"github.com/aws/aws-k8s-tester/pkg/httputil"
@@ -24,10 +26,20 @@ func (md *embedded) createALBAnnotations(healthCheckPath string) (a map[string]s
 	a = map[string]string{
 		"alb.ingress.kubernetes.io/scheme":       "internet-facing",
 		"alb.ingress.kubernetes.io/target-type":  md.cfg.ALBIngressController.TargetType,
-		"alb.ingress.kubernetes.io/listen-ports": `[{"HTTP":80,"HTTPS": 443}]`,
+		"alb.ingress.kubernetes.io/listen-ports": `[{"HTTP":80}]`,
 		"alb.ingress.kubernetes.io/subnets":      strings.Join(md.cfg.SubnetIDs, ","),
 	}
 
+	// HTTPS listener requires a certificate
+	if md.cfg.ALBIngressController.EnableHTTPS {
+		if md.cfg.ALBIngressController.HTTPSCertificateARN == "" {
+			return nil, errors.New("cannot create HTTPS listener without certificate ARN")
+		}
+		a["alb.ingress.kubernetes.io/listen-ports"] = `[{"HTTP":80,"HTTPS": 443}]`
+		a["alb.ingress.kubernetes.io/certificate-arn"] = md.cfg.ALBIngressController.HTTPSCertificateARN
+		a["alb.ingress.kubernetes.io/ssl-policy"] = md.cfg.ALBIngressController.HTTPSSSLPolicy
+	}
+
 	h, _ := os.Hostname()
 
 	// e.g. alb.ingress.kubernetes.io/tags: Environment=dev,Team=test
@@ -55,6 +67,10 @@ func (md *embedded) createALBAnnotations(healthCheckPath string) (a map[string]s
 		// populate this only when the target type is "instance"
 		// pod "ip" model should let ingress controller create a new security group
 		delete(a, "alb.ingress.kubernetes.io/security-groups")
+		// which only allows the source CIDRs
+		if len(md.cfg.ALBIngressController.AllowedSourceCIDRs) > 0 {
+			a["alb.ingress.kubernetes.io/inbound-cidrs"] = strings.Join(md.cfg.ALBIngressController.AllowedSourceCIDRs, ",")
+		}
 
 	default:
 		return nil, fmt.Errorf("unknown ALB target type %q", md.cfg.ALBIngressController.TargetType)
@@ -144,6 +160,7 @@ func (md *embedded) CreateIngressObjects() (err error) {
 		Annotations:          make(map[string]string),
 		GenTargetServicePort: 80,
 	}
+	shards := []eksconfig.IngressShard{{Namespace: cfg2.MetadataNamespace, Name: cfg2.MetadataName}}
 	switch md.cfg.ALBIngressController.TestMode {
 	case "ingress-test-server":
 		cfg2.IngressPaths = []v1beta1.HTTPIngressPath{
@@ -161,10 +178,25 @@ func (md *embedded) CreateIngressObjects() (err error) {
 					ServicePort: intstr.IntOrString{Type: intstr.Int, IntVal: int32(80)},
 				},
 			},
+			{
+				Path: path.PathEcho,
+				Backend: v1beta1.IngressBackend{
+					ServiceName: "ingress-test-server-service",
+					ServicePort: intstr.IntOrString{Type: intstr.Int, IntVal: int32(80)},
+				},
+			},
 		}
 		cfg2.GenTargetServiceName = "ingress-test-server-service"
 		cfg2.GenTargetServiceRoutesN = md.cfg.ALBIngressController.TestServerRoutes
 
+		// ALB supports only a limited number of rules per listener,
+		// so split the routes across multiple Ingress objects (and ALBs)
+		shards = shardRoutes(
+			md.cfg.ALBIngressController.TestServerRoutes,
+			md.cfg.ALBIngressController.TestServerRoutesPerIngress,
+			md.cfg.ALBIngressController.TestServerNamespaces,
+		)
+
 	case "nginx":
 		cfg2.IngressPaths = []v1beta1.HTTPIngressPath{
 			{
@@ -182,28 +214,42 @@ func (md *embedded) CreateIngressObjects() (err error) {
 	if err != nil {
 		return err
 	}
-
-	// TODO: split into separate functions...
-
-	d2, err := ingress.CreateIngressTestServerIngressSpec(cfg2)
-	if err != nil {
-		md.cfg.ALBIngressController.IngressRuleStatusKubeSystem = err.Error()
-		md.cfg.ALBIngressController.IngressRuleStatusDefault = err.Error()
-		md.cfg.Sync()
-		return err
-	}
+	md.cfg.ALBIngressController.IngressShards = shards
+	md.cfg.Sync()
 
 	d := fmt.Sprintf(`---
 %s
 
 
 
----
+`, d1)
+	for i, shard := range shards {
+		cfg := cfg2
+		cfg.MetadataName = shard.Name
+		cfg.MetadataNamespace = shard.Namespace
+		if md.cfg.ALBIngressController.TestMode == "ingress-test-server" {
+			// only the first Ingress object serves the default and metrics paths
+			if i > 0 {
+				cfg.IngressPaths = nil
+			}
+			cfg.GenTargetServiceRoutesStart = shard.RouteStart
+			cfg.GenTargetServiceRoutesN = shard.RouteEnd - shard.RouteStart
+		}
+		var d2 string
+		d2, err = ingress.CreateIngressTestServerIngressSpec(cfg)
+		if err != nil {
+			md.cfg.ALBIngressController.IngressRuleStatusKubeSystem = err.Error()
+			md.cfg.ALBIngressController.IngressRuleStatusDefault = err.Error()
+			md.cfg.Sync()
+			return err
+		}
+		d += fmt.Sprintf(`---
 %s
 
 
 
-`, d1, d2)
+`, d2)
+	}
 
 	f, err := os.OpenFile(md.cfg.ALBIngressController.IngressObjectSpecPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
 	if err != nil {
@@ -311,62 +357,15 @@ func (md *embedded) CreateIngressObjects() (err error) {
 		continue
 	}
 
-	retryStart = time.Now().UTC()
-	for time.Now().UTC().Sub(retryStart) < 5*time.Minute {
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		cmd := md.kubectl.CommandContext(ctx,
-			md.kubectlPath,
-			"--kubeconfig="+md.cfg.KubeConfigPath,
-			"get", "ingress",
-			"--namespace=default",
-			"--output=yaml",
-		)
-		kexo, err = cmd.CombinedOutput()
-		cancel()
-		if err != nil {
-			md.lg.Warn("failed to get ingress", zap.String("namespace", "default"), zap.Error(err))
-			md.cfg.ALBIngressController.IngressRuleStatusDefault = err.Error()
-			md.cfg.Sync()
-			time.Sleep(15 * time.Second)
-			continue
-		}
-
-		h := getHostnameFromKubectlGetIngressOutput(kexo, cfg2.IngressPaths[0].Backend.ServiceName)
-		if h != "*" {
-			md.lg.Info("created ingress",
-				zap.String("service-name", cfg2.IngressPaths[0].Backend.ServiceName),
-				zap.String("namespace", "default"),
-				zap.String("host", h),
-				zap.String("request-started", humanize.RelTime(now, time.Now().UTC(), "ago", "from now")),
-			)
-			if len(md.cfg.ALBIngressController.ELBv2NamespaceToDNSName) == 0 {
-				md.cfg.ALBIngressController.ELBv2NamespaceToDNSName = make(map[string]string)
-			}
-			if len(md.cfg.ALBIngressController.ELBv2NameToDNSName) == 0 {
-				md.cfg.ALBIngressController.ELBv2NameToDNSName = make(map[string]string)
-			}
-			md.cfg.ALBIngressController.ELBv2NamespaceToDNSName["default"] = h
-			md.cfg.ALBIngressController.ELBv2NameToDNSName[strings.Join(strings.Split(h, "-")[:4], "-")] = h
-			md.cfg.ALBIngressController.IngressRuleStatusDefault = "READY"
-			md.cfg.Sync()
-			break
-		}
-
-		md.lg.Info("creating ingress",
-			zap.String("output", string(kexo)),
-			zap.String("namespace", "default"),
-			zap.String("host", h),
-			zap.String("request-started", humanize.RelTime(now, time.Now().UTC(), "ago", "from now")),
-			zap.Error(err),
-		)
-		md.cfg.ALBIngressController.IngressRuleStatusDefault = "CREATING"
+	if err = md.waitIngressShards(now); err != nil {
+		md.cfg.ALBIngressController.IngressRuleStatusDefault = err.Error()
 		md.cfg.Sync()
-		time.Sleep(10 * time.Second)
-		continue
+		return err
 	}
 	md.lg.Info("created ingress",
 		zap.String("dns-name-kube-system", md.cfg.ALBIngressController.ELBv2NamespaceToDNSName["kube-system"]),
 		zap.String("dns-name-default", md.cfg.ALBIngressController.ELBv2NamespaceToDNSName["default"]),
+		zap.Int("ingress-shards", len(md.cfg.ALBIngressController.IngressShards)),
 		zap.String("request-started", humanize.RelTime(now, time.Now().UTC(), "ago", "from now")),
 	)
 
@@ -377,16 +376,26 @@ func (md *embedded) CreateIngressObjects() (err error) {
 	for k := range md.cfg.ALBIngressController.ELBv2NameToDNSName {
 		names = append(names, k)
 	}
-	eo, oerr := md.elbv2.DescribeLoadBalancers(&elbv2.DescribeLoadBalancersInput{
-		Names: aws.StringSlice(names),
-	})
-	if oerr != nil {
-		md.cfg.ALBIngressController.IngressRuleStatusKubeSystem = oerr.Error()
-		md.cfg.ALBIngressController.IngressRuleStatusDefault = oerr.Error()
-		md.cfg.Sync()
-		return oerr
+	var lbs []*elbv2.LoadBalancer
+	for len(names) > 0 {
+		// DescribeLoadBalancers accepts up to 20 names per request
+		n := 20
+		if n > len(names) {
+			n = len(names)
+		}
+		eo, oerr := md.elbv2.DescribeLoadBalancers(&elbv2.DescribeLoadBalancersInput{
+			Names: aws.StringSlice(names[:n]),
+		})
+		if oerr != nil {
+			md.cfg.ALBIngressController.IngressRuleStatusKubeSystem = oerr.Error()
+			md.cfg.ALBIngressController.IngressRuleStatusDefault = oerr.Error()
+			md.cfg.Sync()
+			return oerr
+		}
+		lbs = append(lbs, eo.LoadBalancers...)
+		names = names[n:]
 	}
-	for _, lb := range eo.LoadBalancers {
+	for _, lb := range lbs {
 		name := *lb.LoadBalancerName
 		h, ok := md.cfg.ALBIngressController.ELBv2NameToDNSName[name]
 		if !ok {
@@ -409,8 +418,8 @@ func (md *embedded) CreateIngressObjects() (err error) {
 		md.cfg.ALBIngressController.ELBv2NameToARN[name] = *lb.LoadBalancerArn
 	}
 
-	if len(md.cfg.ALBIngressController.ELBv2NamespaceToDNSName) != 2 {
-		return fmt.Errorf("expected two ELBv2 DNS names, got %+v", md.cfg.ALBIngressController.ELBv2NamespaceToDNSName)
+	if n := 1 + len(md.cfg.ALBIngressController.IngressShards); len(md.cfg.ALBIngressController.ELBv2NameToARN) != n {
+		return fmt.Errorf("expected %d ELBv2 ARNs, got %+v", n, md.cfg.ALBIngressController.ELBv2NameToARN)
 	}
 
 	md.lg.Info("found AWS ELBv2 resources",
@@ -423,10 +432,11 @@ func (md *embedded) CreateIngressObjects() (err error) {
 	if md.cfg.ALBIngressController.TestMode == "ingress-test-server" {
 		ep += path.Path
 	}
-	if !httputil.CheckGet(
+	if !httputil.CheckGetVerify(
 		md.lg,
+		http.DefaultClient,
 		ep,
-		strings.Repeat("0", md.cfg.ALBIngressController.TestResponseSize),
+		VerifyResponse(md.cfg),
 		30,
 		10*time.Second,
 		md.stopc,
@@ -435,6 +445,21 @@ func (md *embedded) CreateIngressObjects() (err error) {
 	}
 	md.lg.Info("created ingress", zap.String("namespace", "default"))
 
+	for _, shard := range md.cfg.ALBIngressController.IngressShards[1:] {
+		if !httputil.CheckGetVerify(
+			md.lg,
+			http.DefaultClient,
+			"http://"+shard.DNSName+path.Create(shard.RouteStart),
+			VerifyResponse(md.cfg),
+			30,
+			10*time.Second,
+			md.stopc,
+		) {
+			return fmt.Errorf("ingress %q in %q is not ready", shard.Name, shard.Namespace)
+		}
+		md.lg.Info("created ingress", zap.String("name", shard.Name), zap.String("namespace", shard.Namespace))
+	}
+
 	if !httputil.CheckGet(
 		md.lg,
 		"http://"+md.cfg.ALBIngressController.ELBv2NamespaceToDNSName["kube-system"]+"/metrics",
@@ -450,6 +475,91 @@ func (md *embedded) CreateIngressObjects() (err error) {
 	return md.cfg.Sync()
 }
 
+// waitIngressShards waits until every Ingress object in "IngressShards"
+// is assigned an ALB, and records its ELBv2 name and DNS name.
+func (md *embedded) waitIngressShards(now time.Time) (err error) {
+	shards := md.cfg.ALBIngressController.IngressShards
+	var namespaces []string
+	seen := make(map[string]struct{})
+	for _, shard := range shards {
+		if _, ok := seen[shard.Namespace]; ok {
+			continue
+		}
+		seen[shard.Namespace] = struct{}{}
+		namespaces = append(namespaces, shard.Namespace)
+	}
+
+	hs := make(map[string]string)
+	retryStart := time.Now().UTC()
+	for time.Now().UTC().Sub(retryStart) < 5*time.Minute {
+		for _, ns := range namespaces {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			cmd := md.kubectl.CommandContext(ctx,
+				md.kubectlPath,
+				"--kubeconfig="+md.cfg.KubeConfigPath,
+				"get", "ingress",
+				"--namespace="+ns,
+				"--output=yaml",
+			)
+			kexo, kerr := cmd.CombinedOutput()
+			cancel()
+			if kerr != nil {
+				md.lg.Warn("failed to get ingress", zap.String("namespace", ns), zap.Error(kerr))
+				continue
+			}
+			for name, h := range getHostnamesFromKubectlGetIngressOutput(kexo) {
+				hs[ns+"/"+name] = h
+			}
+		}
+
+		ready := 0
+		for _, shard := range shards {
+			if _, ok := hs[shard.Namespace+"/"+shard.Name]; ok {
+				ready++
+			}
+		}
+		if ready == len(shards) {
+			break
+		}
+
+		md.lg.Info("creating ingress",
+			zap.Int("ready", ready),
+			zap.Int("total", len(shards)),
+			zap.String("request-started", humanize.RelTime(now, time.Now().UTC(), "ago", "from now")),
+		)
+		md.cfg.ALBIngressController.IngressRuleStatusDefault = "CREATING"
+		md.cfg.Sync()
+		time.Sleep(10 * time.Second)
+	}
+
+	if len(md.cfg.ALBIngressController.ELBv2NamespaceToDNSName) == 0 {
+		md.cfg.ALBIngressController.ELBv2NamespaceToDNSName = make(map[string]string)
+	}
+	if len(md.cfg.ALBIngressController.ELBv2NameToDNSName) == 0 {
+		md.cfg.ALBIngressController.ELBv2NameToDNSName = make(map[string]string)
+	}
+	for i, shard := range shards {
+		h, ok := hs[shard.Namespace+"/"+shard.Name]
+		if !ok {
+			return fmt.Errorf("ingress %q in %q has no ALB hostname", shard.Name, shard.Namespace)
+		}
+		shards[i].DNSName = h
+		shards[i].ELBv2Name = elbv2NameFromDNSName(h)
+		md.cfg.ALBIngressController.ELBv2NameToDNSName[shards[i].ELBv2Name] = h
+		if _, ok = md.cfg.ALBIngressController.ELBv2NamespaceToDNSName[shard.Namespace]; !ok {
+			md.cfg.ALBIngressController.ELBv2NamespaceToDNSName[shard.Namespace] = h
+		}
+		md.lg.Info("created ingress",
+			zap.String("name", shard.Name),
+			zap.String("namespace", shard.Namespace),
+			zap.String("host", h),
+		)
+	}
+	md.cfg.ALBIngressController.IngressShards = shards
+	md.cfg.ALBIngressController.IngressRuleStatusDefault = "READY"
+	return md.cfg.Sync()
+}
+
 // DeleteIngressObjects deletes ingress objects.
 // cloudformation delete often fails due to ELBv2 dependencies.
 // Delete ELBv2 first and see if that helps.
@@ -1,6 +1,75 @@
 package alb
 
-import "testing"
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-k8s-tester/eksconfig"
+
+	"go.uber.org/zap"
+)
+
+func Test_createALBAnnotationsHTTPS(t *testing.T) {
+	cfg := eksconfig.NewDefault()
+	cfg.LogAccess = false
+	md := &embedded{lg: zap.NewExample(), cfg: cfg}
+
+	a, err := md.createALBAnnotations("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := a["alb.ingress.kubernetes.io/listen-ports"]; v != `[{"HTTP":80}]` {
+		t.Fatalf("unexpected listen ports %q", v)
+	}
+	if _, ok := a["alb.ingress.kubernetes.io/certificate-arn"]; ok {
+		t.Fatal("unexpected certificate ARN annotation")
+	}
+
+	cfg.ALBIngressController.EnableHTTPS = true
+	if _, err = md.createALBAnnotations("/"); err == nil {
+		t.Fatal("expected error without certificate ARN")
+	}
+	cfg.ALBIngressController.HTTPSCertificateARN = "arn:aws:acm:us-west-2:123456789012:certificate/test"
+	cfg.ALBIngressController.HTTPSSSLPolicy = "ELBSecurityPolicy-TLS-1-2-2017-01"
+	a, err = md.createALBAnnotations("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := a["alb.ingress.kubernetes.io/listen-ports"]; v != `[{"HTTP":80,"HTTPS": 443}]` {
+		t.Fatalf("unexpected listen ports %q", v)
+	}
+	if v := a["alb.ingress.kubernetes.io/certificate-arn"]; v != cfg.ALBIngressController.HTTPSCertificateARN {
+		t.Fatalf("unexpected certificate ARN %q", v)
+	}
+	if v := a["alb.ingress.kubernetes.io/ssl-policy"]; v != "ELBSecurityPolicy-TLS-1-2-2017-01" {
+		t.Fatalf("unexpected SSL policy %q", v)
+	}
+}
+
+func Test_createALBAnnotationsInboundCIDRs(t *testing.T) {
+	cfg := eksconfig.NewDefault()
+	cfg.LogAccess = false
+	cfg.ALBIngressController.AllowedSourceCIDRs = []string{"1.2.3.4/32", "10.0.0.0/8"}
+	md := &embedded{lg: zap.NewExample(), cfg: cfg}
+
+	// instance mode restricts the security group instead
+	a, err := md.createALBAnnotations("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := a["alb.ingress.kubernetes.io/inbound-cidrs"]; ok {
+		t.Fatal("unexpected inbound CIDRs annotation")
+	}
+
+	cfg.ALBIngressController.TargetType = "ip"
+	a, err = md.createALBAnnotations("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := a["alb.ingress.kubernetes.io/inbound-cidrs"]; v != "1.2.3.4/32,10.0.0.0/8" {
+		t.Fatalf("unexpected inbound CIDRs %q", v)
+	}
+}
 
 func Test_getHostnameFromKubectlGetIngressOutput(t *testing.T) {
 	h := getHostnameFromKubectlGetIngressOutput([]byte(sampleKubectlGetIngressOutput1), "alb-ingress-controller-service")
@@ -14,6 +83,34 @@ func Test_getHostnameFromKubectlGetIngressOutput(t *testing.T) {
 	}
 }
 
+func Test_getHostnamesFromKubectlGetIngressOutput(t *testing.T) {
+	hs := getHostnamesFromKubectlGetIngressOutput([]byte(sampleKubectlGetIngressOutput2))
+	exp := map[string]string{"ingress-for-alb-ingress-controller-service": "fb1dd3ab-kubesystem-ingres-6aec-737236003.us-west-2.elb.amazonaws.com"}
+	if !reflect.DeepEqual(hs, exp) {
+		t.Fatalf("expected %v, got %v", exp, hs)
+	}
+	if hs = getHostnamesFromKubectlGetIngressOutput([]byte("invalid")); len(hs) != 0 {
+		t.Fatalf("expected no hostname, got %v", hs)
+	}
+}
+
+func Test_shardRoutes(t *testing.T) {
+	shards := shardRoutes(70, 30, 2)
+	exp := []eksconfig.IngressShard{
+		{Namespace: "default", Name: "ingress-for-ingress-test-server-service", RouteStart: 0, RouteEnd: 30},
+		{Namespace: "ingress-test-server-1", Name: "ingress-for-ingress-test-server-service-1", RouteStart: 30, RouteEnd: 60},
+		{Namespace: "default", Name: "ingress-for-ingress-test-server-service-2", RouteStart: 60, RouteEnd: 70},
+	}
+	if !reflect.DeepEqual(shards, exp) {
+		t.Fatalf("expected %+v, got %+v", exp, shards)
+	}
+
+	shards = shardRoutes(1, 30, 1)
+	if len(shards) != 1 || shards[0].RouteEnd != 1 || shards[0].Namespace != "default" {
+		t.Fatalf("unexpected shards %+v", shards)
+	}
+}
+
 const sampleKubectlGetIngressOutput1 = `
 apiVersion: v1
 items:
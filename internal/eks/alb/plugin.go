@@ -1,5 +1,11 @@
 package alb
 
+import (
+	"time"
+
+	"github.com/aws/aws-k8s-tester/eksconfig"
+)
+
 // Plugin defines ALB Ingress Controller deployer operations.
 type Plugin interface {
 	DeployBackend() error
@@ -7,12 +13,28 @@ type Plugin interface {
 	CreateRBAC() error
 
 	DeployIngressController() error
+	UpgradeIngressController(image string) error
 
 	CreateSecurityGroup() error
 	DeleteSecurityGroup() error
 
+	CreateCertificate() error
+	DeleteCertificate() error
+
 	CreateIngressObjects() error
 	DeleteIngressObjects() error
 
 	TestAWSResources() error
+	DescribeResourceARNs() ([]string, error)
+	MeasureReconcileLatency(name string) (eksconfig.ReconcileLatency, error)
+	RollTestServer(deregistrationDelay int, readinessGate bool) (eksconfig.RollingUpdateResult, error)
+	ResetRollingUpdate() error
+	TestHTTPS() error
+	TestEcho() error
+	TestAllowedSourceCIDRs() error
+	TestStickiness() error
+	TestWeightedRouting() error
+	TestWebSocket() error
+	TestGRPC() error
+	RunDistributedClients(pods int, clientArgs []string, timeout time.Duration) ([]string, error)
 }
@@ -6,6 +6,7 @@ import (
 	"github.com/aws/aws-k8s-tester/eksconfig"
 	"github.com/aws/aws-k8s-tester/internal/eks/s3"
 
+	"github.com/aws/aws-sdk-go/service/acm/acmiface"
 	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
 	"github.com/aws/aws-sdk-go/service/elbv2/elbv2iface"
 	"github.com/aws/aws-sdk-go/service/iam/iamiface"
@@ -25,6 +26,7 @@ type embedded struct {
 	kubectlPath string
 
 	im       iamiface.IAMAPI
+	acm      acmiface.ACMAPI
 	ec2      ec2iface.EC2API
 	elbv2    elbv2iface.ELBV2API
 	s3Plugin s3.Plugin
@@ -36,6 +38,7 @@ func NewEmbedded(
 	lg *zap.Logger,
 	cfg *eksconfig.Config,
 	im iamiface.IAMAPI,
+	acm acmiface.ACMAPI,
 	ec2 ec2iface.EC2API,
 	elbv2 elbv2iface.ELBV2API,
 	s3Plugin s3.Plugin,
@@ -46,6 +49,7 @@ func NewEmbedded(
 		cfg:      cfg,
 		kubectl:  exec.New(),
 		im:       im,
+		acm:      acm,
 		ec2:      ec2,
 		elbv2:    elbv2,
 		s3Plugin: s3Plugin,
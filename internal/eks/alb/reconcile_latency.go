@@ -0,0 +1,40 @@
+package alb
+
+import (
+	"github.com/aws/aws-sdk-go/service/elbv2"
+)
+
+// findLoadBalancerARNByIngress returns the ARN of load balancer
+// that ALB Ingress Controller tagged with the Ingress namespace and name.
+// It returns an empty string if not found.
+func findLoadBalancerARNByIngress(descs []*elbv2.TagDescription, namespace, name string) string {
+	for _, desc := range descs {
+		var nsMatch, nameMatch bool
+		for _, tag := range desc.Tags {
+			if tag.Key == nil || tag.Value == nil {
+				continue
+			}
+			switch *tag.Key {
+			case "kubernetes.io/namespace":
+				nsMatch = *tag.Value == namespace
+			case "kubernetes.io/ingress-name":
+				nameMatch = *tag.Value == name
+			}
+		}
+		if nsMatch && nameMatch && desc.ResourceArn != nil {
+			return *desc.ResourceArn
+		}
+	}
+	return ""
+}
+
+// hasNonDefaultRule returns true if any listener rule is
+// created from Ingress rules, other than the default rule.
+func hasNonDefaultRule(rules []*elbv2.Rule) bool {
+	for _, r := range rules {
+		if r.IsDefault == nil || !*r.IsDefault {
+			return true
+		}
+	}
+	return false
+}
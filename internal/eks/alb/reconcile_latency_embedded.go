@@ -0,0 +1,312 @@
+package alb
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/eksconfig"
+	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress"
+	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress/path"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"go.uber.org/zap"
+	"k8s.io/api/extensions/v1beta1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// MeasureReconcileLatency creates a separate Ingress object with the name,
+// records the timestamp of each reconcile phase until the first successful
+// response, and deletes the Ingress object and its ALB.
+func (md *embedded) MeasureReconcileLatency(name string) (r eksconfig.ReconcileLatency, err error) {
+	r.IngressName = name
+	ns := "default"
+
+	cfg := ingress.ConfigIngressTestServerIngressSpec{
+		MetadataName:      name,
+		MetadataNamespace: ns,
+	}
+	urlPath := "/"
+	switch md.cfg.ALBIngressController.TestMode {
+	case "ingress-test-server":
+		urlPath = path.Path
+		cfg.IngressPaths = []v1beta1.HTTPIngressPath{
+			{
+				Path: path.Path,
+				Backend: v1beta1.IngressBackend{
+					ServiceName: "ingress-test-server-service",
+					ServicePort: intstr.IntOrString{Type: intstr.Int, IntVal: int32(80)},
+				},
+			},
+		}
+	case "nginx":
+		cfg.IngressPaths = []v1beta1.HTTPIngressPath{
+			{
+				Path: "/*",
+				Backend: v1beta1.IngressBackend{
+					ServiceName: "nginx-service",
+					ServicePort: intstr.IntOrString{Type: intstr.Int, IntVal: int32(80)},
+				},
+			},
+		}
+	}
+	cfg.Annotations, err = md.createALBAnnotations("/")
+	if err != nil {
+		return r, err
+	}
+	d, err := ingress.CreateIngressTestServerIngressSpec(cfg)
+	if err != nil {
+		return r, err
+	}
+	if err = ioutil.WriteFile(md.cfg.ALBIngressController.ReconcileLatencyIngressSpecPath, []byte(d), 0600); err != nil {
+		return r, err
+	}
+
+	// ALBs of other Ingress objects
+	known := make(map[string]struct{})
+	for _, arn := range md.cfg.ALBIngressController.ELBv2NameToARN {
+		known[arn] = struct{}{}
+	}
+
+	if err = md.kubectlSpec("apply", md.cfg.ALBIngressController.ReconcileLatencyIngressSpecPath); err != nil {
+		return r, err
+	}
+	r.IngressCreated = time.Now().UTC()
+	md.lg.Info("created ingress for reconcile latency", zap.String("name", name), zap.String("namespace", ns))
+
+	var lbARN, dnsName string
+	defer func() {
+		if derr := md.deleteTestIngress(md.cfg.ALBIngressController.ReconcileLatencyIngressSpecPath, lbARN); derr != nil {
+			md.lg.Warn("failed to delete ingress for reconcile latency", zap.String("name", name), zap.Error(derr))
+			if err == nil {
+				err = derr
+			}
+		}
+	}()
+
+	hc, err := HTTPClient(md.cfg)
+	if err != nil {
+		return r, err
+	}
+	cli := *hc
+	cli.Timeout = 5 * time.Second
+	scheme := "http://"
+	if md.cfg.ALBIngressController.EnableHTTPS {
+		scheme = "https://"
+	}
+
+	retryStart := time.Now().UTC()
+	for time.Now().UTC().Sub(retryStart) < 15*time.Minute {
+		select {
+		case <-md.stopc:
+			r.Error = "interrupted"
+			return r, fmt.Errorf("reconcile latency of %q interrupted", name)
+		case <-time.After(2 * time.Second):
+		}
+
+		if lbARN == "" {
+			lbARN, dnsName, err = md.findIngressLoadBalancer(ns, name, known)
+			if err != nil {
+				md.lg.Warn("failed to find ALB", zap.String("name", name), zap.Error(err))
+				continue
+			}
+			if lbARN == "" {
+				continue
+			}
+			r.ALBCreated = time.Now().UTC()
+			md.lg.Info("found ALB", zap.String("name", name), zap.String("arn", lbARN), zap.String("dns-name", dnsName))
+		}
+
+		if r.ListenerRulesCreated.IsZero() && md.hasListenerRules(lbARN) {
+			r.ListenerRulesCreated = time.Now().UTC()
+			md.lg.Info("found listener rules", zap.String("name", name))
+		}
+		if r.TargetHealthy.IsZero() && md.hasHealthyTarget(lbARN) {
+			r.TargetHealthy = time.Now().UTC()
+			md.lg.Info("found healthy target", zap.String("name", name))
+		}
+		if r.DNSResolvable.IsZero() {
+			if _, lerr := net.LookupHost(dnsName); lerr == nil {
+				r.DNSResolvable = time.Now().UTC()
+				md.lg.Info("resolved ALB DNS name", zap.String("name", name), zap.String("dns-name", dnsName))
+			}
+		}
+		if r.FirstSuccess.IsZero() && !r.DNSResolvable.IsZero() {
+			rs, gerr := cli.Get(scheme + dnsName + urlPath)
+			if gerr == nil {
+				ioutil.ReadAll(rs.Body)
+				rs.Body.Close()
+				if rs.StatusCode == http.StatusOK {
+					r.FirstSuccess = time.Now().UTC()
+					md.lg.Info("received first successful response", zap.String("name", name))
+				}
+			}
+		}
+
+		if !r.ListenerRulesCreated.IsZero() &&
+			!r.TargetHealthy.IsZero() &&
+			!r.FirstSuccess.IsZero() {
+			md.lg.Info("measured reconcile latency",
+				zap.String("name", name),
+				zap.Duration("alb-created", r.ALBCreated.Sub(r.IngressCreated)),
+				zap.Duration("listener-rules-created", r.ListenerRulesCreated.Sub(r.IngressCreated)),
+				zap.Duration("target-healthy", r.TargetHealthy.Sub(r.IngressCreated)),
+				zap.Duration("dns-resolvable", r.DNSResolvable.Sub(r.IngressCreated)),
+				zap.Duration("first-success", r.FirstSuccess.Sub(r.IngressCreated)),
+			)
+			return r, nil
+		}
+	}
+
+	r.Error = fmt.Sprintf("Ingress %q did not serve traffic in time", name)
+	return r, fmt.Errorf("reconcile latency of %q timed out", name)
+}
+
+// kubectlSpec runs kubectl "apply" or "delete" with the YAML spec file.
+func (md *embedded) kubectlSpec(op, specPath string) (err error) {
+	var kexo []byte
+	retryStart := time.Now().UTC()
+	for time.Now().UTC().Sub(retryStart) < time.Minute {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		cmd := md.kubectl.CommandContext(ctx,
+			md.kubectlPath,
+			"--kubeconfig="+md.cfg.KubeConfigPath,
+			op,
+			"--filename="+specPath,
+		)
+		kexo, err = cmd.CombinedOutput()
+		cancel()
+		if err == nil {
+			return nil
+		}
+		md.lg.Warn("failed to "+op+" spec",
+			zap.String("path", specPath),
+			zap.String("output", string(kexo)),
+			zap.Error(err),
+		)
+		time.Sleep(5 * time.Second)
+	}
+	return fmt.Errorf("failed to %s %q (%v, %q)", op, specPath, err, string(kexo))
+}
+
+// findIngressLoadBalancer returns the ARN and DNS name of ALB
+// created for the Ingress object, skipping the known ALBs.
+func (md *embedded) findIngressLoadBalancer(namespace, name string, known map[string]struct{}) (arn, dnsName string, err error) {
+	lbs := make(map[string]string)
+	err = md.elbv2.DescribeLoadBalancersPages(
+		&elbv2.DescribeLoadBalancersInput{},
+		func(out *elbv2.DescribeLoadBalancersOutput, lastPage bool) bool {
+			for _, lb := range out.LoadBalancers {
+				if _, ok := known[*lb.LoadBalancerArn]; ok {
+					continue
+				}
+				lbs[*lb.LoadBalancerArn] = *lb.DNSName
+			}
+			return true
+		},
+	)
+	if err != nil {
+		return "", "", err
+	}
+
+	arns := make([]string, 0, len(lbs))
+	for k := range lbs {
+		arns = append(arns, k)
+	}
+	for len(arns) > 0 {
+		// DescribeTags accepts up to 20 ARNs per request
+		n := 20
+		if n > len(arns) {
+			n = len(arns)
+		}
+		out, terr := md.elbv2.DescribeTags(&elbv2.DescribeTagsInput{
+			ResourceArns: aws.StringSlice(arns[:n]),
+		})
+		if terr != nil {
+			return "", "", terr
+		}
+		if arn = findLoadBalancerARNByIngress(out.TagDescriptions, namespace, name); arn != "" {
+			return arn, lbs[arn], nil
+		}
+		arns = arns[n:]
+	}
+	return "", "", nil
+}
+
+func (md *embedded) hasListenerRules(lbARN string) bool {
+	lo, err := md.elbv2.DescribeListeners(&elbv2.DescribeListenersInput{
+		LoadBalancerArn: aws.String(lbARN),
+	})
+	if err != nil {
+		return false
+	}
+	for _, l := range lo.Listeners {
+		ro, rerr := md.elbv2.DescribeRules(&elbv2.DescribeRulesInput{
+			ListenerArn: l.ListenerArn,
+		})
+		if rerr != nil {
+			continue
+		}
+		if hasNonDefaultRule(ro.Rules) {
+			return true
+		}
+	}
+	return false
+}
+
+func (md *embedded) hasHealthyTarget(lbARN string) bool {
+	to, err := md.elbv2.DescribeTargetGroups(&elbv2.DescribeTargetGroupsInput{
+		LoadBalancerArn: aws.String(lbARN),
+	})
+	if err != nil {
+		return false
+	}
+	for _, tg := range to.TargetGroups {
+		ho, herr := md.elbv2.DescribeTargetHealth(&elbv2.DescribeTargetHealthInput{
+			TargetGroupArn: tg.TargetGroupArn,
+		})
+		if herr != nil {
+			continue
+		}
+		for _, hv := range ho.TargetHealthDescriptions {
+			if hv.TargetHealth != nil && aws.StringValue(hv.TargetHealth.State) == elbv2.TargetHealthStateEnumHealthy {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// deleteTestIngress deletes the Ingress object in the spec,
+// and waits until its ALB is deleted, so that next run starts clean.
+func (md *embedded) deleteTestIngress(specPath, lbARN string) error {
+	if err := md.kubectlSpec("delete", specPath); err != nil {
+		return err
+	}
+	defer os.RemoveAll(specPath)
+	if lbARN == "" {
+		return nil
+	}
+
+	retryStart := time.Now().UTC()
+	for time.Now().UTC().Sub(retryStart) < 10*time.Minute {
+		_, err := md.elbv2.DescribeLoadBalancers(&elbv2.DescribeLoadBalancersInput{
+			LoadBalancerArns: aws.StringSlice([]string{lbARN}),
+		})
+		if err != nil {
+			if aerr, ok := err.(awserr.Error); ok && aerr.Code() == elbv2.ErrCodeLoadBalancerNotFoundException {
+				md.lg.Info("deleted ALB", zap.String("arn", lbARN))
+				return nil
+			}
+			md.lg.Warn("failed to describe ALB", zap.String("arn", lbARN), zap.Error(err))
+		}
+		time.Sleep(10 * time.Second)
+	}
+	return fmt.Errorf("ALB %q not deleted in time", lbARN)
+}
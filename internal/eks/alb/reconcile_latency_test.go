@@ -0,0 +1,42 @@
+package alb
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+)
+
+func Test_findLoadBalancerARNByIngress(t *testing.T) {
+	descs := []*elbv2.TagDescription{
+		{
+			ResourceArn: aws.String("arn-1"),
+			Tags: []*elbv2.Tag{
+				{Key: aws.String("kubernetes.io/namespace"), Value: aws.String("kube-system")},
+				{Key: aws.String("kubernetes.io/ingress-name"), Value: aws.String("ingress-reconcile-latency-0")},
+			},
+		},
+		{
+			ResourceArn: aws.String("arn-2"),
+			Tags: []*elbv2.Tag{
+				{Key: aws.String("kubernetes.io/namespace"), Value: aws.String("default")},
+				{Key: aws.String("kubernetes.io/ingress-name"), Value: aws.String("ingress-reconcile-latency-0")},
+			},
+		},
+	}
+	if arn := findLoadBalancerARNByIngress(descs, "default", "ingress-reconcile-latency-0"); arn != "arn-2" {
+		t.Fatalf("expected 'arn-2', got %q", arn)
+	}
+	if arn := findLoadBalancerARNByIngress(descs, "default", "ingress-reconcile-latency-1"); arn != "" {
+		t.Fatalf("expected empty ARN, got %q", arn)
+	}
+}
+
+func Test_hasNonDefaultRule(t *testing.T) {
+	if hasNonDefaultRule([]*elbv2.Rule{{IsDefault: aws.Bool(true)}}) {
+		t.Fatal("expected only default rule")
+	}
+	if !hasNonDefaultRule([]*elbv2.Rule{{IsDefault: aws.Bool(true)}, {IsDefault: aws.Bool(false)}}) {
+		t.Fatal("expected non-default rule")
+	}
+}
@@ -0,0 +1,66 @@
+package alb
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/service/elbv2"
+)
+
+// deregistrationDelayAttribute is the target group attribute key
+// of deregistration delay in seconds.
+const deregistrationDelayAttribute = "deregistration_delay.timeout_seconds"
+
+// readinessGateConditionType returns the pod readiness gate condition type,
+// that ALB Ingress Controller sets when the pod is healthy in the target group
+// of the Ingress object and the service.
+func readinessGateConditionType(ingressName, serviceName string, servicePort int) string {
+	return fmt.Sprintf("target-health.alb.ingress.k8s.aws/%s_%s_%d", ingressName, serviceName, servicePort)
+}
+
+// rollingUpdatePatch returns the strategic merge patch that triggers
+// a rolling update of the Deployment, with the pod readiness gates.
+// Empty readiness gates removes existing ones.
+func rollingUpdatePatch(restartedAt string, readinessGates []string) (string, error) {
+	var gates interface{}
+	if len(readinessGates) > 0 {
+		gs := make([]map[string]string, 0, len(readinessGates))
+		for _, g := range readinessGates {
+			gs = append(gs, map[string]string{"conditionType": g})
+		}
+		gates = gs
+	}
+	d, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]string{
+						"aws-k8s-tester/restarted-at": restartedAt,
+					},
+				},
+				"spec": map[string]interface{}{
+					"readinessGates": gates,
+				},
+			},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	return string(d), nil
+}
+
+// targetsStable returns true if every target group has exactly
+// the expected number of targets, all of which are healthy.
+// Draining targets mean that old pods are still deregistering.
+func targetsStable(states []map[string]int, expected int) bool {
+	if len(states) == 0 {
+		return false
+	}
+	for _, st := range states {
+		if len(st) != 1 || st[elbv2.TargetHealthStateEnumHealthy] != expected {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,208 @@
+package alb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/eksconfig"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"go.uber.org/zap"
+)
+
+// RollTestServer sets the deregistration delay on the target groups of
+// the ALB in "default" namespace, triggers a rolling update of the ingress
+// test server Deployment with or without the readiness gate, and waits until
+// the rollout completes and all targets are healthy.
+func (md *embedded) RollTestServer(deregistrationDelay int, readinessGate bool) (r eksconfig.RollingUpdateResult, err error) {
+	r.DeregistrationDelaySeconds = deregistrationDelay
+	r.ReadinessGate = readinessGate
+	r.DrainSeconds = md.cfg.ALBIngressController.TestServerDrainSeconds
+
+	if len(md.cfg.ALBIngressController.IngressShards) == 0 {
+		return r, errors.New("no Ingress object found in default namespace")
+	}
+	shard := md.cfg.ALBIngressController.IngressShards[0]
+	lbARN, ok := md.cfg.ALBIngressController.ELBv2NameToARN[shard.ELBv2Name]
+	if !ok {
+		return r, fmt.Errorf("ALB %q ARN not found", shard.ELBv2Name)
+	}
+
+	if err = md.setDeregistrationDelay(shard.Name, lbARN, deregistrationDelay); err != nil {
+		return r, err
+	}
+
+	var gates []string
+	if readinessGate {
+		gates = []string{readinessGateConditionType(shard.Name, "ingress-test-server-service", 80)}
+	}
+	r.Started = time.Now().UTC()
+	if err = md.rollTestServer(gates, r.Started.Format(time.RFC3339Nano)); err != nil {
+		return r, err
+	}
+
+	expected := md.expectedHealthyTargets("default")
+	retryStart := time.Now().UTC()
+	for time.Now().UTC().Sub(retryStart) < 15*time.Minute {
+		states, serr := md.targetGroupStates(lbARN)
+		if serr != nil {
+			md.lg.Warn("failed to describe target health", zap.Error(serr))
+		} else if targetsStable(states, expected) {
+			r.Stable = time.Now().UTC()
+			r.TimeToStable = r.Stable.Sub(r.Started).String()
+			md.lg.Info("targets are stable",
+				zap.Int("deregistration-delay-seconds", deregistrationDelay),
+				zap.Bool("readiness-gate", readinessGate),
+				zap.String("time-to-stable", r.TimeToStable),
+			)
+			return r, nil
+		} else {
+			md.lg.Info("waiting for targets to be stable", zap.Any("states", states), zap.Int("expected-healthy", expected))
+		}
+
+		select {
+		case <-md.stopc:
+			return r, errors.New("rolling update interrupted")
+		case <-time.After(5 * time.Second):
+		}
+	}
+	return r, fmt.Errorf("targets of %q not stable in time", lbARN)
+}
+
+// ResetRollingUpdate removes the deregistration delay annotation
+// and the readiness gates set by "RollTestServer".
+func (md *embedded) ResetRollingUpdate() error {
+	if len(md.cfg.ALBIngressController.IngressShards) == 0 {
+		return errors.New("no Ingress object found in default namespace")
+	}
+	shard := md.cfg.ALBIngressController.IngressShards[0]
+	kexo, err := md.kubectlCommand(time.Minute,
+		"annotate",
+		"ingress/"+shard.Name,
+		"--namespace=default",
+		"alb.ingress.kubernetes.io/target-group-attributes-",
+	)
+	if err != nil {
+		return fmt.Errorf("failed to remove deregistration delay annotation (%v, %q)", err, string(kexo))
+	}
+	return md.rollTestServer(nil, time.Now().UTC().Format(time.RFC3339Nano))
+}
+
+// setDeregistrationDelay annotates the Ingress object with the deregistration
+// delay, and waits until ALB Ingress Controller updates the target groups.
+func (md *embedded) setDeregistrationDelay(ingressName, lbARN string, seconds int) error {
+	kexo, err := md.kubectlCommand(time.Minute,
+		"annotate", "--overwrite",
+		"ingress/"+ingressName,
+		"--namespace=default",
+		fmt.Sprintf("alb.ingress.kubernetes.io/target-group-attributes=%s=%d", deregistrationDelayAttribute, seconds),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to annotate deregistration delay (%v, %q)", err, string(kexo))
+	}
+
+	exp := strconv.Itoa(seconds)
+	retryStart := time.Now().UTC()
+	for time.Now().UTC().Sub(retryStart) < 5*time.Minute {
+		to, derr := md.elbv2.DescribeTargetGroups(&elbv2.DescribeTargetGroupsInput{
+			LoadBalancerArn: aws.String(lbARN),
+		})
+		if derr != nil {
+			md.lg.Warn("failed to describe target groups", zap.Error(derr))
+			time.Sleep(5 * time.Second)
+			continue
+		}
+		updated := 0
+		for _, tg := range to.TargetGroups {
+			ao, aerr := md.elbv2.DescribeTargetGroupAttributes(&elbv2.DescribeTargetGroupAttributesInput{
+				TargetGroupArn: tg.TargetGroupArn,
+			})
+			if aerr != nil {
+				md.lg.Warn("failed to describe target group attributes", zap.Error(aerr))
+				break
+			}
+			for _, a := range ao.Attributes {
+				if aws.StringValue(a.Key) == deregistrationDelayAttribute && aws.StringValue(a.Value) == exp {
+					updated++
+				}
+			}
+		}
+		if len(to.TargetGroups) > 0 && updated == len(to.TargetGroups) {
+			md.lg.Info("updated deregistration delay", zap.Int("seconds", seconds), zap.Int("target-groups", updated))
+			return nil
+		}
+		time.Sleep(5 * time.Second)
+	}
+	return fmt.Errorf("deregistration delay %d seconds not applied to %q in time", seconds, lbARN)
+}
+
+// rollTestServer patches the ingress test server Deployment
+// in "default" namespace, and waits until the rollout completes.
+func (md *embedded) rollTestServer(readinessGates []string, restartedAt string) error {
+	patch, err := rollingUpdatePatch(restartedAt, readinessGates)
+	if err != nil {
+		return err
+	}
+	kexo, err := md.kubectlCommand(time.Minute,
+		"patch",
+		"deployment/ingress-test-server",
+		"--namespace=default",
+		"--patch="+patch,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to patch ingress test server (%v, %q)", err, string(kexo))
+	}
+	md.lg.Info("patched ingress test server", zap.Strings("readiness-gates", readinessGates))
+
+	kexo, err = md.kubectlCommand(15*time.Minute,
+		"rollout", "status",
+		"deployment/ingress-test-server",
+		"--namespace=default",
+	)
+	if err != nil {
+		return fmt.Errorf("failed to roll out ingress test server (%v, %q)", err, string(kexo))
+	}
+	md.lg.Info("rolled out ingress test server", zap.String("output", string(kexo)))
+	return nil
+}
+
+// targetGroupStates returns the number of targets
+// in each target health state, for each target group of the ALB.
+func (md *embedded) targetGroupStates(lbARN string) (states []map[string]int, err error) {
+	to, err := md.elbv2.DescribeTargetGroups(&elbv2.DescribeTargetGroupsInput{
+		LoadBalancerArn: aws.String(lbARN),
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, tg := range to.TargetGroups {
+		ho, herr := md.elbv2.DescribeTargetHealth(&elbv2.DescribeTargetHealthInput{
+			TargetGroupArn: tg.TargetGroupArn,
+		})
+		if herr != nil {
+			return nil, herr
+		}
+		st := make(map[string]int)
+		for _, hv := range ho.TargetHealthDescriptions {
+			if hv.TargetHealth != nil {
+				st[aws.StringValue(hv.TargetHealth.State)]++
+			}
+		}
+		states = append(states, st)
+	}
+	return states, nil
+}
+
+func (md *embedded) kubectlCommand(timeout time.Duration, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	cmd := md.kubectl.CommandContext(ctx,
+		md.kubectlPath,
+		append([]string{"--kubeconfig=" + md.cfg.KubeConfigPath}, args...)...,
+	)
+	return cmd.CombinedOutput()
+}
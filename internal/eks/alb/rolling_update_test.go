@@ -0,0 +1,55 @@
+package alb
+
+import "testing"
+
+func Test_readinessGateConditionType(t *testing.T) {
+	s := readinessGateConditionType("ingress-for-ingress-test-server-service", "ingress-test-server-service", 80)
+	exp := "target-health.alb.ingress.k8s.aws/ingress-for-ingress-test-server-service_ingress-test-server-service_80"
+	if s != exp {
+		t.Fatalf("expected %q, got %q", exp, s)
+	}
+}
+
+func Test_rollingUpdatePatch(t *testing.T) {
+	tests := []struct {
+		gates []string
+		exp   string
+	}{
+		{
+			nil,
+			`{"spec":{"template":{"metadata":{"annotations":{"aws-k8s-tester/restarted-at":"now"}},"spec":{"readinessGates":null}}}}`,
+		},
+		{
+			[]string{"a"},
+			`{"spec":{"template":{"metadata":{"annotations":{"aws-k8s-tester/restarted-at":"now"}},"spec":{"readinessGates":[{"conditionType":"a"}]}}}}`,
+		},
+	}
+	for i, tt := range tests {
+		s, err := rollingUpdatePatch("now", tt.gates)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s != tt.exp {
+			t.Fatalf("#%d: expected %s, got %s", i, tt.exp, s)
+		}
+	}
+}
+
+func Test_targetsStable(t *testing.T) {
+	tests := []struct {
+		states []map[string]int
+		exp    bool
+	}{
+		{nil, false},
+		{[]map[string]int{{"healthy": 2}}, true},
+		{[]map[string]int{{"healthy": 2}, {"healthy": 2}}, true},
+		{[]map[string]int{{"healthy": 1}}, false},
+		{[]map[string]int{{"healthy": 2, "draining": 1}}, false},
+		{[]map[string]int{{"healthy": 2}, {"initial": 2}}, false},
+	}
+	for i, tt := range tests {
+		if got := targetsStable(tt.states, 2); got != tt.exp {
+			t.Fatalf("#%d: expected %v, got %v", i, tt.exp, got)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package alb
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// stickinessAttributes returns the "alb.ingress.kubernetes.io/target-group-attributes"
+// annotation value that enables ALB cookie based stickiness.
+func stickinessAttributes(durationSeconds int) string {
+	return fmt.Sprintf(
+		"stickiness.enabled=true,stickiness.type=lb_cookie,stickiness.lb_cookie.duration_seconds=%d",
+		durationSeconds,
+	)
+}
+
+// weightedTarget is a backend service with its traffic weight.
+type weightedTarget struct {
+	ServiceName string `json:"ServiceName"`
+	ServicePort string `json:"ServicePort"`
+	Weight      int    `json:"Weight"`
+}
+
+type forwardConfig struct {
+	TargetGroups []weightedTarget `json:"TargetGroups"`
+}
+
+type forwardAction struct {
+	Type          string        `json:"Type"`
+	ForwardConfig forwardConfig `json:"ForwardConfig"`
+}
+
+// weightedForwardAction returns the "alb.ingress.kubernetes.io/actions.*"
+// annotation value that forwards traffic to the services by weights.
+func weightedForwardAction(tgs []weightedTarget) (string, error) {
+	if len(tgs) < 2 {
+		return "", fmt.Errorf("weighted forward action requires at least 2 services, got %d", len(tgs))
+	}
+	d, err := json.Marshal(forwardAction{
+		Type:          "forward",
+		ForwardConfig: forwardConfig{TargetGroups: tgs},
+	})
+	if err != nil {
+		return "", err
+	}
+	return string(d), nil
+}
+
+// deploymentOfPod returns the Deployment name of the pod,
+// e.g. "ingress-test-server-5d8f9c7b6d-x2x8k" is from "ingress-test-server".
+func deploymentOfPod(pod string) string {
+	ss := strings.Split(pod, "-")
+	if len(ss) < 3 {
+		return pod
+	}
+	return strings.Join(ss[:len(ss)-2], "-")
+}
+
+// checkPinned returns an error if the client
+// was served by more than one pod.
+func checkPinned(client int, pods []string) error {
+	if len(pods) == 0 {
+		return fmt.Errorf("client %d: no response", client)
+	}
+	for _, pod := range pods[1:] {
+		if pod != pods[0] {
+			return fmt.Errorf("client %d: expected all responses from %q, got %q", client, pods[0], pod)
+		}
+	}
+	return nil
+}
+
+// checkWeightedRatio returns the traffic ratio of the deployment,
+// and an error if it differs from the expected ratio over the tolerance.
+func checkWeightedRatio(counts map[string]int, deployment string, exp, tolerance float64) (float64, error) {
+	total := 0
+	for _, n := range counts {
+		total += n
+	}
+	if total == 0 {
+		return 0, fmt.Errorf("no response from %q", deployment)
+	}
+	ratio := float64(counts[deployment]) / float64(total)
+	if math.Abs(ratio-exp) > tolerance {
+		return ratio, fmt.Errorf("expected %q traffic ratio %.3f (tolerance %.3f), got %.3f (%v)", deployment, exp, tolerance, ratio, counts)
+	}
+	return ratio, nil
+}
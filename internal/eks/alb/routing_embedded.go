@@ -0,0 +1,381 @@
+package alb
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/cookiejar"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress"
+	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress/path"
+	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress/server"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"go.uber.org/zap"
+	"k8s.io/api/extensions/v1beta1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	stickinessIngressName      = "ingress-for-stickiness"
+	stickinessClients          = 5
+	stickinessClientRequests   = 20
+	weightedRoutingIngressName = "ingress-for-weighted-routing"
+	weightedRoutingServerName  = "ingress-test-server-weighted"
+	weightedRoutingActionName  = "weighted-routing"
+	weightedRoutingRequests    = 1000
+	weightedRoutingClients     = 10
+)
+
+// TestStickiness creates an Ingress object with target group stickiness,
+// and verifies that each client with the ALB cookie is served by the same pod,
+// while a client without cookie is served by multiple pods.
+func (md *embedded) TestStickiness() (err error) {
+	cfg := ingress.ConfigIngressTestServerIngressSpec{
+		MetadataName:      stickinessIngressName,
+		MetadataNamespace: "default",
+		IngressPaths: []v1beta1.HTTPIngressPath{
+			{
+				Path: path.Path,
+				Backend: v1beta1.IngressBackend{
+					ServiceName: "ingress-test-server-service",
+					ServicePort: intstr.IntOrString{Type: intstr.Int, IntVal: int32(80)},
+				},
+			},
+		},
+	}
+	cfg.Annotations, err = md.createALBAnnotations(path.Path)
+	if err != nil {
+		return err
+	}
+	cfg.Annotations["alb.ingress.kubernetes.io/target-group-attributes"] = stickinessAttributes(md.cfg.ALBIngressController.StickinessDurationSeconds)
+	d, err := ingress.CreateIngressTestServerIngressSpec(cfg)
+	if err != nil {
+		return err
+	}
+	specPath := md.cfg.ALBIngressController.StickinessIngressSpecPath
+	if err = ioutil.WriteFile(specPath, []byte(d), 0600); err != nil {
+		return err
+	}
+
+	replicas := md.cfg.ALBIngressController.TestServerReplicas
+	lbARN, ep, err := md.createTestIngress(specPath, stickinessIngressName, func(healthy []int) bool {
+		return len(healthy) == 1 && healthy[0] == replicas
+	}, nil)
+	defer func() {
+		if derr := md.deleteTestIngress(specPath, lbARN); derr != nil {
+			md.lg.Warn("failed to delete ingress for stickiness", zap.Error(derr))
+			if err == nil {
+				err = derr
+			}
+		}
+	}()
+	if err != nil {
+		return err
+	}
+	ep += path.Path
+
+	hc, err := HTTPClient(md.cfg)
+	if err != nil {
+		return err
+	}
+	cli := *hc
+	cli.Timeout = 10 * time.Second
+
+	// without cookie, ALB balances requests across pods
+	pods, err := md.getPods(&cli, ep, stickinessClientRequests)
+	if err != nil {
+		return err
+	}
+	if err = checkPinned(-1, pods); err == nil {
+		return fmt.Errorf("expected requests without cookie to be served by multiple pods, got only %q", pods[0])
+	}
+
+	for i := 0; i < stickinessClients; i++ {
+		jar, jerr := cookiejar.New(nil)
+		if jerr != nil {
+			return jerr
+		}
+		jcli := cli
+		jcli.Jar = jar
+		pods, err = md.getPods(&jcli, ep, stickinessClientRequests)
+		if err != nil {
+			return err
+		}
+		if err = checkPinned(i, pods); err != nil {
+			return err
+		}
+		md.lg.Info("client is pinned", zap.Int("client", i), zap.String("pod", pods[0]))
+	}
+
+	md.lg.Info("tested ALB stickiness",
+		zap.Int("clients", stickinessClients),
+		zap.Int("requests-per-client", stickinessClientRequests),
+	)
+	return nil
+}
+
+// TestWeightedRouting deploys a second ingress test server, creates an Ingress
+// object with weighted forward action between two services, and verifies that
+// the traffic ratio of the second service is within the tolerance.
+func (md *embedded) TestWeightedRouting() (err error) {
+	weight := md.cfg.ALBIngressController.WeightedRoutingWeight
+	action, err := weightedForwardAction([]weightedTarget{
+		{ServiceName: "ingress-test-server-service", ServicePort: "80", Weight: 100 - weight},
+		{ServiceName: weightedRoutingServerName + "-service", ServicePort: "80", Weight: weight},
+	})
+	if err != nil {
+		return err
+	}
+
+	dd, err := ingress.CreateDeploymentServiceIngressTestServer(ingress.ConfigDeploymentServiceIngressTestServer{
+		Name:         weightedRoutingServerName,
+		ServiceName:  weightedRoutingServerName + "-service",
+		Namespace:    "default",
+		Image:        md.cfg.AWSK8sTesterImage,
+		Replicas:     md.cfg.ALBIngressController.TestServerReplicas,
+		Routes:       md.cfg.ALBIngressController.TestServerRoutes,
+		ResponseSize: md.cfg.ALBIngressController.TestResponseSize,
+	})
+	if err != nil {
+		return err
+	}
+	cfg := ingress.ConfigIngressTestServerIngressSpec{
+		MetadataName:      weightedRoutingIngressName,
+		MetadataNamespace: "default",
+		IngressPaths: []v1beta1.HTTPIngressPath{
+			{
+				Path: path.Path,
+				Backend: v1beta1.IngressBackend{
+					ServiceName: weightedRoutingActionName,
+					ServicePort: intstr.FromString("use-annotation"),
+				},
+			},
+		},
+	}
+	cfg.Annotations, err = md.createALBAnnotations(path.Path)
+	if err != nil {
+		return err
+	}
+	cfg.Annotations["alb.ingress.kubernetes.io/actions."+weightedRoutingActionName] = action
+	di, err := ingress.CreateIngressTestServerIngressSpec(cfg)
+	if err != nil {
+		return err
+	}
+	specPath := md.cfg.ALBIngressController.WeightedRoutingSpecPath
+	if err = ioutil.WriteFile(specPath, []byte(dd+"\n---\n"+di), 0600); err != nil {
+		return err
+	}
+
+	lbARN, ep, err := md.createTestIngress(specPath, weightedRoutingIngressName, func(healthy []int) bool {
+		if len(healthy) != 2 {
+			return false
+		}
+		return healthy[0] > 0 && healthy[1] > 0
+	}, nil)
+	defer func() {
+		if derr := md.deleteTestIngress(specPath, lbARN); derr != nil {
+			md.lg.Warn("failed to delete ingress for weighted routing", zap.Error(derr))
+			if err == nil {
+				err = derr
+			}
+		}
+	}()
+	if err != nil {
+		return err
+	}
+	ep += path.Path
+
+	hc, err := HTTPClient(md.cfg)
+	if err != nil {
+		return err
+	}
+	cli := *hc
+	cli.Timeout = 10 * time.Second
+
+	var mu sync.Mutex
+	counts := make(map[string]int)
+	var failures int64
+	var wg sync.WaitGroup
+	wg.Add(weightedRoutingClients)
+	for i := 0; i < weightedRoutingClients; i++ {
+		go func() {
+			defer wg.Done()
+			for j := 0; j < weightedRoutingRequests/weightedRoutingClients; j++ {
+				pod, gerr := getPod(&cli, ep)
+				mu.Lock()
+				if gerr != nil {
+					failures++
+				} else {
+					counts[deploymentOfPod(pod)]++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if failures > md.cfg.ALBIngressController.TestClientErrorThreshold {
+		return fmt.Errorf("expected failures under threshold %d during weighted routing test, got %d", md.cfg.ALBIngressController.TestClientErrorThreshold, failures)
+	}
+	ratio, err := checkWeightedRatio(
+		counts,
+		weightedRoutingServerName,
+		float64(weight)/100,
+		md.cfg.ALBIngressController.WeightedRoutingTolerance,
+	)
+	md.cfg.ALBIngressController.TestResultWeightedRoutingRatio = ratio
+	md.cfg.Sync()
+	md.lg.Info("tested ALB weighted routing",
+		zap.Int("weight", weight),
+		zap.Float64("ratio", ratio),
+		zap.Int64("failures", failures),
+		zap.Any("counts", counts),
+	)
+	return err
+}
+
+// createTestIngress applies the spec with the Ingress object,
+// and waits until its ALB serves traffic and the healthy target counts
+// of its target groups are ready. It returns the ALB ARN and endpoint.
+// The probe returns an error if the ALB endpoint does not serve traffic yet.
+// If nil, the probe sends a request to the ingress test server path.
+func (md *embedded) createTestIngress(specPath, name string, ready func(healthy []int) bool, probe func(ep string) error) (lbARN, ep string, err error) {
+	// ALBs of other Ingress objects
+	known := make(map[string]struct{})
+	for _, arn := range md.cfg.ALBIngressController.ELBv2NameToARN {
+		known[arn] = struct{}{}
+	}
+
+	if err = md.kubectlSpec("apply", specPath); err != nil {
+		return "", "", err
+	}
+	md.lg.Info("created ingress", zap.String("name", name))
+
+	hc, err := HTTPClient(md.cfg)
+	if err != nil {
+		return "", "", err
+	}
+	cli := *hc
+	cli.Timeout = 5 * time.Second
+	scheme := "http://"
+	if md.cfg.ALBIngressController.EnableHTTPS {
+		scheme = "https://"
+	}
+
+	var dnsName string
+	retryStart := time.Now().UTC()
+	for time.Now().UTC().Sub(retryStart) < 15*time.Minute {
+		select {
+		case <-md.stopc:
+			return lbARN, "", fmt.Errorf("creating ingress %q interrupted", name)
+		case <-time.After(5 * time.Second):
+		}
+
+		if lbARN == "" {
+			lbARN, dnsName, err = md.findIngressLoadBalancer("default", name, known)
+			if err != nil {
+				md.lg.Warn("failed to find ALB", zap.String("name", name), zap.Error(err))
+				continue
+			}
+			if lbARN == "" {
+				continue
+			}
+			md.lg.Info("found ALB", zap.String("name", name), zap.String("arn", lbARN), zap.String("dns-name", dnsName))
+		}
+
+		healthy, herr := md.healthyTargets(lbARN)
+		if herr != nil {
+			md.lg.Warn("failed to describe target health", zap.String("name", name), zap.Error(herr))
+			continue
+		}
+		if !ready(healthy) {
+			md.lg.Info("waiting for healthy targets", zap.String("name", name), zap.Ints("healthy", healthy))
+			continue
+		}
+		if _, lerr := net.LookupHost(dnsName); lerr != nil {
+			continue
+		}
+		if probe == nil {
+			probe = func(ep string) error {
+				_, gerr := getPod(&cli, ep+path.Path)
+				return gerr
+			}
+		}
+		if perr := probe(scheme + dnsName); perr != nil {
+			md.lg.Info("waiting for ALB to serve traffic", zap.String("name", name), zap.Error(perr))
+			continue
+		}
+		md.lg.Info("ALB is ready", zap.String("name", name), zap.Ints("healthy", healthy))
+		return lbARN, scheme + dnsName, nil
+	}
+	return lbARN, "", fmt.Errorf("ALB for ingress %q not ready in time", name)
+}
+
+// healthyTargets returns the number of healthy targets
+// in each target group of the ALB.
+func (md *embedded) healthyTargets(lbARN string) (healthy []int, err error) {
+	to, err := md.elbv2.DescribeTargetGroups(&elbv2.DescribeTargetGroupsInput{
+		LoadBalancerArn: aws.String(lbARN),
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, tg := range to.TargetGroups {
+		ho, herr := md.elbv2.DescribeTargetHealth(&elbv2.DescribeTargetHealthInput{
+			TargetGroupArn: tg.TargetGroupArn,
+		})
+		if herr != nil {
+			return nil, herr
+		}
+		n := 0
+		for _, hv := range ho.TargetHealthDescriptions {
+			if hv.TargetHealth != nil && aws.StringValue(hv.TargetHealth.State) == elbv2.TargetHealthStateEnumHealthy {
+				n++
+			}
+		}
+		healthy = append(healthy, n)
+	}
+	return healthy, nil
+}
+
+// getPods sends requests to the endpoint,
+// and returns the pod name that served each request.
+func (md *embedded) getPods(cli *http.Client, ep string, n int) (pods []string, err error) {
+	for i := 0; i < n; i++ {
+		pod, gerr := getPod(cli, ep)
+		if gerr != nil {
+			md.lg.Warn("failed to get pod", zap.String("endpoint", ep), zap.Error(gerr))
+			err = gerr
+			continue
+		}
+		pods = append(pods, pod)
+	}
+	if len(pods) == 0 {
+		return nil, fmt.Errorf("no response from %q (%v)", ep, err)
+	}
+	return pods, nil
+}
+
+// getPod sends a request to the endpoint,
+// and returns the pod name that served the request.
+func getPod(cli *http.Client, ep string) (string, error) {
+	rs, err := cli.Get(ep)
+	if err != nil {
+		return "", err
+	}
+	ioutil.ReadAll(rs.Body)
+	rs.Body.Close()
+	if rs.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%q returned %q", ep, rs.Status)
+	}
+	pod := rs.Header.Get(server.HeaderPodName)
+	if pod == "" {
+		return "", fmt.Errorf("%q returned no %q header", ep, server.HeaderPodName)
+	}
+	return pod, nil
+}
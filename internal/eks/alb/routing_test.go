@@ -0,0 +1,73 @@
+package alb
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func Test_weightedForwardAction(t *testing.T) {
+	s, err := weightedForwardAction([]weightedTarget{
+		{ServiceName: "a", ServicePort: "80", Weight: 80},
+		{ServiceName: "b", ServicePort: "80", Weight: 20},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := `{"Type":"forward","ForwardConfig":{"TargetGroups":[{"ServiceName":"a","ServicePort":"80","Weight":80},{"ServiceName":"b","ServicePort":"80","Weight":20}]}}`
+	if s != exp {
+		t.Fatalf("expected %s, got %s", exp, s)
+	}
+	var v forwardAction
+	if err = json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = weightedForwardAction([]weightedTarget{{ServiceName: "a", ServicePort: "80", Weight: 100}}); err == nil {
+		t.Fatal("expected error for single service")
+	}
+}
+
+func Test_deploymentOfPod(t *testing.T) {
+	tests := []struct {
+		pod string
+		exp string
+	}{
+		{"ingress-test-server-5d8f9c7b6d-x2x8k", "ingress-test-server"},
+		{"ingress-test-server-weighted-5d8f9c7b6d-x2x8k", "ingress-test-server-weighted"},
+		{"localhost", "localhost"},
+	}
+	for i, tt := range tests {
+		if got := deploymentOfPod(tt.pod); got != tt.exp {
+			t.Fatalf("#%d: expected %q, got %q", i, tt.exp, got)
+		}
+	}
+}
+
+func Test_checkPinned(t *testing.T) {
+	if err := checkPinned(0, []string{"a", "a", "a"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkPinned(0, []string{"a", "b", "a"}); err == nil {
+		t.Fatal("expected error for multiple pods")
+	}
+	if err := checkPinned(0, nil); err == nil {
+		t.Fatal("expected error for no response")
+	}
+}
+
+func Test_checkWeightedRatio(t *testing.T) {
+	counts := map[string]int{"a": 780, "b": 220}
+	ratio, err := checkWeightedRatio(counts, "b", 0.2, 0.05)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ratio != 0.22 {
+		t.Fatalf("expected ratio 0.22, got %f", ratio)
+	}
+	if _, err = checkWeightedRatio(counts, "b", 0.5, 0.05); err == nil {
+		t.Fatal("expected error for ratio out of tolerance")
+	}
+	if _, err = checkWeightedRatio(nil, "b", 0.2, 0.05); err == nil {
+		t.Fatal("expected error for no response")
+	}
+}
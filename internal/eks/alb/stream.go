@@ -0,0 +1,61 @@
+package alb
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/eksconfig"
+	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress/client"
+)
+
+// addLoadBalancerAttributes appends the attributes to
+// "alb.ingress.kubernetes.io/load-balancer-attributes" annotation.
+func addLoadBalancerAttributes(a map[string]string, attrs string) {
+	const k = "alb.ingress.kubernetes.io/load-balancer-attributes"
+	if v, ok := a[k]; ok && v != "" {
+		a[k] = v + "," + attrs
+		return
+	}
+	a[k] = attrs
+}
+
+// idleTimeoutAttributes returns the ALB attributes with the idle timeout.
+func idleTimeoutAttributes(seconds int) string {
+	return fmt.Sprintf("idle_timeout.timeout_seconds=%d", seconds)
+}
+
+// checkStreams returns an error if the active connection did not
+// outlive the idle timeout, or if the idle connection was not closed
+// after the idle timeout.
+func checkStreams(active, expired client.StreamResult, idleTimeout time.Duration) error {
+	if active.Failures > 0 {
+		return fmt.Errorf("%s active connection failed %d messages (%s)", active.Protocol, active.Failures, active.Error)
+	}
+	if active.IdleClosed {
+		return fmt.Errorf("%s connection closed after %v idle, before idle timeout %v", active.Protocol, active.Idle, idleTimeout)
+	}
+	if active.Duration <= idleTimeout {
+		return fmt.Errorf("%s active connection duration %v does not exceed idle timeout %v", active.Protocol, active.Duration, idleTimeout)
+	}
+	if expired.Failures > 0 {
+		return fmt.Errorf("%s connection failed %d messages before idle (%s)", expired.Protocol, expired.Failures, expired.Error)
+	}
+	if !expired.IdleClosed {
+		return fmt.Errorf("%s connection not closed after %v idle, beyond idle timeout %v", expired.Protocol, expired.Idle, idleTimeout)
+	}
+	return nil
+}
+
+// toStreamTestResult converts the client stream result for the configuration.
+func toStreamTestResult(rs client.StreamResult) eksconfig.StreamTestResult {
+	return eksconfig.StreamTestResult{
+		Protocol:   rs.Protocol,
+		Idle:       rs.Idle,
+		Messages:   rs.Messages,
+		Failures:   rs.Failures,
+		LatencyP50: rs.Latency.P50,
+		LatencyP99: rs.Latency.P99,
+		Duration:   rs.Duration,
+		IdleClosed: rs.IdleClosed,
+	}
+}
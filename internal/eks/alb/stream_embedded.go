@@ -0,0 +1,220 @@
+package alb
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress"
+	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress/client"
+	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress/grpcecho"
+	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress/path"
+
+	"go.uber.org/zap"
+	"k8s.io/api/extensions/v1beta1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	webSocketIngressName = "ingress-for-websocket"
+	grpcIngressName      = "ingress-for-grpc"
+	// grpcHealthCheckPath is the default health check path of ALB gRPC
+	// target groups, which gRPC servers answer with "Unimplemented" (12).
+	grpcHealthCheckPath = "/AWS.ALB/healthcheck"
+	streamPayloadSize   = 1024
+	// streamIdleMargin is the idle duration beyond the idle timeout,
+	// to wait for ALB to close the idle connection.
+	streamIdleMargin = 15 * time.Second
+)
+
+// TestWebSocket creates an Ingress object with the stream idle timeout,
+// and verifies that an active WebSocket connection outlives the idle timeout,
+// while an idle connection is closed after the idle timeout.
+func (md *embedded) TestWebSocket() (err error) {
+	cfg := ingress.ConfigIngressTestServerIngressSpec{
+		MetadataName:      webSocketIngressName,
+		MetadataNamespace: "default",
+		IngressPaths: []v1beta1.HTTPIngressPath{
+			{
+				Path: path.Path,
+				Backend: v1beta1.IngressBackend{
+					ServiceName: "ingress-test-server-service",
+					ServicePort: intstr.IntOrString{Type: intstr.Int, IntVal: int32(80)},
+				},
+			},
+			{
+				Path: path.PathWebSocket,
+				Backend: v1beta1.IngressBackend{
+					ServiceName: "ingress-test-server-service",
+					ServicePort: intstr.IntOrString{Type: intstr.Int, IntVal: int32(80)},
+				},
+			},
+		},
+	}
+	cfg.Annotations, err = md.createALBAnnotations(path.Path)
+	if err != nil {
+		return err
+	}
+	addLoadBalancerAttributes(cfg.Annotations, idleTimeoutAttributes(md.cfg.ALBIngressController.StreamIdleTimeoutSeconds))
+	d, err := ingress.CreateIngressTestServerIngressSpec(cfg)
+	if err != nil {
+		return err
+	}
+	specPath := md.cfg.ALBIngressController.StreamIngressSpecPath
+	if err = ioutil.WriteFile(specPath, []byte(d), 0600); err != nil {
+		return err
+	}
+
+	lbARN, ep, err := md.createTestIngress(specPath, webSocketIngressName, func(healthy []int) bool {
+		return len(healthy) == 1 && healthy[0] > 0
+	}, nil)
+	defer func() {
+		if derr := md.deleteTestIngress(specPath, lbARN); derr != nil {
+			md.lg.Warn("failed to delete ingress for WebSocket", zap.Error(derr))
+			if err == nil {
+				err = derr
+			}
+		}
+	}()
+	if err != nil {
+		return err
+	}
+
+	wsURL, err := client.WebSocketURL(ep)
+	if err != nil {
+		return err
+	}
+	tlsCfg, err := TLSConfig(md.cfg)
+	if err != nil {
+		return err
+	}
+	return md.runStreamTests(client.ProtocolWebSocket, func(opts client.StreamOptions) (client.StreamResult, error) {
+		return client.RunWebSocket(md.lg, wsURL, tlsCfg, opts)
+	})
+}
+
+// TestGRPC creates an Ingress object with gRPC backend protocol version
+// to the ingress test server gRPC echo service, and runs the same tests
+// as "TestWebSocket" over a gRPC bidirectional stream.
+func (md *embedded) TestGRPC() (err error) {
+	cfg := ingress.ConfigIngressTestServerIngressSpec{
+		MetadataName:      grpcIngressName,
+		MetadataNamespace: "default",
+		IngressPaths: []v1beta1.HTTPIngressPath{
+			{
+				Path: grpcecho.PathPrefix + "*",
+				Backend: v1beta1.IngressBackend{
+					ServiceName: "ingress-test-server-service",
+					ServicePort: intstr.IntOrString{Type: intstr.Int, IntVal: int32(ingress.GRPCServicePort)},
+				},
+			},
+		},
+	}
+	cfg.Annotations, err = md.createALBAnnotations(grpcHealthCheckPath)
+	if err != nil {
+		return err
+	}
+	// gRPC target groups are only supported with HTTPS listeners
+	cfg.Annotations["alb.ingress.kubernetes.io/listen-ports"] = `[{"HTTPS": 443}]`
+	cfg.Annotations["alb.ingress.kubernetes.io/backend-protocol-version"] = "GRPC"
+	cfg.Annotations["alb.ingress.kubernetes.io/success-codes"] = "12"
+	addLoadBalancerAttributes(cfg.Annotations, idleTimeoutAttributes(md.cfg.ALBIngressController.StreamIdleTimeoutSeconds))
+	d, err := ingress.CreateIngressTestServerIngressSpec(cfg)
+	if err != nil {
+		return err
+	}
+	specPath := md.cfg.ALBIngressController.StreamIngressSpecPath
+	if err = ioutil.WriteFile(specPath, []byte(d), 0600); err != nil {
+		return err
+	}
+
+	tlsCfg, err := TLSConfig(md.cfg)
+	if err != nil {
+		return err
+	}
+	probe := func(ep string) error {
+		target, terr := client.GRPCTarget(ep)
+		if terr != nil {
+			return terr
+		}
+		cc, derr := grpcecho.Dial(context.Background(), target, tlsCfg, 10*time.Second)
+		if derr != nil {
+			return derr
+		}
+		defer cc.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_, eerr := grpcecho.Echo(ctx, cc, &grpcecho.Message{Payload: []byte("probe")})
+		return eerr
+	}
+	lbARN, ep, err := md.createTestIngress(specPath, grpcIngressName, func(healthy []int) bool {
+		return len(healthy) == 1 && healthy[0] > 0
+	}, probe)
+	defer func() {
+		if derr := md.deleteTestIngress(specPath, lbARN); derr != nil {
+			md.lg.Warn("failed to delete ingress for gRPC", zap.Error(derr))
+			if err == nil {
+				err = derr
+			}
+		}
+	}()
+	if err != nil {
+		return err
+	}
+
+	target, err := client.GRPCTarget(ep)
+	if err != nil {
+		return err
+	}
+	return md.runStreamTests(client.ProtocolGRPC, func(opts client.StreamOptions) (client.StreamResult, error) {
+		return client.RunGRPC(md.lg, target, tlsCfg, opts)
+	})
+}
+
+// runStreamTests runs an active connection with messages for longer than
+// the idle timeout, and an idle connection beyond the idle timeout.
+func (md *embedded) runStreamTests(protocol string, run func(opts client.StreamOptions) (client.StreamResult, error)) error {
+	idleTimeout := time.Duration(md.cfg.ALBIngressController.StreamIdleTimeoutSeconds) * time.Second
+	timeout := time.Duration(md.cfg.ALBIngressController.TestClientTimeoutSeconds) * time.Second
+
+	active, err := run(client.StreamOptions{
+		Messages:    md.cfg.ALBIngressController.StreamMessages,
+		Interval:    time.Duration(md.cfg.ALBIngressController.StreamIntervalSeconds) * time.Second,
+		Idle:        idleTimeout / 2,
+		PayloadSize: streamPayloadSize,
+		Timeout:     timeout,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to connect %s (%v)", protocol, err)
+	}
+	expired, err := run(client.StreamOptions{
+		Messages:    1,
+		Idle:        idleTimeout + streamIdleMargin,
+		PayloadSize: streamPayloadSize,
+		Timeout:     timeout,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to connect %s (%v)", protocol, err)
+	}
+
+	// results of other protocols, or of the other target type
+	rs := md.cfg.ALBIngressController.TestResultStreams[:0]
+	for _, r := range md.cfg.ALBIngressController.TestResultStreams {
+		if r.Protocol != protocol {
+			rs = append(rs, r)
+		}
+	}
+	md.cfg.ALBIngressController.TestResultStreams = append(rs, toStreamTestResult(active), toStreamTestResult(expired))
+	md.cfg.Sync()
+
+	md.lg.Info("tested ALB streams",
+		zap.String("protocol", protocol),
+		zap.Duration("idle-timeout", idleTimeout),
+		zap.Duration("active-duration", active.Duration),
+		zap.Duration("active-latency-p50", active.Latency.P50),
+		zap.Duration("active-latency-p99", active.Latency.P99),
+		zap.Bool("expired-idle-closed", expired.IdleClosed),
+	)
+	return checkStreams(active, expired, idleTimeout)
+}
@@ -0,0 +1,51 @@
+package alb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress/client"
+)
+
+func Test_addLoadBalancerAttributes(t *testing.T) {
+	a := map[string]string{}
+	addLoadBalancerAttributes(a, idleTimeoutAttributes(30))
+	if v := a["alb.ingress.kubernetes.io/load-balancer-attributes"]; v != "idle_timeout.timeout_seconds=30" {
+		t.Fatalf("unexpected attributes %q", v)
+	}
+	a["alb.ingress.kubernetes.io/load-balancer-attributes"] = "access_logs.s3.enabled=true"
+	addLoadBalancerAttributes(a, idleTimeoutAttributes(30))
+	if v := a["alb.ingress.kubernetes.io/load-balancer-attributes"]; v != "access_logs.s3.enabled=true,idle_timeout.timeout_seconds=30" {
+		t.Fatalf("unexpected attributes %q", v)
+	}
+}
+
+func Test_checkStreams(t *testing.T) {
+	idle := 30 * time.Second
+	active := client.StreamResult{Protocol: "websocket", Messages: 41, Duration: 55 * time.Second, Idle: 15 * time.Second}
+	expired := client.StreamResult{Protocol: "websocket", Messages: 2, Duration: time.Second, Idle: 45 * time.Second, IdleClosed: true}
+	if err := checkStreams(active, expired, idle); err != nil {
+		t.Fatal(err)
+	}
+
+	a := active
+	a.Failures, a.Error = 1, "reset"
+	if err := checkStreams(a, expired, idle); err == nil {
+		t.Fatal("expected error for active connection failure")
+	}
+	a = active
+	a.IdleClosed = true
+	if err := checkStreams(a, expired, idle); err == nil {
+		t.Fatal("expected error for connection closed before idle timeout")
+	}
+	a = active
+	a.Duration = 20 * time.Second
+	if err := checkStreams(a, expired, idle); err == nil {
+		t.Fatal("expected error for short connection duration")
+	}
+	e := expired
+	e.IdleClosed = false
+	if err := checkStreams(active, e, idle); err == nil {
+		t.Fatal("expected error for connection not closed after idle timeout")
+	}
+}
@@ -109,6 +109,18 @@ func (md *embedded) TestAWSResources() error {
 		}
 	}
 
+	// compare each ALB with its Ingress object spec
+	errs := md.validateAWSResources()
+	md.cfg.ALBIngressController.AWSResourcesFailures = nil
+	for _, err := range errs {
+		md.lg.Warn("ALB resource mismatch", zap.Error(err))
+		md.cfg.ALBIngressController.AWSResourcesFailures = append(md.cfg.ALBIngressController.AWSResourcesFailures, err.Error())
+	}
+	md.cfg.Sync()
+	if len(errs) > 0 {
+		return fmt.Errorf("found %d ALB resource mismatch(es) %v", len(errs), md.cfg.ALBIngressController.AWSResourcesFailures)
+	}
+
 	md.lg.Info(
 		"tested ALB resources",
 		zap.Int("elbv2-number", len(md.cfg.ALBIngressController.ELBv2NameToARN)),
@@ -0,0 +1,150 @@
+package alb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress"
+	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress/path"
+
+	"go.uber.org/zap"
+)
+
+const sidecarName = "alb-allowed-source-cidrs-sidecar"
+
+// TestAllowedSourceCIDRs deploys a sidecar pod, and verifies that
+// ALB refuses requests from the sidecar, whose egress IP is outside
+// the allowed source CIDRs of the ALB security group.
+func (md *embedded) TestAllowedSourceCIDRs() (err error) {
+	if len(md.cfg.ALBIngressController.AllowedSourceCIDRs) == 0 {
+		return errors.New("no allowed source CIDRs")
+	}
+	if md.cfg.AWSK8sTesterImage == "" {
+		return errors.New("empty AWSK8sTesterImage")
+	}
+
+	d, err := ingress.CreateDeploymentSidecar(ingress.ConfigDeploymentSidecar{
+		Name:      sidecarName,
+		Namespace: "default",
+		Image:     md.cfg.AWSK8sTesterImage,
+	})
+	if err != nil {
+		return err
+	}
+	if err = ioutil.WriteFile(md.cfg.ALBIngressController.SidecarSpecPath, []byte(d), 0600); err != nil {
+		return err
+	}
+	defer os.RemoveAll(md.cfg.ALBIngressController.SidecarSpecPath)
+
+	if err = md.kubectlSpec("apply", md.cfg.ALBIngressController.SidecarSpecPath); err != nil {
+		return err
+	}
+	defer func() {
+		if derr := md.kubectlSpec("delete", md.cfg.ALBIngressController.SidecarSpecPath); derr != nil {
+			md.lg.Warn("failed to delete sidecar", zap.Error(derr))
+		}
+	}()
+
+	pod, err := md.waitSidecar()
+	if err != nil {
+		return err
+	}
+
+	// sidecar must reach outside, otherwise ALB refusal cannot be told
+	// apart from sidecar networking failure
+	out, err := md.sidecarGet(pod, checkIPURL)
+	if err != nil {
+		return err
+	}
+	ip := strings.TrimSpace(out)
+	if _, err = parsePublicIP(ip); err != nil {
+		return fmt.Errorf("sidecar failed to detect its egress IP (%v)", err)
+	}
+	if cidrsContainIP(md.cfg.ALBIngressController.AllowedSourceCIDRs, ip) {
+		return fmt.Errorf("sidecar egress IP %q is within allowed source CIDRs %v", ip, md.cfg.ALBIngressController.AllowedSourceCIDRs)
+	}
+	md.lg.Info("sidecar egress IP is outside allowed source CIDRs",
+		zap.String("egress-ip", ip),
+		zap.Strings("cidrs", md.cfg.ALBIngressController.AllowedSourceCIDRs),
+	)
+
+	ep := Endpoint(md.cfg, "default")
+	if md.cfg.ALBIngressController.TestMode == "ingress-test-server" {
+		ep += path.Path
+	}
+	out, err = md.sidecarGet(pod, ep)
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(out, "failed") {
+		return fmt.Errorf("expected ALB %q to refuse sidecar request, got %q", ep, out)
+	}
+	md.lg.Info("ALB refused request from outside allowed source CIDRs",
+		zap.String("endpoint", ep),
+		zap.String("output", out),
+	)
+	return nil
+}
+
+// waitSidecar waits until the sidecar rollout completes,
+// and returns the sidecar pod name.
+func (md *embedded) waitSidecar() (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	cmd := md.kubectl.CommandContext(ctx,
+		md.kubectlPath,
+		"--kubeconfig="+md.cfg.KubeConfigPath,
+		"rollout", "status",
+		"deployment/"+sidecarName,
+		"--namespace=default",
+	)
+	kexo, err := cmd.CombinedOutput()
+	cancel()
+	if err != nil {
+		return "", fmt.Errorf("failed to roll out sidecar (%v, %q)", err, string(kexo))
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+	cmd = md.kubectl.CommandContext(ctx,
+		md.kubectlPath,
+		"--kubeconfig="+md.cfg.KubeConfigPath,
+		"get", "pods",
+		"--namespace=default",
+		"--selector=app="+sidecarName,
+		"--output=jsonpath={.items[0].metadata.name}",
+	)
+	kexo, err = cmd.CombinedOutput()
+	cancel()
+	if err != nil {
+		return "", fmt.Errorf("failed to get sidecar pod (%v, %q)", err, string(kexo))
+	}
+	pod := strings.TrimSpace(string(kexo))
+	if pod == "" {
+		return "", errors.New("sidecar pod not found")
+	}
+	md.lg.Info("sidecar is ready", zap.String("pod", pod))
+	return pod, nil
+}
+
+// sidecarGet sends HTTP GET to the target URL from the sidecar pod,
+// via Kubernetes API server proxy.
+func (md *embedded) sidecarGet(pod, target string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	cmd := md.kubectl.CommandContext(ctx,
+		md.kubectlPath,
+		"--kubeconfig="+md.cfg.KubeConfigPath,
+		"get", "--raw",
+		fmt.Sprintf("/api/v1/namespaces/default/pods/%s:%d/proxy/sidecar?target-url=%s", pod, ingress.SidecarPort, url.QueryEscape(target)),
+	)
+	kexo, err := cmd.CombinedOutput()
+	cancel()
+	if err != nil {
+		return "", fmt.Errorf("failed to send request via sidecar %q (%v, %q)", pod, err, string(kexo))
+	}
+	return string(kexo), nil
+}
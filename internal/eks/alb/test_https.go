@@ -0,0 +1,223 @@
+package alb
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/eksconfig"
+	alblog "github.com/aws/aws-k8s-tester/internal/alb-log"
+	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress/client"
+	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress/path"
+	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress/payload"
+
+	"go.uber.org/zap"
+)
+
+// Endpoint returns the ALB endpoint of the namespace, with "https://"
+// scheme if HTTPS listener is enabled.
+func Endpoint(cfg *eksconfig.Config, namespace string) string {
+	if cfg.ALBIngressController.EnableHTTPS {
+		return "https://" + cfg.ALBIngressController.ELBv2NamespaceToDNSName[namespace]
+	}
+	return "http://" + cfg.ALBIngressController.ELBv2NamespaceToDNSName[namespace]
+}
+
+// VerifyResponse returns the function to verify the responses from the
+// ALB endpoints. Ingress test server responses are verified with their
+// payload length and checksum headers, and nginx responses with the body.
+func VerifyResponse(cfg *eksconfig.Config) func(http.Header, []byte) error {
+	if cfg.ALBIngressController.TestMode == "ingress-test-server" {
+		return payload.VerifyStrict
+	}
+	exp := strings.Repeat("0", cfg.ALBIngressController.TestResponseSize)
+	return func(_ http.Header, d []byte) error {
+		if string(d) != exp {
+			return fmt.Errorf("expected %d bytes, got %d bytes", len(exp), len(d))
+		}
+		return nil
+	}
+}
+
+// HTTPClient returns the HTTP client for the ALB endpoints,
+// with the test client connection settings.
+// If HTTPS listener is enabled with an imported self-signed certificate,
+// the client only trusts that certificate.
+func HTTPClient(cfg *eksconfig.Config) (*http.Client, error) {
+	tlsCfg, err := TLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return client.NewHTTPClient(TransportOptions(cfg), tlsCfg), nil
+}
+
+// TLSConfig returns the TLS configuration for the ALB HTTPS listener,
+// or nil if HTTPS listener is not enabled.
+func TLSConfig(cfg *eksconfig.Config) (*tls.Config, error) {
+	if !cfg.ALBIngressController.EnableHTTPS {
+		return nil, nil
+	}
+	var certPEM []byte
+	if cfg.ALBIngressController.HTTPSCertificateImported {
+		var err error
+		certPEM, err = ioutil.ReadFile(cfg.ALBIngressController.HTTPSCertificatePath)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return newTLSConfig(certPEM)
+}
+
+// TransportOptions returns the test client connection settings.
+func TransportOptions(cfg *eksconfig.Config) client.TransportOptions {
+	return client.TransportOptions{
+		MaxConnsPerHost:  cfg.ALBIngressController.TestClientMaxConnsPerHost,
+		DisableKeepAlive: cfg.ALBIngressController.TestClientDisableKeepAlive,
+		HTTP2:            cfg.ALBIngressController.TestClientHTTP2,
+		Timeout:          time.Duration(cfg.ALBIngressController.TestClientTimeoutSeconds) * time.Second,
+	}
+}
+
+// TestHTTPS sends a request to the ALB HTTPS listener, and verifies that
+// the TLS protocol and cipher negotiated match the ALB access log.
+func (md *embedded) TestHTTPS() error {
+	if !md.cfg.ALBIngressController.EnableHTTPS {
+		return errors.New("HTTPS listener is not enabled")
+	}
+
+	cli, err := HTTPClient(md.cfg)
+	if err != nil {
+		return err
+	}
+	ep := Endpoint(md.cfg, "default")
+	if md.cfg.ALBIngressController.TestMode == "ingress-test-server" {
+		ep += path.Path
+	}
+	// unique user agent to find the request in access logs
+	userAgent := fmt.Sprintf("aws-k8s-tester-https-%d", time.Now().UTC().UnixNano())
+
+	var resp *http.Response
+	for i := 0; i < 30; i++ {
+		select {
+		case <-md.stopc:
+			return errors.New("HTTPS test aborted")
+		default:
+		}
+		var req *http.Request
+		req, err = http.NewRequest(http.MethodGet, ep, nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("User-Agent", userAgent)
+		resp, err = cli.Do(req)
+		if err == nil {
+			break
+		}
+		md.lg.Warn("HTTPS Get failed", zap.String("endpoint", ep), zap.Error(err))
+		time.Sleep(10 * time.Second)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to HTTPS Get %q (%v)", ep, err)
+	}
+	ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTPS Get %q returned %q", ep, resp.Status)
+	}
+	if resp.TLS == nil {
+		return fmt.Errorf("HTTPS Get %q returned no TLS connection state", ep)
+	}
+
+	md.cfg.ALBIngressController.TestResultSSLProtocol = alblog.TLSProtocol(resp.TLS.Version)
+	md.cfg.ALBIngressController.TestResultSSLCipher = alblog.TLSCipher(resp.TLS.CipherSuite)
+	md.cfg.Sync()
+	md.lg.Info("HTTPS Get success",
+		zap.String("endpoint", ep),
+		zap.String("ssl-protocol", md.cfg.ALBIngressController.TestResultSSLProtocol),
+		zap.String("ssl-cipher", md.cfg.ALBIngressController.TestResultSSLCipher),
+	)
+
+	if !md.cfg.LogAccess {
+		md.lg.Info("skipping ALB access log verification (access logs disabled)")
+		return nil
+	}
+
+	entry, err := md.waitAccessLog(userAgent)
+	if err != nil {
+		return err
+	}
+	md.lg.Info("found access log",
+		zap.String("type", entry.Type),
+		zap.String("ssl-protocol", entry.SSLProtocol),
+		zap.String("ssl-cipher", entry.SSLCipher),
+		zap.String("chosen-cert-arn", entry.ChosenCertARN),
+	)
+
+	if entry.Type != "https" {
+		return fmt.Errorf("expected access log type 'https', got %q", entry.Type)
+	}
+	if entry.SSLProtocol != md.cfg.ALBIngressController.TestResultSSLProtocol {
+		return fmt.Errorf("expected SSL protocol %q, got %q from access log", md.cfg.ALBIngressController.TestResultSSLProtocol, entry.SSLProtocol)
+	}
+	if entry.SSLCipher != md.cfg.ALBIngressController.TestResultSSLCipher {
+		return fmt.Errorf("expected SSL cipher %q, got %q from access log", md.cfg.ALBIngressController.TestResultSSLCipher, entry.SSLCipher)
+	}
+	return nil
+}
+
+// waitAccessLog waits until the access log entry of the request
+// with the user agent is delivered.
+func (md *embedded) waitAccessLog(userAgent string) (*alblog.Log, error) {
+	// ALB delivers access logs every 5 minutes
+	dir, err := ioutil.TempDir("", "alb-access-logs")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	var entry *alblog.Log
+	retryStart := time.Now().UTC()
+	for entry == nil && time.Now().UTC().Sub(retryStart) < 10*time.Minute {
+		select {
+		case <-md.stopc:
+			return nil, errors.New("access log wait aborted")
+		case <-time.After(time.Minute):
+		}
+
+		var ps []string
+		ps, err = md.s3Plugin.DownloadAccessLogs(dir)
+		if err != nil {
+			md.lg.Warn("failed to download access logs", zap.Error(err))
+			continue
+		}
+		entry = findLogByUserAgent(md.lg, ps, userAgent)
+		if entry == nil {
+			md.lg.Info("waiting for access log", zap.String("user-agent", userAgent), zap.Int("files", len(ps)))
+		}
+	}
+	if entry == nil {
+		return nil, fmt.Errorf("access log not found for user agent %q", userAgent)
+	}
+	return entry, nil
+}
+
+// findLogByUserAgent returns the first access log entry with the user agent.
+func findLogByUserAgent(lg *zap.Logger, ps []string, userAgent string) *alblog.Log {
+	for _, p := range ps {
+		logs, err := alblog.Parse(p)
+		if err != nil {
+			lg.Warn("failed to parse access log", zap.String("path", p), zap.Error(err))
+			continue
+		}
+		for i := range logs {
+			if logs[i].UserAgent == userAgent {
+				return &logs[i]
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package alb
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-k8s-tester/eksconfig"
+	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress/path"
+	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress/server"
+	"github.com/aws/aws-k8s-tester/pkg/httputil"
+
+	"go.uber.org/zap"
+)
+
+func TestVerifyResponse(t *testing.T) {
+	cfg := eksconfig.NewDefault()
+	cfg.ALBIngressController.TestMode = "ingress-test-server"
+	cfg.ALBIngressController.TestResponseSize = 1024
+
+	lg := zap.NewExample()
+	mux, err := server.NewMux(context.Background(), lg, 1, cfg.ALBIngressController.TestResponseSize, nil, server.NewHealth())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	// truncates the ingress test server response, keeping its headers
+	truncated := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		for k, vs := range rec.Header() {
+			w.Header()[k] = vs
+		}
+		w.Write(rec.Body.Bytes()[:512])
+	}))
+	defer truncated.Close()
+
+	// responds without payload headers (e.g. error page from load balancer)
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte(strings.Repeat("0", 1024)))
+	}))
+	defer plain.Close()
+
+	verify := VerifyResponse(cfg)
+	if !httputil.CheckGetVerify(lg, http.DefaultClient, ts.URL+path.Create(0), verify, 1, 0, nil) {
+		t.Fatal("expected ingress test server response to be verified")
+	}
+	if httputil.CheckGetVerify(lg, http.DefaultClient, truncated.URL+path.Create(0), verify, 1, 0, nil) {
+		t.Fatal("expected truncated response to fail")
+	}
+	if httputil.CheckGetVerify(lg, http.DefaultClient, plain.URL, verify, 1, 0, nil) {
+		t.Fatal("expected response without payload headers to fail")
+	}
+
+	cfg.ALBIngressController.TestMode = "nginx"
+	verify = VerifyResponse(cfg)
+	if !httputil.CheckGetVerify(lg, http.DefaultClient, plain.URL, verify, 1, 0, nil) {
+		t.Fatal("expected nginx response to be verified")
+	}
+	if httputil.CheckGetVerify(lg, http.DefaultClient, ts.URL+path.Create(0), verify, 1, 0, nil) {
+		t.Fatal("expected pseudo-random payload to fail nginx verification")
+	}
+}
@@ -0,0 +1,96 @@
+package eks
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/eksconfig"
+	"github.com/aws/aws-k8s-tester/internal/eks/alb"
+	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress/client"
+)
+
+// distributedClientArgs returns the "aws-k8s-tester eks ingress client" flags
+// for each ingress client pod, with the same load settings as the QPS test.
+func distributedClientArgs(cfg *eksconfig.Config) (args []string) {
+	if shards := cfg.ALBIngressController.IngressShards; len(shards) > 1 {
+		scheme := "http://"
+		if cfg.ALBIngressController.EnableHTTPS {
+			scheme = "https://"
+		}
+		for _, shard := range shards {
+			args = append(args, "--shard="+client.ShardFlag(scheme+shard.DNSName, shard.RouteStart, shard.RouteEnd))
+		}
+	} else {
+		args = append(args,
+			"--endpoint="+alb.Endpoint(cfg, "default"),
+			fmt.Sprintf("--routes=%d", cfg.ALBIngressController.TestServerRoutes),
+		)
+	}
+	args = append(args,
+		fmt.Sprintf("--max-conns-per-host=%d", cfg.ALBIngressController.TestClientMaxConnsPerHost),
+		fmt.Sprintf("--disable-keep-alive=%v", cfg.ALBIngressController.TestClientDisableKeepAlive),
+		fmt.Sprintf("--http2=%v", cfg.ALBIngressController.TestClientHTTP2),
+		fmt.Sprintf("--timeout=%s", time.Duration(cfg.ALBIngressController.TestClientTimeoutSeconds)*time.Second),
+	)
+	if cfg.ALBIngressController.EnableHTTPS {
+		// pods do not have the self-signed certificate,
+		// and ALB DNS name does not match the issued certificate
+		args = append(args, "--insecure-skip-verify=true")
+	}
+	if cfg.ALBIngressController.TestClientMethod != "" {
+		args = append(args, "--method="+cfg.ALBIngressController.TestClientMethod)
+	}
+	for _, h := range cfg.ALBIngressController.TestClientHeaders {
+		args = append(args, "--header="+h)
+	}
+	if cfg.ALBIngressController.TestClientBody != "" {
+		args = append(args, "--body="+cfg.ALBIngressController.TestClientBody)
+	}
+	args = append(args,
+		fmt.Sprintf("--clients=%d", cfg.ALBIngressController.TestClients),
+		fmt.Sprintf("--requests=%d", cfg.ALBIngressController.TestClientRequests),
+		fmt.Sprintf("--bucket-interval=%s", time.Duration(cfg.ALBIngressController.TestClientBucketSeconds)*time.Second),
+	)
+	if cfg.ALBIngressController.TestClientRate > 0 {
+		args = append(args,
+			fmt.Sprintf("--rate=%f", cfg.ALBIngressController.TestClientRate),
+			fmt.Sprintf("--rate-step=%f", cfg.ALBIngressController.TestClientRateStep),
+			fmt.Sprintf("--rate-step-interval=%s", time.Duration(cfg.ALBIngressController.TestClientRateStepSeconds)*time.Second),
+		)
+	}
+	if cfg.ALBIngressController.TestClientProfile != "" {
+		args = append(args,
+			"--profile="+cfg.ALBIngressController.TestClientProfile,
+			fmt.Sprintf("--profile-open-loop=%v", cfg.ALBIngressController.TestClientProfileOpenLoop),
+			fmt.Sprintf("--profile-duration=%s", time.Duration(cfg.ALBIngressController.TestClientProfileDurationSeconds)*time.Second),
+			fmt.Sprintf("--profile-start=%f", cfg.ALBIngressController.TestClientProfileStart),
+			fmt.Sprintf("--profile-peak=%f", cfg.ALBIngressController.TestClientProfilePeak),
+			fmt.Sprintf("--profile-steps=%d", cfg.ALBIngressController.TestClientProfileSteps),
+			fmt.Sprintf("--profile-spike-start=%s", time.Duration(cfg.ALBIngressController.TestClientProfileSpikeStartSeconds)*time.Second),
+			fmt.Sprintf("--profile-spike-duration=%s", time.Duration(cfg.ALBIngressController.TestClientProfileSpikeSeconds)*time.Second),
+		)
+	}
+	return args
+}
+
+// distributedClientTimeout returns the timeout for ingress client pods
+// to finish sending requests after the start barrier.
+func distributedClientTimeout(cfg *eksconfig.Config) time.Duration {
+	timeout := 30 * time.Minute
+	if cfg.ALBIngressController.TestClientProfile != "" {
+		timeout = time.Duration(cfg.ALBIngressController.TestClientProfileDurationSeconds)*time.Second + 10*time.Minute
+	}
+	return timeout
+}
+
+// ALBDistributedQPSMetrics returns the merged QPS, latencies and failures
+// of the distributed QPS test, for test result metrics.
+func ALBDistributedQPSMetrics(cfg *eksconfig.ALBIngressController) map[string]float64 {
+	return map[string]float64{
+		"distributed-clients":             float64(cfg.TestDistributedClients),
+		"distributed-qps":                 cfg.TestResultDistributedQPS,
+		"distributed-latency-p50-seconds": cfg.TestResultDistributedLatencyP50.Seconds(),
+		"distributed-latency-p99-seconds": cfg.TestResultDistributedLatencyP99.Seconds(),
+		"distributed-failures":            float64(cfg.TestResultDistributedFailures),
+	}
+}
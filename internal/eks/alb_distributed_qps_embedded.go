@@ -0,0 +1,67 @@
+package eks
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress/client"
+
+	"go.uber.org/zap"
+)
+
+// TestALBDistributedQPS runs the ingress client in "TestDistributedClients"
+// pods, started together by the start barrier, and merges their results
+// into one report with percentiles from the merged latency histograms.
+func (md *embedded) TestALBDistributedQPS() error {
+	if !md.cfg.ALBIngressController.Enable || !md.cfg.ALBIngressController.Created {
+		return fmt.Errorf("ALB Ingress Controller is not created for %q", md.cfg.ClusterName)
+	}
+	if md.cfg.ALBIngressController.TestDistributedClients == 0 {
+		return fmt.Errorf("ALB distributed QPS test is not enabled for %q", md.cfg.ClusterName)
+	}
+
+	logs, err := md.albPlugin.RunDistributedClients(
+		md.cfg.ALBIngressController.TestDistributedClients,
+		distributedClientArgs(md.cfg),
+		distributedClientTimeout(md.cfg),
+	)
+	if err != nil {
+		return err
+	}
+	rs := make([]client.TestResult, 0, len(logs))
+	for i, l := range logs {
+		r, rerr := client.ReadResult(l)
+		if rerr != nil {
+			md.lg.Warn("failed to read ingress client result", zap.Int("pod", i), zap.String("logs", l))
+			return fmt.Errorf("failed to read ingress client pod %d result (%v)", i, rerr)
+		}
+		rs = append(rs, r)
+	}
+	merged := client.Merge(rs)
+
+	fmt.Printf("TestALBDistributedQPS Result:\n\n%s\n\n", merged.Result)
+
+	d, err := merged.JSON()
+	if err != nil {
+		return err
+	}
+	if err = ioutil.WriteFile(md.cfg.ALBIngressController.DistributedQPSOutputToUploadPath, d, 0600); err != nil {
+		return err
+	}
+	if md.cfg.ALBIngressController.UploadTesterLogs {
+		if err = md.uploadALBTesterLogs(); err != nil {
+			md.lg.Warn("failed to upload ALB", zap.Error(err))
+		}
+	}
+
+	md.cfg.ALBIngressController.TestResultDistributedQPS = merged.QPS
+	md.cfg.ALBIngressController.TestResultDistributedFailures = merged.Failure
+	md.cfg.ALBIngressController.TestResultDistributedLatencyP50 = merged.LatencyP50
+	md.cfg.ALBIngressController.TestResultDistributedLatencyP99 = merged.LatencyP99
+	md.cfg.Sync()
+
+	if merged.Failure > md.cfg.ALBIngressController.TestClientErrorThreshold {
+		return fmt.Errorf("expected failures under threshold %d, got %d", md.cfg.ALBIngressController.TestClientErrorThreshold, merged.Failure)
+	}
+	return nil
+}
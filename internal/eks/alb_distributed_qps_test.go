@@ -0,0 +1,61 @@
+package eks
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/eksconfig"
+)
+
+func Test_distributedClientArgs(t *testing.T) {
+	cfg := &eksconfig.Config{ALBIngressController: &eksconfig.ALBIngressController{
+		TestServerRoutes:                 3,
+		TestClients:                      100,
+		TestClientRequests:               5000,
+		TestClientBucketSeconds:          10,
+		TestClientProfile:                "ramp",
+		TestClientProfileDurationSeconds: 300,
+		TestClientProfilePeak:            50,
+		TestClientDisableKeepAlive:       true,
+		TestClientTimeoutSeconds:         30,
+		TestClientHeaders:                []string{"X-Test: a"},
+		ELBv2NamespaceToDNSName:          map[string]string{"default": "a.us-west-2.elb.amazonaws.com"},
+	}}
+	args := strings.Join(distributedClientArgs(cfg), " ")
+	for _, s := range []string{
+		"--endpoint=http://a.us-west-2.elb.amazonaws.com",
+		"--routes=3",
+		"--clients=100",
+		"--bucket-interval=10s",
+		"--profile=ramp",
+		"--profile-duration=5m0s",
+		"--disable-keep-alive=true",
+		"--timeout=30s",
+		"--header=X-Test: a",
+	} {
+		if !strings.Contains(args, s) {
+			t.Fatalf("expected %q in %q", s, args)
+		}
+	}
+	if strings.Contains(args, "--rate=") || strings.Contains(args, "--shard=") || strings.Contains(args, "--insecure-skip-verify") {
+		t.Fatalf("unexpected flags %q", args)
+	}
+	if d := distributedClientTimeout(cfg); d != 15*time.Minute {
+		t.Fatalf("unexpected timeout %v", d)
+	}
+
+	cfg.ALBIngressController.IngressShards = []eksconfig.IngressShard{
+		{DNSName: "a.us-west-2.elb.amazonaws.com", RouteStart: 0, RouteEnd: 2},
+		{DNSName: "b.us-west-2.elb.amazonaws.com", RouteStart: 2, RouteEnd: 3},
+	}
+	args = strings.Join(distributedClientArgs(cfg), " ")
+	if !strings.Contains(args, "--shard=http://b.us-west-2.elb.amazonaws.com=2-3") || strings.Contains(args, "--endpoint=") {
+		t.Fatalf("unexpected sharded flags %q", args)
+	}
+
+	metrics := ALBDistributedQPSMetrics(&eksconfig.ALBIngressController{TestDistributedClients: 5, TestResultDistributedQPS: 1000, TestResultDistributedFailures: 3})
+	if metrics["distributed-qps"] != 1000 || metrics["distributed-failures"] != 3 || metrics["distributed-clients"] != 5 {
+		t.Fatalf("unexpected metrics %v", metrics)
+	}
+}
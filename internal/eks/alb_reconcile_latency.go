@@ -0,0 +1,111 @@
+package eks
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/eksconfig"
+)
+
+// reconcilePhases is the list of reconcile phases in order,
+// with the timestamp of each phase.
+var reconcilePhases = []struct {
+	name string
+	fn   func(r eksconfig.ReconcileLatency) time.Time
+}{
+	{"alb-created", func(r eksconfig.ReconcileLatency) time.Time { return r.ALBCreated }},
+	{"listener-rules-created", func(r eksconfig.ReconcileLatency) time.Time { return r.ListenerRulesCreated }},
+	{"target-healthy", func(r eksconfig.ReconcileLatency) time.Time { return r.TargetHealthy }},
+	{"dns-resolvable", func(r eksconfig.ReconcileLatency) time.Time { return r.DNSResolvable }},
+	{"first-success", func(r eksconfig.ReconcileLatency) time.Time { return r.FirstSuccess }},
+}
+
+// reconcilePhaseLatency is the latency percentiles of a reconcile phase,
+// measured from Ingress object creation.
+type reconcilePhaseLatency struct {
+	phase string
+	runs  int
+	p50   time.Duration
+	p90   time.Duration
+	p99   time.Duration
+	max   time.Duration
+}
+
+// reconcileLatencyPercentiles computes the latency percentiles of each phase.
+// Runs that did not reach the phase are excluded.
+func reconcileLatencyPercentiles(rs []eksconfig.ReconcileLatency) (ls []reconcilePhaseLatency) {
+	for _, ph := range reconcilePhases {
+		var ds []time.Duration
+		for _, r := range rs {
+			ts := ph.fn(r)
+			if ts.IsZero() || r.IngressCreated.IsZero() {
+				continue
+			}
+			ds = append(ds, ts.Sub(r.IngressCreated))
+		}
+		sort.Slice(ds, func(i, j int) bool { return ds[i] < ds[j] })
+		l := reconcilePhaseLatency{phase: ph.name, runs: len(ds)}
+		if len(ds) > 0 {
+			l.p50 = durationPercentile(ds, 0.5)
+			l.p90 = durationPercentile(ds, 0.9)
+			l.p99 = durationPercentile(ds, 0.99)
+			l.max = ds[len(ds)-1]
+		}
+		ls = append(ls, l)
+	}
+	return ls
+}
+
+// durationPercentile returns the p-th percentile (0 < p <= 1) of the sorted durations.
+func durationPercentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	return sorted[idx]
+}
+
+// reconcileLatencyReport writes the latency percentiles of each phase
+// from Ingress object creation, followed by the errors of failed runs.
+func reconcileLatencyReport(rs []eksconfig.ReconcileLatency) string {
+	buf := new(bytes.Buffer)
+	tw := tabwriter.NewWriter(buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join([]string{"PHASE", "RUNS", "P50", "P90", "P99", "MAX"}, "\t"))
+	for _, l := range reconcileLatencyPercentiles(rs) {
+		fmt.Fprintln(tw, strings.Join([]string{
+			l.phase,
+			fmt.Sprintf("%d/%d", l.runs, len(rs)),
+			l.p50.String(),
+			l.p90.String(),
+			l.p99.String(),
+			l.max.String(),
+		}, "\t"))
+	}
+	tw.Flush()
+
+	for _, r := range rs {
+		if r.Error != "" {
+			fmt.Fprintf(buf, "\n[%s] %s", r.IngressName, r.Error)
+		}
+	}
+	return buf.String()
+}
+
+// ReconcileLatencyMetrics returns the p50 and p99 latencies
+// of each reconcile phase in milliseconds, for test result metrics.
+func ReconcileLatencyMetrics(rs []eksconfig.ReconcileLatency) map[string]float64 {
+	metrics := make(map[string]float64)
+	for _, l := range reconcileLatencyPercentiles(rs) {
+		metrics[l.phase+"-p50-ms"] = l.p50.Seconds() * 1000
+		metrics[l.phase+"-p99-ms"] = l.p99.Seconds() * 1000
+	}
+	return metrics
+}
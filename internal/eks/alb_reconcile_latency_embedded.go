@@ -0,0 +1,78 @@
+package eks
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/aws/aws-k8s-tester/eksconfig"
+
+	"go.uber.org/zap"
+)
+
+// TestALBReconcileLatency creates and deletes a separate Ingress object
+// "TestReconcileLatencyRuns" times, and writes the latency percentiles
+// of each reconcile phase from Ingress object creation.
+func (md *embedded) TestALBReconcileLatency() error {
+	if !md.cfg.ALBIngressController.Enable || !md.cfg.ALBIngressController.Created {
+		return fmt.Errorf("ALB Ingress Controller is not created for %q", md.cfg.ClusterName)
+	}
+	if md.cfg.ALBIngressController.TestReconcileLatencyRuns == 0 {
+		return fmt.Errorf("ALB reconcile latency test runs is not specified for %q", md.cfg.ClusterName)
+	}
+
+	var rs []eksconfig.ReconcileLatency
+	failed := 0
+	for i := 0; i < md.cfg.ALBIngressController.TestReconcileLatencyRuns; i++ {
+		name := fmt.Sprintf("ingress-reconcile-latency-%d", i)
+		md.lg.Info("measuring reconcile latency",
+			zap.String("name", name),
+			zap.Int("run", i+1),
+			zap.Int("runs", md.cfg.ALBIngressController.TestReconcileLatencyRuns),
+		)
+		r, err := md.albPlugin.MeasureReconcileLatency(name)
+		if err != nil {
+			md.lg.Warn("failed to measure reconcile latency", zap.String("name", name), zap.Error(err))
+			if r.Error == "" {
+				r.Error = err.Error()
+			}
+			failed++
+		}
+		rs = append(rs, r)
+		md.cfg.ALBIngressController.ReconcileLatencies = rs
+		md.cfg.Sync()
+
+		select {
+		case <-md.stopc:
+			md.saveALBReconcileLatencies(rs)
+			return fmt.Errorf("reconcile latency test interrupted after %d run(s)", len(rs))
+		default:
+		}
+	}
+
+	if err := md.saveALBReconcileLatencies(rs); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d out of %d reconcile latency run(s) failed", failed, len(rs))
+	}
+	return nil
+}
+
+// saveALBReconcileLatencies writes the reconcile latency report file.
+func (md *embedded) saveALBReconcileLatencies(rs []eksconfig.ReconcileLatency) error {
+	report := reconcileLatencyReport(rs)
+	fmt.Printf("TestALBReconcileLatency Result:\n\n%s\n\n", report)
+	if err := ioutil.WriteFile(
+		md.cfg.ALBIngressController.ReconcileLatencyOutputToUploadPath,
+		[]byte(report),
+		0600,
+	); err != nil {
+		return err
+	}
+	if md.cfg.ALBIngressController.UploadTesterLogs {
+		if err := md.uploadALBTesterLogs(); err != nil {
+			md.lg.Warn("failed to upload ALB", zap.Error(err))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package eks
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/eksconfig"
+)
+
+func Test_reconcileLatencyPercentiles(t *testing.T) {
+	now := time.Now().UTC()
+	var rs []eksconfig.ReconcileLatency
+	for i := 1; i <= 10; i++ {
+		d := time.Duration(i) * time.Second
+		rs = append(rs, eksconfig.ReconcileLatency{
+			IngressName:          "ingress-reconcile-latency",
+			IngressCreated:       now,
+			ALBCreated:           now.Add(d),
+			ListenerRulesCreated: now.Add(2 * d),
+			TargetHealthy:        now.Add(3 * d),
+			DNSResolvable:        now.Add(4 * d),
+			FirstSuccess:         now.Add(5 * d),
+		})
+	}
+	// failed run that never became healthy
+	rs = append(rs, eksconfig.ReconcileLatency{
+		IngressName:    "ingress-reconcile-latency-failed",
+		IngressCreated: now,
+		ALBCreated:     now.Add(time.Second),
+		Error:          "timed out",
+	})
+
+	ls := reconcileLatencyPercentiles(rs)
+	if len(ls) != len(reconcilePhases) {
+		t.Fatalf("expected %d phases, got %d", len(reconcilePhases), len(ls))
+	}
+	if ls[0].phase != "alb-created" || ls[0].runs != 11 {
+		t.Fatalf("unexpected %+v", ls[0])
+	}
+	fs := ls[len(ls)-1]
+	if fs.phase != "first-success" || fs.runs != 10 {
+		t.Fatalf("unexpected %+v", fs)
+	}
+	if fs.p50 != 25*time.Second || fs.p90 != 45*time.Second || fs.p99 != 50*time.Second || fs.max != 50*time.Second {
+		t.Fatalf("unexpected %+v", fs)
+	}
+
+	s := reconcileLatencyReport(rs)
+	for _, exp := range []string{"PHASE", "first-success", "10/11", "[ingress-reconcile-latency-failed] timed out"} {
+		if !strings.Contains(s, exp) {
+			t.Fatalf("expected %q in report:\n%s", exp, s)
+		}
+	}
+}
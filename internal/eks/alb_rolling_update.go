@@ -0,0 +1,120 @@
+package eks
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/eksconfig"
+	alblog "github.com/aws/aws-k8s-tester/internal/alb-log"
+)
+
+// accessLogELBName returns the "elb" field of ALB access log from the ALB ARN.
+// e.g. "arn:aws:elasticloadbalancing:us-west-2:123456789012:loadbalancer/app/my-alb/50dc6c495c0c9188"
+// is logged as "app/my-alb/50dc6c495c0c9188".
+func accessLogELBName(lbARN string) string {
+	idx := strings.Index(lbARN, ":loadbalancer/")
+	if idx < 0 {
+		return lbARN
+	}
+	return lbARN[idx+len(":loadbalancer/"):]
+}
+
+// countELBStatusCodes returns the number of HTTP 502 and 503 responses
+// from the ALB, logged within the time range.
+func countELBStatusCodes(logs []alblog.Log, elb string, start, end time.Time) (n502, n503 int) {
+	for _, l := range logs {
+		if l.ELB != elb {
+			continue
+		}
+		ts, err := time.Parse(time.RFC3339Nano, l.Timestamp)
+		if err != nil || ts.Before(start) || ts.After(end) {
+			continue
+		}
+		switch l.ELBStatusCode {
+		case "502":
+			n502++
+		case "503":
+			n503++
+		}
+	}
+	return n502, n503
+}
+
+// latestLogTimestamp returns the latest timestamp of the ALB access logs.
+func latestLogTimestamp(logs []alblog.Log, elb string) (latest time.Time) {
+	for _, l := range logs {
+		if l.ELB != elb {
+			continue
+		}
+		ts, err := time.Parse(time.RFC3339Nano, l.Timestamp)
+		if err == nil && ts.After(latest) {
+			latest = ts
+		}
+	}
+	return latest
+}
+
+// rollingUpdateReport writes the result of each rolling update run,
+// followed by the errors of failed runs.
+func rollingUpdateReport(rs []eksconfig.RollingUpdateResult) string {
+	buf := new(bytes.Buffer)
+	tw := tabwriter.NewWriter(buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join([]string{"DEREGISTRATION-DELAY", "READINESS-GATE", "DRAIN", "REQUESTS", "FAILURES", "ELB-502", "ELB-503", "TIME-TO-STABLE"}, "\t"))
+	for _, r := range rs {
+		fmt.Fprintln(tw, strings.Join([]string{
+			(time.Duration(r.DeregistrationDelaySeconds) * time.Second).String(),
+			strconv.FormatBool(r.ReadinessGate),
+			(time.Duration(r.DrainSeconds) * time.Second).String(),
+			strconv.FormatInt(r.Requests, 10),
+			strconv.FormatInt(r.Failures, 10),
+			elbCount(r.ELB502),
+			elbCount(r.ELB503),
+			r.TimeToStable,
+		}, "\t"))
+	}
+	tw.Flush()
+
+	for _, r := range rs {
+		if r.Error != "" {
+			fmt.Fprintf(buf, "\n[deregistration-delay %ds, readiness-gate %v] %s", r.DeregistrationDelaySeconds, r.ReadinessGate, r.Error)
+		}
+	}
+	return buf.String()
+}
+
+func elbCount(n int) string {
+	if n < 0 {
+		return "n/a"
+	}
+	return strconv.Itoa(n)
+}
+
+// RollingUpdateMetrics returns the failures, ELB 502/503 counts
+// and time-to-stable in seconds of each run, for test result metrics.
+func RollingUpdateMetrics(rs []eksconfig.RollingUpdateResult) map[string]float64 {
+	metrics := make(map[string]float64)
+	for _, r := range rs {
+		pfx := fmt.Sprintf("delay-%ds", r.DeregistrationDelaySeconds)
+		if r.ReadinessGate {
+			pfx += "-readiness-gate"
+		}
+		if r.DrainSeconds > 0 {
+			pfx += fmt.Sprintf("-drain-%ds", r.DrainSeconds)
+		}
+		metrics[pfx+"-failures"] = float64(r.Failures)
+		if r.ELB502 >= 0 {
+			metrics[pfx+"-elb-502"] = float64(r.ELB502)
+		}
+		if r.ELB503 >= 0 {
+			metrics[pfx+"-elb-503"] = float64(r.ELB503)
+		}
+		if !r.Stable.IsZero() {
+			metrics[pfx+"-time-to-stable-seconds"] = r.Stable.Sub(r.Started).Seconds()
+		}
+	}
+	return metrics
+}
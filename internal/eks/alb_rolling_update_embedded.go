@@ -0,0 +1,233 @@
+package eks
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/eksconfig"
+	alblog "github.com/aws/aws-k8s-tester/internal/alb-log"
+	"github.com/aws/aws-k8s-tester/internal/eks/alb"
+	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress/client"
+	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress/path"
+
+	"go.uber.org/zap"
+)
+
+// TestALBRollingUpdate rolls the ingress test server Deployment in "default"
+// namespace with each deregistration delay (and readiness gate if enabled)
+// while sending requests, and writes failed requests, ALB 502/503 counts
+// from access logs and time-to-stable of each run.
+func (md *embedded) TestALBRollingUpdate() error {
+	if !md.cfg.ALBIngressController.Enable || !md.cfg.ALBIngressController.Created {
+		return fmt.Errorf("ALB Ingress Controller is not created for %q", md.cfg.ClusterName)
+	}
+	if len(md.cfg.ALBIngressController.TestRollingUpdateDeregistrationDelays) == 0 {
+		return fmt.Errorf("ALB rolling update deregistration delays are not specified for %q", md.cfg.ClusterName)
+	}
+	gates := []bool{false}
+	if md.cfg.ALBIngressController.TestRollingUpdateReadinessGate {
+		gates = append(gates, true)
+	}
+
+	var rs []eksconfig.RollingUpdateResult
+	failed := 0
+	for _, delay := range md.cfg.ALBIngressController.TestRollingUpdateDeregistrationDelays {
+		for _, gate := range gates {
+			md.lg.Info("testing rolling update",
+				zap.Int("deregistration-delay-seconds", delay),
+				zap.Bool("readiness-gate", gate),
+			)
+			r, err := md.rollALBTestServer(delay, gate)
+			if err != nil {
+				md.lg.Warn("failed rolling update", zap.Error(err))
+				if r.Error == "" {
+					r.Error = err.Error()
+				}
+				failed++
+			}
+			rs = append(rs, r)
+			md.cfg.ALBIngressController.RollingUpdateResults = rs
+			md.cfg.Sync()
+
+			select {
+			case <-md.stopc:
+				md.saveALBRollingUpdateResults(rs)
+				return fmt.Errorf("rolling update test interrupted after %d run(s)", len(rs))
+			default:
+			}
+		}
+	}
+
+	if err := md.albPlugin.ResetRollingUpdate(); err != nil {
+		md.lg.Warn("failed to reset rolling update", zap.Error(err))
+	}
+
+	if md.cfg.LogAccess {
+		if err := md.countALBRollingUpdateStatusCodes(rs); err != nil {
+			md.lg.Warn("failed to count ELB status codes", zap.Error(err))
+		}
+		md.cfg.ALBIngressController.RollingUpdateResults = rs
+		md.cfg.Sync()
+	}
+
+	if err := md.saveALBRollingUpdateResults(rs); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d out of %d rolling update run(s) failed", failed, len(rs))
+	}
+	return nil
+}
+
+// rollALBTestServer sends requests to the ALB in "default" namespace
+// until the rolling update completes and all targets are stable.
+func (md *embedded) rollALBTestServer(delay int, gate bool) (r eksconfig.RollingUpdateResult, err error) {
+	cli, err := md.newALBDefaultClient()
+	if err != nil {
+		r.DeregistrationDelaySeconds, r.ReadinessGate = delay, gate
+		return r, err
+	}
+	donec := make(chan client.TestResult)
+	go func() {
+		donec <- cli.Run()
+	}()
+
+	// establish steady traffic before rolling update
+	md.lg.Info("waiting for steady traffic", zap.Duration("wait", 15*time.Second))
+	time.Sleep(15 * time.Second)
+
+	r, err = md.albPlugin.RollTestServer(delay, gate)
+
+	cli.Stop()
+	rs := <-donec
+	r.Requests = rs.Success + rs.Failure
+	r.Failures = rs.Failure
+	r.ELB502, r.ELB503 = -1, -1
+	md.lg.Info("tested rolling update",
+		zap.Int("deregistration-delay-seconds", r.DeregistrationDelaySeconds),
+		zap.Bool("readiness-gate", r.ReadinessGate),
+		zap.Int64("requests", r.Requests),
+		zap.Int64("failures", r.Failures),
+		zap.String("time-to-stable", r.TimeToStable),
+	)
+	return r, err
+}
+
+// newALBDefaultClient creates the ingress test server client
+// that sends requests only to the ALB in "default" namespace.
+func (md *embedded) newALBDefaultClient() (cli *client.Client, err error) {
+	if len(md.cfg.ALBIngressController.IngressShards) == 0 {
+		return nil, errors.New("no Ingress object found in default namespace")
+	}
+	shard := md.cfg.ALBIngressController.IngressShards[0]
+	tg := client.Target{
+		Endpoint: alb.Endpoint(md.cfg, "default"),
+		Routes:   []string{path.Path},
+	}
+	for i := shard.RouteStart; i < shard.RouteEnd; i++ {
+		tg.Routes = append(tg.Routes, path.Create(i))
+	}
+	cli, err = client.NewSharded(
+		md.lg,
+		[]client.Target{tg},
+		md.cfg.ALBIngressController.TestClients,
+		math.MaxInt32,
+	)
+	if err != nil {
+		return nil, err
+	}
+	cli.HTTPClient, err = alb.HTTPClient(md.cfg)
+	if err != nil {
+		return nil, err
+	}
+	if md.cfg.ALBIngressController.TestMode == "ingress-test-server" {
+		// missing payload headers count as integrity errors
+		cli.Verify = alb.VerifyResponse(md.cfg)
+	}
+	return cli, nil
+}
+
+// countALBRollingUpdateStatusCodes waits until ALB access logs
+// are delivered for all runs, and counts 502/503 responses of each run.
+func (md *embedded) countALBRollingUpdateStatusCodes(rs []eksconfig.RollingUpdateResult) error {
+	shard := md.cfg.ALBIngressController.IngressShards[0]
+	elb := accessLogELBName(md.cfg.ALBIngressController.ELBv2NameToARN[shard.ELBv2Name])
+
+	var last time.Time
+	for _, r := range rs {
+		end := r.Stable
+		if end.IsZero() {
+			end = r.Started
+		}
+		if end.After(last) {
+			last = end
+		}
+	}
+
+	dir, err := ioutil.TempDir("", "alb-access-logs")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	// ALB delivers access logs every 5 minutes
+	var logs []alblog.Log
+	retryStart := time.Now().UTC()
+	for time.Now().UTC().Sub(retryStart) < 15*time.Minute {
+		select {
+		case <-md.stopc:
+			return errors.New("counting ELB status codes aborted")
+		case <-time.After(time.Minute):
+		}
+
+		ps, derr := md.s3Plugin.DownloadAccessLogs(dir)
+		if derr != nil {
+			md.lg.Warn("failed to download access logs", zap.Error(derr))
+			continue
+		}
+		logs = logs[:0]
+		for _, p := range ps {
+			ls, perr := alblog.Parse(p)
+			if perr != nil {
+				md.lg.Warn("failed to parse access log", zap.String("path", p), zap.Error(perr))
+				continue
+			}
+			logs = append(logs, ls...)
+		}
+		if latest := latestLogTimestamp(logs, elb); !latest.Before(last) {
+			for i := range rs {
+				end := rs[i].Stable
+				if end.IsZero() {
+					end = time.Now().UTC()
+				}
+				rs[i].ELB502, rs[i].ELB503 = countELBStatusCodes(logs, elb, rs[i].Started, end)
+			}
+			return nil
+		}
+		md.lg.Info("waiting for access logs", zap.String("elb", elb), zap.Time("until", last))
+	}
+	return fmt.Errorf("access logs of %q not delivered in time", elb)
+}
+
+// saveALBRollingUpdateResults writes the rolling update report file.
+func (md *embedded) saveALBRollingUpdateResults(rs []eksconfig.RollingUpdateResult) error {
+	report := rollingUpdateReport(rs)
+	fmt.Printf("TestALBRollingUpdate Result:\n\n%s\n\n", report)
+	if err := ioutil.WriteFile(
+		md.cfg.ALBIngressController.RollingUpdateOutputToUploadPath,
+		[]byte(report),
+		0600,
+	); err != nil {
+		return err
+	}
+	if md.cfg.ALBIngressController.UploadTesterLogs {
+		if err := md.uploadALBTesterLogs(); err != nil {
+			md.lg.Warn("failed to upload ALB", zap.Error(err))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package eks
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/eksconfig"
+	alblog "github.com/aws/aws-k8s-tester/internal/alb-log"
+)
+
+func Test_accessLogELBName(t *testing.T) {
+	arn := "arn:aws:elasticloadbalancing:us-west-2:123456789012:loadbalancer/app/my-alb/50dc6c495c0c9188"
+	if s := accessLogELBName(arn); s != "app/my-alb/50dc6c495c0c9188" {
+		t.Fatalf("unexpected ELB name %q", s)
+	}
+}
+
+func Test_countELBStatusCodes(t *testing.T) {
+	start := time.Date(2018, 11, 1, 10, 0, 0, 0, time.UTC)
+	end := start.Add(time.Minute)
+	logs := []alblog.Log{
+		{ELB: "app/a/1", Timestamp: "2018-11-01T10:00:10.000000Z", ELBStatusCode: "502"},
+		{ELB: "app/a/1", Timestamp: "2018-11-01T10:00:20.000000Z", ELBStatusCode: "503"},
+		{ELB: "app/a/1", Timestamp: "2018-11-01T10:00:30.000000Z", ELBStatusCode: "200"},
+		{ELB: "app/a/1", Timestamp: "2018-11-01T10:00:40.000000Z", ELBStatusCode: "502"},
+		// out of time range
+		{ELB: "app/a/1", Timestamp: "2018-11-01T10:02:00.000000Z", ELBStatusCode: "502"},
+		// other ALB
+		{ELB: "app/b/2", Timestamp: "2018-11-01T10:00:10.000000Z", ELBStatusCode: "503"},
+	}
+	n502, n503 := countELBStatusCodes(logs, "app/a/1", start, end)
+	if n502 != 2 || n503 != 1 {
+		t.Fatalf("expected 2 502s and 1 503, got %d and %d", n502, n503)
+	}
+	if ts := latestLogTimestamp(logs, "app/a/1"); !ts.Equal(start.Add(2 * time.Minute)) {
+		t.Fatalf("unexpected latest timestamp %v", ts)
+	}
+}
+
+func Test_rollingUpdateReport(t *testing.T) {
+	now := time.Now().UTC()
+	rs := []eksconfig.RollingUpdateResult{
+		{DeregistrationDelaySeconds: 0, Started: now, Stable: now.Add(time.Minute), TimeToStable: "1m0s", Requests: 100, Failures: 3, ELB502: 3, ELB503: 0},
+		{DeregistrationDelaySeconds: 30, ReadinessGate: true, Started: now, ELB502: -1, ELB503: -1, Error: "timed out"},
+		{DeregistrationDelaySeconds: 30, DrainSeconds: 40, Started: now, Requests: 100, ELB502: -1, ELB503: -1},
+	}
+	report := rollingUpdateReport(rs)
+	for _, s := range []string{"DEREGISTRATION-DELAY", "DRAIN", "30s", "40s", "n/a", "timed out"} {
+		if !strings.Contains(report, s) {
+			t.Fatalf("expected %q in report:\n%s", s, report)
+		}
+	}
+	metrics := RollingUpdateMetrics(rs)
+	if metrics["delay-0s-elb-502"] != 3 || metrics["delay-0s-time-to-stable-seconds"] != 60 {
+		t.Fatalf("unexpected metrics %v", metrics)
+	}
+	if _, ok := metrics["delay-30s-readiness-gate-elb-502"]; ok {
+		t.Fatalf("unexpected ELB 502 metric without access logs %v", metrics)
+	}
+	if v, ok := metrics["delay-30s-drain-40s-failures"]; !ok || v != 0 {
+		t.Fatalf("expected drain failures metric, got %v", metrics)
+	}
+}
@@ -0,0 +1,59 @@
+package eks
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/aws/aws-k8s-tester/eksconfig"
+)
+
+// albTargetTypes returns the target types to compare,
+// with the configured target type first.
+func albTargetTypes(targetType string) []string {
+	if targetType == "ip" {
+		return []string{"ip", "instance"}
+	}
+	return []string{"instance", "ip"}
+}
+
+// albTargetTypeReport writes the side-by-side comparison
+// of the target type test results.
+func albTargetTypeReport(rs []eksconfig.TargetTypeResult) string {
+	buf := new(bytes.Buffer)
+	tw := tabwriter.NewWriter(buf, 0, 0, 2, ' ', 0)
+
+	header := []string{"METRIC"}
+	for _, r := range rs {
+		header = append(header, strings.ToUpper(r.TargetType))
+	}
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+
+	rows := []struct {
+		name string
+		fn   func(r eksconfig.TargetTypeResult) string
+	}{
+		{"ALB ready took", func(r eksconfig.TargetTypeResult) string { return r.IngressUpTook }},
+		{"QPS", func(r eksconfig.TargetTypeResult) string { return fmt.Sprintf("%.2f", r.QPS) }},
+		{"Latency p50", func(r eksconfig.TargetTypeResult) string { return r.LatencyP50.String() }},
+		{"Latency p99", func(r eksconfig.TargetTypeResult) string { return r.LatencyP99.String() }},
+		{"Failures", func(r eksconfig.TargetTypeResult) string { return fmt.Sprintf("%d", r.Failures) }},
+		{"Errors", func(r eksconfig.TargetTypeResult) string { return fmt.Sprintf("%d", len(r.Errors)) }},
+	}
+	for _, row := range rows {
+		line := []string{row.name}
+		for _, r := range rs {
+			line = append(line, row.fn(r))
+		}
+		fmt.Fprintln(tw, strings.Join(line, "\t"))
+	}
+	tw.Flush()
+
+	for _, r := range rs {
+		for _, e := range r.Errors {
+			fmt.Fprintf(buf, "\n[%s] %s", r.TargetType, e)
+		}
+	}
+	return buf.String()
+}
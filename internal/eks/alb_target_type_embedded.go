@@ -0,0 +1,135 @@
+package eks
+
+import (
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/eksconfig"
+
+	"go.uber.org/zap"
+)
+
+// TestALBTargetTypes re-creates the Ingress objects with each target type
+// on the same cluster, runs correctness and QPS tests, and writes
+// the side-by-side comparison report.
+func (md *embedded) TestALBTargetTypes() error {
+	if !md.cfg.ALBIngressController.Enable || !md.cfg.ALBIngressController.Created {
+		return fmt.Errorf("ALB Ingress Controller is not created for %q", md.cfg.ClusterName)
+	}
+
+	// configured target type runs first with the existing Ingress objects,
+	// and is restored at the end, so that it is left deployed
+	origTargetType := md.cfg.ALBIngressController.TargetType
+	var rs []eksconfig.TargetTypeResult
+	for _, tt := range albTargetTypes(origTargetType) {
+		md.lg.Info("testing ALB target type", zap.String("target-type", tt))
+		r := eksconfig.TargetTypeResult{TargetType: tt}
+
+		if err := md.recreateALBIngress(tt); err != nil {
+			// cannot test other target types without ingress
+			r.Errors = append(r.Errors, err.Error())
+			rs = append(rs, r)
+			md.saveALBTargetTypeResults(rs)
+			return fmt.Errorf("failed to create Ingress objects with target type %q (%v)", tt, err)
+		}
+		if tt != origTargetType {
+			r.IngressUpTook = md.cfg.ALBIngressController.IngressUpTook
+		}
+
+		if err := md.TestALBCorrectness(); err != nil {
+			md.lg.Warn("failed ALB correctness test", zap.String("target-type", tt), zap.Error(err))
+			r.Errors = append(r.Errors, fmt.Sprintf("correctness: %v", err))
+		}
+		if err := md.TestALBQPS(); err != nil {
+			md.lg.Warn("failed ALB QPS test", zap.String("target-type", tt), zap.Error(err))
+			r.Errors = append(r.Errors, fmt.Sprintf("qps: %v", err))
+		}
+		r.QPS = md.cfg.ALBIngressController.TestResultQPS
+		r.LatencyP50 = md.cfg.ALBIngressController.TestResultLatencyP50
+		r.LatencyP99 = md.cfg.ALBIngressController.TestResultLatencyP99
+		r.Failures = md.cfg.ALBIngressController.TestResultFailures
+		rs = append(rs, r)
+		md.lg.Info("tested ALB target type",
+			zap.String("target-type", tt),
+			zap.String("ingress-up-took", r.IngressUpTook),
+			zap.Float64("qps", r.QPS),
+			zap.Duration("latency-p50", r.LatencyP50),
+			zap.Duration("latency-p99", r.LatencyP99),
+			zap.Int64("failures", r.Failures),
+			zap.Strings("errors", r.Errors),
+		)
+	}
+
+	// time the configured target type on re-creation, same as the others
+	if err := md.recreateALBIngress(origTargetType); err != nil {
+		rs[0].Errors = append(rs[0].Errors, err.Error())
+		md.saveALBTargetTypeResults(rs)
+		return fmt.Errorf("failed to restore Ingress objects with target type %q (%v)", origTargetType, err)
+	}
+	rs[0].IngressUpTook = md.cfg.ALBIngressController.IngressUpTook
+
+	if err := md.saveALBTargetTypeResults(rs); err != nil {
+		return err
+	}
+	for _, r := range rs {
+		if len(r.Errors) > 0 {
+			return fmt.Errorf("target type %q failed with %d error(s) (%v)", r.TargetType, len(r.Errors), r.Errors)
+		}
+	}
+	return nil
+}
+
+// recreateALBIngress deletes the existing Ingress objects, and re-creates
+// them with the target type. The ALB Ingress Controller is kept running,
+// since the target type is set per Ingress object. Only the Ingress
+// creation is timed, as "IngressUpTook".
+func (md *embedded) recreateALBIngress(targetType string) error {
+	if md.cfg.ALBIngressController.TargetType == targetType &&
+		len(md.cfg.ALBIngressController.ELBv2NameToARN) > 0 {
+		md.lg.Info("reusing existing Ingress objects", zap.String("target-type", targetType))
+		return nil
+	}
+
+	if len(md.cfg.ALBIngressController.ELBv2NameToARN) > 0 {
+		if err := md.albPlugin.DeleteIngressObjects(); err != nil {
+			return err
+		}
+	}
+	md.cfg.ALBIngressController.ELBv2NamespaceToDNSName = nil
+	md.cfg.ALBIngressController.ELBv2NameToDNSName = nil
+	md.cfg.ALBIngressController.ELBv2NameToARN = nil
+	md.cfg.ALBIngressController.IngressShards = nil
+	md.cfg.ALBIngressController.TargetType = targetType
+	md.cfg.Sync()
+
+	now := time.Now().UTC()
+	if err := catchStopc(md.lg, md.stopc, md.albPlugin.CreateIngressObjects); err != nil {
+		return err
+	}
+	md.cfg.SetIngressUpTook(time.Now().UTC().Sub(now))
+	return md.cfg.Sync()
+}
+
+// saveALBTargetTypeResults writes the target type comparison
+// to the config and the report file.
+func (md *embedded) saveALBTargetTypeResults(rs []eksconfig.TargetTypeResult) error {
+	md.cfg.ALBIngressController.TargetTypeResults = rs
+	md.cfg.Sync()
+
+	report := albTargetTypeReport(rs)
+	fmt.Printf("TestALBTargetTypes Result:\n\n%s\n\n", report)
+	if err := ioutil.WriteFile(
+		md.cfg.ALBIngressController.TargetTypeComparisonOutputToUploadPath,
+		[]byte(report),
+		0600,
+	); err != nil {
+		return err
+	}
+	if md.cfg.ALBIngressController.UploadTesterLogs {
+		if err := md.uploadALBTesterLogs(); err != nil {
+			md.lg.Warn("failed to upload ALB", zap.Error(err))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package eks
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/eksconfig"
+)
+
+func Test_albTargetTypes(t *testing.T) {
+	if tt := albTargetTypes("instance"); !reflect.DeepEqual(tt, []string{"instance", "ip"}) {
+		t.Fatalf("unexpected target types %v", tt)
+	}
+	if tt := albTargetTypes("ip"); !reflect.DeepEqual(tt, []string{"ip", "instance"}) {
+		t.Fatalf("unexpected target types %v", tt)
+	}
+}
+
+func Test_albTargetTypeReport(t *testing.T) {
+	s := albTargetTypeReport([]eksconfig.TargetTypeResult{
+		{
+			TargetType:    "ip",
+			IngressUpTook: "3m10s",
+			QPS:           1234.5,
+			LatencyP50:    5 * time.Millisecond,
+			LatencyP99:    120 * time.Millisecond,
+			Failures:      3,
+		},
+		{
+			TargetType:    "instance",
+			IngressUpTook: "2m50s",
+			QPS:           1000,
+			LatencyP50:    7 * time.Millisecond,
+			LatencyP99:    150 * time.Millisecond,
+			Errors:        []string{"expected QPS 2000, got 1000"},
+		},
+	})
+	t.Log(s)
+
+	lines := strings.Split(s, "\n")
+	if fs := strings.Fields(lines[0]); !reflect.DeepEqual(fs, []string{"METRIC", "IP", "INSTANCE"}) {
+		t.Fatalf("unexpected header %v", fs)
+	}
+	for _, exp := range []string{
+		"QPS             1234.50  1000.00",
+		"Latency p99     120ms    150ms",
+		"Failures        3        0",
+		"[instance] expected QPS 2000, got 1000",
+	} {
+		if !strings.Contains(s, exp) {
+			t.Fatalf("expected %q in report:\n%s", exp, s)
+		}
+	}
+}
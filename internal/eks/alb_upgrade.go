@@ -0,0 +1,27 @@
+package eks
+
+import "sort"
+
+// diffARNs returns the ARNs that are only in "before" (removed)
+// and the ones that are only in "after" (added).
+func diffARNs(before, after []string) (removed, added []string) {
+	bm := make(map[string]struct{}, len(before))
+	for _, v := range before {
+		bm[v] = struct{}{}
+	}
+	am := make(map[string]struct{}, len(after))
+	for _, v := range after {
+		am[v] = struct{}{}
+		if _, ok := bm[v]; !ok {
+			added = append(added, v)
+		}
+	}
+	for _, v := range before {
+		if _, ok := am[v]; !ok {
+			removed = append(removed, v)
+		}
+	}
+	sort.Strings(removed)
+	sort.Strings(added)
+	return removed, added
+}
@@ -0,0 +1,99 @@
+package eks
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/eksconfig"
+	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress/client"
+
+	"go.uber.org/zap"
+)
+
+// TestALBUpgrade rolls the ALB Ingress Controller to the upgrade image
+// while sending steady traffic, and fails if any ALB listener, listener rule
+// or target group was re-created, or if client errors exceed the threshold.
+func (md *embedded) TestALBUpgrade() error {
+	if !md.cfg.ALBIngressController.Enable || !md.cfg.ALBIngressController.Created {
+		return fmt.Errorf("ALB Ingress Controller is not created for %q", md.cfg.ClusterName)
+	}
+	if md.cfg.ALBIngressController.UpgradeIngressControllerImage == "" {
+		return fmt.Errorf("ALB Ingress Controller upgrade image is not specified for %q", md.cfg.ClusterName)
+	}
+
+	r := &eksconfig.UpgradeResult{
+		FromImage: md.cfg.ALBIngressController.IngressControllerImage,
+		ToImage:   md.cfg.ALBIngressController.UpgradeIngressControllerImage,
+	}
+	md.cfg.ALBIngressController.UpgradeResult = r
+	md.cfg.Sync()
+
+	before, err := md.albPlugin.DescribeResourceARNs()
+	if err != nil {
+		return err
+	}
+	md.lg.Info("described ALB resources before upgrade", zap.Int("arns", len(before)))
+
+	// send requests until upgrade completes
+	cli, err := md.newALBClient(math.MaxInt32)
+	if err != nil {
+		return err
+	}
+	donec := make(chan client.TestResult)
+	go func() {
+		donec <- cli.Run()
+	}()
+
+	// establish steady traffic before upgrade
+	md.lg.Info("waiting for steady traffic", zap.Duration("wait", 30*time.Second))
+	time.Sleep(30 * time.Second)
+
+	now := time.Now().UTC()
+	uerr := catchStopc(md.lg, md.stopc, func() error {
+		return md.albPlugin.UpgradeIngressController(r.ToImage)
+	})
+	r.RolloutTook = time.Now().UTC().Sub(now).String()
+
+	// new controller reconciles Ingress objects on start
+	if uerr == nil {
+		md.lg.Info("waiting for controller to reconcile", zap.Duration("wait", time.Minute))
+		select {
+		case <-md.stopc:
+		case <-time.After(time.Minute):
+		}
+	}
+	cli.Stop()
+	rs := <-donec
+
+	r.Requests = rs.Success + rs.Failure
+	r.Failures = rs.Failure
+	md.cfg.Sync()
+	if uerr != nil {
+		return uerr
+	}
+
+	after, err := md.albPlugin.DescribeResourceARNs()
+	if err != nil {
+		return err
+	}
+	r.RemovedARNs, r.AddedARNs = diffARNs(before, after)
+	md.cfg.Sync()
+
+	md.lg.Info("tested ALB Ingress Controller upgrade",
+		zap.String("from-image", r.FromImage),
+		zap.String("to-image", r.ToImage),
+		zap.String("rollout-took", r.RolloutTook),
+		zap.Int64("requests", r.Requests),
+		zap.Int64("failures", r.Failures),
+		zap.Strings("removed-arns", r.RemovedARNs),
+		zap.Strings("added-arns", r.AddedARNs),
+	)
+	if len(r.RemovedARNs) > 0 || len(r.AddedARNs) > 0 {
+		return fmt.Errorf("ALB resources were re-created during upgrade (removed %v, added %v)", r.RemovedARNs, r.AddedARNs)
+	}
+	if r.Failures > md.cfg.ALBIngressController.TestClientErrorThreshold {
+		return fmt.Errorf("expected failures under threshold %d during upgrade, got %d", md.cfg.ALBIngressController.TestClientErrorThreshold, r.Failures)
+	}
+	return nil
+}
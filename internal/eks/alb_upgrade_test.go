@@ -0,0 +1,39 @@
+package eks
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_diffARNs(t *testing.T) {
+	tests := []struct {
+		before  []string
+		after   []string
+		removed []string
+		added   []string
+	}{
+		{
+			before: []string{"listener/a", "tg/a"},
+			after:  []string{"tg/a", "listener/a"},
+		},
+		{
+			before:  []string{"listener/a", "rule/a", "tg/a"},
+			after:   []string{"listener/a", "rule/b", "tg/b"},
+			removed: []string{"rule/a", "tg/a"},
+			added:   []string{"rule/b", "tg/b"},
+		},
+		{
+			before:  []string{"tg/a"},
+			removed: []string{"tg/a"},
+		},
+	}
+	for i, tt := range tests {
+		removed, added := diffARNs(tt.before, tt.after)
+		if !reflect.DeepEqual(removed, tt.removed) {
+			t.Fatalf("#%d: removed expected %v, got %v", i, tt.removed, removed)
+		}
+		if !reflect.DeepEqual(added, tt.added) {
+			t.Fatalf("#%d: added expected %v, got %v", i, tt.added, added)
+		}
+	}
+}
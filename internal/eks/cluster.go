@@ -5,6 +5,8 @@ import (
 	"io/ioutil"
 	"strings"
 	"text/template"
+
+	"github.com/aws/aws-k8s-tester/eksconfig"
 )
 
 // isEKSDeletedGoClient returns true if error from EKS API indicates that
@@ -49,7 +51,14 @@ users:
       args:
         - token
         - -i
-        - {{.ClusterName}}
+        - {{.ClusterName}}{{if .RoleARN}}
+        - -r
+        - {{.RoleARN}}{{end}}{{if .ExternalID}}
+        - --external-id
+        - {{.ExternalID}}{{end}}{{if .Env}}
+      env:{{range .Env}}
+        - name: {{.Name}}
+          value: {{.Value}}{{end}}{{end}}
 
 `
 
@@ -57,20 +66,56 @@ type kubeConfig struct {
 	ClusterEndpoint string
 	ClusterCA       string
 	ClusterName     string
+	// RoleARN is the IAM role for "aws-iam-authenticator" to assume,
+	// so that kubectl authenticates as the role that created the cluster.
+	RoleARN string
+	// ExternalID is the external ID to pass when assuming "RoleARN".
+	ExternalID string
+	// Env is the environment variables of "aws-iam-authenticator",
+	// to use the same credential source as the tester.
+	Env []kubeConfigEnv
+}
+
+type kubeConfigEnv struct {
+	Name  string
+	Value string
 }
 
-func writeKubeConfig(ep, ca, clusterName, p string) (err error) {
+// newKubeConfig returns the kubeconfig that authenticates with
+// the same AWS credentials that created the cluster.
+func newKubeConfig(cfg *eksconfig.Config) kubeConfig {
 	kc := kubeConfig{
-		ClusterEndpoint: ep,
-		ClusterCA:       ca,
-		ClusterName:     clusterName,
+		ClusterEndpoint: cfg.ClusterState.Endpoint,
+		ClusterCA:       cfg.ClusterState.CA,
+		ClusterName:     cfg.ClusterName,
+	}
+	if cfg.AWSProfile != "" {
+		kc.Env = append(kc.Env, kubeConfigEnv{Name: "AWS_PROFILE", Value: cfg.AWSProfile})
 	}
+	if cfg.AWSWebIdentityTokenFile != "" {
+		// the web identity credentials are already of the role
+		kc.Env = append(kc.Env,
+			kubeConfigEnv{Name: "AWS_ROLE_ARN", Value: cfg.AWSRoleARN},
+			kubeConfigEnv{Name: "AWS_WEB_IDENTITY_TOKEN_FILE", Value: cfg.AWSWebIdentityTokenFile},
+		)
+		if cfg.AWSRoleSessionName != "" {
+			kc.Env = append(kc.Env, kubeConfigEnv{Name: "AWS_ROLE_SESSION_NAME", Value: cfg.AWSRoleSessionName})
+		}
+		return kc
+	}
+	kc.RoleARN = cfg.AWSRoleARN
+	kc.ExternalID = cfg.AWSRoleExternalID
+	return kc
+}
+
+// writeKubeConfig writes the kubeconfig of the cluster to "KubeConfigPath".
+func writeKubeConfig(cfg *eksconfig.Config) (err error) {
 	tpl := template.Must(template.New("kubeCfgTempl").Parse(kubeConfigTempl))
 	buf := bytes.NewBuffer(nil)
-	if err = tpl.Execute(buf, kc); err != nil {
+	if err = tpl.Execute(buf, newKubeConfig(cfg)); err != nil {
 		return err
 	}
-	return ioutil.WriteFile(p, buf.Bytes(), 0600)
+	return ioutil.WriteFile(cfg.KubeConfigPath, buf.Bytes(), 0600)
 }
 
 /*
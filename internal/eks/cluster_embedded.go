@@ -121,12 +121,7 @@ func (md *embedded) createCluster() error {
 		return errors.New("cannot find cluster endpoint or cluster CA")
 	}
 
-	if err = writeKubeConfig(
-		md.cfg.ClusterState.Endpoint,
-		md.cfg.ClusterState.CA,
-		md.cfg.ClusterName,
-		md.cfg.KubeConfigPath,
-	); err != nil {
+	if err = writeKubeConfig(md.cfg); err != nil {
 		return err
 	}
 	if err = md.s3Plugin.UploadToBucketForTests(
@@ -0,0 +1,103 @@
+package eks
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-k8s-tester/eksconfig"
+
+	gyaml "github.com/ghodss/yaml"
+)
+
+func TestWriteKubeConfig(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "kubeconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	type execEnv struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+	type execConfig struct {
+		Args []string  `json:"args"`
+		Env  []execEnv `json:"env"`
+	}
+	tests := []struct {
+		profile, roleARN, externalID, sessionName, tokenFile string
+
+		args []string
+		env  []execEnv
+	}{
+		{
+			args: []string{"token", "-i", "test-cluster"},
+		},
+		{
+			profile: "dev",
+			args:    []string{"token", "-i", "test-cluster"},
+			env:     []execEnv{{"AWS_PROFILE", "dev"}},
+		},
+		{
+			profile:    "dev",
+			roleARN:    "arn:aws:iam::123456789012:role/test",
+			externalID: "my-external-id",
+			args:       []string{"token", "-i", "test-cluster", "-r", "arn:aws:iam::123456789012:role/test", "--external-id", "my-external-id"},
+			env:        []execEnv{{"AWS_PROFILE", "dev"}},
+		},
+		{
+			roleARN:     "arn:aws:iam::123456789012:role/test",
+			sessionName: "my-session",
+			tokenFile:   "/var/run/secrets/token",
+			args:        []string{"token", "-i", "test-cluster"},
+			env: []execEnv{
+				{"AWS_ROLE_ARN", "arn:aws:iam::123456789012:role/test"},
+				{"AWS_WEB_IDENTITY_TOKEN_FILE", "/var/run/secrets/token"},
+				{"AWS_ROLE_SESSION_NAME", "my-session"},
+			},
+		},
+	}
+	for i, tt := range tests {
+		cfg := eksconfig.NewDefault()
+		cfg.ClusterName = "test-cluster"
+		cfg.ClusterState.Endpoint = "https://test.eks.amazonaws.com"
+		cfg.ClusterState.CA = "test-ca"
+		cfg.KubeConfigPath = filepath.Join(dir, "kubeconfig")
+		cfg.AWSProfile = tt.profile
+		cfg.AWSRoleARN = tt.roleARN
+		cfg.AWSRoleExternalID = tt.externalID
+		cfg.AWSRoleSessionName = tt.sessionName
+		cfg.AWSWebIdentityTokenFile = tt.tokenFile
+		if err = writeKubeConfig(cfg); err != nil {
+			t.Fatal(err)
+		}
+
+		d, err := ioutil.ReadFile(cfg.KubeConfigPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var kc struct {
+			Users []struct {
+				User struct {
+					Exec execConfig `json:"exec"`
+				} `json:"user"`
+			} `json:"users"`
+		}
+		if err = gyaml.Unmarshal(d, &kc); err != nil {
+			t.Fatalf("#%d: invalid kubeconfig (%v)\n%s", i, err, string(d))
+		}
+		if len(kc.Users) != 1 {
+			t.Fatalf("#%d: unexpected users %+v", i, kc.Users)
+		}
+		ec := kc.Users[0].User.Exec
+		if !reflect.DeepEqual(ec.Args, tt.args) {
+			t.Fatalf("#%d: expected args %v, got %v", i, tt.args, ec.Args)
+		}
+		if !reflect.DeepEqual(ec.Env, tt.env) {
+			t.Fatalf("#%d: expected env %v, got %v", i, tt.env, ec.Env)
+		}
+	}
+}
@@ -0,0 +1,3 @@
+// Package elb implements "LoadBalancer" type Service plugin,
+// which tests classic ELB and NLB provisioned for the ingress test server.
+package elb
@@ -0,0 +1,9 @@
+package elb
+
+// Plugin defines "LoadBalancer" type Service deployer operations.
+type Plugin interface {
+	CreateServices() error
+	DeleteServices() error
+
+	TestTargets(name string) error
+}
@@ -0,0 +1,56 @@
+package elb
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-k8s-tester/eksconfig"
+
+	"github.com/aws/aws-sdk-go/service/elb/elbiface"
+	"github.com/aws/aws-sdk-go/service/elbv2/elbv2iface"
+	"go.uber.org/zap"
+	"k8s.io/utils/exec"
+)
+
+type embedded struct {
+	stopc chan struct{}
+
+	lg  *zap.Logger
+	cfg *eksconfig.Config
+
+	// TODO: move this "kubectl" to AWS CLI deployer
+	// and instead use "k8s.io/client-go" with STS token
+	kubectl     exec.Interface
+	kubectlPath string
+
+	elb   elbiface.ELBAPI
+	elbv2 elbv2iface.ELBV2API
+}
+
+// NewEmbedded creates a new Plugin using AWS CLI.
+func NewEmbedded(
+	stopc chan struct{},
+	lg *zap.Logger,
+	cfg *eksconfig.Config,
+	elb elbiface.ELBAPI,
+	elbv2 elbv2iface.ELBV2API,
+) (Plugin, error) {
+	md := &embedded{
+		stopc:   stopc,
+		lg:      lg,
+		cfg:     cfg,
+		kubectl: exec.New(),
+		elb:     elb,
+		elbv2:   elbv2,
+	}
+
+	var err error
+	md.kubectlPath, err = md.kubectl.LookPath("kubectl")
+	if err != nil {
+		return nil, fmt.Errorf("cannot find 'kubectl' executable (%v)", err)
+	}
+	if _, err = exec.New().LookPath("aws-iam-authenticator"); err != nil {
+		return nil, fmt.Errorf("cannot find 'aws-iam-authenticator' executable (%v)", err)
+	}
+
+	return md, nil
+}
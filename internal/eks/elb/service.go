@@ -0,0 +1,34 @@
+package elb
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// ServiceClassicELB is the name of "LoadBalancer" type Service with classic ELB.
+	ServiceClassicELB = "ingress-test-server-classic-elb"
+	// ServiceNLB is the name of "LoadBalancer" type Service with NLB.
+	ServiceNLB = "ingress-test-server-nlb"
+)
+
+// ServiceType returns the load balancer type of the Service,
+// either "classic" or "nlb".
+func ServiceType(name string) string {
+	if name == ServiceNLB {
+		return "nlb"
+	}
+	return "classic"
+}
+
+// loadBalancerNameFromDNSName parses the load balancer name
+// from its DNS name (e.g. "a1b2c3-0123456789.us-west-2.elb.amazonaws.com").
+func loadBalancerNameFromDNSName(dnsName string) (string, error) {
+	label := strings.Split(dnsName, ".")[0]
+	label = strings.TrimPrefix(label, "internal-")
+	idx := strings.LastIndex(label, "-")
+	if idx <= 0 {
+		return "", fmt.Errorf("cannot parse load balancer name from %q", dnsName)
+	}
+	return label[:idx], nil
+}
@@ -0,0 +1,352 @@
+package elb
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/eksconfig"
+	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress"
+	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress/path"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	awselb "github.com/aws/aws-sdk-go/service/elb"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"go.uber.org/zap"
+)
+
+// CreateServices creates "LoadBalancer" type Service objects
+// for the ingress test server, and waits until each load balancer
+// serves the test server.
+func (md *embedded) CreateServices() (err error) {
+	var d string
+	for _, name := range []string{ServiceClassicELB, ServiceNLB} {
+		var s string
+		s, err = ingress.CreateServiceLoadBalancer(ingress.ConfigServiceLoadBalancer{
+			Name:         name,
+			Namespace:    "default",
+			Selector:     "ingress-test-server",
+			NLB:          name == ServiceNLB,
+			SourceRanges: md.cfg.ALBIngressController.AllowedSourceCIDRs,
+		})
+		if err != nil {
+			return err
+		}
+		d += s
+	}
+	if err = ioutil.WriteFile(md.cfg.ALBIngressController.LoadBalancerServiceSpecPath, []byte(d), 0600); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	if err = md.kubectlSpec("apply", md.cfg.ALBIngressController.LoadBalancerServiceSpecPath); err != nil {
+		return err
+	}
+	md.lg.Info("created load balancer services", zap.String("path", md.cfg.ALBIngressController.LoadBalancerServiceSpecPath))
+
+	md.cfg.ALBIngressController.LoadBalancerServiceToDNSName = make(map[string]string)
+	md.cfg.ALBIngressController.LoadBalancerServiceResults = nil
+	for _, name := range []string{ServiceClassicELB, ServiceNLB} {
+		var dnsName string
+		dnsName, err = md.waitService(name)
+		if err != nil {
+			return err
+		}
+		md.cfg.ALBIngressController.LoadBalancerServiceToDNSName[name] = dnsName
+		md.cfg.ALBIngressController.LoadBalancerServiceResults = append(md.cfg.ALBIngressController.LoadBalancerServiceResults,
+			eksconfig.LoadBalancerServiceResult{
+				Service:       name,
+				Type:          ServiceType(name),
+				DNSName:       dnsName,
+				ProvisionTook: time.Now().UTC().Sub(now).String(),
+			},
+		)
+		md.cfg.Sync()
+	}
+	return md.cfg.Sync()
+}
+
+// waitService waits until the load balancer of the Service is provisioned,
+// and serves the test server. It returns the load balancer DNS name.
+func (md *embedded) waitService(name string) (dnsName string, err error) {
+	cli := &http.Client{Timeout: 5 * time.Second}
+
+	retryStart := time.Now().UTC()
+	for time.Now().UTC().Sub(retryStart) < 15*time.Minute {
+		select {
+		case <-md.stopc:
+			return "", fmt.Errorf("creating service %q interrupted", name)
+		case <-time.After(5 * time.Second):
+		}
+
+		if dnsName == "" {
+			out, kerr := md.kubectlCommand(10*time.Second,
+				"--namespace=default",
+				"get",
+				"service/"+name,
+				"--output=jsonpath={.status.loadBalancer.ingress[0].hostname}",
+			)
+			if kerr != nil {
+				md.lg.Warn("failed to get service", zap.String("name", name), zap.String("output", string(out)), zap.Error(kerr))
+				continue
+			}
+			dnsName = strings.TrimSpace(string(out))
+			if dnsName == "" {
+				md.lg.Info("waiting for load balancer", zap.String("name", name))
+				continue
+			}
+			md.lg.Info("found load balancer", zap.String("name", name), zap.String("dns-name", dnsName))
+		}
+
+		if _, lerr := net.LookupHost(dnsName); lerr != nil {
+			continue
+		}
+		rs, gerr := cli.Get("http://" + dnsName + path.Path)
+		if gerr != nil {
+			md.lg.Info("waiting for load balancer to serve traffic", zap.String("name", name), zap.Error(gerr))
+			continue
+		}
+		ioutil.ReadAll(rs.Body)
+		rs.Body.Close()
+		if rs.StatusCode != http.StatusOK {
+			md.lg.Info("waiting for load balancer to serve traffic", zap.String("name", name), zap.String("status", rs.Status))
+			continue
+		}
+		md.lg.Info("load balancer is ready", zap.String("name", name), zap.String("dns-name", dnsName))
+		return dnsName, nil
+	}
+	return dnsName, fmt.Errorf("load balancer for service %q not ready in time", name)
+}
+
+// DeleteServices deletes "LoadBalancer" type Service objects,
+// and waits until their load balancers are deleted.
+func (md *embedded) DeleteServices() error {
+	out, err := md.kubectlCommand(time.Minute,
+		"--namespace=default",
+		"delete",
+		"service",
+		ServiceClassicELB,
+		ServiceNLB,
+		"--ignore-not-found",
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete load balancer services (%v, %q)", err, string(out))
+	}
+	os.RemoveAll(md.cfg.ALBIngressController.LoadBalancerServiceSpecPath)
+	md.lg.Info("deleted load balancer services")
+
+	for name, dnsName := range md.cfg.ALBIngressController.LoadBalancerServiceToDNSName {
+		lbName, err := loadBalancerNameFromDNSName(dnsName)
+		if err != nil {
+			return err
+		}
+		if err = md.waitLoadBalancerDeleted(name, lbName); err != nil {
+			return err
+		}
+	}
+	md.cfg.ALBIngressController.LoadBalancerServiceToDNSName = nil
+	return md.cfg.Sync()
+}
+
+func (md *embedded) waitLoadBalancerDeleted(name, lbName string) error {
+	retryStart := time.Now().UTC()
+	for time.Now().UTC().Sub(retryStart) < 10*time.Minute {
+		var err error
+		if name == ServiceNLB {
+			_, err = md.elbv2.DescribeLoadBalancers(&elbv2.DescribeLoadBalancersInput{
+				Names: aws.StringSlice([]string{lbName}),
+			})
+		} else {
+			_, err = md.elb.DescribeLoadBalancers(&awselb.DescribeLoadBalancersInput{
+				LoadBalancerNames: aws.StringSlice([]string{lbName}),
+			})
+		}
+		if err != nil {
+			if aerr, ok := err.(awserr.Error); ok &&
+				(aerr.Code() == elbv2.ErrCodeLoadBalancerNotFoundException ||
+					aerr.Code() == awselb.ErrCodeAccessPointNotFoundException) {
+				md.lg.Info("deleted load balancer", zap.String("name", name), zap.String("load-balancer-name", lbName))
+				return nil
+			}
+			md.lg.Warn("failed to describe load balancer", zap.String("name", name), zap.Error(err))
+		}
+		time.Sleep(10 * time.Second)
+	}
+	return fmt.Errorf("load balancer %q for service %q not deleted in time", lbName, name)
+}
+
+// TestTargets validates the listener and the registered targets
+// of the load balancer for the Service, via ELB or ELBv2 APIs.
+// Every worker node is expected to be a healthy target.
+func (md *embedded) TestTargets(name string) error {
+	dnsName, ok := md.cfg.ALBIngressController.LoadBalancerServiceToDNSName[name]
+	if !ok {
+		return fmt.Errorf("load balancer for service %q not found", name)
+	}
+	lbName, err := loadBalancerNameFromDNSName(dnsName)
+	if err != nil {
+		return err
+	}
+	expTargets := len(md.cfg.ClusterState.WorkerNodes)
+
+	retryStart := time.Now().UTC()
+	for time.Now().UTC().Sub(retryStart) < 5*time.Minute {
+		var healthy int
+		if name == ServiceNLB {
+			healthy, err = md.testNLBTargets(lbName)
+		} else {
+			healthy, err = md.testClassicELBTargets(lbName)
+		}
+		if err != nil {
+			return err
+		}
+		if healthy == expTargets {
+			md.lg.Info("validated load balancer targets",
+				zap.String("name", name),
+				zap.String("load-balancer-name", lbName),
+				zap.Int("healthy", healthy),
+			)
+			return nil
+		}
+		md.lg.Info("waiting for healthy targets",
+			zap.String("name", name),
+			zap.Int("expected", expTargets),
+			zap.Int("healthy", healthy),
+		)
+		select {
+		case <-md.stopc:
+			return fmt.Errorf("testing service %q interrupted", name)
+		case <-time.After(10 * time.Second):
+		}
+	}
+	return fmt.Errorf("load balancer %q for service %q: expected %d healthy targets", lbName, name, expTargets)
+}
+
+// testClassicELBTargets validates the classic ELB listener,
+// and returns the number of "InService" instances.
+func (md *embedded) testClassicELBTargets(lbName string) (int, error) {
+	lo, err := md.elb.DescribeLoadBalancers(&awselb.DescribeLoadBalancersInput{
+		LoadBalancerNames: aws.StringSlice([]string{lbName}),
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(lo.LoadBalancerDescriptions) != 1 {
+		return 0, fmt.Errorf("classic ELB %q: expected 1 load balancer, got %d", lbName, len(lo.LoadBalancerDescriptions))
+	}
+	found := false
+	for _, ld := range lo.LoadBalancerDescriptions[0].ListenerDescriptions {
+		if ld.Listener != nil && aws.Int64Value(ld.Listener.LoadBalancerPort) == 80 {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return 0, fmt.Errorf("classic ELB %q: listener on port 80 not found", lbName)
+	}
+
+	ho, err := md.elb.DescribeInstanceHealth(&awselb.DescribeInstanceHealthInput{
+		LoadBalancerName: aws.String(lbName),
+	})
+	if err != nil {
+		return 0, err
+	}
+	healthy := 0
+	for _, st := range ho.InstanceStates {
+		if aws.StringValue(st.State) == "InService" {
+			healthy++
+		}
+	}
+	return healthy, nil
+}
+
+// testNLBTargets validates the NLB type and its TCP listener,
+// and returns the number of healthy targets.
+func (md *embedded) testNLBTargets(lbName string) (int, error) {
+	lo, err := md.elbv2.DescribeLoadBalancers(&elbv2.DescribeLoadBalancersInput{
+		Names: aws.StringSlice([]string{lbName}),
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(lo.LoadBalancers) != 1 {
+		return 0, fmt.Errorf("NLB %q: expected 1 load balancer, got %d", lbName, len(lo.LoadBalancers))
+	}
+	lb := lo.LoadBalancers[0]
+	if aws.StringValue(lb.Type) != elbv2.LoadBalancerTypeEnumNetwork {
+		return 0, fmt.Errorf("NLB %q: expected type %q, got %q", lbName, elbv2.LoadBalancerTypeEnumNetwork, aws.StringValue(lb.Type))
+	}
+
+	so, err := md.elbv2.DescribeListeners(&elbv2.DescribeListenersInput{
+		LoadBalancerArn: lb.LoadBalancerArn,
+	})
+	if err != nil {
+		return 0, err
+	}
+	found := false
+	for _, l := range so.Listeners {
+		if aws.Int64Value(l.Port) == 80 && aws.StringValue(l.Protocol) == elbv2.ProtocolEnumTcp {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return 0, fmt.Errorf("NLB %q: TCP listener on port 80 not found", lbName)
+	}
+
+	to, err := md.elbv2.DescribeTargetGroups(&elbv2.DescribeTargetGroupsInput{
+		LoadBalancerArn: lb.LoadBalancerArn,
+	})
+	if err != nil {
+		return 0, err
+	}
+	healthy := 0
+	for _, tg := range to.TargetGroups {
+		ho, herr := md.elbv2.DescribeTargetHealth(&elbv2.DescribeTargetHealthInput{
+			TargetGroupArn: tg.TargetGroupArn,
+		})
+		if herr != nil {
+			return 0, herr
+		}
+		for _, hv := range ho.TargetHealthDescriptions {
+			if hv.TargetHealth != nil && aws.StringValue(hv.TargetHealth.State) == elbv2.TargetHealthStateEnumHealthy {
+				healthy++
+			}
+		}
+	}
+	return healthy, nil
+}
+
+func (md *embedded) kubectlSpec(op, specPath string) (err error) {
+	var kexo []byte
+	retryStart := time.Now().UTC()
+	for time.Now().UTC().Sub(retryStart) < time.Minute {
+		kexo, err = md.kubectlCommand(10*time.Second, op, "--filename="+specPath)
+		if err == nil {
+			return nil
+		}
+		md.lg.Warn("failed to "+op+" spec",
+			zap.String("path", specPath),
+			zap.String("output", string(kexo)),
+			zap.Error(err),
+		)
+		time.Sleep(5 * time.Second)
+	}
+	return fmt.Errorf("failed to %s %q (%v, %q)", op, specPath, err, string(kexo))
+}
+
+func (md *embedded) kubectlCommand(timeout time.Duration, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	cmd := md.kubectl.CommandContext(ctx,
+		md.kubectlPath,
+		append([]string{"--kubeconfig=" + md.cfg.KubeConfigPath}, args...)...,
+	)
+	return cmd.CombinedOutput()
+}
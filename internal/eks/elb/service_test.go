@@ -0,0 +1,28 @@
+package elb
+
+import "testing"
+
+func TestLoadBalancerNameFromDNSName(t *testing.T) {
+	tests := []struct {
+		dnsName string
+		name    string
+		err     bool
+	}{
+		{"a8f3e5d6c7b8a9f0e1d2c3b4a5f6e7d8-1234567890.us-west-2.elb.amazonaws.com", "a8f3e5d6c7b8a9f0e1d2c3b4a5f6e7d8", false},
+		{"a8f3e5d6c7b8a9f0e1d2c3b4a5f6e7d8-0123456789abcdef.elb.us-west-2.amazonaws.com", "a8f3e5d6c7b8a9f0e1d2c3b4a5f6e7d8", false},
+		{"internal-a8f3e5d6c7b8-1234567890.us-west-2.elb.amazonaws.com", "a8f3e5d6c7b8", false},
+		{"localhost", "", true},
+	}
+	for i, tt := range tests {
+		name, err := loadBalancerNameFromDNSName(tt.dnsName)
+		if (err != nil) != tt.err {
+			t.Fatalf("#%d: expected error %v, got %v", i, tt.err, err)
+		}
+		if name != tt.name {
+			t.Fatalf("#%d: expected %q, got %q", i, tt.name, name)
+		}
+	}
+	if ServiceType(ServiceNLB) != "nlb" || ServiceType(ServiceClassicELB) != "classic" {
+		t.Fatal("unexpected service type")
+	}
+}
@@ -0,0 +1,51 @@
+package eks
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/aws/aws-k8s-tester/eksconfig"
+)
+
+// elbServiceReport writes the result of each "LoadBalancer" type Service test,
+// followed by the errors of failed tests.
+func elbServiceReport(rs []eksconfig.LoadBalancerServiceResult) string {
+	buf := new(bytes.Buffer)
+	tw := tabwriter.NewWriter(buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join([]string{"SERVICE", "TYPE", "PROVISION-TOOK", "QPS", "LATENCY-P50", "LATENCY-P99", "FAILURES"}, "\t"))
+	for _, r := range rs {
+		fmt.Fprintln(tw, strings.Join([]string{
+			r.Service,
+			r.Type,
+			r.ProvisionTook,
+			fmt.Sprintf("%.3f", r.QPS),
+			r.LatencyP50.String(),
+			r.LatencyP99.String(),
+			fmt.Sprintf("%d", r.Failures),
+		}, "\t"))
+	}
+	tw.Flush()
+
+	for _, r := range rs {
+		for _, e := range r.Errors {
+			fmt.Fprintf(buf, "\n[%s] %s", r.Service, e)
+		}
+	}
+	return buf.String()
+}
+
+// ELBServiceMetrics returns the QPS, latencies and failures
+// of each load balancer type, for test result metrics.
+func ELBServiceMetrics(rs []eksconfig.LoadBalancerServiceResult) map[string]float64 {
+	metrics := make(map[string]float64)
+	for _, r := range rs {
+		metrics[r.Type+"-qps"] = r.QPS
+		metrics[r.Type+"-latency-p50-seconds"] = r.LatencyP50.Seconds()
+		metrics[r.Type+"-latency-p99-seconds"] = r.LatencyP99.Seconds()
+		metrics[r.Type+"-failures"] = float64(r.Failures)
+		metrics[r.Type+"-errors"] = float64(len(r.Errors))
+	}
+	return metrics
+}
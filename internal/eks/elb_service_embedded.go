@@ -0,0 +1,123 @@
+package eks
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/eksconfig"
+	"github.com/aws/aws-k8s-tester/internal/eks/alb"
+	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress/client"
+	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress/path"
+	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress/payload"
+	"github.com/aws/aws-k8s-tester/pkg/httputil"
+
+	"go.uber.org/zap"
+)
+
+// TestELBServices creates "LoadBalancer" type Service objects for the
+// ingress test server if not created yet, and runs target validation,
+// correctness and QPS tests against the classic ELB and NLB.
+func (md *embedded) TestELBServices() error {
+	if !md.cfg.ALBIngressController.Enable || !md.cfg.ALBIngressController.Created {
+		return fmt.Errorf("ALB Ingress Controller is not created for %q", md.cfg.ClusterName)
+	}
+	if !md.cfg.ALBIngressController.TestLoadBalancerServices {
+		return fmt.Errorf("load balancer Service test is not enabled for %q", md.cfg.ClusterName)
+	}
+	if len(md.cfg.ALBIngressController.LoadBalancerServiceToDNSName) == 0 {
+		if err := catchStopc(md.lg, md.stopc, md.elbPlugin.CreateServices); err != nil {
+			return err
+		}
+	}
+
+	failed := 0
+	rs := md.cfg.ALBIngressController.LoadBalancerServiceResults
+	for i := range rs {
+		rs[i].Errors = nil
+		md.testELBService(&rs[i])
+		if len(rs[i].Errors) > 0 {
+			failed++
+		}
+		md.cfg.ALBIngressController.LoadBalancerServiceResults = rs
+		md.cfg.Sync()
+
+		select {
+		case <-md.stopc:
+			return fmt.Errorf("load balancer Service test interrupted after %d service(s)", i+1)
+		default:
+		}
+	}
+
+	fmt.Printf("TestELBServices Result:\n\n%s\n\n", elbServiceReport(rs))
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d load balancer Service(s) failed", failed, len(rs))
+	}
+	return nil
+}
+
+// testELBService runs the tests against the load balancer of a Service,
+// and records the QPS test result and errors.
+func (md *embedded) testELBService(r *eksconfig.LoadBalancerServiceResult) {
+	md.lg.Info("testing load balancer service",
+		zap.String("name", r.Service),
+		zap.String("type", r.Type),
+		zap.String("dns-name", r.DNSName),
+	)
+	if err := md.elbPlugin.TestTargets(r.Service); err != nil {
+		r.Errors = append(r.Errors, err.Error())
+	}
+
+	ep := "http://" + r.DNSName
+	if !httputil.CheckGetVerify(
+		md.lg,
+		http.DefaultClient,
+		ep+path.Path,
+		payload.VerifyStrict,
+		30,
+		5*time.Second,
+		md.stopc) {
+		r.Errors = append(r.Errors, fmt.Sprintf("failed to HTTP Get %q", ep+path.Path))
+		return
+	}
+
+	cli, err := client.New(
+		md.lg,
+		ep,
+		md.cfg.ALBIngressController.TestServerRoutes,
+		md.cfg.ALBIngressController.TestClients,
+		md.cfg.ALBIngressController.TestClientRequests,
+	)
+	if err != nil {
+		r.Errors = append(r.Errors, err.Error())
+		return
+	}
+	// same connection settings as ALB, except HTTP/2 that
+	// requires TLS listener
+	opts := alb.TransportOptions(md.cfg)
+	opts.HTTP2 = false
+	cli.HTTPClient = client.NewHTTPClient(opts, nil)
+	cli.Verify = payload.VerifyStrict
+	if err = md.setClientLoad(cli); err != nil {
+		r.Errors = append(r.Errors, err.Error())
+		return
+	}
+	if err = md.setClientRequest(cli); err != nil {
+		r.Errors = append(r.Errors, err.Error())
+		return
+	}
+	rs := cli.Run()
+	fmt.Printf("TestELBServices QPS Result: %q\n\n%s\n\n", ep, rs.Result)
+
+	r.QPS = rs.QPS
+	r.LatencyP50 = rs.LatencyP50
+	r.LatencyP99 = rs.LatencyP99
+	r.Failures = rs.Failure
+	if int64(len(rs.Errors)) > md.cfg.ALBIngressController.TestClientErrorThreshold {
+		r.Errors = append(r.Errors, fmt.Sprintf("expected errors under threshold %d, got %v", md.cfg.ALBIngressController.TestClientErrorThreshold, rs.Errors))
+	}
+	if rs.Failure > md.cfg.ALBIngressController.TestClientErrorThreshold {
+		r.Errors = append(r.Errors, fmt.Sprintf("expected failures under threshold %d, got %d", md.cfg.ALBIngressController.TestClientErrorThreshold, rs.Failure))
+	}
+}
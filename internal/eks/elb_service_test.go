@@ -0,0 +1,26 @@
+package eks
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/eksconfig"
+)
+
+func Test_elbServiceReport(t *testing.T) {
+	rs := []eksconfig.LoadBalancerServiceResult{
+		{Service: "ingress-test-server-classic-elb", Type: "classic", ProvisionTook: "2m0s", QPS: 100, LatencyP50: 10 * time.Millisecond, LatencyP99: 50 * time.Millisecond},
+		{Service: "ingress-test-server-nlb", Type: "nlb", ProvisionTook: "3m0s", Failures: 2, Errors: []string{"expected 3 healthy targets"}},
+	}
+	report := elbServiceReport(rs)
+	for _, s := range []string{"PROVISION-TOOK", "100.000", "50ms", "[ingress-test-server-nlb] expected 3 healthy targets"} {
+		if !strings.Contains(report, s) {
+			t.Fatalf("expected %q in report:\n%s", s, report)
+		}
+	}
+	metrics := ELBServiceMetrics(rs)
+	if metrics["classic-qps"] != 100 || metrics["nlb-failures"] != 2 || metrics["nlb-errors"] != 1 {
+		t.Fatalf("unexpected metrics %v", metrics)
+	}
+}
@@ -7,5 +7,6 @@ type Plugin interface {
 	BucketForAccessLogs() string
 	BucketForTests() string
 	UploadToBucketForTests(localPath, s3Path string) error
+	DownloadAccessLogs(dir string) ([]string, error)
 	DeleteBucket(bucket string) error
 }
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
@@ -241,6 +242,65 @@ func (md *embedded) UploadToBucketForTests(localPath, s3Path string) error {
 	return err
 }
 
+// DownloadAccessLogs downloads all ALB access logs of the cluster
+// to the directory, and returns the local file paths.
+func (md *embedded) DownloadAccessLogs(dir string) (paths []string, err error) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	if err = os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	bucket := md.bucketForAccessLogs
+	var keys []string
+	err = md.s3.ListObjectsPages(
+		&s3.ListObjectsInput{
+			Bucket: aws.String(bucket),
+			Prefix: aws.String(md.cfg.ClusterName),
+		},
+		func(out *s3.ListObjectsOutput, lastPage bool) bool {
+			for _, obj := range out.Contents {
+				keys = append(keys, *obj.Key)
+			}
+			return true
+		},
+	)
+	if err != nil {
+		md.lg.Warn("failed to list access logs", zap.String("bucket", bucket), zap.Error(err))
+		return nil, err
+	}
+
+	for _, key := range keys {
+		var out *s3.GetObjectOutput
+		out, err = md.s3.GetObject(&s3.GetObjectInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(key),
+		})
+		if err != nil {
+			md.lg.Warn("failed to download access log", zap.String("bucket", bucket), zap.String("key", key), zap.Error(err))
+			return nil, err
+		}
+		var d []byte
+		d, err = ioutil.ReadAll(out.Body)
+		out.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		p := filepath.Join(dir, filepath.Base(key))
+		if err = ioutil.WriteFile(p, d, 0600); err != nil {
+			return nil, err
+		}
+		paths = append(paths, p)
+	}
+	md.lg.Info("downloaded access logs",
+		zap.String("bucket", bucket),
+		zap.String("dir", dir),
+		zap.Int("files", len(paths)),
+	)
+	return paths, nil
+}
+
 func (md *embedded) DeleteBucket(bucket string) error {
 	md.mu.Lock()
 	defer md.mu.Unlock()
@@ -26,10 +26,10 @@ func TestEmbedded(t *testing.T) {
 	}
 
 	awsCfg := &awsapi.Config{
-		Logger:         zap.NewExample(),
-		DebugAPICalls:  cfg.LogDebug,
-		Region:         cfg.AWSRegion,
-		CustomEndpoint: cfg.AWSCustomEndpoint,
+		Logger:        zap.NewExample(),
+		DebugAPICalls: cfg.LogDebug,
+		Region:        cfg.AWSRegion,
+		Endpoints:     cfg.ServiceEndpoints(),
 	}
 	ss, err := awsapi.New(awsCfg)
 	if err != nil {
@@ -1,4 +1,23 @@
 package eks
 
+import (
+	"bytes"
+	"fmt"
+)
+
 // if this changes, make sure to update "internal/ingress" for volume mounts, as well
 const awsCredentialSecretName = "aws-cred-aws-k8s-tester"
+
+// genAWSRoleConfig returns the AWS config file that assumes the role
+// with the worker node instance profile credentials, so that no
+// long-lived keys are mounted.
+func genAWSRoleConfig(roleARN, externalID string) []byte {
+	buf := new(bytes.Buffer)
+	buf.WriteString("[default]\n")
+	fmt.Fprintf(buf, "role_arn = %s\n", roleARN)
+	buf.WriteString("credential_source = Ec2InstanceMetadata\n")
+	if externalID != "" {
+		fmt.Fprintf(buf, "external_id = %s\n", externalID)
+	}
+	return buf.Bytes()
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"os"
 	"strings"
 	"time"
 
@@ -13,7 +15,7 @@ import (
 
 // TODO: use "k8s.io/client-go" with "aws-iam-authenticator"
 func (md *embedded) createAWSCredentialSecret() error {
-	if md.cfg.AWSCredentialToMountPath == "" {
+	if md.cfg.AWSCredentialToMountPath == "" && !md.cfg.AWSMountRole {
 		md.lg.Info("no AWS credentials to mount")
 		return nil
 	}
@@ -21,6 +23,23 @@ func (md *embedded) createAWSCredentialSecret() error {
 		return errors.New("cannot find KUBECONFIG")
 	}
 
+	credPath := md.cfg.AWSCredentialToMountPath
+	if md.cfg.AWSMountRole {
+		f, err := ioutil.TempFile(os.TempDir(), "aws-k8s-tester-aws-config")
+		if err != nil {
+			return err
+		}
+		_, err = f.Write(genAWSRoleConfig(md.cfg.AWSRoleARN, md.cfg.AWSRoleExternalID))
+		f.Close()
+		if err != nil {
+			os.RemoveAll(f.Name())
+			return err
+		}
+		credPath = f.Name()
+		defer os.RemoveAll(credPath)
+		md.lg.Info("mounting AWS config to assume role", zap.String("role-arn", md.cfg.AWSRoleARN))
+	}
+
 	now := time.Now().UTC()
 
 	kcfgPath := md.cfg.KubeConfigPath
@@ -37,7 +56,7 @@ func (md *embedded) createAWSCredentialSecret() error {
 			"--kubeconfig="+kcfgPath,
 			"create", "secret", "generic", awsCredentialSecretName,
 			"--namespace=kube-system",
-			fmt.Sprintf("--from-file=%s=%s", awsCredentialSecretName, md.cfg.AWSCredentialToMountPath),
+			fmt.Sprintf("--from-file=%s=%s", awsCredentialSecretName, credPath),
 		)
 		kexo, err = cmd.CombinedOutput()
 		cancel()
@@ -95,6 +95,30 @@ func (ac *awsCli) TestALBQPS() error {
 	panic("TODO")
 }
 
+func (ac *awsCli) TestALBTargetTypes() error {
+	panic("TODO")
+}
+
+func (ac *awsCli) TestALBUpgrade() error {
+	panic("TODO")
+}
+
+func (ac *awsCli) TestALBReconcileLatency() error {
+	panic("TODO")
+}
+
+func (ac *awsCli) TestALBRollingUpdate() error {
+	panic("TODO")
+}
+
+func (ac *awsCli) TestELBServices() error {
+	panic("TODO")
+}
+
+func (ac *awsCli) TestALBDistributedQPS() error {
+	panic("TODO")
+}
+
 func (ac *awsCli) TestALBMetrics() error {
 	panic("TODO")
 }
@@ -16,6 +16,7 @@ import (
 	"github.com/aws/aws-k8s-tester/internal/eks/alb"
 	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress/client"
 	"github.com/aws/aws-k8s-tester/internal/eks/alb/ingress/path"
+	"github.com/aws/aws-k8s-tester/internal/eks/elb"
 	"github.com/aws/aws-k8s-tester/internal/eks/s3"
 	"github.com/aws/aws-k8s-tester/pkg/awsapi"
 	"github.com/aws/aws-k8s-tester/pkg/fileutil"
@@ -25,6 +26,7 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/acm"
 	"github.com/aws/aws-sdk-go/service/autoscaling"
 	"github.com/aws/aws-sdk-go/service/autoscaling/autoscalingiface"
 	"github.com/aws/aws-sdk-go/service/cloudformation"
@@ -33,6 +35,7 @@ import (
 	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
 	awseks "github.com/aws/aws-sdk-go/service/eks"
 	"github.com/aws/aws-sdk-go/service/eks/eksiface"
+	awselb "github.com/aws/aws-sdk-go/service/elb"
 	"github.com/aws/aws-sdk-go/service/elbv2"
 	"github.com/aws/aws-sdk-go/service/iam"
 	"github.com/aws/aws-sdk-go/service/iam/iamiface"
@@ -56,13 +59,17 @@ type embedded struct {
 	kubectl     exec.Interface
 	kubectlPath string
 
-	ss  *session.Session
-	im  iamiface.IAMAPI
-	sts stsiface.STSAPI
-	cf  cloudformationiface.CloudFormationAPI
-	asg autoscalingiface.AutoScalingAPI
-	eks eksiface.EKSAPI
-	ec2 ec2iface.EC2API
+	ss *session.Session
+	// stats counts calls, retries and throttles of each AWS API
+	stats *awsapi.Stats
+	im    iamiface.IAMAPI
+	sts   stsiface.STSAPI
+	cf    cloudformationiface.CloudFormationAPI
+	asg   autoscalingiface.AutoScalingAPI
+	eks   eksiface.EKSAPI
+	ec2   ec2iface.EC2API
+	// recorder records AWS API calls, if "AWSAPIRecordPath" is set
+	recorder *awsapi.Recorder
 
 	ec2InstancesMu *sync.RWMutex
 	ec2Instances   []*ec2.Instance
@@ -73,6 +80,7 @@ type embedded struct {
 
 	// for plugins, sub-project implementation
 	albPlugin alb.Plugin
+	elbPlugin elb.Plugin
 
 	// TODO: add EBS (with CSI) plugin
 	// TODO: add KMS plugin
@@ -94,6 +102,7 @@ func newTesterEmbedded(cfg *eksconfig.Config) (ekstester.Tester, error) {
 		kubectl:           exec.New(),
 		ec2InstancesMu:    &sync.RWMutex{},
 		ec2InstancesLogMu: &sync.RWMutex{},
+		stats:             awsapi.NewStats(),
 	}
 	md.kubectlPath, err = md.kubectl.LookPath("kubectl")
 	if err != nil {
@@ -103,11 +112,27 @@ func newTesterEmbedded(cfg *eksconfig.Config) (ekstester.Tester, error) {
 		return nil, fmt.Errorf("cannot find 'aws-iam-authenticator' executable (%v)", err)
 	}
 
+	if cfg.AWSAPIRecordPath != "" {
+		md.recorder, err = awsapi.NewRecorder(cfg.AWSAPIRecordPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	awsCfg := &awsapi.Config{
-		Logger:         md.lg,
-		DebugAPICalls:  cfg.LogDebug,
-		Region:         cfg.AWSRegion,
-		CustomEndpoint: cfg.AWSCustomEndpoint,
+		Logger:               md.lg,
+		DebugAPICalls:        cfg.LogDebug,
+		Region:               cfg.AWSRegion,
+		Endpoints:            cfg.ServiceEndpoints(),
+		Profile:              cfg.AWSProfile,
+		RoleARN:              cfg.AWSRoleARN,
+		RoleExternalID:       cfg.AWSRoleExternalID,
+		RoleSessionName:      cfg.AWSRoleSessionName,
+		WebIdentityTokenFile: cfg.AWSWebIdentityTokenFile,
+		Recorder:             md.recorder,
+		Retry:                cfg.AWSAPIRetry,
+		ServiceRetry:         cfg.AWSAPIServiceRetry,
+		Stats:                md.stats,
 	}
 	md.ss, err = awsapi.New(awsCfg)
 	if err != nil {
@@ -129,10 +154,16 @@ func newTesterEmbedded(cfg *eksconfig.Config) (ekstester.Tester, error) {
 	md.cfg.AWSAccountID = *output.Account
 
 	if cfg.ALBIngressController.Enable {
-		md.albPlugin, err = alb.NewEmbedded(md.stopc, lg, md.cfg, md.im, md.ec2, elbv2.New(md.ss), md.s3Plugin)
+		md.albPlugin, err = alb.NewEmbedded(md.stopc, lg, md.cfg, md.im, acm.New(md.ss), md.ec2, elbv2.New(md.ss), md.s3Plugin)
 		if err != nil {
 			return nil, err
 		}
+		if cfg.ALBIngressController.TestLoadBalancerServices {
+			md.elbPlugin, err = elb.NewEmbedded(md.stopc, lg, md.cfg, awselb.New(md.ss), elbv2.New(md.ss))
+			if err != nil {
+				return nil, err
+			}
+		}
 
 		// TODO
 		// build binary
@@ -202,12 +233,7 @@ func newTesterEmbedded(cfg *eksconfig.Config) (ekstester.Tester, error) {
 			// fetch cluster information with cluster name
 			md.cfg.ClusterState.Endpoint = *co.Cluster.Endpoint
 			md.cfg.ClusterState.CA = *co.Cluster.CertificateAuthority.Data
-			if err = writeKubeConfig(
-				md.cfg.ClusterState.Endpoint,
-				md.cfg.ClusterState.CA,
-				md.cfg.ClusterName,
-				md.cfg.KubeConfigPath,
-			); err != nil {
+			if err = writeKubeConfig(md.cfg); err != nil {
 				return nil, err
 			}
 			md.lg.Info(
@@ -241,6 +267,7 @@ func (md *embedded) Up() (err error) {
 	if md.cfg.ClusterState.Status == "ACTIVE" {
 		return fmt.Errorf("%q is already %q", md.cfg.ClusterName, md.cfg.ClusterState.Status)
 	}
+	defer md.syncAWSAPIStats()
 	if md.cfg.LogAccess {
 		if err = md.s3Plugin.CreateBucketForAccessLogs(); err != nil {
 			return err
@@ -258,6 +285,11 @@ func (md *embedded) Up() (err error) {
 					md.lg.Warn("failed to delete ALB Ingress Controller security group", zap.Error(derr))
 				}
 			}
+			if md.cfg.ALBIngressController.Enable && md.cfg.ALBIngressController.EnableHTTPS {
+				if derr := md.albPlugin.DeleteCertificate(); derr != nil {
+					md.lg.Warn("failed to delete ALB HTTPS certificate", zap.Error(derr))
+				}
+			}
 			if derr := md.deleteWorkerNode(); derr != nil {
 				md.lg.Warn("failed to delete node group stack", zap.Error(derr))
 			}
@@ -320,7 +352,7 @@ func (md *embedded) Up() (err error) {
 		return err
 	}
 
-	if md.cfg.AWSCredentialToMountPath != "" {
+	if md.cfg.AWSCredentialToMountPath != "" || md.cfg.AWSMountRole {
 		if err = md.createAWSCredentialSecret(); err != nil {
 			return err
 		}
@@ -350,6 +382,11 @@ func (md *embedded) Up() (err error) {
 		if err = catchStopc(md.lg, md.stopc, md.albPlugin.CreateSecurityGroup); err != nil {
 			return err
 		}
+		if md.cfg.ALBIngressController.EnableHTTPS {
+			if err = catchStopc(md.lg, md.stopc, md.albPlugin.CreateCertificate); err != nil {
+				return err
+			}
+		}
 		if err = catchStopc(md.lg, md.stopc, md.albPlugin.CreateIngressObjects); err != nil {
 			return err
 		}
@@ -383,6 +420,14 @@ func (md *embedded) Up() (err error) {
 	return nil
 }
 
+// syncAWSAPIStats writes AWS API call statistics to the config file.
+func (md *embedded) syncAWSAPIStats() {
+	md.cfg.AWSAPIStats = awsapi.StatsSnapshot(md.lg, md.stats)
+	if err := md.cfg.Sync(); err != nil {
+		md.lg.Warn("failed to sync AWS API stats", zap.Error(err))
+	}
+}
+
 func catchStopc(lg *zap.Logger, stopc chan struct{}, run func() error) (err error) {
 	errc := make(chan error)
 	go func() {
@@ -403,6 +448,10 @@ func catchStopc(lg *zap.Logger, stopc chan struct{}, run func() error) (err erro
 func (md *embedded) Down() (err error) {
 	md.mu.Lock()
 	defer md.mu.Unlock()
+	if md.recorder != nil {
+		// deletion is the last step of the run
+		defer md.recorder.Close()
+	}
 
 	if md.cfg.ClusterState.Status == "DELETING" ||
 		md.cfg.ClusterState.Status == "FAILED" {
@@ -412,6 +461,8 @@ func (md *embedded) Down() (err error) {
 		)
 	}
 
+	defer md.syncAWSAPIStats()
+
 	now := time.Now().UTC()
 
 	if md.cfg.UploadWorkerNodeLogs {
@@ -426,6 +477,13 @@ func (md *embedded) Down() (err error) {
 
 	md.lg.Info("Down", zap.String("cluster-name", md.cfg.ClusterName))
 	var errs []string
+	if md.cfg.ALBIngressController.TestLoadBalancerServices && len(md.cfg.ALBIngressController.LoadBalancerServiceToDNSName) > 0 {
+		// delete load balancers before worker nodes are deleted
+		if err = md.elbPlugin.DeleteServices(); err != nil {
+			md.lg.Warn("failed to delete load balancer services", zap.Error(err))
+			errs = append(errs, err.Error())
+		}
+	}
 	if md.cfg.ALBIngressController.Enable && md.cfg.ALBIngressController.Created {
 		if err = md.albPlugin.DeleteIngressObjects(); err != nil {
 			md.lg.Warn("failed to delete ALB Ingress Controller ELBv2", zap.Error(err))
@@ -439,6 +497,13 @@ func (md *embedded) Down() (err error) {
 			md.lg.Warn("tried to delete ALB Ingress Controller security group", zap.Error(err))
 		}
 	}
+	if md.cfg.ALBIngressController.Enable && md.cfg.ALBIngressController.EnableHTTPS {
+		// certificate is in use until ALB listeners are deleted
+		if err = md.albPlugin.DeleteCertificate(); err != nil {
+			md.lg.Warn("failed to delete ALB HTTPS certificate", zap.Error(err))
+			errs = append(errs, err.Error())
+		}
+	}
 	if err = md.deleteWorkerNode(); err != nil {
 		md.lg.Warn("failed to delete node group stack", zap.Error(err))
 		errs = append(errs, err.Error())
@@ -564,48 +629,194 @@ func (md *embedded) LoadConfig() (eksconfig.Config, error) {
 }
 
 func (md *embedded) TestALBCorrectness() error {
-	ep := "http://" + md.cfg.ALBIngressController.ELBv2NamespaceToDNSName["default"]
+	cli, err := alb.HTTPClient(md.cfg)
+	if err != nil {
+		return err
+	}
+	ep := alb.Endpoint(md.cfg, "default")
 	if md.cfg.ALBIngressController.TestMode == "ingress-test-server" {
 		ep += path.Path
 	}
-	if !httputil.CheckGet(
+	if !httputil.CheckGetVerify(
 		md.lg,
+		cli,
 		ep,
-		strings.Repeat("0", md.cfg.ALBIngressController.TestResponseSize),
+		alb.VerifyResponse(md.cfg),
 		30,
 		5*time.Second,
 		md.stopc) {
 		return fmt.Errorf("failed to HTTP Get %q", ep)
 	}
-	return md.albPlugin.TestAWSResources()
+	if err = md.albPlugin.TestAWSResources(); err != nil {
+		return err
+	}
+	if md.cfg.ALBIngressController.EnableHTTPS {
+		if err = md.albPlugin.TestHTTPS(); err != nil {
+			return err
+		}
+	}
+	if md.cfg.ALBIngressController.TestEcho {
+		if err = md.albPlugin.TestEcho(); err != nil {
+			return err
+		}
+	}
+	if md.cfg.ALBIngressController.TestAllowedSourceCIDRs {
+		if err = md.albPlugin.TestAllowedSourceCIDRs(); err != nil {
+			return err
+		}
+	}
+	if md.cfg.ALBIngressController.TestStickiness {
+		if err = md.albPlugin.TestStickiness(); err != nil {
+			return err
+		}
+	}
+	if md.cfg.ALBIngressController.TestWebSocket {
+		if err = md.albPlugin.TestWebSocket(); err != nil {
+			return err
+		}
+	}
+	if md.cfg.ALBIngressController.TestGRPC {
+		if err = md.albPlugin.TestGRPC(); err != nil {
+			return err
+		}
+	}
+	if md.cfg.ALBIngressController.TestWeightedRouting {
+		return md.albPlugin.TestWeightedRouting()
+	}
+	return nil
+}
+
+// newALBClient creates the ingress test server client
+// that sends requests to all ALB routes.
+func (md *embedded) newALBClient(requestsN int) (cli *client.Client, err error) {
+	if shards := md.cfg.ALBIngressController.IngressShards; len(shards) > 1 {
+		// routes are sharded across multiple ALBs
+		scheme := "http://"
+		if md.cfg.ALBIngressController.EnableHTTPS {
+			scheme = "https://"
+		}
+		targets := make([]client.Target, 0, len(shards))
+		for _, shard := range shards {
+			tg := client.Target{Endpoint: scheme + shard.DNSName}
+			for i := shard.RouteStart; i < shard.RouteEnd; i++ {
+				tg.Routes = append(tg.Routes, path.Create(i))
+			}
+			targets = append(targets, tg)
+		}
+		cli, err = client.NewSharded(
+			md.lg,
+			targets,
+			md.cfg.ALBIngressController.TestClients,
+			requestsN,
+		)
+	} else {
+		cli, err = client.New(
+			md.lg,
+			alb.Endpoint(md.cfg, "default"),
+			md.cfg.ALBIngressController.TestServerRoutes,
+			md.cfg.ALBIngressController.TestClients,
+			requestsN,
+		)
+	}
+	if err != nil {
+		return nil, err
+	}
+	cli.HTTPClient, err = alb.HTTPClient(md.cfg)
+	if err != nil {
+		return nil, err
+	}
+	if md.cfg.ALBIngressController.TestMode == "ingress-test-server" {
+		// missing payload headers count as integrity errors
+		cli.Verify = alb.VerifyResponse(md.cfg)
+	}
+	return cli, nil
+}
+
+// setClientLoad configures the client to send requests
+// at the constant arrival rate (open-loop) and with the load profile,
+// if specified.
+func (md *embedded) setClientLoad(cli *client.Client) error {
+	cli.Rate = md.cfg.ALBIngressController.TestClientRate
+	cli.RateStep = md.cfg.ALBIngressController.TestClientRateStep
+	cli.RateStepInterval = time.Duration(md.cfg.ALBIngressController.TestClientRateStepSeconds) * time.Second
+	cli.BucketInterval = time.Duration(md.cfg.ALBIngressController.TestClientBucketSeconds) * time.Second
+	if md.cfg.ALBIngressController.TestClientProfile == "" {
+		return nil
+	}
+	p := &client.Profile{
+		Type:          md.cfg.ALBIngressController.TestClientProfile,
+		OpenLoop:      md.cfg.ALBIngressController.TestClientProfileOpenLoop,
+		Duration:      time.Duration(md.cfg.ALBIngressController.TestClientProfileDurationSeconds) * time.Second,
+		Start:         md.cfg.ALBIngressController.TestClientProfileStart,
+		Peak:          md.cfg.ALBIngressController.TestClientProfilePeak,
+		Steps:         md.cfg.ALBIngressController.TestClientProfileSteps,
+		SpikeStart:    time.Duration(md.cfg.ALBIngressController.TestClientProfileSpikeStartSeconds) * time.Second,
+		SpikeDuration: time.Duration(md.cfg.ALBIngressController.TestClientProfileSpikeSeconds) * time.Second,
+	}
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	cli.Profile = p
+	return nil
+}
+
+// setClientRequest configures the method, headers and body
+// of the client requests.
+func (md *embedded) setClientRequest(cli *client.Client) (err error) {
+	cli.Method = md.cfg.ALBIngressController.TestClientMethod
+	cli.Body = []byte(md.cfg.ALBIngressController.TestClientBody)
+	cli.Header, err = client.ParseHeaders(md.cfg.ALBIngressController.TestClientHeaders)
+	return err
+}
+
+// toLoadBuckets converts the client result buckets for the configuration.
+func toLoadBuckets(bs []client.Bucket) (lbs []eksconfig.LoadBucket) {
+	for _, b := range bs {
+		lbs = append(lbs, eksconfig.LoadBucket{
+			Start:      b.Start,
+			Level:      b.Level,
+			Requests:   b.Requests,
+			Success:    b.Success,
+			Failure:    b.Failure,
+			QPS:        b.QPS,
+			LatencyP50: b.LatencyP50,
+			LatencyP99: b.LatencyP99,
+		})
+	}
+	return lbs
 }
 
 func (md *embedded) TestALBQPS() error {
-	ep := "http://" + md.cfg.ALBIngressController.ELBv2NamespaceToDNSName["default"]
+	ep := alb.Endpoint(md.cfg, "default")
 
 	var rs client.TestResult
 	var rbytes []byte
 	switch md.cfg.ALBIngressController.TestMode {
 	case "ingress-test-server":
-		cli, err := client.New(
-			md.lg,
-			ep,
-			md.cfg.ALBIngressController.TestServerRoutes,
-			md.cfg.ALBIngressController.TestClients,
-			md.cfg.ALBIngressController.TestClientRequests,
-		)
+		cli, err := md.newALBClient(md.cfg.ALBIngressController.TestClientRequests)
 		if err != nil {
 			return err
 		}
+		if err = md.setClientLoad(cli); err != nil {
+			return err
+		}
+		if err = md.setClientRequest(cli); err != nil {
+			return err
+		}
 		rs = cli.Run()
 		rbytes = []byte(rs.Result)
 
 	case "nginx":
+		dur := time.Duration(md.cfg.ALBIngressController.TestScalabilityMinutes) * time.Minute
+		if md.cfg.ALBIngressController.TestClientProfileDurationSeconds > 0 {
+			// same duration as ingress test server client, for comparison
+			dur = time.Duration(md.cfg.ALBIngressController.TestClientProfileDurationSeconds) * time.Second
+		}
 		// wrk --threads 2 --connections 200 --duration 15s --latency http://127.0.0.1
 		args := []string{
 			"--threads", "2",
 			"--connections", fmt.Sprintf("%d", md.cfg.ALBIngressController.TestClients),
-			"--duration", fmt.Sprintf("%s", time.Duration(md.cfg.ALBIngressController.TestScalabilityMinutes)*time.Minute),
+			"--duration", fmt.Sprintf("%s", dur),
 			"--latency",
 			ep,
 		}
@@ -629,6 +840,12 @@ func (md *embedded) TestALBQPS() error {
 		return err
 	}
 
+	if md.cfg.ALBIngressController.TestMode == "ingress-test-server" {
+		if err := md.saveALBQPSResult(rs); err != nil {
+			return err
+		}
+	}
+
 	if md.cfg.ALBIngressController.UploadTesterLogs {
 		if err := md.uploadALBTesterLogs(); err != nil {
 			md.lg.Warn("failed to upload ALB", zap.Error(err))
@@ -638,6 +855,12 @@ func (md *embedded) TestALBQPS() error {
 	if md.cfg.ALBIngressController.TestMode == "ingress-test-server" {
 		md.cfg.ALBIngressController.TestResultQPS = rs.QPS
 		md.cfg.ALBIngressController.TestResultFailures = rs.Failure
+		md.cfg.ALBIngressController.TestResultIntegrityFailures = rs.ErrorCategories[client.ErrorCategoryIntegrity]
+		md.cfg.ALBIngressController.TestResultLatencyP50 = rs.LatencyP50
+		md.cfg.ALBIngressController.TestResultLatencyP99 = rs.LatencyP99
+		md.cfg.ALBIngressController.TestResultDropped = rs.Dropped
+		md.cfg.ALBIngressController.TestResultLate = rs.Late
+		md.cfg.ALBIngressController.TestResultBuckets = toLoadBuckets(rs.Buckets)
 	} else {
 		pv, perr := wrk.Parse(string(rbytes))
 		if perr != nil {
@@ -645,6 +868,8 @@ func (md *embedded) TestALBQPS() error {
 		}
 		md.cfg.ALBIngressController.TestResultQPS = pv.RequestsPerSec
 		md.cfg.ALBIngressController.TestResultFailures = pv.ErrorsConnect + pv.ErrorsWrite + pv.ErrorsRead + pv.ErrorsTimeout
+		md.cfg.ALBIngressController.TestResultLatencyP50 = pv.Latency50Pct
+		md.cfg.ALBIngressController.TestResultLatencyP99 = pv.Latency99Pct
 	}
 	md.cfg.Sync()
 
@@ -661,6 +886,22 @@ func (md *embedded) TestALBQPS() error {
 	return nil
 }
 
+// saveALBQPSResult writes the structured QPS test result
+// in JSON, and its per-route and per-second results in CSV.
+func (md *embedded) saveALBQPSResult(rs client.TestResult) error {
+	d, err := rs.JSON()
+	if err != nil {
+		return err
+	}
+	if err = ioutil.WriteFile(md.cfg.ALBIngressController.ScalabilityJSONOutputToUploadPath, d, 0600); err != nil {
+		return err
+	}
+	return rs.SaveCSV(
+		md.cfg.ALBIngressController.ScalabilityRoutesCSVOutputToUploadPath,
+		md.cfg.ALBIngressController.ScalabilityThroughputCSVOutputToUploadPath,
+	)
+}
+
 func (md *embedded) TestALBMetrics() error {
 	ep := "http://" + md.cfg.ALBIngressController.ELBv2NamespaceToDNSName["kube-system"] + "/metrics"
 
@@ -762,12 +1003,59 @@ func (md *embedded) uploadALBTesterLogs() (err error) {
 		if err != nil {
 			return err
 		}
+		if md.cfg.ALBIngressController.TestMode == "ingress-test-server" {
+			for _, p := range [][2]string{
+				{md.cfg.ALBIngressController.ScalabilityJSONOutputToUploadPath, md.cfg.ALBIngressController.ScalabilityJSONOutputToUploadPathBucket},
+				{md.cfg.ALBIngressController.ScalabilityRoutesCSVOutputToUploadPath, md.cfg.ALBIngressController.ScalabilityRoutesCSVOutputToUploadPathBucket},
+				{md.cfg.ALBIngressController.ScalabilityThroughputCSVOutputToUploadPath, md.cfg.ALBIngressController.ScalabilityThroughputCSVOutputToUploadPathBucket},
+			} {
+				if err = md.s3Plugin.UploadToBucketForTests(p[0], p[1]); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if md.cfg.ALBIngressController.TestDistributedClients > 0 && md.cfg.ALBIngressController.TestResultDistributedQPS > 0 {
+		err = md.s3Plugin.UploadToBucketForTests(
+			md.cfg.ALBIngressController.DistributedQPSOutputToUploadPath,
+			md.cfg.ALBIngressController.DistributedQPSOutputToUploadPathBucket,
+		)
+		if err != nil {
+			return err
+		}
 	}
 	if md.cfg.ALBIngressController.TestMetrics {
-		return md.s3Plugin.UploadToBucketForTests(
+		err = md.s3Plugin.UploadToBucketForTests(
 			md.cfg.ALBIngressController.MetricsOutputToUploadPath,
 			md.cfg.ALBIngressController.MetricsOutputToUploadPathBucket,
 		)
+		if err != nil {
+			return err
+		}
+	}
+	if md.cfg.ALBIngressController.CompareTargetTypes && len(md.cfg.ALBIngressController.TargetTypeResults) > 0 {
+		err = md.s3Plugin.UploadToBucketForTests(
+			md.cfg.ALBIngressController.TargetTypeComparisonOutputToUploadPath,
+			md.cfg.ALBIngressController.TargetTypeComparisonOutputToUploadPathBucket,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	if len(md.cfg.ALBIngressController.ReconcileLatencies) > 0 {
+		err = md.s3Plugin.UploadToBucketForTests(
+			md.cfg.ALBIngressController.ReconcileLatencyOutputToUploadPath,
+			md.cfg.ALBIngressController.ReconcileLatencyOutputToUploadPathBucket,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	if len(md.cfg.ALBIngressController.RollingUpdateResults) > 0 {
+		return md.s3Plugin.UploadToBucketForTests(
+			md.cfg.ALBIngressController.RollingUpdateOutputToUploadPath,
+			md.cfg.ALBIngressController.RollingUpdateOutputToUploadPathBucket,
+		)
 	}
 	return nil
 }
@@ -14,6 +14,14 @@ import (
 	"go.uber.org/zap"
 )
 
+var (
+	// vpcStackWait is the wait before polling the VPC stack status,
+	// since stack creation usually takes 1-minute.
+	vpcStackWait = time.Minute
+	// vpcStackPollInterval is the interval to poll the VPC stack status.
+	vpcStackPollInterval = 10 * time.Second
+)
+
 func (md *embedded) createVPC() error {
 	if md.cfg.ClusterState.CFStackVPCName == "" {
 		return errors.New("cannot create empty VPC stack")
@@ -55,13 +63,12 @@ func (md *embedded) createVPC() error {
 	md.cfg.ClusterState.StatusVPCCreated = true
 	md.cfg.Sync()
 
-	// usually take 1-minute
-	md.lg.Info("waiting for 1-minute")
+	md.lg.Info("waiting for VPC stack", zap.Duration("wait", vpcStackWait))
 	select {
 	case <-md.stopc:
 		md.lg.Info("interrupted VPC stack creation")
 		return nil
-	case <-time.After(time.Minute):
+	case <-time.After(vpcStackWait):
 	}
 
 	retryStart := time.Now().UTC()
@@ -82,7 +89,7 @@ func (md *embedded) createVPC() error {
 				zap.Error(err),
 			)
 			md.cfg.ClusterState.CFStackVPCStatus = err.Error()
-			time.Sleep(10 * time.Second)
+			time.Sleep(vpcStackPollInterval)
 			continue
 		}
 
@@ -92,6 +99,7 @@ func (md *embedded) createVPC() error {
 
 		md.cfg.ClusterState.CFStackVPCStatus = *do.Stacks[0].StackStatus
 		if isCFCreateFailed(md.cfg.ClusterState.CFStackVPCStatus) {
+			md.cfg.Sync()
 			return fmt.Errorf("failed to create %q (%q)", md.cfg.ClusterState.CFStackVPCName, md.cfg.ClusterState.CFStackVPCStatus)
 		}
 
@@ -121,7 +129,7 @@ func (md *embedded) createVPC() error {
 			zap.String("request-started", humanize.RelTime(now, time.Now().UTC(), "ago", "from now")),
 		)
 
-		time.Sleep(10 * time.Second)
+		time.Sleep(vpcStackPollInterval)
 	}
 	if err != nil {
 		md.lg.Info("failed to create VPC stack",
@@ -1,11 +1,19 @@
 package eks
 
 import (
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-k8s-tester/eksconfig"
+	"github.com/aws/aws-k8s-tester/pkg/awsapi"
+
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"go.uber.org/zap"
 )
 
 func TestEmbeddedVPCStack(t *testing.T) {
@@ -32,3 +40,58 @@ func TestEmbeddedVPCStack(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+// TestEmbeddedVPCStackRollbackReplay replays the VPC stack that is
+// rolled back during creation, without calling AWS.
+func TestEmbeddedVPCStackRollbackReplay(t *testing.T) {
+	defer func(wait, interval time.Duration) {
+		vpcStackWait, vpcStackPollInterval = wait, interval
+	}(vpcStackWait, vpcStackPollInterval)
+	vpcStackWait, vpcStackPollInterval = 0, 0
+
+	dir, err := ioutil.TempDir(os.TempDir(), "eks-vpc-replay")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	lg := zap.NewExample()
+	ss, err := awsapi.New(&awsapi.Config{
+		Logger:     lg,
+		Region:     "us-west-2",
+		ReplayPath: "testdata/create-vpc-rollback-complete.json",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := eksconfig.NewDefault()
+	cfg.ConfigPath = filepath.Join(dir, "config.yaml")
+	cfg.ClusterState = &eksconfig.ClusterState{CFStackVPCName: "test-vpc-stack"}
+	md := &embedded{
+		stopc: make(chan struct{}),
+		lg:    lg,
+		cfg:   cfg,
+		ss:    ss,
+		cf:    cloudformation.New(ss),
+	}
+
+	err = md.createVPC()
+	if err == nil || !strings.Contains(err.Error(), "ROLLBACK_COMPLETE") {
+		t.Fatalf("expected 'ROLLBACK_COMPLETE' error, got %v", err)
+	}
+	if !cfg.ClusterState.StatusVPCCreated {
+		t.Fatal("expected VPC stack creation to be recorded")
+	}
+	if cfg.ClusterState.CFStackVPCStatus != "ROLLBACK_COMPLETE" {
+		t.Fatalf("expected VPC stack status 'ROLLBACK_COMPLETE', got %q", cfg.ClusterState.CFStackVPCStatus)
+	}
+
+	// failed status is written to the config file
+	loaded, err := eksconfig.Load(cfg.ConfigPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.ClusterState.CFStackVPCStatus != "ROLLBACK_COMPLETE" {
+		t.Fatalf("expected synced VPC stack status 'ROLLBACK_COMPLETE', got %q", loaded.ClusterState.CFStackVPCStatus)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"time"
 
 	"github.com/aws/aws-k8s-tester/pkg/ctxhandler"
 
@@ -32,10 +33,20 @@ type Request struct {
 	Method    string `json:"method"`
 }
 
+// client times out requests to unreachable targets
+// (e.g. dropped by security group), instead of hanging.
+var client = &http.Client{Timeout: 15 * time.Second}
+
 func handler(ctx context.Context, w http.ResponseWriter, req *http.Request) error {
 	http.DefaultTransport.(*http.Transport).TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
 	switch req.Method {
 	case http.MethodGet:
+		// e.g. "/sidecar?target-url=http://..." forwards HTTP GET,
+		// so that it can be called through Kubernetes API server proxy
+		if u := req.URL.Query().Get("target-url"); u != "" {
+			forwardGet(w, u)
+			return nil
+		}
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("sidecar is healthy"))
 
@@ -49,21 +60,7 @@ func handler(ctx context.Context, w http.ResponseWriter, req *http.Request) erro
 
 		switch creq.Method {
 		case http.MethodGet:
-			resp, err := http.Get(creq.TargetURL)
-			if err != nil {
-				w.Write([]byte(fmt.Sprintf("HTTP GET to %q failed (%v)", creq.TargetURL, err)))
-				return nil
-			}
-			d, err := ioutil.ReadAll(resp.Body)
-			if err != nil {
-				w.Write([]byte(fmt.Sprintf("HTTP GET Read from %q failed (%v)", creq.TargetURL, err)))
-				return nil
-			}
-			resp.Body.Close()
-			_, err = w.Write(d)
-			if err != nil {
-				w.Write([]byte(fmt.Sprintf("HTTP GET Write to %q failed (%v)", creq.TargetURL, err)))
-			}
+			forwardGet(w, creq.TargetURL)
 
 		default:
 			w.Write([]byte(fmt.Sprintf("method %q is not supported", creq.Method)))
@@ -74,3 +71,21 @@ func handler(ctx context.Context, w http.ResponseWriter, req *http.Request) erro
 	}
 	return nil
 }
+
+func forwardGet(w http.ResponseWriter, u string) {
+	resp, err := client.Get(u)
+	if err != nil {
+		w.Write([]byte(fmt.Sprintf("HTTP GET to %q failed (%v)", u, err)))
+		return
+	}
+	d, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		w.Write([]byte(fmt.Sprintf("HTTP GET Read from %q failed (%v)", u, err)))
+		return
+	}
+	resp.Body.Close()
+	_, err = w.Write(d)
+	if err != nil {
+		w.Write([]byte(fmt.Sprintf("HTTP GET Write to %q failed (%v)", u, err)))
+	}
+}
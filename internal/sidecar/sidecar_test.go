@@ -8,6 +8,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 
 	"go.uber.org/zap"
@@ -44,3 +45,40 @@ func TestNewMux(t *testing.T) {
 	}
 	fmt.Println(string(d))
 }
+
+func TestNewMuxGetTargetURL(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte("hello"))
+	}))
+	defer target.Close()
+
+	p := "/sidecar"
+	mux, err := NewMux(context.Background(), zap.NewExample(), p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	tests := []struct {
+		query string
+		exp   string
+	}{
+		{"", "sidecar is healthy"},
+		{"?target-url=" + url.QueryEscape(target.URL), "hello"},
+	}
+	for i, tt := range tests {
+		rs, err := http.Get(ts.URL + p + tt.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		d, err := ioutil.ReadAll(rs.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rs.Body.Close()
+		if string(d) != tt.exp {
+			t.Fatalf("#%d: expected %q, got %q", i, tt.exp, string(d))
+		}
+	}
+}
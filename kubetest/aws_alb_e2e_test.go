@@ -93,6 +93,48 @@ var _ = Describe("EKS with ALB Ingress Controller on worker nodes", func() {
 			time.Sleep(3 * time.Second)
 		}
 
+		if cfg.ALBIngressController.CompareTargetTypes {
+			It("ALB Ingress Controller expects to serve both 'instance' and 'ip' target types", func() {
+				err := tester.TestALBTargetTypes()
+				Expect(err).ShouldNot(HaveOccurred())
+			})
+		}
+
+		if cfg.ALBIngressController.UpgradeIngressControllerImage != "" {
+			It("ALB Ingress Controller expects to upgrade without downtime", func() {
+				err := tester.TestALBUpgrade()
+				Expect(err).ShouldNot(HaveOccurred())
+			})
+		}
+
+		if cfg.ALBIngressController.TestReconcileLatencyRuns > 0 {
+			It("ALB Ingress Controller expects to reconcile new Ingress objects", func() {
+				err := tester.TestALBReconcileLatency()
+				Expect(err).ShouldNot(HaveOccurred())
+			})
+		}
+
+		if len(cfg.ALBIngressController.TestRollingUpdateDeregistrationDelays) > 0 {
+			It("ALB Ingress Controller expects to serve traffic during rolling update", func() {
+				err := tester.TestALBRollingUpdate()
+				Expect(err).ShouldNot(HaveOccurred())
+			})
+		}
+
+		if cfg.ALBIngressController.TestLoadBalancerServices {
+			It("classic ELB and NLB expect to serve ingress test server", func() {
+				err := tester.TestELBServices()
+				Expect(err).ShouldNot(HaveOccurred())
+			})
+		}
+
+		if cfg.ALBIngressController.TestDistributedClients > 0 {
+			It("ALB Ingress Controller expects to serve traffic from multiple client pods", func() {
+				err := tester.TestALBDistributedQPS()
+				Expect(err).ShouldNot(HaveOccurred())
+			})
+		}
+
 		It("ALB Ingress Controller expects to serve '/metrics'", func() {
 			err := tester.TestALBMetrics()
 			Expect(err).ShouldNot(HaveOccurred())
@@ -52,6 +52,84 @@ func (tr *tester) TestALBQPS() (err error) {
 	return err
 }
 
+func (tr *tester) TestALBTargetTypes() (err error) {
+	if _, err = tr.LoadConfig(); err != nil {
+		return err
+	}
+	_, err = tr.ctrl.Output(exec.Command(
+		tr.awsK8sTesterPath,
+		"eks",
+		"--path="+tr.cfg.ConfigPath,
+		"test", "alb", "target-types",
+	))
+	return err
+}
+
+func (tr *tester) TestALBUpgrade() (err error) {
+	if _, err = tr.LoadConfig(); err != nil {
+		return err
+	}
+	_, err = tr.ctrl.Output(exec.Command(
+		tr.awsK8sTesterPath,
+		"eks",
+		"--path="+tr.cfg.ConfigPath,
+		"test", "alb", "upgrade",
+	))
+	return err
+}
+
+func (tr *tester) TestALBReconcileLatency() (err error) {
+	if _, err = tr.LoadConfig(); err != nil {
+		return err
+	}
+	_, err = tr.ctrl.Output(exec.Command(
+		tr.awsK8sTesterPath,
+		"eks",
+		"--path="+tr.cfg.ConfigPath,
+		"test", "alb", "reconcile-latency",
+	))
+	return err
+}
+
+func (tr *tester) TestALBRollingUpdate() (err error) {
+	if _, err = tr.LoadConfig(); err != nil {
+		return err
+	}
+	_, err = tr.ctrl.Output(exec.Command(
+		tr.awsK8sTesterPath,
+		"eks",
+		"--path="+tr.cfg.ConfigPath,
+		"test", "alb", "rolling-update",
+	))
+	return err
+}
+
+func (tr *tester) TestELBServices() (err error) {
+	if _, err = tr.LoadConfig(); err != nil {
+		return err
+	}
+	_, err = tr.ctrl.Output(exec.Command(
+		tr.awsK8sTesterPath,
+		"eks",
+		"--path="+tr.cfg.ConfigPath,
+		"test", "alb", "elb-services",
+	))
+	return err
+}
+
+func (tr *tester) TestALBDistributedQPS() (err error) {
+	if _, err = tr.LoadConfig(); err != nil {
+		return err
+	}
+	_, err = tr.ctrl.Output(exec.Command(
+		tr.awsK8sTesterPath,
+		"eks",
+		"--path="+tr.cfg.ConfigPath,
+		"test", "alb", "distributed-qps",
+	))
+	return err
+}
+
 func (tr *tester) TestALBMetrics() (err error) {
 	if _, err = tr.LoadConfig(); err != nil {
 		return err
@@ -5,11 +5,16 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"time"
 
 	"github.com/aws/aws-k8s-tester/pkg/fileutil"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
 	"go.uber.org/zap"
 	"k8s.io/client-go/util/homedir"
 )
@@ -26,10 +31,58 @@ type Config struct {
 	// Each AWS Region has multiple, isolated locations known as Availability Zones.
 	Region string
 
-	// CustomEndpoint is a custom endpoint for pre-release versions of the service.
-	// TODO: support EKS testing endpoint
+	// CustomEndpoint is a custom endpoint for all services
+	// that are not found in "Endpoints".
 	// https://docs.aws.amazon.com/sdk-for-go/v1/developer-guide/configuring-sdk.html#custom-endpoint
 	CustomEndpoint string
+	// Endpoints maps each service name to its custom endpoint
+	// (e.g. "eks" to a pre-release EKS endpoint, or every service to
+	// a local AWS emulator). Services that are not found here resolve
+	// to "CustomEndpoint", if set, or to the production endpoint.
+	// See "Services" for supported service names.
+	Endpoints map[string]string
+
+	// Profile is the named profile in the shared config and credentials files.
+	// Profiles that assume a role with MFA prompt for the token code on stdin.
+	// Leave empty to use the default credential chain.
+	Profile string
+	// RoleARN is the ARN of the IAM role to assume.
+	// If "WebIdentityTokenFile" is set, the role is assumed with the web identity token.
+	// Otherwise, the role is assumed with the base credentials (e.g. "Profile").
+	// Leave empty to use the base credentials without assuming a role.
+	RoleARN string
+	// RoleExternalID is the external ID to pass when assuming "RoleARN".
+	// Required by roles that are assumable by third-party accounts.
+	RoleExternalID string
+	// RoleSessionName is the session name to use when assuming "RoleARN".
+	// If empty, a unique session name is generated.
+	RoleSessionName string
+	// WebIdentityTokenFile is the file path to the OpenID Connect token
+	// (e.g. projected service account token), used to assume "RoleARN".
+	// The file is re-read on every credential refresh.
+	WebIdentityTokenFile string
+
+	// Retry is the retry policy of all AWS API calls.
+	// Empty fields are set from "DefaultRetryConfig".
+	Retry RetryConfig
+	// ServiceRetry maps each service name to its retry policy,
+	// overriding "Retry" (e.g. more retries for "ec2" polling).
+	// Empty fields are set from "Retry".
+	// See "Services" for supported service names.
+	ServiceRetry map[string]RetryConfig
+	// Stats is optional, to collect the number of calls, retries
+	// and throttles of each AWS API.
+	Stats *Stats
+
+	// Recorder is optional, to record every AWS API request and response
+	// to its cassette file, with credentials redacted.
+	// The caller closes it at the end of the run.
+	Recorder *Recorder
+	// ReplayPath is the cassette file path to serve AWS API responses from,
+	// without sending any request over the network.
+	// Useful for deterministic unit tests with previously recorded failures.
+	// Leave empty to disable replay.
+	ReplayPath string
 }
 
 // New creates a new AWS session.
@@ -44,33 +97,37 @@ func New(cfg *Config) (*session.Session, error) {
 	if cfg.Region == "" {
 		return nil, fmt.Errorf("missing region")
 	}
+	if cfg.Recorder != nil && cfg.ReplayPath != "" {
+		return nil, errors.New("cannot record and replay at the same time")
+	}
+	if err := ValidateCredentials(cfg.Profile, cfg.RoleARN, cfg.RoleExternalID, cfg.RoleSessionName, cfg.WebIdentityTokenFile); err != nil {
+		return nil, err
+	}
 
-	// Credential is the path to the shared credentials file.
-	//
-	// If empty will look for "AWS_SHARED_CREDENTIALS_FILE" env variable. If the
-	// env value is empty will default to current user's home directory.
-	// Linux/OSX: "$HOME/.aws/credentials"
-	// Windows:   "%USERPROFILE%\.aws\credentials"
-	//
-	// See https://godoc.org/github.com/aws/aws-sdk-go/aws/credentials#SharedCredentialsProvider.
-	// See https://godoc.org/github.com/aws/aws-sdk-go/aws/session#hdr-Environment_Variables.
-	awsCredsPath := filepath.Join(homedir.HomeDir(), ".aws", "credentials")
-	if os.Getenv("AWS_SHARED_CREDENTIALS_FILE") != "" {
-		awsCredsPath = os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
-	}
-	if fileutil.Exist(awsCredsPath) {
-		cfg.Logger.Debug("found AWS cred file", zap.String("path", awsCredsPath))
-	} else {
-		cfg.Logger.Debug("cannot find AWS cred file", zap.String("path", awsCredsPath))
-		if os.Getenv("AWS_ACCESS_KEY_ID") == "" || os.Getenv("AWS_SECRET_ACCESS_KEY") == "" {
-			return nil, errors.New("cannot find AWS credentials")
+	retryCfg := cfg.Retry.withDefaults(DefaultRetryConfig)
+	if err := retryCfg.validate(); err != nil {
+		return nil, err
+	}
+	svcToRetryer := make(map[string]retryer, len(cfg.ServiceRetry))
+	for svc, rc := range cfg.ServiceRetry {
+		id, ok := serviceToEndpointsID[svc]
+		if !ok {
+			return nil, fmt.Errorf("unknown service %q for retry policy (expected one of %v)", svc, Services())
+		}
+		rc = rc.withDefaults(retryCfg)
+		if err := rc.validate(); err != nil {
+			return nil, fmt.Errorf("invalid %q retry policy (%v)", svc, err)
 		}
-		cfg.Logger.Debug("found AWS env vars")
+		if prev, ok := svcToRetryer[id]; ok && !reflect.DeepEqual(prev.cfg, rc) {
+			return nil, fmt.Errorf("conflicting %q retry policies %+v and %+v", id, prev.cfg, rc)
+		}
+		svcToRetryer[id] = newRetryer(rc)
 	}
 
 	ac := aws.Config{
 		Region:                        aws.String(cfg.Region),
 		CredentialsChainVerboseErrors: aws.Bool(true),
+		Retryer:                       newRetryer(retryCfg),
 		Logger:                        toLogger(cfg.Logger),
 	}
 	if cfg.DebugAPICalls {
@@ -82,11 +139,148 @@ func New(cfg *Config) (*session.Session, error) {
 		ac.LogLevel = &lvl
 	}
 
-	// TODO: support test endpoint
-	// https://docs.aws.amazon.com/sdk-for-go/v1/developer-guide/configuring-sdk.html#custom-endpoint
-	if cfg.CustomEndpoint != "" {
-		ac.Endpoint = aws.String(cfg.CustomEndpoint)
+	if err := ValidateEndpoints(cfg.Endpoints); err != nil {
+		return nil, err
+	}
+	if cfg.CustomEndpoint != "" || len(cfg.Endpoints) > 0 {
+		ac.EndpointResolver = newEndpointResolver(cfg.Endpoints, cfg.CustomEndpoint)
+		cfg.Logger.Info("using custom AWS endpoints",
+			zap.String("default", cfg.CustomEndpoint),
+			zap.Any("endpoints", cfg.Endpoints),
+		)
+	}
+	if _, ok := cfg.Endpoints["s3"]; ok {
+		// custom S3 endpoints (e.g. emulators) do not serve virtual-hosted buckets
+		ac.S3ForcePathStyle = aws.Bool(true)
+	}
+
+	if cfg.ReplayPath != "" {
+		c, err := LoadCassette(cfg.ReplayPath)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Logger.Info("replaying AWS API calls",
+			zap.String("path", cfg.ReplayPath),
+			zap.Int("interactions", len(c.Interactions)),
+		)
+		// requests are never sent, so credentials are never used
+		ac.Credentials = credentials.NewStaticCredentials("REPLAY", "REPLAY", "")
+		ss, err := session.NewSession(&ac)
+		if err != nil {
+			return nil, err
+		}
+		setReplayer(ss, c)
+		setStatsAndRetryers(ss, cfg.Stats, svcToRetryer)
+		return ss, nil
+	}
+
+	roleSessionName := cfg.RoleSessionName
+	if roleSessionName == "" {
+		roleSessionName = fmt.Sprintf("aws-k8s-tester-%d", time.Now().UnixNano())
 	}
 
-	return session.NewSession(&ac)
+	var ss *session.Session
+	var err error
+	switch {
+	case cfg.WebIdentityTokenFile != "":
+		if !fileutil.Exist(cfg.WebIdentityTokenFile) {
+			return nil, fmt.Errorf("cannot find web identity token file %q", cfg.WebIdentityTokenFile)
+		}
+		// AssumeRoleWithWebIdentity is not signed,
+		// so no base credentials are required
+		var anon *session.Session
+		anon, err = session.NewSession(&aws.Config{
+			Region:           aws.String(cfg.Region),
+			Credentials:      credentials.AnonymousCredentials,
+			EndpointResolver: ac.EndpointResolver,
+			Logger:           ac.Logger,
+			LogLevel:         ac.LogLevel,
+		})
+		if err != nil {
+			return nil, err
+		}
+		cfg.Logger.Info("assuming role with web identity",
+			zap.String("role-arn", cfg.RoleARN),
+			zap.String("role-session-name", roleSessionName),
+			zap.String("web-identity-token-file", cfg.WebIdentityTokenFile),
+		)
+		ac.Credentials = credentials.NewCredentials(newWebIdentityProvider(
+			sts.New(anon),
+			cfg.RoleARN,
+			roleSessionName,
+			cfg.WebIdentityTokenFile,
+		))
+		ss, err = session.NewSession(&ac)
+
+	case cfg.Profile != "":
+		cfg.Logger.Info("using AWS profile", zap.String("profile", cfg.Profile))
+		ss, err = session.NewSessionWithOptions(session.Options{
+			Config:                  ac,
+			Profile:                 cfg.Profile,
+			SharedConfigState:       session.SharedConfigEnable,
+			AssumeRoleTokenProvider: stscreds.StdinTokenProvider,
+		})
+
+	default:
+		// Credential is the path to the shared credentials file.
+		//
+		// If empty will look for "AWS_SHARED_CREDENTIALS_FILE" env variable. If the
+		// env value is empty will default to current user's home directory.
+		// Linux/OSX: "$HOME/.aws/credentials"
+		// Windows:   "%USERPROFILE%\.aws\credentials"
+		//
+		// See https://godoc.org/github.com/aws/aws-sdk-go/aws/credentials#SharedCredentialsProvider.
+		// See https://godoc.org/github.com/aws/aws-sdk-go/aws/session#hdr-Environment_Variables.
+		awsCredsPath := filepath.Join(homedir.HomeDir(), ".aws", "credentials")
+		if os.Getenv("AWS_SHARED_CREDENTIALS_FILE") != "" {
+			awsCredsPath = os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+		}
+		if fileutil.Exist(awsCredsPath) {
+			cfg.Logger.Debug("found AWS cred file", zap.String("path", awsCredsPath))
+		} else {
+			cfg.Logger.Debug("cannot find AWS cred file", zap.String("path", awsCredsPath))
+			if os.Getenv("AWS_ACCESS_KEY_ID") == "" || os.Getenv("AWS_SECRET_ACCESS_KEY") == "" {
+				return nil, errors.New("cannot find AWS credentials")
+			}
+			cfg.Logger.Debug("found AWS env vars")
+		}
+		ss, err = session.NewSession(&ac)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.RoleARN != "" && cfg.WebIdentityTokenFile == "" {
+		cfg.Logger.Info("assuming role",
+			zap.String("role-arn", cfg.RoleARN),
+			zap.String("role-session-name", roleSessionName),
+			zap.Bool("external-id", cfg.RoleExternalID != ""),
+		)
+		ac.Credentials = stscreds.NewCredentials(ss, cfg.RoleARN, func(p *stscreds.AssumeRoleProvider) {
+			p.RoleSessionName = roleSessionName
+			if cfg.RoleExternalID != "" {
+				p.ExternalID = aws.String(cfg.RoleExternalID)
+			}
+		})
+		ss, err = session.NewSession(&ac)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.Recorder != nil {
+		cfg.Logger.Info("recording AWS API calls", zap.String("path", cfg.Recorder.p))
+		setRecorder(ss, cfg.Recorder)
+	}
+	setStatsAndRetryers(ss, cfg.Stats, svcToRetryer)
+	return ss, nil
+}
+
+func setStatsAndRetryers(ss *session.Session, st *Stats, svcToRetryer map[string]retryer) {
+	if len(svcToRetryer) > 0 {
+		setServiceRetryers(ss, svcToRetryer)
+	}
+	if st != nil {
+		setStats(ss, st)
+	}
 }
@@ -0,0 +1,324 @@
+package awsapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+// Cassette is a list of recorded AWS API requests and responses.
+// Credentials are redacted before being written to disk.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a recorded AWS API request and its response.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is a recorded AWS API request.
+type RecordedRequest struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	// Action is the AWS API operation name (e.g. "DescribeStacks").
+	Action string      `json:"action,omitempty"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// RecordedResponse is a recorded AWS API response.
+type RecordedResponse struct {
+	StatusCode int         `json:"status-code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// LoadCassette loads a cassette from disk. The file is either a JSON
+// object written by "Save", or one interaction per line as recorded.
+func LoadCassette(p string) (*Cassette, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	c := &Cassette{Interactions: make([]Interaction, 0)}
+	dec := json.NewDecoder(f)
+	for {
+		var v struct {
+			Interactions []Interaction `json:"interactions"`
+			Interaction
+		}
+		err = dec.Decode(&v)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse cassette %q (%v)", p, err)
+		}
+		if v.Interactions != nil {
+			c.Interactions = append(c.Interactions, v.Interactions...)
+		} else {
+			c.Interactions = append(c.Interactions, v.Interaction)
+		}
+	}
+	return c, nil
+}
+
+// Save writes the cassette to disk.
+func (c *Cassette) Save(p string) error {
+	d, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(p, d, 0600)
+}
+
+// Recorder appends every AWS API request and response to a cassette file,
+// one interaction per line, so that long polling runs do not rewrite
+// previously recorded interactions. It is safe for concurrent use.
+type Recorder struct {
+	mu sync.Mutex
+	p  string
+	f  *os.File
+}
+
+// NewRecorder creates the cassette file to record to,
+// truncating any previous recording.
+func NewRecorder(p string) (*Recorder, error) {
+	f, err := os.OpenFile(p, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &Recorder{p: p, f: f}, nil
+}
+
+// Close closes the cassette file.
+// AWS API calls after Close are not recorded.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.f == nil {
+		return nil
+	}
+	err := r.f.Close()
+	r.f = nil
+	return err
+}
+
+// send runs after the core send handler, to record the request
+// and the response. Network errors are not recorded.
+func (r *Recorder) send(req *request.Request) {
+	if req.HTTPRequest == nil || req.HTTPResponse == nil || req.HTTPResponse.Body == nil {
+		return
+	}
+
+	var reqBody []byte
+	if req.Body != nil {
+		pos, err := req.Body.Seek(0, io.SeekCurrent)
+		if err == nil {
+			if _, err = req.Body.Seek(0, io.SeekStart); err == nil {
+				reqBody, _ = ioutil.ReadAll(req.Body)
+			}
+			req.Body.Seek(pos, io.SeekStart)
+		}
+	}
+	respBody, err := ioutil.ReadAll(req.HTTPResponse.Body)
+	req.HTTPResponse.Body.Close()
+	req.HTTPResponse.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+	if err != nil {
+		return
+	}
+
+	it := Interaction{
+		Request: RecordedRequest{
+			Method: req.HTTPRequest.Method,
+			URL:    redactURL(req.HTTPRequest.URL),
+			Action: req.Operation.Name,
+			Header: redactHeader(req.HTTPRequest.Header),
+			Body:   redactBody(string(reqBody)),
+		},
+		Response: RecordedResponse{
+			StatusCode: req.HTTPResponse.StatusCode,
+			Header:     redactHeader(req.HTTPResponse.Header),
+			Body:       redactBody(string(respBody)),
+		},
+	}
+
+	d, err := json.Marshal(it)
+	if err != nil {
+		req.Config.Logger.Log("failed to encode interaction", err)
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.f == nil {
+		return
+	}
+	if _, err = r.f.Write(append(d, '\n')); err != nil {
+		req.Config.Logger.Log("failed to record interaction", r.p, err)
+	}
+}
+
+// replayer serves recorded AWS API responses without the network.
+// Responses to the same request are served in the recorded order,
+// and the last one is repeated once all have been served
+// (e.g. polling "DescribeStacks" until the stack is created).
+type replayer struct {
+	mu     sync.Mutex
+	queues map[string][]RecordedResponse
+	last   map[string]RecordedResponse
+}
+
+func newReplayer(c *Cassette) *replayer {
+	rp := &replayer{
+		queues: make(map[string][]RecordedResponse),
+		last:   make(map[string]RecordedResponse),
+	}
+	for _, it := range c.Interactions {
+		u, err := url.Parse(it.Request.URL)
+		if err != nil {
+			continue
+		}
+		k := requestKey(it.Request.Method, u, it.Request.Action)
+		rp.queues[k] = append(rp.queues[k], it.Response)
+	}
+	return rp
+}
+
+// send replaces the core send handler, to serve the recorded response.
+func (rp *replayer) send(req *request.Request) {
+	k := requestKey(req.HTTPRequest.Method, req.HTTPRequest.URL, req.Operation.Name)
+
+	rp.mu.Lock()
+	rs, ok := rp.last[k]
+	if q := rp.queues[k]; len(q) > 0 {
+		rs, ok = q[0], true
+		rp.queues[k] = q[1:]
+		rp.last[k] = rs
+	}
+	rp.mu.Unlock()
+	if !ok {
+		req.Error = awserr.New("ReplayNotFound", fmt.Sprintf("no recorded response for %q", k), nil)
+		req.Retryable = aws.Bool(false)
+		return
+	}
+
+	header := make(http.Header, len(rs.Header))
+	for k, v := range rs.Header {
+		header[k] = v
+	}
+	req.HTTPResponse = &http.Response{
+		Status:        fmt.Sprintf("%d %s", rs.StatusCode, http.StatusText(rs.StatusCode)),
+		StatusCode:    rs.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader([]byte(rs.Body))),
+		ContentLength: int64(len(rs.Body)),
+		Request:       req.HTTPRequest,
+	}
+}
+
+// requestKey identifies requests to the same AWS API action.
+func requestKey(method string, u *url.URL, action string) string {
+	k := method + " " + u.Host + u.Path
+	if u.RawQuery != "" {
+		k += "?" + u.RawQuery
+	}
+	return k + " " + action
+}
+
+// setRecorder records all AWS API calls of the session to the cassette file.
+func setRecorder(ss *session.Session, r *Recorder) {
+	ss.Handlers.Send.PushBackNamed(request.NamedHandler{
+		Name: "awsapi.recorder",
+		Fn:   r.send,
+	})
+}
+
+// setReplayer serves all AWS API calls of the session from the cassette.
+func setReplayer(ss *session.Session, c *Cassette) {
+	ss.Handlers.Send.Clear()
+	ss.Handlers.Send.PushBackNamed(request.NamedHandler{
+		Name: "awsapi.replayer",
+		Fn:   newReplayer(c).send,
+	})
+}
+
+const redacted = "REDACTED"
+
+// redactedHeaders is the list of HTTP headers that carry credentials.
+var redactedHeaders = []string{
+	"Authorization",
+	"X-Amz-Security-Token",
+}
+
+// redactedQueries is the list of presigned URL query parameters
+// that carry credentials.
+var redactedQueries = []string{
+	"X-Amz-Credential",
+	"X-Amz-Security-Token",
+	"X-Amz-Signature",
+}
+
+func redactURL(u *url.URL) string {
+	q := u.Query()
+	found := false
+	for _, k := range redactedQueries {
+		if q.Get(k) != "" {
+			q.Set(k, redacted)
+			found = true
+		}
+	}
+	if !found {
+		return u.String()
+	}
+	copied := *u
+	copied.RawQuery = q.Encode()
+	return copied.String()
+}
+
+func redactHeader(h http.Header) http.Header {
+	copied := make(http.Header, len(h))
+	for k, v := range h {
+		copied[k] = v
+	}
+	for _, k := range redactedHeaders {
+		if copied.Get(k) != "" {
+			copied.Set(k, redacted)
+		}
+	}
+	return copied
+}
+
+var (
+	// e.g. "<SecretAccessKey>...</SecretAccessKey>" in STS responses
+	redactXML = regexp.MustCompile(`<(SecretAccessKey|SessionToken|AccessKeyId)>[^<]*</(SecretAccessKey|SessionToken|AccessKeyId)>`)
+	// e.g. "SecretAccessKey": "..." in JSON responses
+	redactJSON = regexp.MustCompile(`(?i)"(secretAccessKey|sessionToken|accessKeyId)"(\s*):(\s*)"[^"]*"`)
+	// e.g. "WebIdentityToken=..." in query protocol requests
+	redactQuery = regexp.MustCompile(`(WebIdentityToken|SerialNumber|TokenCode)=[^&]*`)
+)
+
+func redactBody(s string) string {
+	s = redactXML.ReplaceAllString(s, "<$1>"+redacted+"</$2>")
+	s = redactJSON.ReplaceAllString(s, `"$1"$2:$3"`+redacted+`"`)
+	s = redactQuery.ReplaceAllString(s, "$1="+redacted)
+	return s
+}
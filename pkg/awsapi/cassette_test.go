@@ -0,0 +1,211 @@
+package awsapi
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"go.uber.org/zap"
+)
+
+func TestRecordReplay(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte(`<AssumeRoleResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/"><AssumeRoleResult><Credentials><AccessKeyId>ASIATEST</AccessKeyId><SecretAccessKey>secret</SecretAccessKey><SessionToken>token</SessionToken></Credentials></AssumeRoleResult></AssumeRoleResponse>`))
+	}))
+	defer ts.Close()
+
+	f, err := ioutil.TempFile(os.TempDir(), "awsapi-cassette")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := f.Name()
+	f.Close()
+	defer os.RemoveAll(p)
+
+	ss, err := session.NewSession(&aws.Config{
+		Region:      aws.String("us-west-2"),
+		Endpoint:    aws.String(ts.URL),
+		Credentials: credentials.NewStaticCredentials("AKIATEST", "secret", ""),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewRecorder(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	setRecorder(ss, r)
+	var out *sts.AssumeRoleOutput
+	for i := 0; i < 2; i++ {
+		out, err = sts.New(ss).AssumeRole(&sts.AssumeRoleInput{
+			RoleArn:         aws.String("arn:aws:iam::123456789012:role/test"),
+			RoleSessionName: aws.String("test"),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if *out.Credentials.SecretAccessKey != "secret" {
+			t.Fatalf("expected recorder to pass through response, got %q", *out.Credentials.SecretAccessKey)
+		}
+	}
+
+	// no more interactions are recorded once closed
+	if err = r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = sts.New(ss).AssumeRole(&sts.AssumeRoleInput{
+		RoleArn:         aws.String("arn:aws:iam::123456789012:role/test"),
+		RoleSessionName: aws.String("test"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err = r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// interactions are appended one per line
+	d, err := ioutil.ReadFile(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(string(d), "\n"); lines != 2 {
+		t.Fatalf("expected 2 recorded lines, got %d", lines)
+	}
+	c, err := LoadCassette(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Interactions) != 2 {
+		t.Fatalf("expected 2 interactions, got %d", len(c.Interactions))
+	}
+	it := c.Interactions[0]
+	if it.Request.Action != "AssumeRole" {
+		t.Fatalf("expected action 'AssumeRole', got %q", it.Request.Action)
+	}
+	if it.Request.Header.Get("Authorization") != redacted {
+		t.Fatalf("expected redacted Authorization header, got %q", it.Request.Header.Get("Authorization"))
+	}
+	if !strings.Contains(it.Request.Body, "RoleSessionName=test") {
+		t.Fatalf("unexpected request body %q", it.Request.Body)
+	}
+	for _, s := range []string{"ASIATEST", "secret", "token"} {
+		if strings.Contains(it.Response.Body, s) {
+			t.Fatalf("expected %q to be redacted, got %q", s, it.Response.Body)
+		}
+	}
+
+	// replay serves the recorded response twice without the network
+	ts.Close()
+	ss, err = session.NewSession(&aws.Config{
+		Region:      aws.String("us-west-2"),
+		Endpoint:    aws.String(ts.URL),
+		Credentials: credentials.NewStaticCredentials("REPLAY", "REPLAY", ""),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	setReplayer(ss, c)
+	for i := 0; i < 2; i++ {
+		out, err = sts.New(ss).AssumeRole(&sts.AssumeRoleInput{
+			RoleArn:         aws.String("arn:aws:iam::123456789012:role/test"),
+			RoleSessionName: aws.String("test"),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if *out.Credentials.SecretAccessKey != redacted {
+			t.Fatalf("expected %q, got %q", redacted, *out.Credentials.SecretAccessKey)
+		}
+	}
+	if _, err = sts.New(ss).GetCallerIdentity(&sts.GetCallerIdentityInput{}); err == nil {
+		t.Fatal("expected error for unrecorded request, got nil")
+	}
+}
+
+func TestNewReplay(t *testing.T) {
+	c := &Cassette{
+		Interactions: []Interaction{
+			{
+				Request: RecordedRequest{
+					Method: http.MethodPost,
+					URL:    "https://sts.amazonaws.com/",
+					Action: "GetCallerIdentity",
+				},
+				Response: RecordedResponse{
+					StatusCode: http.StatusOK,
+					Body:       `<GetCallerIdentityResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/"><GetCallerIdentityResult><Arn>arn:aws:iam::123456789012:user/test</Arn><UserId>AIDATEST</UserId><Account>123456789012</Account></GetCallerIdentityResult></GetCallerIdentityResponse>`,
+				},
+			},
+		},
+	}
+	f, err := ioutil.TempFile(os.TempDir(), "awsapi-cassette")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := f.Name()
+	f.Close()
+	defer os.RemoveAll(p)
+	if err = c.Save(p); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = New(&Config{
+		Logger:     zap.NewExample(),
+		Region:     "us-west-2",
+		Recorder:   &Recorder{},
+		ReplayPath: p,
+	}); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	ss, err := New(&Config{
+		Logger:     zap.NewExample(),
+		Region:     "us-west-2",
+		ReplayPath: p,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := sts.New(ss).GetCallerIdentity(&sts.GetCallerIdentityInput{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *out.Account != "123456789012" {
+		t.Fatalf("expected account '123456789012', got %q", *out.Account)
+	}
+}
+
+func TestRedactURL(t *testing.T) {
+	u, err := url.Parse("https://test.s3.amazonaws.com/key?X-Amz-Algorithm=AWS4-HMAC-SHA256&X-Amz-Credential=AKIATEST%2F20181018%2Fus-west-2%2Fs3%2Faws4_request&X-Amz-Security-Token=token&X-Amz-Signature=signature")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := redactURL(u)
+	for _, v := range []string{"AKIATEST", "token", "signature"} {
+		if strings.Contains(s, v) {
+			t.Fatalf("expected %q to be redacted, got %q", v, s)
+		}
+	}
+	if !strings.Contains(s, "X-Amz-Algorithm=AWS4-HMAC-SHA256") || !strings.Contains(s, "X-Amz-Signature="+redacted) {
+		t.Fatalf("unexpected redacted URL %q", s)
+	}
+	if u.Query().Get("X-Amz-Signature") != "signature" {
+		t.Fatal("expected original URL to be unchanged")
+	}
+
+	u, err = url.Parse("https://ec2.us-west-2.amazonaws.com/?Action=DescribeInstances")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s = redactURL(u); s != "https://ec2.us-west-2.amazonaws.com/?Action=DescribeInstances" {
+		t.Fatalf("unexpected URL %q", s)
+	}
+}
@@ -0,0 +1,85 @@
+package awsapi
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/aws/aws-sdk-go/service/sts/stsiface"
+)
+
+// ValidateCredentials returns an error if the credential sources
+// cannot be used together. A web identity token requires a role ARN,
+// and does not take a profile or an external ID.
+func ValidateCredentials(profile, roleARN, roleExternalID, roleSessionName, webIdentityTokenFile string) error {
+	if webIdentityTokenFile != "" {
+		if roleARN == "" {
+			return errors.New("web identity token file requires a role ARN")
+		}
+		if profile != "" {
+			return errors.New("cannot use profile with web identity token file")
+		}
+		if roleExternalID != "" {
+			return errors.New("cannot use role external ID with web identity token file")
+		}
+	}
+	if roleARN == "" && (roleExternalID != "" || roleSessionName != "") {
+		return errors.New("role external ID and session name require a role ARN")
+	}
+	return nil
+}
+
+// webIdentityProviderName is the name of web identity credential provider.
+const webIdentityProviderName = "WebIdentityProvider"
+
+// webIdentityProvider retrieves temporary credentials by exchanging
+// a web identity token (e.g. projected service account token) for an
+// assumed role. The token file is re-read on every refresh, since the
+// token is rotated by its issuer.
+type webIdentityProvider struct {
+	credentials.Expiry
+
+	svc             stsiface.STSAPI
+	roleARN         string
+	roleSessionName string
+	tokenFile       string
+}
+
+func newWebIdentityProvider(svc stsiface.STSAPI, roleARN, roleSessionName, tokenFile string) *webIdentityProvider {
+	return &webIdentityProvider{
+		svc:             svc,
+		roleARN:         roleARN,
+		roleSessionName: roleSessionName,
+		tokenFile:       tokenFile,
+	}
+}
+
+// Retrieve implements "credentials.Provider".
+func (p *webIdentityProvider) Retrieve() (credentials.Value, error) {
+	d, err := ioutil.ReadFile(p.tokenFile)
+	if err != nil {
+		return credentials.Value{ProviderName: webIdentityProviderName}, fmt.Errorf("failed to read web identity token file %q (%v)", p.tokenFile, err)
+	}
+	out, err := p.svc.AssumeRoleWithWebIdentity(&sts.AssumeRoleWithWebIdentityInput{
+		RoleArn:          aws.String(p.roleARN),
+		RoleSessionName:  aws.String(p.roleSessionName),
+		WebIdentityToken: aws.String(strings.TrimSpace(string(d))),
+	})
+	if err != nil {
+		return credentials.Value{ProviderName: webIdentityProviderName}, err
+	}
+
+	// refresh a minute before the credentials actually expire
+	p.SetExpiration(*out.Credentials.Expiration, time.Minute)
+	return credentials.Value{
+		AccessKeyID:     *out.Credentials.AccessKeyId,
+		SecretAccessKey: *out.Credentials.SecretAccessKey,
+		SessionToken:    *out.Credentials.SessionToken,
+		ProviderName:    webIdentityProviderName,
+	}, nil
+}
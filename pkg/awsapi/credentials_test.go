@@ -0,0 +1,92 @@
+package awsapi
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"go.uber.org/zap"
+)
+
+func TestWebIdentityProvider(t *testing.T) {
+	exp := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	var form url.Values
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		req.ParseForm()
+		form = req.PostForm
+		w.Write([]byte(`<AssumeRoleWithWebIdentityResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/"><AssumeRoleWithWebIdentityResult><Credentials><AccessKeyId>ASIATEST</AccessKeyId><SecretAccessKey>secret</SecretAccessKey><SessionToken>token</SessionToken><Expiration>` + exp + `</Expiration></Credentials></AssumeRoleWithWebIdentityResult></AssumeRoleWithWebIdentityResponse>`))
+	}))
+	defer ts.Close()
+
+	f, err := ioutil.TempFile(os.TempDir(), "awsapi-token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("oidc-token\n")
+	p := f.Name()
+	f.Close()
+	defer os.RemoveAll(p)
+
+	ss, err := session.NewSession(&aws.Config{
+		Region:      aws.String("us-west-2"),
+		Endpoint:    aws.String(ts.URL),
+		Credentials: credentials.AnonymousCredentials,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	creds := credentials.NewCredentials(newWebIdentityProvider(
+		sts.New(ss),
+		"arn:aws:iam::123456789012:role/test",
+		"test-session",
+		p,
+	))
+	v, err := creds.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.AccessKeyID != "ASIATEST" || v.SecretAccessKey != "secret" || v.SessionToken != "token" {
+		t.Fatalf("unexpected credentials %+v", v)
+	}
+	if v.ProviderName != webIdentityProviderName {
+		t.Fatalf("expected provider %q, got %q", webIdentityProviderName, v.ProviderName)
+	}
+	if creds.IsExpired() {
+		t.Fatal("expected credentials to be valid")
+	}
+	if form.Get("Action") != "AssumeRoleWithWebIdentity" {
+		t.Fatalf("unexpected action %q", form.Get("Action"))
+	}
+	if form.Get("WebIdentityToken") != "oidc-token" {
+		t.Fatalf("expected token to be read from file, got %q", form.Get("WebIdentityToken"))
+	}
+	if form.Get("RoleSessionName") != "test-session" {
+		t.Fatalf("unexpected role session name %q", form.Get("RoleSessionName"))
+	}
+}
+
+func TestNewCredentialOptions(t *testing.T) {
+	tests := []Config{
+		{WebIdentityTokenFile: "token"},
+		{WebIdentityTokenFile: "token", RoleARN: "arn:aws:iam::123456789012:role/test", Profile: "dev"},
+		{WebIdentityTokenFile: "token", RoleARN: "arn:aws:iam::123456789012:role/test", RoleExternalID: "external-id"},
+		{RoleExternalID: "external-id"},
+		{RoleSessionName: "test-session"},
+		{WebIdentityTokenFile: "/does-not-exist", RoleARN: "arn:aws:iam::123456789012:role/test"},
+	}
+	for i, cfg := range tests {
+		cfg.Logger = zap.NewExample()
+		cfg.Region = "us-west-2"
+		if _, err := New(&cfg); err == nil {
+			t.Fatalf("#%d: expected error for %+v", i, cfg)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package awsapi
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/aws/aws-sdk-go/service/acm"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/eks"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/sts"
+)
+
+// serviceToEndpointsID maps each service name in "Config.Endpoints"
+// to its AWS SDK endpoint ID.
+var serviceToEndpointsID = map[string]string{
+	"acm":            acm.EndpointsID,
+	"autoscaling":    autoscaling.EndpointsID,
+	"cloudformation": cloudformation.EndpointsID,
+	"ec2":            ec2.EndpointsID,
+	"eks":            eks.EndpointsID,
+	"elb":            endpoints.ElasticloadbalancingServiceID,
+	"elbv2":          elbv2.EndpointsID,
+	"iam":            iam.EndpointsID,
+	"s3":             s3.EndpointsID,
+	"sts":            sts.EndpointsID,
+}
+
+// Services returns the service names supported in "Config.Endpoints".
+func Services() (ss []string) {
+	ss = make([]string, 0, len(serviceToEndpointsID))
+	for k := range serviceToEndpointsID {
+		ss = append(ss, k)
+	}
+	sort.Strings(ss)
+	return ss
+}
+
+// ValidateEndpoints returns an error if the endpoint map has
+// an unknown service name or a malformed endpoint URL.
+// Services sharing an endpoint ID (e.g. "elb" and "elbv2")
+// must have the same endpoint.
+func ValidateEndpoints(eps map[string]string) error {
+	idToEp := make(map[string]string, len(eps))
+	for svc, ep := range eps {
+		id, ok := serviceToEndpointsID[svc]
+		if !ok {
+			return fmt.Errorf("unknown service %q for endpoint %q (expected one of %v)", svc, ep, Services())
+		}
+		if prev, ok := idToEp[id]; ok && prev != ep {
+			return fmt.Errorf("conflicting %q endpoints %q and %q", id, prev, ep)
+		}
+		idToEp[id] = ep
+		u, err := url.Parse(ep)
+		if err != nil {
+			return fmt.Errorf("invalid %q endpoint %q (%v)", svc, ep, err)
+		}
+		if u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("invalid %q endpoint %q (expected scheme and host)", svc, ep)
+		}
+	}
+	return nil
+}
+
+// newEndpointResolver returns an endpoint resolver that resolves
+// each service to its custom endpoint in "eps", or to "defaultEp"
+// if not found. Services without any custom endpoint resolve to the
+// production endpoint. Signing region and name are kept from the
+// production endpoint, so that requests are signed the same way.
+func newEndpointResolver(eps map[string]string, defaultEp string) endpoints.Resolver {
+	idToEp := make(map[string]string, len(eps))
+	for svc, ep := range eps {
+		idToEp[serviceToEndpointsID[svc]] = ep
+	}
+	return endpoints.ResolverFunc(func(service, region string, opts ...func(*endpoints.Options)) (endpoints.ResolvedEndpoint, error) {
+		re, err := endpoints.DefaultResolver().EndpointFor(service, region, opts...)
+		if err != nil {
+			return re, err
+		}
+		if ep, ok := idToEp[service]; ok {
+			re.URL = ep
+		} else if defaultEp != "" {
+			re.URL = defaultEp
+		}
+		return re, nil
+	})
+}
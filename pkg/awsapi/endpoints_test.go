@@ -0,0 +1,115 @@
+package awsapi
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/eks"
+	"github.com/aws/aws-sdk-go/service/elb"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"go.uber.org/zap"
+)
+
+func TestValidateEndpoints(t *testing.T) {
+	tests := []struct {
+		eps map[string]string
+		ok  bool
+	}{
+		{nil, true},
+		{map[string]string{"eks": "https://eks-beta.us-west-2.amazonaws.com"}, true},
+		{map[string]string{"elbv2": "http://localhost:4566", "s3": "http://localhost:4566"}, true},
+		{map[string]string{"elb": "http://localhost:4566"}, true},
+		{map[string]string{"elb": "http://localhost:4566", "elbv2": "http://localhost:4566"}, true},
+		{map[string]string{"elb": "http://localhost:4566", "elbv2": "http://localhost:4567"}, false},
+		{map[string]string{"ecs": "http://localhost:4566"}, false},
+		{map[string]string{"ec2": "localhost"}, false},
+		{map[string]string{"ec2": "://"}, false},
+	}
+	for i, tt := range tests {
+		err := ValidateEndpoints(tt.eps)
+		if tt.ok != (err == nil) {
+			t.Fatalf("#%d: expected ok %v, got error %v", i, tt.ok, err)
+		}
+	}
+}
+
+func TestEndpoints(t *testing.T) {
+	f, err := ioutil.TempFile(os.TempDir(), "awsapi-cassette")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := f.Name()
+	f.Close()
+	defer os.RemoveAll(p)
+	if err = (&Cassette{}).Save(p); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = New(&Config{
+		Logger:     zap.NewExample(),
+		Region:     "us-west-2",
+		ReplayPath: p,
+		Endpoints:  map[string]string{"ecs": "http://localhost:4566"},
+	}); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	ss, err := New(&Config{
+		Logger:     zap.NewExample(),
+		Region:     "us-west-2",
+		ReplayPath: p,
+		Endpoints: map[string]string{
+			"eks": "https://eks-beta.us-west-2.amazonaws.com",
+			"s3":  "http://localhost:4566",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ep := eks.New(ss).Endpoint; ep != "https://eks-beta.us-west-2.amazonaws.com" {
+		t.Fatalf("unexpected EKS endpoint %q", ep)
+	}
+	if ep := ec2.New(ss).Endpoint; ep != "https://ec2.us-west-2.amazonaws.com" {
+		t.Fatalf("unexpected EC2 endpoint %q", ep)
+	}
+	if ep := cloudformation.New(ss).Endpoint; ep != "https://cloudformation.us-west-2.amazonaws.com" {
+		t.Fatalf("unexpected CloudFormation endpoint %q", ep)
+	}
+	sc := s3.New(ss)
+	if sc.Endpoint != "http://localhost:4566" {
+		t.Fatalf("unexpected S3 endpoint %q", sc.Endpoint)
+	}
+	if !*sc.Config.S3ForcePathStyle {
+		t.Fatal("expected S3 path style for custom S3 endpoint")
+	}
+
+	// every other service resolves to the default custom endpoint
+	ss, err = New(&Config{
+		Logger:         zap.NewExample(),
+		Region:         "us-west-2",
+		ReplayPath:     p,
+		CustomEndpoint: "http://localhost:4566",
+		Endpoints:      map[string]string{"sts": "https://sts.us-west-2.amazonaws.com"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ep := elbv2.New(ss).Endpoint; ep != "http://localhost:4566" {
+		t.Fatalf("unexpected ELBv2 endpoint %q", ep)
+	}
+	if ep := elb.New(ss).Endpoint; ep != "http://localhost:4566" {
+		t.Fatalf("unexpected ELB endpoint %q", ep)
+	}
+	cl := sts.New(ss)
+	if cl.Endpoint != "https://sts.us-west-2.amazonaws.com" {
+		t.Fatalf("unexpected STS endpoint %q", cl.Endpoint)
+	}
+	if cl.SigningRegion != "us-east-1" {
+		t.Fatalf("expected production signing region 'us-east-1', got %q", cl.SigningRegion)
+	}
+}
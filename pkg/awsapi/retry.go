@@ -0,0 +1,156 @@
+package awsapi
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+// RetryConfig defines the retry policy of AWS API calls.
+// Retry delays grow exponentially with jitter, so that concurrent
+// polling loops do not retry in lockstep.
+type RetryConfig struct {
+	// MaxRetries is the maximum number of retries per API call.
+	// Set to 0 to disable retries. Leave nil to use the default.
+	MaxRetries *int `json:"max-retries,omitempty"`
+	// MinDelay is the base delay to retry retryable errors (e.g. 5xx).
+	MinDelay time.Duration `json:"min-delay,omitempty"`
+	// ThrottleMinDelay is the base delay to retry throttling errors
+	// (e.g. "RequestLimitExceeded", "Throttling").
+	ThrottleMinDelay time.Duration `json:"throttle-min-delay,omitempty"`
+	// MaxDelay is the upper limit of each retry delay.
+	MaxDelay time.Duration `json:"max-delay,omitempty"`
+}
+
+// DefaultRetryConfig is the default retry policy of AWS API calls.
+var DefaultRetryConfig = RetryConfig{
+	MaxRetries:       aws.Int(10),
+	MinDelay:         50 * time.Millisecond,
+	ThrottleMinDelay: 500 * time.Millisecond,
+	MaxDelay:         30 * time.Second,
+}
+
+// withDefaults returns a copy of the retry policy,
+// with every empty field set from "def".
+func (rc RetryConfig) withDefaults(def RetryConfig) RetryConfig {
+	if rc.MaxRetries == nil {
+		rc.MaxRetries = def.MaxRetries
+	}
+	if rc.MinDelay == 0 {
+		rc.MinDelay = def.MinDelay
+	}
+	if rc.ThrottleMinDelay == 0 {
+		rc.ThrottleMinDelay = def.ThrottleMinDelay
+	}
+	if rc.MaxDelay == 0 {
+		rc.MaxDelay = def.MaxDelay
+	}
+	return rc
+}
+
+func (rc RetryConfig) validate() error {
+	if rc.MaxRetries != nil && *rc.MaxRetries < 0 {
+		return fmt.Errorf("invalid max retries %d", *rc.MaxRetries)
+	}
+	if rc.MinDelay < 0 || rc.ThrottleMinDelay < 0 || rc.MaxDelay < 0 {
+		return fmt.Errorf("invalid negative retry delay %+v", rc)
+	}
+	if rc.MinDelay > rc.MaxDelay || rc.ThrottleMinDelay > rc.MaxDelay {
+		return fmt.Errorf("min delay must not exceed max delay %v", rc.MaxDelay)
+	}
+	return nil
+}
+
+func (rc RetryConfig) String() string {
+	maxRetries := "<nil>"
+	if rc.MaxRetries != nil {
+		maxRetries = strconv.Itoa(*rc.MaxRetries)
+	}
+	return fmt.Sprintf("{MaxRetries:%s MinDelay:%v ThrottleMinDelay:%v MaxDelay:%v}", maxRetries, rc.MinDelay, rc.ThrottleMinDelay, rc.MaxDelay)
+}
+
+// retryer implements "request.Retryer" with jittered exponential backoff.
+type retryer struct {
+	client.DefaultRetryer
+	cfg RetryConfig
+}
+
+func newRetryer(cfg RetryConfig) retryer {
+	return retryer{
+		DefaultRetryer: client.DefaultRetryer{NumMaxRetries: aws.IntValue(cfg.MaxRetries)},
+		cfg:            cfg,
+	}
+}
+
+// RetryRules returns the delay before retrying the request.
+// The delay doubles on every retry, starting from "MinDelay"
+// ("ThrottleMinDelay" for throttling errors) up to "MaxDelay",
+// and then a random half of it is removed.
+// "Retry-After" from the server is honored, up to "MaxDelay".
+func (rt retryer) RetryRules(r *request.Request) time.Duration {
+	if d, ok := retryAfter(r); ok {
+		if d > rt.cfg.MaxDelay {
+			d = rt.cfg.MaxDelay
+		}
+		return d
+	}
+	base := rt.cfg.MinDelay
+	if isThrottle(r) {
+		base = rt.cfg.ThrottleMinDelay
+	}
+	d := rt.cfg.MaxDelay
+	if r.RetryCount < 32 && base<<uint(r.RetryCount) < d && base<<uint(r.RetryCount) > 0 {
+		d = base << uint(r.RetryCount)
+	}
+	if d < 2 {
+		return d
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)))
+}
+
+// isThrottle returns true if the request failed from throttling
+// (e.g. "RequestLimitExceeded", HTTP 429). Other 5xx errors
+// (e.g. 502, 503, 504) are still retried, but not as throttles.
+func isThrottle(r *request.Request) bool {
+	if r.HTTPResponse != nil && r.HTTPResponse.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	return request.IsErrorThrottle(r.Error)
+}
+
+// retryAfter returns the delay in "Retry-After" header, if any.
+func retryAfter(r *request.Request) (time.Duration, bool) {
+	if r.HTTPResponse == nil {
+		return 0, false
+	}
+	switch r.HTTPResponse.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+	default:
+		return 0, false
+	}
+	sec, err := strconv.Atoi(r.HTTPResponse.Header.Get("Retry-After"))
+	if err != nil || sec < 0 {
+		return 0, false
+	}
+	return time.Duration(sec) * time.Second, true
+}
+
+// setServiceRetryers overrides the session-wide retryer
+// for each service in "svcToRetryer".
+func setServiceRetryers(ss *session.Session, svcToRetryer map[string]retryer) {
+	ss.Handlers.Validate.PushFrontNamed(request.NamedHandler{
+		Name: "awsapi.retryer",
+		Fn: func(r *request.Request) {
+			if rt, ok := svcToRetryer[r.ClientInfo.ServiceName]; ok {
+				r.Retryer = rt
+			}
+		},
+	})
+}
@@ -0,0 +1,194 @@
+package awsapi
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"go.uber.org/zap"
+)
+
+func TestRetryRules(t *testing.T) {
+	rt := newRetryer(RetryConfig{
+		MaxRetries:       aws.Int(5),
+		MinDelay:         100 * time.Millisecond,
+		ThrottleMinDelay: 400 * time.Millisecond,
+		MaxDelay:         time.Second,
+	})
+	tests := []struct {
+		retryCount int
+		throttle   bool
+		max        time.Duration
+	}{
+		{0, false, 100 * time.Millisecond},
+		{1, false, 200 * time.Millisecond},
+		{3, false, 800 * time.Millisecond},
+		{4, false, time.Second},
+		{100, false, time.Second},
+		{0, true, 400 * time.Millisecond},
+		{1, true, 800 * time.Millisecond},
+		{2, true, time.Second},
+	}
+	for i, tt := range tests {
+		r := &request.Request{
+			RetryCount:   tt.retryCount,
+			HTTPResponse: &http.Response{StatusCode: http.StatusBadRequest},
+		}
+		if tt.throttle {
+			r.Error = awserr.New("RequestLimitExceeded", "Request limit exceeded.", nil)
+		}
+		for j := 0; j < 10; j++ {
+			d := rt.RetryRules(r)
+			if d < tt.max/2 || d > tt.max {
+				t.Fatalf("#%d: expected delay in [%v, %v], got %v", i, tt.max/2, tt.max, d)
+			}
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	rt := newRetryer(DefaultRetryConfig)
+	r := &request.Request{
+		HTTPResponse: &http.Response{
+			StatusCode: http.StatusTooManyRequests,
+			Header:     http.Header{"Retry-After": []string{"2"}},
+		},
+	}
+	if d := rt.RetryRules(r); d != 2*time.Second {
+		t.Fatalf("expected Retry-After delay %v, got %v", 2*time.Second, d)
+	}
+	r.HTTPResponse.Header.Set("Retry-After", "3600")
+	if d := rt.RetryRules(r); d != DefaultRetryConfig.MaxDelay {
+		t.Fatalf("expected Retry-After delay capped at %v, got %v", DefaultRetryConfig.MaxDelay, d)
+	}
+}
+
+func TestRetryConfigWithDefaults(t *testing.T) {
+	rc := RetryConfig{}.withDefaults(DefaultRetryConfig)
+	if aws.IntValue(rc.MaxRetries) != 10 {
+		t.Fatalf("expected default max retries 10, got %v", rc.MaxRetries)
+	}
+	// explicit zero disables retries
+	rc = RetryConfig{MaxRetries: aws.Int(0)}.withDefaults(DefaultRetryConfig)
+	if rt := newRetryer(rc); rt.MaxRetries() != 0 {
+		t.Fatalf("expected retries disabled, got %d", rt.MaxRetries())
+	}
+}
+
+func TestRetryStats(t *testing.T) {
+	throttled := Interaction{
+		Request: RecordedRequest{
+			Method: http.MethodPost,
+			URL:    "https://ec2.us-west-2.amazonaws.com/",
+			Action: "DescribeInstances",
+		},
+		Response: RecordedResponse{
+			StatusCode: http.StatusServiceUnavailable,
+			Body:       `<Response><Errors><Error><Code>RequestLimitExceeded</Code><Message>Request limit exceeded.</Message></Error></Errors><RequestID>test</RequestID></Response>`,
+		},
+	}
+	unavailable := Interaction{
+		Request: throttled.Request,
+		Response: RecordedResponse{
+			StatusCode: http.StatusServiceUnavailable,
+			Body:       `<Response><Errors><Error><Code>Unavailable</Code><Message>Service unavailable.</Message></Error></Errors><RequestID>test</RequestID></Response>`,
+		},
+	}
+	c := &Cassette{
+		Interactions: []Interaction{
+			throttled,
+			unavailable,
+			throttled,
+			{
+				Request: throttled.Request,
+				Response: RecordedResponse{
+					StatusCode: http.StatusOK,
+					Body:       `<DescribeInstancesResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/"><requestId>test</requestId><reservationSet/></DescribeInstancesResponse>`,
+				},
+			},
+			{
+				Request: RecordedRequest{
+					Method: http.MethodPost,
+					URL:    "https://sts.amazonaws.com/",
+					Action: "GetCallerIdentity",
+				},
+				Response: RecordedResponse{
+					StatusCode: http.StatusBadRequest,
+					Body:       `<ErrorResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/"><Error><Type>Sender</Type><Code>Throttling</Code><Message>Rate exceeded</Message></Error><RequestId>test</RequestId></ErrorResponse>`,
+				},
+			},
+		},
+	}
+	f, err := ioutil.TempFile(os.TempDir(), "awsapi-cassette")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := f.Name()
+	f.Close()
+	defer os.RemoveAll(p)
+	if err = c.Save(p); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = New(&Config{
+		Logger:       zap.NewExample(),
+		Region:       "us-west-2",
+		ReplayPath:   p,
+		ServiceRetry: map[string]RetryConfig{"ecs": {MaxRetries: aws.Int(1)}},
+	}); err == nil {
+		t.Fatal("expected error for unknown service, got nil")
+	}
+	if _, err = New(&Config{
+		Logger:       zap.NewExample(),
+		Region:       "us-west-2",
+		ReplayPath:   p,
+		ServiceRetry: map[string]RetryConfig{"elb": {MaxRetries: aws.Int(1)}, "elbv2": {MaxRetries: aws.Int(2)}},
+	}); err == nil {
+		t.Fatal("expected error for conflicting ELB retry policies, got nil")
+	}
+
+	st := NewStats()
+	ss, err := New(&Config{
+		Logger:     zap.NewExample(),
+		Region:     "us-west-2",
+		ReplayPath: p,
+		Retry: RetryConfig{
+			MinDelay:         time.Millisecond,
+			ThrottleMinDelay: time.Millisecond,
+			MaxDelay:         2 * time.Millisecond,
+		},
+		ServiceRetry: map[string]RetryConfig{"sts": {MaxRetries: aws.Int(1)}},
+		Stats:        st,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = ec2.New(ss).DescribeInstances(&ec2.DescribeInstancesInput{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = sts.New(ss).GetCallerIdentity(&sts.GetCallerIdentityInput{}); err == nil {
+		t.Fatal("expected throttling error, got nil")
+	}
+
+	exp := map[string]APIStats{
+		"ec2:DescribeInstances": {Requests: 1, Retries: 3, Throttles: 2, Errors: 0},
+		"sts:GetCallerIdentity": {Requests: 1, Retries: 1, Throttles: 2, Errors: 1},
+	}
+	if m := st.Snapshot(); !reflect.DeepEqual(m, exp) {
+		t.Fatalf("expected %+v, got %+v", exp, m)
+	}
+	if m := StatsSnapshot(zap.NewExample(), st); !reflect.DeepEqual(m, exp) {
+		t.Fatalf("expected %+v, got %+v", exp, m)
+	}
+	if apis := st.Throttled(); !reflect.DeepEqual(apis, []string{"ec2:DescribeInstances", "sts:GetCallerIdentity"}) {
+		t.Fatalf("unexpected throttled APIs %v", apis)
+	}
+}
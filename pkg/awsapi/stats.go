@@ -0,0 +1,126 @@
+package awsapi
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"go.uber.org/zap"
+)
+
+// APIStats is the number of calls, retries and throttles of an AWS API.
+type APIStats struct {
+	// Requests is the number of API calls, excluding retries.
+	Requests int64 `json:"requests"`
+	// Retries is the number of retried API calls.
+	Retries int64 `json:"retries"`
+	// Throttles is the number of throttling errors (e.g. "RequestLimitExceeded", HTTP 429),
+	// including the ones that were retried. Other 5xx errors are counted only in "Retries".
+	Throttles int64 `json:"throttles"`
+	// Errors is the number of API calls that failed after all retries.
+	Errors int64 `json:"errors"`
+}
+
+// Stats collects per-API statistics of AWS API calls.
+// It is safe for concurrent use.
+type Stats struct {
+	mu   sync.Mutex
+	apis map[string]*APIStats
+}
+
+// NewStats creates a new Stats.
+func NewStats() *Stats {
+	return &Stats{apis: make(map[string]*APIStats)}
+}
+
+// Snapshot returns the current statistics, keyed by
+// "[service]:[operation]" (e.g. "ec2:DescribeInstances").
+func (s *Stats) Snapshot() map[string]APIStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m := make(map[string]APIStats, len(s.apis))
+	for k, v := range s.apis {
+		m[k] = *v
+	}
+	return m
+}
+
+// Throttled returns the API names with at least one throttling error,
+// sorted by the number of throttles in descending order.
+func (s *Stats) Throttled() []string {
+	return throttled(s.Snapshot())
+}
+
+func throttled(m map[string]APIStats) (apis []string) {
+	for k, v := range m {
+		if v.Throttles > 0 {
+			apis = append(apis, k)
+		}
+	}
+	sort.Slice(apis, func(i, j int) bool {
+		if m[apis[i]].Throttles == m[apis[j]].Throttles {
+			return apis[i] < apis[j]
+		}
+		return m[apis[i]].Throttles > m[apis[j]].Throttles
+	})
+	return apis
+}
+
+// StatsSnapshot returns the current statistics of "s",
+// and warns about throttled APIs.
+func StatsSnapshot(lg *zap.Logger, s *Stats) map[string]APIStats {
+	m := s.Snapshot()
+	for _, api := range throttled(m) {
+		v := m[api]
+		lg.Warn("AWS API throttled",
+			zap.String("api", api),
+			zap.Int64("requests", v.Requests),
+			zap.Int64("retries", v.Retries),
+			zap.Int64("throttles", v.Throttles),
+			zap.Int64("errors", v.Errors),
+		)
+	}
+	return m
+}
+
+func (s *Stats) get(r *request.Request) *APIStats {
+	k := r.ClientInfo.ServiceName
+	if r.Operation != nil {
+		k += ":" + r.Operation.Name
+	}
+	v, ok := s.apis[k]
+	if !ok {
+		v = new(APIStats)
+		s.apis[k] = v
+	}
+	return v
+}
+
+// setStats records every AWS API call of the session to "s".
+func setStats(ss *session.Session, s *Stats) {
+	ss.Handlers.Retry.PushBackNamed(request.NamedHandler{
+		Name: "awsapi.stats.retry",
+		Fn: func(r *request.Request) {
+			if !isThrottle(r) {
+				return
+			}
+			s.mu.Lock()
+			s.get(r).Throttles++
+			s.mu.Unlock()
+		},
+	})
+	ss.Handlers.Complete.PushBackNamed(request.NamedHandler{
+		Name: "awsapi.stats.complete",
+		Fn: func(r *request.Request) {
+			s.mu.Lock()
+			v := s.get(r)
+			v.Requests++
+			v.Retries += int64(r.RetryCount)
+			if r.Error != nil {
+				v.Errors++
+			}
+			s.mu.Unlock()
+		},
+	})
+}
@@ -1,6 +1,7 @@
 package httputil
 
 import (
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"time"
@@ -10,13 +11,30 @@ import (
 
 // CheckGet retries until HTTP response returns the expected output.
 func CheckGet(lg *zap.Logger, u, exp string, retries int, interval time.Duration, stopc chan struct{}) bool {
+	return CheckGetWithClient(lg, http.DefaultClient, u, exp, retries, interval, stopc)
+}
+
+// CheckGetWithClient is CheckGet with a custom HTTP client
+// (e.g. to trust a self-signed certificate).
+func CheckGetWithClient(lg *zap.Logger, cli *http.Client, u, exp string, retries int, interval time.Duration, stopc chan struct{}) bool {
+	return CheckGetVerify(lg, cli, u, func(_ http.Header, d []byte) error {
+		if exp != "" && string(d) != exp {
+			return fmt.Errorf("expected %d bytes, got %d bytes", len(exp), len(d))
+		}
+		return nil
+	}, retries, interval, stopc)
+}
+
+// CheckGetVerify retries until HTTP response passes "verify"
+// (e.g. payload length and checksum headers).
+func CheckGetVerify(lg *zap.Logger, cli *http.Client, u string, verify func(http.Header, []byte) error, retries int, interval time.Duration, stopc chan struct{}) bool {
 	for retries > 0 {
 		select {
 		case <-stopc:
 			return false
 		default:
 		}
-		resp, err := http.Get(u)
+		resp, err := cli.Get(u)
 		if err != nil {
 			lg.Warn(
 				"HTTP Get failed",
@@ -29,6 +47,7 @@ func CheckGet(lg *zap.Logger, u, exp string, retries int, interval time.Duration
 		}
 
 		d, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
 		if err != nil {
 			lg.Warn(
 				"failed to read from HTTP Response",
@@ -39,14 +58,13 @@ func CheckGet(lg *zap.Logger, u, exp string, retries int, interval time.Duration
 			time.Sleep(interval)
 			continue
 		}
-		resp.Body.Close()
 
-		if exp != "" && string(d) != exp {
+		if err = verify(resp.Header, d); err != nil {
 			lg.Warn(
 				"unexpected data from HTTP Response",
 				zap.String("endpoint", u),
-				zap.Int("expected-bytes", len(exp)),
 				zap.Int("response-bytes", len(d)),
+				zap.Error(err),
 			)
 			retries--
 			time.Sleep(interval)
@@ -1,6 +1,7 @@
 package httputil
 
 import (
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -19,4 +20,16 @@ func TestCheckGet(t *testing.T) {
 	if !CheckGet(zap.NewExample(), ts.URL+"/hello", "OK", 10, time.Second, nil) {
 		t.Fatal("unexpected response")
 	}
+	if CheckGet(zap.NewExample(), ts.URL+"/hello", "NOT-OK", 1, 0, nil) {
+		t.Fatal("expected unexpected response to fail")
+	}
+	verify := func(h http.Header, d []byte) error {
+		if h.Get("Content-Length") != "2" {
+			return fmt.Errorf("unexpected Content-Length %q", h.Get("Content-Length"))
+		}
+		return nil
+	}
+	if !CheckGetVerify(zap.NewExample(), http.DefaultClient, ts.URL+"/hello", verify, 1, 0, nil) {
+		t.Fatal("expected verified response")
+	}
 }
@@ -0,0 +1,2 @@
+// Package junit implements JUnit XML and JSON test result reports.
+package junit
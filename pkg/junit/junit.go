@@ -0,0 +1,152 @@
+package junit
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Suite is a JUnit test suite.
+// Its JUnit XML output is consumed by kubetest and Testgrid.
+// See https://github.com/kubernetes/test-infra/tree/master/testgrid for more.
+type Suite struct {
+	XMLName   xml.Name `xml:"testsuite" json:"-"`
+	Name      string   `xml:"name,attr" json:"name"`
+	Tests     int      `xml:"tests,attr" json:"tests"`
+	Failures  int      `xml:"failures,attr" json:"failures"`
+	Time      float64  `xml:"time,attr" json:"time"`
+	Timestamp string   `xml:"timestamp,attr,omitempty" json:"timestamp,omitempty"`
+
+	TestCases []TestCase `xml:"testcase" json:"test-cases"`
+}
+
+// TestCase is a JUnit test case.
+type TestCase struct {
+	ClassName string `xml:"classname,attr" json:"class-name"`
+	Name      string `xml:"name,attr" json:"name"`
+	// Time is the test duration in seconds.
+	Time float64 `xml:"time,attr" json:"time"`
+	// Failure is non-nil if the test failed.
+	Failure *Failure `xml:"failure,omitempty" json:"failure,omitempty"`
+	// Properties are the test metrics, displayed by Testgrid.
+	Properties *Properties `xml:"properties,omitempty" json:"-"`
+	// Metrics maps each metric name to its value (e.g. QPS, failures).
+	Metrics map[string]float64 `xml:"-" json:"metrics,omitempty"`
+}
+
+// Failure is a JUnit test failure.
+type Failure struct {
+	Message string `xml:"message,attr" json:"message"`
+	Type    string `xml:"type,attr,omitempty" json:"type,omitempty"`
+	Output  string `xml:",chardata" json:"output,omitempty"`
+}
+
+// Properties is a list of JUnit test case properties.
+type Properties struct {
+	Property []Property `xml:"property"`
+}
+
+// Property is a JUnit test case property.
+type Property struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// NewSuite creates a new test suite.
+func NewSuite(name string) *Suite {
+	return &Suite{
+		Name:      name,
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		TestCases: make([]TestCase, 0),
+	}
+}
+
+// Add adds a test case result to the suite.
+// Non-nil error marks the test case failed.
+func (s *Suite) Add(name string, took time.Duration, err error, metrics map[string]float64) {
+	tc := TestCase{
+		ClassName: s.Name,
+		Name:      name,
+		Time:      took.Seconds(),
+		Metrics:   metrics,
+	}
+	if err != nil {
+		tc.Failure = &Failure{
+			Message: err.Error(),
+			Type:    "Failure",
+			Output:  err.Error(),
+		}
+		s.Failures++
+	}
+	if len(metrics) > 0 {
+		keys := make([]string, 0, len(metrics))
+		for k := range metrics {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		tc.Properties = &Properties{Property: make([]Property, 0, len(keys))}
+		for _, k := range keys {
+			tc.Properties.Property = append(tc.Properties.Property, Property{
+				Name:  k,
+				Value: fmt.Sprintf("%g", metrics[k]),
+			})
+		}
+	}
+	s.Tests++
+	s.Time += tc.Time
+	s.TestCases = append(s.TestCases, tc)
+}
+
+// Failed returns true if any test case has failed.
+func (s *Suite) Failed() bool {
+	return s.Failures > 0
+}
+
+// XML encodes the suite in JUnit XML.
+func (s *Suite) XML() ([]byte, error) {
+	d, err := xml.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), d...), nil
+}
+
+// JSON encodes the suite in JSON.
+func (s *Suite) JSON() ([]byte, error) {
+	return json.MarshalIndent(s, "", "  ")
+}
+
+// Save writes "junit_[name].xml" and "[name].json" to the directory.
+// The directory is created if it does not exist.
+func (s *Suite) Save(dir string) error {
+	if dir == "" {
+		return errors.New("empty artifacts directory")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	name := strings.Replace(s.Name, " ", "-", -1)
+	name = strings.Replace(name, string(filepath.Separator), "-", -1)
+
+	d, err := s.XML()
+	if err != nil {
+		return err
+	}
+	if err = ioutil.WriteFile(filepath.Join(dir, "junit_"+name+".xml"), d, 0644); err != nil {
+		return err
+	}
+
+	d, err = s.JSON()
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, name+".json"), d, 0644)
+}
@@ -0,0 +1,73 @@
+package junit
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSuite(t *testing.T) {
+	s := NewSuite("eks-alb")
+	s.Add("correctness", 3*time.Second, nil, nil)
+	s.Add("qps", 2*time.Minute, errors.New("expected QPS 20000, got 100"), map[string]float64{
+		"qps":      100,
+		"failures": 3,
+	})
+	if s.Tests != 2 {
+		t.Fatalf("expected 2 tests, got %d", s.Tests)
+	}
+	if s.Failures != 1 || !s.Failed() {
+		t.Fatalf("expected 1 failure, got %d", s.Failures)
+	}
+	if s.Time != 123 {
+		t.Fatalf("expected 123 seconds, got %f", s.Time)
+	}
+
+	d, err := s.XML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`<testsuite name="eks-alb" tests="2" failures="1" time="123"`,
+		`<testcase classname="eks-alb" name="correctness" time="3"></testcase>`,
+		`<failure message="expected QPS 20000, got 100" type="Failure">expected QPS 20000, got 100</failure>`,
+		`<property name="failures" value="3"></property>`,
+		`<property name="qps" value="100"></property>`,
+	} {
+		if !strings.Contains(string(d), want) {
+			t.Fatalf("expected %q in\n%s", want, string(d))
+		}
+	}
+
+	dir, err := ioutil.TempDir(os.TempDir(), "junit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err = s.Save(dir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(filepath.Join(dir, "junit_eks-alb.xml")); err != nil {
+		t.Fatal(err)
+	}
+	d, err = ioutil.ReadFile(filepath.Join(dir, "eks-alb.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s2 Suite
+	if err = json.Unmarshal(d, &s2); err != nil {
+		t.Fatal(err)
+	}
+	if s2.TestCases[1].Metrics["qps"] != 100 {
+		t.Fatalf("expected QPS 100, got %v", s2.TestCases[1].Metrics)
+	}
+	if s2.TestCases[1].Failure == nil {
+		t.Fatal("expected failure, got nil")
+	}
+}